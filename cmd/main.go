@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,9 +12,10 @@ import (
 
 	"github.com/sirupsen/logrus"
 
-	"file-manager/internal/adapters/localstorage"
 	"file-manager/internal/adapters/server"
+	"file-manager/internal/adapters/storagefactory"
 	"file-manager/internal/config"
+	"file-manager/internal/domain"
 	"file-manager/internal/usecases"
 )
 
@@ -21,17 +23,101 @@ import (
 // это для предотвращения бесконечной блокировки, если соединения не закрываются вовремя.
 const shutdownTimeout = 5 * time.Second
 
+// данные сборки, подставляются через -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=...".
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
+)
+
 func main() {
 	cfg := config.LoadConfig("config.yaml")
 
-	// надо убедиться, что директория существует прежде чем запускать сервер.
-	// грубо говоря, чтобы нам было куда записывать.
-	if err := os.MkdirAll(cfg.Storage.BasePath, cfg.File.DirPermissions); err != nil {
-		logrus.Fatalf("Failed to create storage directory: %v", err)
+	if cfg.Log.File != "" {
+		logFile, err := os.OpenFile(cfg.Log.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			logrus.Fatalf("Failed to open log file: %v", err)
+		}
+		defer logFile.Close()
+		logrus.SetOutput(io.MultiWriter(os.Stderr, logFile))
+	}
+
+	fileStorage, err := storagefactory.New(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to set up storage: %v", err)
+	}
+	var usecaseOpts []usecases.Option
+	if cfg.File.StripImageMetadata {
+		usecaseOpts = append(usecaseOpts, usecases.WithPostUploadHook(usecases.NewImageMetadataStripper(fileStorage)))
 	}
+	if cfg.File.WriteUploadMetadata {
+		usecaseOpts = append(usecaseOpts, usecases.WithPostUploadHook(usecases.NewUploadMetadataWriter(fileStorage)))
+	}
+	fileUsecase := usecases.NewFileManagementUseCase(fileStorage, cfg, usecaseOpts...)
 
-	fileStorage := localstorage.NewLocalStorageService(cfg.Storage.BasePath, cfg.File.DirPermissions)
-	fileUsecase := usecases.NewFileManagementUseCase(fileStorage, cfg)
+	// фоновые задачи: проверка, что том с хранилищем не отмонтировали на ходу, и распаковка входящих zip.
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	handlerOpts := []server.Option{
+		server.WithMaxConcurrentUploadsPerClient(cfg.Server.MaxConcurrentUploadsPerClient),
+		server.WithMaxConcurrentRequests(cfg.Server.MaxConcurrentRequests),
+		server.WithRateLimit(cfg.Server.RateLimit.RequestsPerSecond, cfg.Server.RateLimit.Burst),
+		server.WithUploadThrottle(cfg.Server.MaxUploadBPS, cfg.Server.UploadTimeout),
+		server.WithDownloadThrottle(cfg.Server.MaxDownloadBPS),
+		server.WithVerifyTimeout(cfg.Server.VerifyTimeout),
+		server.WithDuplicatesTimeout(cfg.Server.DuplicatesTimeout),
+		server.WithSlowOpThreshold(cfg.Server.SlowOpThreshold),
+		server.WithShareLinks(cfg.Server.ShareSecret, cfg.Server.ShareMaxTTL, cfg.Routes.Shared),
+		server.WithDeleteConfirmation(
+			cfg.Server.RequireDeleteConfirmation, cfg.Server.DeleteTokenSecret, cfg.Server.DeleteTokenTTL),
+		server.WithAdminToken(cfg.Server.AdminToken),
+		server.WithCSRFProtection(cfg.Server.CSRFProtection),
+		server.WithDownloadRoute(cfg.Routes.Download),
+		server.WithLogFile(cfg.Log.File),
+		server.WithTemplateReload(cfg.Static.TemplateReload),
+		server.WithBuildInfo(server.BuildInfo{
+			Version:    version,
+			Commit:     commit,
+			BuildDate:  buildDate,
+			APIVersion: cfg.Server.APIVersion,
+		}),
+	}
+	if cfg.Server.RedirectFolderDownload {
+		handlerOpts = append(handlerOpts, server.WithFolderDownloadRedirect(cfg.Routes.DownloadFolder))
+	}
+	if len(cfg.ACL.Rules) > 0 || cfg.ACL.Default == domain.ACLDeny {
+		acl, err := usecases.NewACL(fileUsecase, cfg.ACL)
+		if err != nil {
+			logrus.Fatalf("Invalid acl: %v", err)
+		}
+		handlerOpts = append(handlerOpts, server.WithACL(acl))
+	}
+	if cfg.Auth.Enabled() {
+		// конфиг уже провалидирован при загрузке, ошибки тут быть не должно.
+		users, err := cfg.Auth.Credentials()
+		if err != nil {
+			logrus.Fatalf("Invalid auth: %v", err)
+		}
+		handlerOpts = append(handlerOpts, server.WithAuth(users, cfg.Auth.Token, cfg.Auth.Roles))
+	}
+	if cfg.File.AutoExtractDir != "" {
+		extractor, err := usecases.NewAutoExtractor(fileUsecase)
+		if err != nil {
+			logrus.Fatalf("Invalid auto extract dir: %v", err)
+		}
+		go extractor.Run(backgroundCtx, cfg.File.AutoExtractInterval)
+	}
+	go usecases.NewChunkExpirer(fileUsecase, cfg.File.ChunkTTL).Run(backgroundCtx, cfg.File.ChunkExpiryInterval)
+	if cfg.File.TrashPurgeInterval > 0 {
+		purger := usecases.NewTrashPurger(fileUsecase, cfg.File.TrashRetention)
+		go purger.Run(backgroundCtx, cfg.File.TrashPurgeInterval)
+		handlerOpts = append(handlerOpts, server.WithTrashPurger(purger))
+	}
+	if cfg.Storage.HealthCheckInterval > 0 {
+		storageHealth := usecases.NewStorageHealth(fileStorage)
+		go storageHealth.Run(backgroundCtx, cfg.Storage.HealthCheckInterval)
+		handlerOpts = append(handlerOpts, server.WithStorageHealth(storageHealth))
+	}
 
 	handler := server.NewHandler(
 		fileUsecase,
@@ -40,29 +126,110 @@ func main() {
 		cfg.File.ForbiddenExtensions,
 		cfg.Server.MaxUploadSize,
 		cfg.Messages,
+		handlerOpts...,
 	)
 
 	// регистрация всех маршрутов, они все настроены через config.yaml.
 	// можно задать любые настройки без необходимости изменения кода.
-	http.HandleFunc(cfg.Routes.Browse, handler.Browse)
-	http.HandleFunc(cfg.Routes.BrowseAlt, handler.Browse)
-	http.HandleFunc(cfg.Routes.Upload, handler.Upload)
-	http.HandleFunc(cfg.Routes.CreateFolder, handler.CreateFolder)
-	http.HandleFunc(cfg.Routes.Delete, handler.Delete)
-	http.HandleFunc(cfg.Routes.Rename, handler.Rename)
-	http.HandleFunc(cfg.Routes.Download, handler.Download)
-	http.HandleFunc(cfg.Routes.DownloadFolder, handler.DownloadFolder)
+	// пустой маршрут пропускаем, так старый config.yaml без новых ключей не роняет сервер.
+	handleOpen := func(pattern string, h http.HandlerFunc) {
+		if pattern == "" {
+			return
+		}
+		http.HandleFunc(pattern, handler.RequireStorage(h))
+	}
+	// RequireAuth без секции auth пропускает всех.
+	handle := func(pattern string, h http.HandlerFunc) {
+		handleOpen(pattern, handler.RequireAuth(h))
+	}
+
+	handle(cfg.Routes.Browse, handler.Browse)
+	handle(cfg.Routes.BrowseAlt, handler.Browse)
+	handle(cfg.Routes.Upload, handler.Upload)
+	handle(cfg.Routes.CreateFolder, handler.CreateFolder)
+	handle(cfg.Routes.Delete, handler.Delete)
+	handle(cfg.Routes.Rename, handler.Rename)
+	handle(cfg.Routes.RenameAPI, handler.RenameAPI)
+	handle(cfg.Routes.ListAPI, handler.ListAPI)
+	handle(cfg.Routes.UploadChunk, handler.UploadChunk)
+	handle(cfg.Routes.UploadFinalize, handler.FinalizeUpload)
+	handle(cfg.Routes.StatBatch, handler.StatBatch)
+	handle(cfg.Routes.CreateTree, handler.CreateTree)
+	handle(cfg.Routes.Download, handler.Download)
+	handle(cfg.Routes.DownloadFolder, handler.DownloadFolder)
+	handle(cfg.Routes.DownloadSelection, handler.DownloadSelection)
+	handle(cfg.Routes.Archive, handler.Archive)
+	handle(cfg.Routes.Capabilities, handler.Capabilities)
+	handle(cfg.Routes.ConfirmDelete, handler.ConfirmDelete)
+	handle(cfg.Routes.Compare, handler.Compare)
+	handle(cfg.Routes.Preview, handler.Preview)
+	handle(cfg.Routes.Extract, handler.Extract)
+	handle(cfg.Routes.Info, handler.Info)
+	handle(cfg.Routes.Hash, handler.Hash)
+	handle(cfg.Routes.Recent, handler.Recent)
+	handle(cfg.Routes.Prune, handler.Prune)
+	handle(cfg.Routes.ZipManifest, handler.ZipManifest)
+	handle(cfg.Routes.Bundle, handler.Bundle)
+	handle(cfg.Routes.Ancestors, handler.Ancestors)
+	handle(cfg.Routes.Verify, handler.Verify)
+	handle(cfg.Routes.Duplicates, handler.Duplicates)
+	handle(cfg.Routes.Stats, handler.Stats)
+	handle(cfg.Routes.Tree, handler.Tree)
+	handle(cfg.Routes.Fetch, handler.Fetch)
+	handle(cfg.Routes.Swap, handler.Swap)
+	handle(cfg.Routes.Feed, handler.Feed)
+	handle(cfg.Routes.Restore, handler.Restore)
+	handle(cfg.Routes.EmptyTrash, handler.EmptyTrash)
+	handle(cfg.Routes.TrashPurge, handler.TrashPurge)
+	// по ссылке качают без учётки, доступ даёт сама подпись.
+	handleOpen(cfg.Routes.Shared, handler.Shared)
+	// логи нужны как раз когда с хранилищем беда, поэтому только админская проверка.
+	if cfg.Routes.Logs != "" {
+		http.HandleFunc(cfg.Routes.Logs, handler.RequireAdmin(handler.Logs))
+	}
+	if cfg.Routes.Sign != "" {
+		http.HandleFunc(cfg.Routes.Sign, handler.RequireAdmin(handler.Sign))
+	}
+	if cfg.Routes.AdminRebuild != "" {
+		http.HandleFunc(cfg.Routes.AdminRebuild, handler.RequireAdmin(handler.Rebuild))
+	}
+	// проба сама сообщает о недоступности, поэтому без RequireStorage.
+	if cfg.Routes.Ready != "" {
+		http.HandleFunc(cfg.Routes.Ready, handler.Ready)
+	}
+	// версия нужна и при отвалившемся хранилище: понять, какая сборка сломалась.
+	if cfg.Routes.Version != "" {
+		http.HandleFunc(cfg.Routes.Version, handler.Version)
+	}
 
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
+	// частоту проверяем снаружи: отбитый по лимиту запрос не занимает слот одновременных.
+	limited := handler.RateLimit(handler.LimitRequests(http.DefaultServeMux, cfg.Routes.Ready), cfg.Routes.Ready)
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: nil, // http.DefaultServeMux
+		Addr: addr,
+		// Recover снаружи, чтобы id запроса и перехват паники покрывали и сам лимит и лог медленных запросов.
+		Handler: handler.Recover(handler.LogSlowRequests(limited)),
+	}
+
+	if cfg.Server.TLS.Enabled {
+		// конфиг уже провалидирован при загрузке, ошибки тут быть не должно.
+		tlsConfig, err := cfg.Server.TLS.Build()
+		if err != nil {
+			logrus.Fatalf("Invalid TLS config: %v", err)
+		}
+		srv.TLSConfig = tlsConfig
 	}
 
 	// graceful shutdown.
 	go func() {
-		logrus.Infof("Server running on %s", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logrus.Infof("Server running on %s (tls: %t)", addr, cfg.Server.TLS.Enabled)
+		var err error
+		if cfg.Server.TLS.Enabled {
+			err = srv.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logrus.Fatalf("Server failed: %v", err)
 		}
 	}()
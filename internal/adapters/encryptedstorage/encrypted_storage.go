@@ -0,0 +1,351 @@
+package encryptedstorage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"file-manager/internal/domain"
+)
+
+// формат файла: magic, случайный nonce файла и чанки по chunkSize открытого текста, каждый запечатан AES-GCM
+// отдельно. nonce чанка это nonce файла с номером чанка в последних 8 байтах, поэтому читать можно с любого
+// места (Range), а не только подряд. в AAD флаг последнего чанка: обрезанный по границе чанка файл не пройдёт.
+const (
+	chunkSize   = 64 << 10
+	keySize     = 32
+	nonceSize   = 12
+	tagSize     = 16
+	sealedChunk = chunkSize + tagSize
+)
+
+var (
+	magic      = []byte("FMENC1")
+	headerSize = int64(len(magic) + nonceSize)
+
+	errNotEncrypted = errors.New("file is not in encrypted format")
+)
+
+// EncryptedStorage декоратор над FileStorage: содержимое файлов на диске зашифровано, имена и структура папок нет.
+// шифрование включается явно (storage.encryption), оно стоит процессора на каждом чтении и записи,
+// а всё, что читает файлы с диска мимо Open (сравнение, метаданные картинок), видит шифротекст.
+type EncryptedStorage struct {
+	next domain.FileStorage
+	aead cipher.AEAD
+}
+
+// NewEncryptedStorage key — 32 байта ключа AES-256.
+func NewEncryptedStorage(next domain.FileStorage, key []byte) (*EncryptedStorage, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d: %w",
+			keySize, len(key), domain.ErrInvalidParameter)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &EncryptedStorage{next: next, aead: aead}, nil
+}
+
+// ReadDirectory показывает размеры открытого текста, чтобы листинг и проверки размера не видели оверхед шифра.
+// файл не нашего формата (положили мимо сервера) отдаётся с размером как есть.
+func (s *EncryptedStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	entries, err := s.next.ReadDirectory(relPath)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if !e.Mode().IsRegular() {
+			continue
+		}
+		if size, ok := plainSize(e.Size()); ok {
+			entries[i] = plainInfo{FileInfo: e, size: size}
+		}
+	}
+	return entries, nil
+}
+
+func (s *EncryptedStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	f, err := s.next.Open(relPath)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.newReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("open encrypted '%s': %w", relPath, err)
+	}
+	return r, nil
+}
+
+// Stat с размером открытого текста, как в ReadDirectory.
+func (s *EncryptedStorage) Stat(relPath string) (os.FileInfo, error) {
+	info, err := s.next.Stat(relPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return info, err
+	}
+	if size, ok := plainSize(info.Size()); ok {
+		return plainInfo{FileInfo: info, size: size}, nil
+	}
+	return info, nil
+}
+
+// WriteFile шифрует на лету: атомарность записи остаётся за внутренним хранилищем.
+func (s *EncryptedStorage) WriteFile(relPath string, file io.Reader) error {
+	pr, pw := io.Pipe()
+	encryptErr := make(chan error, 1)
+	go func() {
+		w, err := s.newWriter(pw)
+		if err == nil {
+			if _, err = io.Copy(w, file); err == nil {
+				err = w.Close()
+			}
+		}
+		_ = pw.CloseWithError(err)
+		encryptErr <- err
+	}()
+
+	writeErr := s.next.WriteFile(relPath, pr)
+	// внутреннее хранилище могло бросить чтение на середине, тогда шифрующая горутина ждёт в Write.
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	// исходная ошибка чтения (лимит размера, обрыв клиента) важнее той, что вернуло хранилище.
+	if err := <-encryptErr; err != nil && !errors.Is(err, io.ErrClosedPipe) {
+		return err
+	}
+	return writeErr
+}
+
+func (s *EncryptedStorage) OpenWriter(relPath string) (io.WriteCloser, error) {
+	w, err := s.next.OpenWriter(relPath)
+	if err != nil {
+		return nil, err
+	}
+	return s.newWriter(w)
+}
+
+func (s *EncryptedStorage) Remove(relPath string) error {
+	return s.next.Remove(relPath)
+}
+
+func (s *EncryptedStorage) Move(oldRel, newRel string) error {
+	return s.next.Move(oldRel, newRel)
+}
+
+func (s *EncryptedStorage) CreateDirectory(relPath string) error {
+	return s.next.CreateDirectory(relPath)
+}
+
+func (s *EncryptedStorage) GetAbsolutePath(relPath string) string {
+	return s.next.GetAbsolutePath(relPath)
+}
+
+// plainSize размер открытого текста по размеру файла, false — файл не может быть нашего формата.
+func plainSize(size int64) (int64, bool) {
+	body := size - headerSize
+	if body < tagSize {
+		return 0, false
+	}
+	if rest := body % sealedChunk; rest != 0 && rest < tagSize {
+		return 0, false
+	}
+	chunks := (body + sealedChunk - 1) / sealedChunk
+	return body - chunks*tagSize, true
+}
+
+func chunkNonce(base []byte, index int64) []byte {
+	nonce := make([]byte, nonceSize)
+	copy(nonce, base)
+	counter := binary.BigEndian.Uint64(nonce[nonceSize-8:]) ^ uint64(index)
+	binary.BigEndian.PutUint64(nonce[nonceSize-8:], counter)
+	return nonce
+}
+
+func chunkAAD(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type plainInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (i plainInfo) Size() int64 { return i.size }
+
+// writer копит открытый текст и запечатывает полные чанки. последний чанк (возможно пустой) пишется на Close:
+// до него неизвестно, что данных больше не будет.
+type writer struct {
+	dst   io.WriteCloser
+	aead  cipher.AEAD
+	nonce []byte
+	index int64
+	buf   []byte
+	err   error
+}
+
+func (s *EncryptedStorage) newWriter(dst io.WriteCloser) (*writer, error) {
+	nonce := make([]byte, nonceSize)
+	// crypto/rand.Read не возвращает ошибку с go 1.24.
+	_, _ = rand.Read(nonce)
+	header := append(append([]byte{}, magic...), nonce...)
+	if _, err := dst.Write(header); err != nil {
+		_ = dst.Close()
+		return nil, err
+	}
+	return &writer{dst: dst, aead: s.aead, nonce: nonce, buf: make([]byte, 0, chunkSize+1)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	written := 0
+	for len(p) > 0 {
+		// чанк запечатывается, только когда за ним уже есть хотя бы байт: так он точно не последний.
+		if len(w.buf) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (w *writer) seal(last bool) error {
+	sealed := w.aead.Seal(nil, chunkNonce(w.nonce, w.index), w.buf, chunkAAD(last))
+	if _, err := w.dst.Write(sealed); err != nil {
+		w.err = err
+		return err
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *writer) Close() error {
+	if w.err == nil {
+		_ = w.seal(true)
+	}
+	// после ошибки записи внутренний writer сам не публикует файл, Close вернёт ту же ошибку.
+	closeErr := w.dst.Close()
+	if w.err != nil {
+		return w.err
+	}
+	return closeErr
+}
+
+// reader расшифровывает по одному чанку, чанк под текущей позицией держится в памяти.
+type reader struct {
+	src        io.ReadSeekCloser
+	aead       cipher.AEAD
+	nonce      []byte
+	size       int64
+	cipherSize int64
+	pos        int64
+	index      int64
+	plain      []byte
+	sealed     []byte
+}
+
+func (s *EncryptedStorage) newReader(src io.ReadSeekCloser) (*reader, error) {
+	cipherSize, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	size, ok := plainSize(cipherSize)
+	if !ok {
+		return nil, errNotEncrypted
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(src, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(magic)], magic) {
+		return nil, errNotEncrypted
+	}
+	return &reader{
+		src:        src,
+		aead:       s.aead,
+		nonce:      header[len(magic):],
+		size:       size,
+		cipherSize: cipherSize,
+		index:      -1,
+		sealed:     make([]byte, sealedChunk),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	index := r.pos / chunkSize
+	if index != r.index {
+		if err := r.load(index); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain[r.pos-index*chunkSize:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *reader) load(index int64) error {
+	offset := headerSize + index*sealedChunk
+	sealed := r.sealed[:min(sealedChunk, r.cipherSize-offset)]
+	if _, err := r.src.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		return err
+	}
+	last := offset+int64(len(sealed)) == r.cipherSize
+	plain, err := r.aead.Open(r.plain[:0], chunkNonce(r.nonce, index), sealed, chunkAAD(last))
+	if err != nil {
+		r.index = -1
+		return fmt.Errorf("chunk %d is corrupted or the key is wrong: %w", index, err)
+	}
+	r.plain, r.index = plain, index
+	return nil
+}
+
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.size + offset
+	default:
+		return 0, fmt.Errorf("bad whence %d", whence)
+	}
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.pos = pos
+	return pos, nil
+}
+
+func (r *reader) Close() error {
+	return r.src.Close()
+}
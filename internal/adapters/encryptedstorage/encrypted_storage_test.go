@@ -0,0 +1,191 @@
+package encryptedstorage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/adapters/localstorage"
+	"file-manager/internal/domain"
+)
+
+func newTestStorage(t *testing.T, key byte) (*EncryptedStorage, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := NewEncryptedStorage(localstorage.NewLocalStorageService(dir, 0o755), bytes.Repeat([]byte{key}, keySize))
+	require.NoError(t, err)
+	return s, dir
+}
+
+func readAll(t *testing.T, s *EncryptedStorage, relPath string) string {
+	t.Helper()
+	f, err := s.Open(relPath)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNewEncryptedStorage_KeySize(t *testing.T) {
+	_, err := NewEncryptedStorage(localstorage.NewLocalStorageService(t.TempDir(), 0o755), []byte("short"))
+	assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+}
+
+func TestEncryptedStorage_RoundTrip(t *testing.T) {
+	s, dir := newTestStorage(t, 1)
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "one byte", size: 1},
+		{name: "exactly one chunk", size: chunkSize},
+		{name: "chunk and a byte", size: chunkSize + 1},
+		{name: "several chunks", size: 3*chunkSize + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := strings.Repeat("secret data ", tt.size/12+1)[:tt.size]
+			name := strings.ReplaceAll(tt.name, " ", "_") + ".txt"
+
+			require.NoError(t, s.WriteFile(name, strings.NewReader(content)))
+
+			raw, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			if tt.size > 0 {
+				assert.NotContains(t, string(raw), "secret data", "content is not stored in plaintext")
+			}
+			assert.Equal(t, content, readAll(t, s, name))
+
+			entries, err := s.ReadDirectory("")
+			require.NoError(t, err)
+			for _, e := range entries {
+				if e.Name() == name {
+					assert.Equal(t, int64(tt.size), e.Size(), "listing shows plaintext size")
+				}
+			}
+			info, err := s.Stat(name)
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.size), info.Size(), "stat shows plaintext size")
+		})
+	}
+}
+
+func TestEncryptedStorage_OpenWriter(t *testing.T) {
+	s, _ := newTestStorage(t, 1)
+	content := strings.Repeat("x", 2*chunkSize+7)
+
+	w, err := s.OpenWriter("pushed.bin")
+	require.NoError(t, err)
+	// кусками, не совпадающими с границей чанка.
+	for rest := content; rest != ""; {
+		n := min(len(rest), 1000)
+		_, err = w.Write([]byte(rest[:n]))
+		require.NoError(t, err)
+		rest = rest[n:]
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, content, readAll(t, s, "pushed.bin"))
+}
+
+func TestEncryptedStorage_Seek(t *testing.T) {
+	s, _ := newTestStorage(t, 1)
+	content := make([]byte, 2*chunkSize+100)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	require.NoError(t, s.WriteFile("data.bin", bytes.NewReader(content)))
+
+	f, err := s.Open("data.bin")
+	require.NoError(t, err)
+	defer f.Close()
+
+	end, err := f.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), end)
+
+	// через границу чанков, как отдаёт Range.
+	from := int64(chunkSize - 10)
+	_, err = f.Seek(from, io.SeekStart)
+	require.NoError(t, err)
+	part := make([]byte, 30)
+	_, err = io.ReadFull(f, part)
+	require.NoError(t, err)
+	assert.Equal(t, content[from:from+30], part)
+}
+
+func TestEncryptedStorage_ReadFailures(t *testing.T) {
+	content := strings.Repeat("y", chunkSize+50)
+
+	tests := []struct {
+		name   string
+		mangle func(t *testing.T, file string)
+		key    byte
+	}{
+		{
+			name: "flipped byte",
+			mangle: func(t *testing.T, file string) {
+				raw, err := os.ReadFile(file)
+				require.NoError(t, err)
+				raw[len(raw)-20] ^= 0xff
+				require.NoError(t, os.WriteFile(file, raw, 0o644))
+			},
+			key: 1,
+		},
+		{
+			name: "truncated at chunk boundary",
+			mangle: func(t *testing.T, file string) {
+				require.NoError(t, os.Truncate(file, headerSize+sealedChunk))
+			},
+			key: 1,
+		},
+		{name: "wrong key", mangle: func(*testing.T, string) {}, key: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, dir := newTestStorage(t, 1)
+			require.NoError(t, writer.WriteFile("f.txt", strings.NewReader(content)))
+			tt.mangle(t, filepath.Join(dir, "f.txt"))
+
+			reader, err := NewEncryptedStorage(localstorage.NewLocalStorageService(dir, 0o755),
+				bytes.Repeat([]byte{tt.key}, keySize))
+			require.NoError(t, err)
+			f, err := reader.Open("f.txt")
+			require.NoError(t, err)
+			defer f.Close()
+
+			_, err = io.ReadAll(f)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("plaintext file", func(t *testing.T) {
+		s, dir := newTestStorage(t, 1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plain.txt"), []byte("not encrypted at all"), 0o644))
+
+		_, err := s.Open("plain.txt")
+		assert.ErrorIs(t, err, errNotEncrypted)
+	})
+}
+
+func TestEncryptedStorage_WriteFileSourceError(t *testing.T) {
+	s, dir := newTestStorage(t, 1)
+	sourceErr := errors.New("connection reset")
+
+	err := s.WriteFile("broken.txt", io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(sourceErr)))
+
+	assert.ErrorIs(t, err, sourceErr)
+	_, statErr := os.Stat(filepath.Join(dir, "broken.txt"))
+	assert.True(t, os.IsNotExist(statErr), "half-written file is not published")
+}
@@ -0,0 +1,98 @@
+package localstorage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+// atomicWriter пишет во временный файл рядом с целевым, на Close переименовывает его в целевой.
+// пока Close не вызван, целевой файл не тронут; если какая-то запись упала,
+// Close не публикует недописанный файл, а возвращает ту же ошибку.
+type atomicWriter struct {
+	tmp      *os.File
+	target   string
+	writeErr error
+	closed   bool
+	// chmod режим, который выставить перед публикацией, 0 — оставить как создан (через umask).
+	chmod os.FileMode
+}
+
+func (s *LocalStorageService) openAtomic(relPath string) (*atomicWriter, error) {
+	fullPath := s.GetAbsolutePath(relPath)
+
+	// тут я не знаю на самом деле какая практика будет лучше, но сделал так:
+	// создаем родительские директории, если они отсутствуют, чтобы поддерживать вложенные пути.
+	if err := s.mkdirAll(filepath.Dir(fullPath)); err != nil {
+		return nil, storageError(err)
+	}
+
+	tmp, err := s.createTemp(filepath.Dir(fullPath))
+	if err != nil {
+		return nil, storageError(err)
+	}
+	w := &atomicWriter{tmp: tmp, target: fullPath}
+	if s.exactPerm {
+		w.chmod = s.filePerm
+	}
+	return w, nil
+}
+
+func (w *atomicWriter) Write(p []byte) (int, error) {
+	if w.writeErr != nil {
+		return 0, w.writeErr
+	}
+	n, err := w.tmp.Write(p)
+	if err != nil {
+		w.writeErr = storageError(err)
+	}
+	return n, w.writeErr
+}
+
+// Close публикует файл. повторный Close ничего не делает.
+func (w *atomicWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if w.writeErr != nil {
+		w.abort()
+		return w.writeErr
+	}
+	w.closed = true
+
+	var err error
+	if w.chmod != 0 {
+		err = w.tmp.Chmod(w.chmod)
+	}
+	if closeErr := w.tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(w.tmp.Name(), w.target)
+	}
+	if err != nil {
+		w.removeTmp()
+	}
+	// место может кончиться и на Close, когда ФС сбрасывает отложенную запись.
+	return storageError(err)
+}
+
+// abort выбрасывает временный файл, целевой остаётся как был.
+func (w *atomicWriter) abort() {
+	if w.closed {
+		return
+	}
+	w.closed = true
+	if err := w.tmp.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		logrus.Warnf("Failed to close temp file %s: %v", w.tmp.Name(), err)
+	}
+	w.removeTmp()
+}
+
+func (w *atomicWriter) removeTmp() {
+	if err := os.Remove(w.tmp.Name()); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove temp file %s: %v", w.tmp.Name(), err)
+	}
+}
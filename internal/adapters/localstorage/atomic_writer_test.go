@@ -0,0 +1,72 @@
+package localstorage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorageService_OpenWriter(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := NewLocalStorageService(tmpDir, 0o755)
+
+	tempFiles := func(dir string) []string {
+		matches, err := filepath.Glob(filepath.Join(dir, ".upload-*"))
+		require.NoError(t, err)
+		return matches
+	}
+
+	t.Run("published on close", func(t *testing.T) {
+		w, err := service.OpenWriter("reports/daily.txt")
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("part 1, "))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("part 2"))
+		require.NoError(t, err)
+
+		_, statErr := os.Stat(filepath.Join(tmpDir, "reports", "daily.txt"))
+		assert.True(t, os.IsNotExist(statErr), "file must not be visible before Close")
+
+		require.NoError(t, w.Close())
+		require.NoError(t, w.Close(), "second Close is a no-op")
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "reports", "daily.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "part 1, part 2", string(data))
+
+		assert.Empty(t, tempFiles(filepath.Join(tmpDir, "reports")))
+	})
+
+	t.Run("overwrites existing file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "old.txt"), []byte("old"), 0o644))
+
+		w, err := service.OpenWriter("old.txt")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("new"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "old.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("failed write is not published", func(t *testing.T) {
+		w, err := service.OpenWriter("broken.txt")
+		require.NoError(t, err)
+		aw := w.(*atomicWriter)
+		// закрываю файл из-под writer, чтобы следующая запись упала.
+		require.NoError(t, aw.tmp.Close())
+
+		_, err = w.Write([]byte("data"))
+		require.Error(t, err)
+		assert.Error(t, w.Close())
+
+		assert.NoFileExists(t, filepath.Join(tmpDir, "broken.txt"))
+		assert.Empty(t, tempFiles(tmpDir))
+	})
+}
@@ -0,0 +1,18 @@
+package localstorage
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"file-manager/internal/domain"
+)
+
+// storageError помечает нехватку места как domain.ErrStorageFull, чтобы клиент отличил её от поломки сервера.
+// исходная ошибка остаётся в цепочке для логов.
+func storageError(err error) error {
+	if err != nil && errors.Is(err, syscall.ENOSPC) && !errors.Is(err, domain.ErrStorageFull) {
+		return fmt.Errorf("%w: %w", domain.ErrStorageFull, err)
+	}
+	return err
+}
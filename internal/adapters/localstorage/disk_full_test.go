@@ -0,0 +1,38 @@
+package localstorage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"file-manager/internal/domain"
+)
+
+func TestStorageError(t *testing.T) {
+	enospc := &os.PathError{Op: "write", Path: "/data/.upload-1", Err: syscall.ENOSPC}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantFull bool
+	}{
+		{"nil", nil, false},
+		{"disk full", enospc, true},
+		{"already marked", storageError(enospc), true},
+		{"other error", io.ErrUnexpectedEOF, false},
+		{"permission", &os.PathError{Op: "open", Path: "/data", Err: syscall.EACCES}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := storageError(tt.err)
+			assert.Equal(t, tt.wantFull, errors.Is(got, domain.ErrStorageFull))
+			if tt.err != nil {
+				assert.True(t, errors.Is(got, tt.err), "original error is kept")
+			}
+		})
+	}
+}
@@ -1,23 +1,42 @@
 package localstorage
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// tmpFilePrefix временный файл пишется рядом с целевым, чтобы rename был атомарным (одна ФС).
+	tmpFilePrefix = ".upload-"
+	// defaultFilePerm права файлов, если не заданы через WithFilePermissions.
+	defaultFilePerm os.FileMode = 0o644
 )
 
 type LocalStorageService struct {
-	basePath string
-	dirPerm  os.FileMode
+	basePath  string
+	dirPerm   os.FileMode
+	filePerm  os.FileMode
+	exactPerm bool
+	// allowSymlinkMove разрешает переносить симлинки, по умолчанию Move их отклоняет.
+	allowSymlinkMove bool
 }
 
-func NewLocalStorageService(basePath string, dirPerm os.FileMode) *LocalStorageService {
-	return &LocalStorageService{
+func NewLocalStorageService(basePath string, dirPerm os.FileMode, opts ...Option) *LocalStorageService {
+	s := &LocalStorageService{
 		basePath: basePath,
 		dirPerm:  dirPerm,
+		filePerm: defaultFilePerm,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *LocalStorageService) GetAbsolutePath(relPath string) string {
@@ -48,28 +67,33 @@ func (s *LocalStorageService) ReadDirectory(relPath string) ([]os.FileInfo, erro
 
 // WriteFile записывает файл в хранилище.
 // директории с нужными правами
-func (s *LocalStorageService) WriteFile(relPath string, file io.Reader) error {
-	fullPath := s.GetAbsolutePath(relPath)
-	dir := filepath.Dir(fullPath)
+// пишем во временный файл и переименовываем, так что при ошибке чтения (обрыв, неверный Content-MD5)
+// старый файл остаётся как был, а недописанный не появляется.
+func (s *LocalStorageService) Open(relPath string) (io.ReadSeekCloser, error) {
+	return os.Open(s.GetAbsolutePath(relPath))
+}
+
+func (s *LocalStorageService) Stat(relPath string) (os.FileInfo, error) {
+	return os.Stat(s.GetAbsolutePath(relPath))
+}
 
-	// тут я не знаю на самом деле какая практика будет лучше, но сделал так:
-	// создаем родительские директории, если они отсутствуют, чтобы поддерживать вложенные пути.
-	if err := os.MkdirAll(dir, s.dirPerm); err != nil {
+func (s *LocalStorageService) WriteFile(relPath string, file io.Reader) error {
+	out, err := s.openAtomic(relPath)
+	if err != nil {
 		return err
 	}
 
-	out, err := os.Create(fullPath)
-	if err != nil {
+	if _, err = io.Copy(out, file); err != nil {
+		out.abort()
 		return err
 	}
-	defer func() {
-		if closeErr := out.Close(); closeErr != nil {
-			logrus.Warnf("Failed to close file %s: %v", fullPath, closeErr)
-		}
-	}()
+	return out.Close()
+}
 
-	_, err = io.Copy(out, file)
-	return err
+// OpenWriter для тех, кто хочет писать сам (push), а не отдавать io.Reader.
+// файл появляется по relPath только после успешного Close.
+func (s *LocalStorageService) OpenWriter(relPath string) (io.WriteCloser, error) {
+	return s.openAtomic(relPath)
 }
 
 func (s *LocalStorageService) Remove(relPath string) error {
@@ -78,13 +102,21 @@ func (s *LocalStorageService) Remove(relPath string) error {
 
 // Move переименовывает файл или директорий внутри базового хранилища.
 // пустой путь отклоняется, чтобы избежать случайную потерю данных.
+// симлинк (положенный снаружи) без WithAllowSymlinkMove не переносим: перемещённая ссылка
+// с относительной целью может начать смотреть за пределы хранилища.
 func (s *LocalStorageService) Move(oldRel, newRel string) error {
 	if newRel == "" {
 		return os.ErrInvalid
 	}
-	return os.Rename(s.GetAbsolutePath(oldRel), s.GetAbsolutePath(newRel))
+	oldPath := s.GetAbsolutePath(oldRel)
+	if !s.allowSymlinkMove {
+		if info, err := os.Lstat(oldPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("move symlink '%s': %w", oldRel, domain.ErrUnsupportedOperation)
+		}
+	}
+	return os.Rename(oldPath, s.GetAbsolutePath(newRel))
 }
 
 func (s *LocalStorageService) CreateDirectory(relPath string) error {
-	return os.MkdirAll(s.GetAbsolutePath(relPath), s.dirPerm)
+	return s.mkdirAll(s.GetAbsolutePath(relPath))
 }
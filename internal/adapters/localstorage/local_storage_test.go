@@ -1,13 +1,18 @@
 package localstorage
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
 )
 
 func TestNewLocalStorageService(t *testing.T) {
@@ -19,6 +24,8 @@ func TestNewLocalStorageService(t *testing.T) {
 	assert.NotNil(t, service)
 	assert.Equal(t, basePath, service.basePath)
 	assert.Equal(t, dirPerm, service.dirPerm)
+	assert.Equal(t, defaultFilePerm, service.filePerm)
+	assert.False(t, service.exactPerm)
 }
 
 func TestLocalStorageService_GetAbsolutePath(t *testing.T) {
@@ -101,6 +108,21 @@ func TestLocalStorageService_WriteFile(t *testing.T) {
 		assert.Equal(t, testData, string(data))
 	})
 
+	t.Run("failed read keeps old file", func(t *testing.T) {
+		require.NoError(t, service.WriteFile("keep.txt", strings.NewReader("old")))
+
+		err := service.WriteFile("keep.txt", iotest.ErrReader(errors.New("connection reset")))
+		require.Error(t, err)
+
+		data, err := os.ReadFile(filepath.Join(tmpDir, "keep.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+
+		matches, err := filepath.Glob(filepath.Join(tmpDir, ".upload-*"))
+		require.NoError(t, err)
+		assert.Empty(t, matches, "temp file must be removed")
+	})
+
 	t.Run("large file", func(t *testing.T) {
 		largeData := strings.Repeat("a", 1024*1024) // 1MB
 		reader := strings.NewReader(largeData)
@@ -114,6 +136,47 @@ func TestLocalStorageService_WriteFile(t *testing.T) {
 	})
 }
 
+func TestLocalStorageService_Open(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := NewLocalStorageService(tmpDir, 0o755)
+	require.NoError(t, service.WriteFile("dir/file.txt", strings.NewReader("hello world")))
+
+	t.Run("reads and seeks", func(t *testing.T) {
+		f, err := service.Open("dir/file.txt")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.Seek(6, io.SeekStart)
+		require.NoError(t, err)
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "world", string(data))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := service.Open("dir/nope.txt")
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestLocalStorageService_Stat(t *testing.T) {
+	tmpDir := t.TempDir()
+	service := NewLocalStorageService(tmpDir, 0o755)
+	require.NoError(t, service.WriteFile("dir/file.txt", strings.NewReader("hello world")))
+
+	info, err := service.Stat("dir/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", info.Name())
+	assert.Equal(t, int64(11), info.Size())
+
+	info, err = service.Stat("dir")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	_, err = service.Stat("dir/nope.txt")
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestLocalStorageService_Remove(t *testing.T) {
 	tmpDir := t.TempDir()
 	service := NewLocalStorageService(tmpDir, 0o755)
@@ -185,6 +248,41 @@ func TestLocalStorageService_Move(t *testing.T) {
 	})
 }
 
+func TestLocalStorageService_Move_Symlink(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "target.txt"), []byte("content"), 0o644))
+	if err := os.Symlink("target.txt", filepath.Join(tmpDir, "link.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		service := NewLocalStorageService(tmpDir, 0o755)
+
+		err := service.Move("link.txt", "moved.txt")
+
+		require.ErrorIs(t, err, domain.ErrUnsupportedOperation)
+		_, statErr := os.Lstat(filepath.Join(tmpDir, "link.txt"))
+		assert.NoError(t, statErr, "link stays in place")
+	})
+
+	t.Run("regular file still moves", func(t *testing.T) {
+		service := NewLocalStorageService(tmpDir, 0o755)
+
+		require.NoError(t, service.Move("target.txt", "target2.txt"))
+		require.NoError(t, service.Move("target2.txt", "target.txt"))
+	})
+
+	t.Run("allowed by option", func(t *testing.T) {
+		service := NewLocalStorageService(tmpDir, 0o755, WithAllowSymlinkMove(true))
+
+		require.NoError(t, service.Move("link.txt", "moved.txt"))
+
+		info, err := os.Lstat(filepath.Join(tmpDir, "moved.txt"))
+		require.NoError(t, err)
+		assert.NotZero(t, info.Mode()&os.ModeSymlink)
+	})
+}
+
 func TestLocalStorageService_CreateDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 	service := NewLocalStorageService(tmpDir, 0o755)
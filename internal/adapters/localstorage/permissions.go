@@ -0,0 +1,89 @@
+package localstorage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// maxTempAttempts сколько раз пробовать случайное имя временного файла до отказа.
+const maxTempAttempts = 100
+
+// Option необязательная настройка LocalStorageService.
+type Option func(*LocalStorageService)
+
+// WithFilePermissions права создаваемых файлов, по умолчанию 0644.
+func WithFilePermissions(perm os.FileMode) Option {
+	return func(s *LocalStorageService) {
+		if perm != 0 {
+			s.filePerm = perm
+		}
+	}
+}
+
+// WithExactPermissions после создания делает chmod файлам и директориям ровно в настроенный режим.
+// без этого режим урезается umask процесса (0755 при umask 077 превращается в 0700),
+// и результат зависит от окружения, где запущен сервер.
+func WithExactPermissions(exact bool) Option {
+	return func(s *LocalStorageService) {
+		s.exactPerm = exact
+	}
+}
+
+// WithAllowSymlinkMove разрешает Move переносить симлинки, по умолчанию это ErrUnsupportedOperation.
+func WithAllowSymlinkMove(allow bool) Option {
+	return func(s *LocalStorageService) {
+		s.allowSymlinkMove = allow
+	}
+}
+
+// mkdirAll как os.MkdirAll, но в exact-режиме выставляет dirPerm каждой созданной директории.
+// уже существующие директории не трогаем.
+func (s *LocalStorageService) mkdirAll(fullPath string) error {
+	if !s.exactPerm {
+		return os.MkdirAll(fullPath, s.dirPerm)
+	}
+
+	var created []string
+	for dir := fullPath; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		created = append(created, dir)
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(fullPath, s.dirPerm); err != nil {
+		return err
+	}
+	for _, dir := range created {
+		if err := os.Chmod(dir, s.dirPerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTemp как os.CreateTemp, но с filePerm вместо жёстких 0600: права итогового файла
+// задаются при создании и, как у os.Create, проходят через umask.
+func (s *LocalStorageService) createTemp(dir string) (*os.File, error) {
+	suffix := make([]byte, 8)
+	for range maxTempAttempts {
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		name := filepath.Join(dir, tmpFilePrefix+hex.EncodeToString(suffix))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, s.filePerm)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: filepath.Join(dir, tmpFilePrefix+"*"), Err: os.ErrExist}
+}
@@ -0,0 +1,70 @@
+//go:build unix
+
+package localstorage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withUmask umask общий на процесс, поэтому эти тесты не параллельные.
+func withUmask(t *testing.T, mask int) {
+	t.Helper()
+	old := syscall.Umask(mask)
+	t.Cleanup(func() { syscall.Umask(old) })
+}
+
+func TestLocalStorageService_Permissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		exact    bool
+		wantDir  os.FileMode
+		wantFile os.FileMode
+	}{
+		{"umask applies by default", false, 0o700, 0o600},
+		{"exact permissions bypass umask", true, 0o755, 0o644},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withUmask(t, 0o077)
+			tmpDir := t.TempDir()
+			service := NewLocalStorageService(tmpDir, 0o755,
+				WithFilePermissions(0o644), WithExactPermissions(tt.exact))
+
+			require.NoError(t, service.CreateDirectory("a/b"))
+			require.NoError(t, service.WriteFile("c/d/file.txt", strings.NewReader("x")))
+
+			for _, dir := range []string{"a", "a/b", "c", "c/d"} {
+				info, err := os.Stat(filepath.Join(tmpDir, dir))
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantDir, info.Mode().Perm(), dir)
+			}
+			info, err := os.Stat(filepath.Join(tmpDir, "c/d/file.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFile, info.Mode().Perm())
+		})
+	}
+}
+
+func TestLocalStorageService_ExactPermissionsKeepExistingDirs(t *testing.T) {
+	withUmask(t, 0o022)
+	tmpDir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "existing"), 0o700))
+	service := NewLocalStorageService(tmpDir, 0o775, WithExactPermissions(true))
+
+	require.NoError(t, service.CreateDirectory("existing/new"))
+
+	info, err := os.Stat(filepath.Join(tmpDir, "existing"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm(), "pre-existing directory must not be chmod-ed")
+	info, err = os.Stat(filepath.Join(tmpDir, "existing", "new"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o775), info.Mode().Perm())
+}
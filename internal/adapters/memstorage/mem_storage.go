@@ -0,0 +1,259 @@
+package memstorage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"file-manager/internal/domain"
+)
+
+// VirtualRoot префикс, который GetAbsolutePath ставит перед путём. на диске такого пути нет.
+const VirtualRoot = "/mem"
+
+const (
+	dirPerm  os.FileMode = 0o755
+	filePerm os.FileMode = 0o644
+)
+
+// MemStorageService хранилище целиком в памяти процесса: для тестов и одноразовых развёртываний,
+// после перезапуска всё пропадает. ошибки как у os (os.IsNotExist и т.п. работают), чтобы usecase-слой
+// не отличал его от локального диска.
+// GetAbsolutePath даёт виртуальный путь. usecase-код, который ходит на диск мимо хранилища
+// (os.Stat в ServeFile, обход папки для zip, хеши), такие файлы не видит и отвечает «не найдено».
+type MemStorageService struct {
+	mu sync.RWMutex
+	// entries ключ — путь через `/` без ведущего слеша, корень (".") есть всегда.
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+type entry struct {
+	data    []byte
+	isDir   bool
+	modTime time.Time
+}
+
+func NewMemStorageService() *MemStorageService {
+	s := &MemStorageService{entries: make(map[string]*entry), now: time.Now}
+	s.entries[domain.PathCurrent] = &entry{isDir: true, modTime: s.now()}
+	return s
+}
+
+// key путь хранилища в ключ карты: `./docs/a.txt` и `docs/a.txt` — одна запись.
+func key(relPath string) string {
+	return path.Clean(filepath.ToSlash(relPath))
+}
+
+func (s *MemStorageService) GetAbsolutePath(relPath string) string {
+	return path.Join(VirtualRoot, key(relPath))
+}
+
+func (s *MemStorageService) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	dir := key(relPath)
+	e, ok := s.entries[dir]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: relPath, Err: fs.ErrNotExist}
+	}
+	if !e.isDir {
+		return nil, &fs.PathError{Op: "readdir", Path: relPath, Err: fs.ErrInvalid}
+	}
+
+	var infos []os.FileInfo
+	for name, child := range s.entries {
+		if name != dir && path.Dir(name) == dir {
+			infos = append(infos, child.info(path.Base(name)))
+		}
+	}
+	// как os.ReadDir: по имени.
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	return infos, nil
+}
+
+func (s *MemStorageService) Open(relPath string) (io.ReadSeekCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[key(relPath)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: relPath, Err: fs.ErrNotExist}
+	}
+	if e.isDir {
+		return nil, &fs.PathError{Op: "open", Path: relPath, Err: fs.ErrInvalid}
+	}
+	// data не меняется на месте, WriteFile кладёт новый срез, так что читатель видит снимок.
+	return nopCloser{bytes.NewReader(e.data)}, nil
+}
+
+func (s *MemStorageService) Stat(relPath string) (os.FileInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k := key(relPath)
+	e, ok := s.entries[k]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: relPath, Err: fs.ErrNotExist}
+	}
+	return e.info(path.Base(k)), nil
+}
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+// WriteFile как у локального хранилища: недостающие папки создаются, файл появляется целиком или никак.
+func (s *MemStorageService) WriteFile(relPath string, file io.Reader) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	return s.put(relPath, data)
+}
+
+// OpenWriter файл публикуется на Close, до этого его не видно.
+func (s *MemStorageService) OpenWriter(relPath string) (io.WriteCloser, error) {
+	return &writer{s: s, relPath: relPath}, nil
+}
+
+type writer struct {
+	s       *MemStorageService
+	relPath string
+	buf     bytes.Buffer
+}
+
+func (w *writer) Write(p []byte) (int, error) { return w.buf.Write(p) }
+
+func (w *writer) Close() error { return w.s.put(w.relPath, w.buf.Bytes()) }
+
+func (s *MemStorageService) put(relPath string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(relPath)
+	if e, ok := s.entries[k]; ok && e.isDir {
+		return &fs.PathError{Op: "write", Path: relPath, Err: fs.ErrExist}
+	}
+	if err := s.mkdirAll(path.Dir(k)); err != nil {
+		return err
+	}
+	s.entries[k] = &entry{data: data, modTime: s.now()}
+	return nil
+}
+
+// Remove рекурсивный, отсутствующий путь не ошибка (как os.RemoveAll).
+func (s *MemStorageService) Remove(relPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(relPath)
+	if k == domain.PathCurrent {
+		return &fs.PathError{Op: "remove", Path: relPath, Err: fs.ErrPermission}
+	}
+	for name := range s.entries {
+		if name == k || strings.HasPrefix(name, k+"/") {
+			delete(s.entries, name)
+		}
+	}
+	return nil
+}
+
+// Move как os.Rename: родитель цели должен существовать, файл поверх файла заменяется,
+// на существующую папку и внутрь самого себя перенести нельзя.
+func (s *MemStorageService) Move(oldRel, newRel string) error {
+	if newRel == "" {
+		return os.ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to := key(oldRel), key(newRel)
+	src, ok := s.entries[from]
+	if !ok || from == domain.PathCurrent {
+		return &os.LinkError{Op: "rename", Old: oldRel, New: newRel, Err: fs.ErrNotExist}
+	}
+	if from == to {
+		return nil
+	}
+	if strings.HasPrefix(to, from+"/") {
+		return &os.LinkError{Op: "rename", Old: oldRel, New: newRel, Err: fs.ErrInvalid}
+	}
+	if parent, exists := s.entries[path.Dir(to)]; !exists || !parent.isDir {
+		return &os.LinkError{Op: "rename", Old: oldRel, New: newRel, Err: fs.ErrNotExist}
+	}
+	if dst, exists := s.entries[to]; exists && (dst.isDir || src.isDir) {
+		return &os.LinkError{Op: "rename", Old: oldRel, New: newRel, Err: fs.ErrExist}
+	}
+
+	moved := make(map[string]*entry)
+	for name, e := range s.entries {
+		if name == from || strings.HasPrefix(name, from+"/") {
+			moved[to+strings.TrimPrefix(name, from)] = e
+			delete(s.entries, name)
+		}
+	}
+	for name, e := range moved {
+		s.entries[name] = e
+	}
+	return nil
+}
+
+func (s *MemStorageService) CreateDirectory(relPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mkdirAll(key(relPath))
+}
+
+// mkdirAll под s.mu. файл на месте одной из папок — ошибка, как у os.MkdirAll.
+func (s *MemStorageService) mkdirAll(k string) error {
+	var missing []string
+	for dir := k; ; dir = path.Dir(dir) {
+		e, ok := s.entries[dir]
+		if ok {
+			if !e.isDir {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fmt.Errorf("not a directory: %w", fs.ErrExist)}
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+	now := s.now()
+	for _, dir := range missing {
+		s.entries[dir] = &entry{isDir: true, modTime: now}
+	}
+	return nil
+}
+
+func (e *entry) info(name string) os.FileInfo {
+	return fileInfo{name: name, entry: e}
+}
+
+type fileInfo struct {
+	name  string
+	entry *entry
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return int64(len(fi.entry.data)) }
+func (fi fileInfo) ModTime() time.Time { return fi.entry.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.entry.isDir }
+func (fi fileInfo) Sys() any           { return nil }
+
+func (fi fileInfo) Mode() os.FileMode {
+	if fi.entry.isDir {
+		return os.ModeDir | dirPerm
+	}
+	return filePerm
+}
@@ -0,0 +1,184 @@
+package memstorage
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// names имена записей папки в порядке ReadDirectory, у папок `/` на конце.
+func names(t *testing.T, s *MemStorageService, dir string) []string {
+	t.Helper()
+	infos, err := s.ReadDirectory(dir)
+	require.NoError(t, err)
+	result := make([]string, 0, len(infos))
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() {
+			name += "/"
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+func read(t *testing.T, s *MemStorageService, relPath string) string {
+	t.Helper()
+	f, err := s.Open(relPath)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMemStorageService_GetAbsolutePath(t *testing.T) {
+	s := NewMemStorageService()
+
+	tests := []struct {
+		relPath  string
+		expected string
+	}{
+		{"", "/mem"},
+		{".", "/mem"},
+		{"docs/a.txt", "/mem/docs/a.txt"},
+		{"./docs//a.txt", "/mem/docs/a.txt"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, s.GetAbsolutePath(tt.relPath), tt.relPath)
+	}
+}
+
+func TestMemStorageService_WriteReadList(t *testing.T) {
+	s := NewMemStorageService()
+
+	require.NoError(t, s.WriteFile("docs/sub/b.txt", strings.NewReader("bb")))
+	require.NoError(t, s.WriteFile("docs/a.txt", strings.NewReader("a")))
+	require.NoError(t, s.CreateDirectory("empty"))
+
+	assert.Equal(t, []string{"docs/", "empty/"}, names(t, s, ""))
+	assert.Equal(t, []string{"a.txt", "sub/"}, names(t, s, "docs"))
+	assert.Equal(t, "bb", read(t, s, "docs/sub/b.txt"))
+
+	infos, err := s.ReadDirectory("docs/sub")
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, int64(2), infos[0].Size())
+	assert.Equal(t, os.FileMode(0o644), infos[0].Mode())
+
+	t.Run("overwrite", func(t *testing.T) {
+		require.NoError(t, s.WriteFile("docs/a.txt", strings.NewReader("new")))
+		assert.Equal(t, "new", read(t, s, "docs/a.txt"))
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		f, openErr := s.Open("docs/a.txt")
+		require.NoError(t, openErr)
+		_, seekErr := f.Seek(1, io.SeekStart)
+		require.NoError(t, seekErr)
+		rest, _ := io.ReadAll(f)
+		assert.Equal(t, "ew", string(rest))
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		info, statErr := s.Stat("docs/sub/b.txt")
+		require.NoError(t, statErr)
+		assert.Equal(t, "b.txt", info.Name())
+		assert.Equal(t, int64(2), info.Size())
+		assert.False(t, info.IsDir())
+
+		info, statErr = s.Stat("docs")
+		require.NoError(t, statErr)
+		assert.True(t, info.IsDir())
+
+		info, statErr = s.Stat("")
+		require.NoError(t, statErr)
+		assert.True(t, info.IsDir(), "root always exists")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, openErr := s.Open("nope.txt")
+		assert.True(t, os.IsNotExist(openErr))
+		_, readErr := s.ReadDirectory("nope")
+		assert.True(t, os.IsNotExist(readErr))
+		_, statErr := s.Stat("docs/nope.txt")
+		assert.True(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("file over folder", func(t *testing.T) {
+		assert.Error(t, s.WriteFile("docs", strings.NewReader("x")))
+		assert.Error(t, s.CreateDirectory("docs/a.txt/inner"))
+	})
+
+	t.Run("failed read keeps old content", func(t *testing.T) {
+		assert.Error(t, s.WriteFile("docs/a.txt", iotest.ErrReader(io.ErrUnexpectedEOF)))
+		assert.Equal(t, "new", read(t, s, "docs/a.txt"))
+	})
+}
+
+func TestMemStorageService_OpenWriter(t *testing.T) {
+	s := NewMemStorageService()
+
+	w, err := s.OpenWriter("report.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("draft"))
+	require.NoError(t, err)
+	_, err = s.Open("report.txt")
+	assert.True(t, os.IsNotExist(err), "file must not appear before Close")
+
+	require.NoError(t, w.Close())
+	assert.Equal(t, "draft", read(t, s, "report.txt"))
+}
+
+func TestMemStorageService_Move(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		wantErr  bool
+		want     []string
+	}{
+		{name: "rename file", old: "a/x.txt", new: "a/y.txt", want: []string{"a/", "b/"}},
+		{name: "move folder with contents", old: "a", new: "b/a", want: []string{"b/"}},
+		{name: "file over file", old: "a/x.txt", new: "b/z.txt", want: []string{"a/", "b/"}},
+		{name: "onto folder", old: "a/x.txt", new: "b", wantErr: true},
+		{name: "into itself", old: "a", new: "a/inner", wantErr: true},
+		{name: "missing parent", old: "a/x.txt", new: "c/x.txt", wantErr: true},
+		{name: "missing source", old: "nope", new: "b/nope", wantErr: true},
+		{name: "empty target", old: "a/x.txt", new: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemStorageService()
+			require.NoError(t, s.WriteFile("a/x.txt", strings.NewReader("x")))
+			require.NoError(t, s.WriteFile("b/z.txt", strings.NewReader("z")))
+
+			err := s.Move(tt.old, tt.new)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, "x", read(t, s, "a/x.txt"), "source must stay")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, names(t, s, ""))
+			assert.Equal(t, "x", read(t, s, tt.new+strings.TrimPrefix("a/x.txt", tt.old)))
+		})
+	}
+}
+
+func TestMemStorageService_Remove(t *testing.T) {
+	s := NewMemStorageService()
+	require.NoError(t, s.WriteFile("a/x.txt", strings.NewReader("x")))
+	require.NoError(t, s.WriteFile("ab.txt", strings.NewReader("ab")))
+
+	require.NoError(t, s.Remove("a"))
+	require.NoError(t, s.Remove("missing"), "like os.RemoveAll")
+
+	assert.Equal(t, []string{"ab.txt"}, names(t, s, ""))
+	assert.Error(t, s.Remove(""), "root cannot be removed")
+}
@@ -0,0 +1,147 @@
+package mountstorage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// MountStorage собирает несколько хранилищ в одно дерево: первый сегмент пути выбирает точку монтирования
+// (`photos/a.jpg` -> хранилище photos, путь `a.jpg`), всё остальное уходит в корневое хранилище.
+// пути приходят уже очищенными usecase-слоем, а после filepath.Clean `..` не может перескочить
+// из одного монтирования в другое, так что выйти за пределы хранилища нельзя.
+// zip, tar.gz, сравнение папок и прочие обходы идут через хранилище и заходят в монтирования.
+type MountStorage struct {
+	root   domain.FileStorage
+	mounts map[string]domain.FileStorage
+}
+
+func NewMountStorage(root domain.FileStorage, mounts map[string]domain.FileStorage) *MountStorage {
+	return &MountStorage{root: root, mounts: mounts}
+}
+
+// route хранилище и путь внутри него. mountRoot — путь указывает на саму точку монтирования.
+func (s *MountStorage) route(relPath string) (store domain.FileStorage, inner string, mountRoot bool) {
+	clean := filepath.Clean(relPath)
+	first, rest, _ := strings.Cut(filepath.ToSlash(clean), domain.PathRoot)
+	mounted, ok := s.mounts[first]
+	if !ok {
+		return s.root, relPath, false
+	}
+	if rest == "" {
+		return mounted, domain.PathEmpty, true
+	}
+	return mounted, filepath.FromSlash(rest), false
+}
+
+func (s *MountStorage) GetAbsolutePath(relPath string) string {
+	store, inner, _ := s.route(relPath)
+	return store.GetAbsolutePath(inner)
+}
+
+// ReadDirectory в корне добавляет точки монтирования как папки, перекрывая одноимённые записи корня.
+func (s *MountStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	store, inner, _ := s.route(relPath)
+	entries, err := store.ReadDirectory(inner)
+	if err != nil || filepath.Clean(relPath) != domain.PathCurrent {
+		return entries, err
+	}
+
+	merged := make([]os.FileInfo, 0, len(entries)+len(s.mounts))
+	for _, e := range entries {
+		if _, shadowed := s.mounts[e.Name()]; !shadowed {
+			merged = append(merged, e)
+		}
+	}
+	for _, name := range s.mountNames() {
+		info, statErr := os.Stat(s.mounts[name].GetAbsolutePath(domain.PathEmpty))
+		if statErr != nil {
+			logrus.Warnf("Mount %s is unavailable: %v", name, statErr)
+			continue
+		}
+		merged = append(merged, mountInfo{FileInfo: info, name: name})
+	}
+	return merged, nil
+}
+
+func (s *MountStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	store, inner, _ := s.route(relPath)
+	return store.Open(inner)
+}
+
+// Stat точки монтирования отдаёт корень подключённого хранилища под именем точки, как в ReadDirectory.
+func (s *MountStorage) Stat(relPath string) (os.FileInfo, error) {
+	store, inner, mountRoot := s.route(relPath)
+	info, err := store.Stat(inner)
+	if err != nil || !mountRoot {
+		return info, err
+	}
+	return mountInfo{FileInfo: info, name: filepath.Base(filepath.Clean(relPath))}, nil
+}
+
+func (s *MountStorage) WriteFile(relPath string, file io.Reader) error {
+	store, inner, mountRoot := s.route(relPath)
+	if mountRoot {
+		return fmt.Errorf("write over mount point '%s': %w", relPath, domain.ErrPermissionDenied)
+	}
+	return store.WriteFile(inner, file)
+}
+
+func (s *MountStorage) OpenWriter(relPath string) (io.WriteCloser, error) {
+	store, inner, mountRoot := s.route(relPath)
+	if mountRoot {
+		return nil, fmt.Errorf("write over mount point '%s': %w", relPath, domain.ErrPermissionDenied)
+	}
+	return store.OpenWriter(inner)
+}
+
+// Remove точку монтирования не удаляет: RemoveAll снёс бы весь подключённый каталог.
+func (s *MountStorage) Remove(relPath string) error {
+	store, inner, mountRoot := s.route(relPath)
+	if mountRoot {
+		return fmt.Errorf("remove mount point '%s': %w", relPath, domain.ErrPermissionDenied)
+	}
+	return store.Remove(inner)
+}
+
+// Move только внутри одного хранилища, между монтированиями это копирование, а не rename.
+func (s *MountStorage) Move(oldRel, newRel string) error {
+	oldStore, oldInner, oldMountRoot := s.route(oldRel)
+	newStore, newInner, newMountRoot := s.route(newRel)
+	if oldMountRoot || newMountRoot {
+		return fmt.Errorf("move of mount point '%s' -> '%s': %w", oldRel, newRel, domain.ErrPermissionDenied)
+	}
+	if oldStore != newStore {
+		return fmt.Errorf("move across mounts '%s' -> '%s': %w", oldRel, newRel, domain.ErrUnsupportedOperation)
+	}
+	return oldStore.Move(oldInner, newInner)
+}
+
+func (s *MountStorage) CreateDirectory(relPath string) error {
+	store, inner, _ := s.route(relPath)
+	return store.CreateDirectory(inner)
+}
+
+func (s *MountStorage) mountNames() []string {
+	names := make([]string, 0, len(s.mounts))
+	for name := range s.mounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mountInfo FileInfo каталога монтирования под именем точки монтирования.
+type mountInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (m mountInfo) Name() string { return m.name }
@@ -0,0 +1,137 @@
+package mountstorage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/adapters/localstorage"
+	"file-manager/internal/domain"
+)
+
+func newTestMounts(t *testing.T) (*MountStorage, map[string]string) {
+	t.Helper()
+	dirs := map[string]string{
+		"root":   t.TempDir(),
+		"photos": t.TempDir(),
+		"docs":   t.TempDir(),
+	}
+	s := NewMountStorage(
+		localstorage.NewLocalStorageService(dirs["root"], 0o755),
+		map[string]domain.FileStorage{
+			"photos": localstorage.NewLocalStorageService(dirs["photos"], 0o755),
+			"docs":   localstorage.NewLocalStorageService(dirs["docs"], 0o755),
+		},
+	)
+	return s, dirs
+}
+
+func names(entries []os.FileInfo) []string {
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.Name())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestMountStorage_Routing(t *testing.T) {
+	s, dirs := newTestMounts(t)
+
+	require.NoError(t, s.WriteFile(filepath.Join("photos", "2024", "a.jpg"), strings.NewReader("jpg")))
+	require.NoError(t, s.WriteFile("readme.txt", strings.NewReader("root")))
+
+	data, err := os.ReadFile(filepath.Join(dirs["photos"], "2024", "a.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpg", string(data))
+	_, err = os.Stat(filepath.Join(dirs["root"], "readme.txt"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dirs["docs"], "x.txt"), s.GetAbsolutePath(filepath.Join("docs", "x.txt")))
+	assert.Equal(t, dirs["docs"], s.GetAbsolutePath("docs"))
+
+	t.Run("root lists mounts", func(t *testing.T) {
+		entries, err := s.ReadDirectory("")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs", "photos", "readme.txt"}, names(entries))
+		for _, e := range entries {
+			if e.Name() != "readme.txt" {
+				assert.True(t, e.IsDir(), e.Name())
+			}
+		}
+	})
+
+	t.Run("mount contents", func(t *testing.T) {
+		entries, err := s.ReadDirectory("photos")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2024"}, names(entries))
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		info, err := s.Stat(filepath.Join("photos", "2024", "a.jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), info.Size())
+
+		info, err = s.Stat("photos")
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+		assert.Equal(t, "photos", info.Name(), "mount point keeps its name")
+	})
+
+	t.Run("open reads from the mount", func(t *testing.T) {
+		f, err := s.Open(filepath.Join("photos", "2024", "a.jpg"))
+		require.NoError(t, err)
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, "jpg", string(data))
+	})
+
+	t.Run("cannot escape a mount", func(t *testing.T) {
+		escaped := s.GetAbsolutePath(filepath.Join("photos", "..", "docs", "x"))
+		assert.Equal(t, filepath.Join(dirs["docs"], "x"), escaped, "Clean resolves to another mount, not outside")
+	})
+}
+
+func TestMountStorage_Guards(t *testing.T) {
+	s, dirs := newTestMounts(t)
+	require.NoError(t, s.WriteFile(filepath.Join("photos", "a.jpg"), strings.NewReader("jpg")))
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{"remove mount point", func() error { return s.Remove("photos") }, domain.ErrPermissionDenied},
+		{"rename mount point", func() error { return s.Move("photos", "pics") }, domain.ErrPermissionDenied},
+		{
+			"move across mounts",
+			func() error { return s.Move(filepath.Join("photos", "a.jpg"), filepath.Join("docs", "a.jpg")) },
+			domain.ErrUnsupportedOperation,
+		},
+		{"write over mount point", func() error { return s.WriteFile("docs", strings.NewReader("x")) },
+			domain.ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+
+	_, err := os.Stat(filepath.Join(dirs["photos"], "a.jpg"))
+	assert.NoError(t, err, "mount contents untouched")
+
+	t.Run("move within a mount", func(t *testing.T) {
+		require.NoError(t, s.Move(filepath.Join("photos", "a.jpg"), filepath.Join("photos", "b.jpg")))
+		_, err := os.Stat(filepath.Join(dirs["photos"], "b.jpg"))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,139 @@
+package retrystorage
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// RetryStorage декоратор над FileStorage для сетевых хранилищ (NFS и т.п.).
+// повторяет только идемпотентные чтения, записи пропускаются как есть,
+// потому что повтор недописанной записи может применить её дважды.
+type RetryStorage struct {
+	next     domain.FileStorage
+	attempts int
+	backoff  time.Duration
+	sleep    func(time.Duration)
+}
+
+func NewRetryStorage(next domain.FileStorage, attempts int, backoff time.Duration) *RetryStorage {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &RetryStorage{
+		next:     next,
+		attempts: attempts,
+		backoff:  backoff,
+		sleep:    time.Sleep,
+	}
+}
+
+func (s *RetryStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	var entries []os.FileInfo
+	err := s.retry("ReadDirectory", relPath, func() error {
+		var readErr error
+		entries, readErr = s.next.ReadDirectory(relPath)
+		return readErr
+	})
+	return entries, err
+}
+
+func (s *RetryStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	var f io.ReadSeekCloser
+	err := s.retry("Open", relPath, func() error {
+		var openErr error
+		f, openErr = s.next.Open(relPath)
+		return openErr
+	})
+	return f, err
+}
+
+func (s *RetryStorage) Stat(relPath string) (os.FileInfo, error) {
+	var info os.FileInfo
+	err := s.retry("Stat", relPath, func() error {
+		var statErr error
+		info, statErr = s.next.Stat(relPath)
+		return statErr
+	})
+	return info, err
+}
+
+func (s *RetryStorage) GetAbsolutePath(relPath string) string {
+	return s.next.GetAbsolutePath(relPath)
+}
+
+func (s *RetryStorage) WriteFile(relPath string, file io.Reader) error {
+	return s.next.WriteFile(relPath, file)
+}
+
+func (s *RetryStorage) OpenWriter(relPath string) (io.WriteCloser, error) {
+	return s.next.OpenWriter(relPath)
+}
+
+func (s *RetryStorage) Remove(relPath string) error {
+	return s.next.Remove(relPath)
+}
+
+func (s *RetryStorage) Move(oldRel, newRel string) error {
+	return s.next.Move(oldRel, newRel)
+}
+
+func (s *RetryStorage) CreateDirectory(relPath string) error {
+	return s.next.CreateDirectory(relPath)
+}
+
+// retry экспоненциальный backoff: backoff, 2*backoff, 4*backoff...
+func (s *RetryStorage) retry(op, relPath string, fn func() error) error {
+	delay := s.backoff
+	var err error
+	for attempt := 1; attempt <= s.attempts; attempt++ {
+		err = fn()
+		if err == nil || !IsTransient(err) || attempt == s.attempts {
+			return err
+		}
+
+		logrus.Warnf("Transient storage error on %s '%s' (attempt %d/%d): %v",
+			op, relPath, attempt, s.attempts, err)
+		s.sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+// IsTransient отделяет временные сбои (таймауты, EAGAIN, протухший NFS-хэндл)
+// от окончательных ошибок вроде "нет файла" или "нет прав", которые повторять бессмысленно.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, os.ErrPermission) || errors.Is(err, os.ErrExist) {
+		return false
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.EAGAIN, syscall.EINTR, syscall.ETIMEDOUT, syscall.EBUSY,
+			syscall.ESTALE, syscall.EIO, syscall.ECONNRESET, syscall.ECONNREFUSED:
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
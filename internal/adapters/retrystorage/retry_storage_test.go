@@ -0,0 +1,127 @@
+package retrystorage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	readDirErrs []error
+	readDirCall int
+	writeCalls  int
+	writeErr    error
+}
+
+func (f *fakeStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	f.readDirCall++
+	if len(f.readDirErrs) > 0 {
+		err := f.readDirErrs[0]
+		f.readDirErrs = f.readDirErrs[1:]
+		return nil, err
+	}
+	return []os.FileInfo{}, nil
+}
+
+func (f *fakeStorage) WriteFile(relPath string, file io.Reader) error {
+	f.writeCalls++
+	return f.writeErr
+}
+
+func (f *fakeStorage) Open(relPath string) (io.ReadSeekCloser, error)    { return nil, nil }
+func (f *fakeStorage) Stat(relPath string) (os.FileInfo, error)          { return nil, nil }
+func (f *fakeStorage) OpenWriter(relPath string) (io.WriteCloser, error) { return nil, nil }
+func (f *fakeStorage) Remove(relPath string) error                       { return nil }
+func (f *fakeStorage) Move(oldRel, newRel string) error                  { return nil }
+func (f *fakeStorage) CreateDirectory(relPath string) error              { return nil }
+func (f *fakeStorage) GetAbsolutePath(relPath string) string {
+	return "/base/" + relPath
+}
+
+func newTestStorage(inner *fakeStorage, attempts int) (*RetryStorage, *[]time.Duration) {
+	s := NewRetryStorage(inner, attempts, 10*time.Millisecond)
+	var sleeps []time.Duration
+	s.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	return s, &sleeps
+}
+
+func TestRetryStorage_ReadDirectory(t *testing.T) {
+	t.Run("recovers after transient errors", func(t *testing.T) {
+		inner := &fakeStorage{readDirErrs: []error{syscall.EAGAIN, &os.PathError{Op: "open", Err: syscall.ESTALE}}}
+		s, sleeps := newTestStorage(inner, 3)
+
+		entries, err := s.ReadDirectory("docs")
+
+		require.NoError(t, err)
+		assert.NotNil(t, entries)
+		assert.Equal(t, 3, inner.readDirCall)
+		assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *sleeps)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		inner := &fakeStorage{readDirErrs: []error{syscall.EIO, syscall.EIO, syscall.EIO, syscall.EIO}}
+		s, _ := newTestStorage(inner, 3)
+
+		_, err := s.ReadDirectory("docs")
+
+		assert.True(t, errors.Is(err, syscall.EIO))
+		assert.Equal(t, 3, inner.readDirCall)
+	})
+
+	t.Run("permanent error is not retried", func(t *testing.T) {
+		inner := &fakeStorage{readDirErrs: []error{os.ErrNotExist}}
+		s, sleeps := newTestStorage(inner, 3)
+
+		_, err := s.ReadDirectory("missing")
+
+		assert.True(t, os.IsNotExist(err))
+		assert.Equal(t, 1, inner.readDirCall)
+		assert.Empty(t, *sleeps)
+	})
+}
+
+func TestRetryStorage_WritesAreNotRetried(t *testing.T) {
+	inner := &fakeStorage{writeErr: syscall.EAGAIN}
+	s, _ := newTestStorage(inner, 5)
+
+	err := s.WriteFile("file.txt", strings.NewReader("data"))
+
+	assert.True(t, errors.Is(err, syscall.EAGAIN))
+	assert.Equal(t, 1, inner.writeCalls)
+}
+
+func TestRetryStorage_GetAbsolutePath(t *testing.T) {
+	s, _ := newTestStorage(&fakeStorage{}, 3)
+	assert.Equal(t, "/base/docs", s.GetAbsolutePath("docs"))
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"not exist", os.ErrNotExist, false},
+		{"permission", &os.PathError{Op: "open", Err: syscall.EACCES}, false},
+		{"eagain", syscall.EAGAIN, true},
+		{"stale nfs handle", &os.PathError{Op: "readdir", Err: syscall.ESTALE}, true},
+		{"timeout", fmt.Errorf("wrapped: %w", syscall.ETIMEDOUT), true},
+		{"deadline", os.ErrDeadlineExceeded, true},
+		{"generic", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	headerAuthorization = "Authorization"
+	bearerPrefix        = "Bearer "
+)
+
+// RequireAdmin пускает дальше только запросы с `Authorization: Bearer <server.admin_token>`.
+// без настроенного токена админские эндпоинты выключены совсем, а не открыты всем.
+func (h *Handler) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.adminToken == "" {
+			logrus.Warnf("Admin endpoint %s requested, but server.admin_token is not set", r.URL.Path)
+			http.Error(w, h.messages.ForbiddenFile, http.StatusForbidden)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get(headerAuthorization), bearerPrefix)
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+			logrus.Warnf("Unauthorized admin request to %s from %s", r.URL.Path, clientIP(r))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, h.messages.Unauthorized, http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
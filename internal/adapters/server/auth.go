@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// authTokenUser имя пользователя для запросов со статическим bearer-токеном, по нему берутся роли.
+	authTokenUser = "token"
+	authRealm     = "file-manager"
+	htpasswdSHA   = "{SHA}"
+)
+
+// authSettings учётки для RequireAuth: логин -> хэш htpasswd, bearer-токен и роли.
+type authSettings struct {
+	users map[string]string
+	token string
+	roles map[string][]string
+}
+
+// RequireAuth пускает дальше только с basic-авторизацией по auth.users или `Authorization: Bearer <auth.token>`,
+// пользователь кладётся в контекст для acl и сайдкаров. без WithAuth пропускает всех.
+func (h *Handler) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.auth == nil {
+			next(w, r)
+			return
+		}
+		user, ok := h.auth.authenticate(r)
+		if !ok {
+			logrus.Warnf("Unauthorized request to %s from %s", r.URL.Path, clientIP(r))
+			if len(h.auth.users) > 0 {
+				w.Header().Add("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+			}
+			if h.auth.token != "" {
+				w.Header().Add("WWW-Authenticate", `Bearer realm="`+authRealm+`"`)
+			}
+			http.Error(w, h.messages.Unauthorized, http.StatusUnauthorized)
+			return
+		}
+		next(w, r.WithContext(domain.WithUser(r.Context(), user)))
+	}
+}
+
+func (a *authSettings) authenticate(r *http.Request) (domain.User, bool) {
+	if token, found := strings.CutPrefix(r.Header.Get(headerAuthorization), bearerPrefix); found {
+		if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			return domain.User{}, false
+		}
+		return domain.User{Name: authTokenUser, Roles: a.roles[authTokenUser]}, true
+	}
+
+	login, password, ok := r.BasicAuth()
+	if !ok {
+		return domain.User{}, false
+	}
+	hash, known := a.users[login]
+	// неизвестный логин тоже проверяем, чтобы по времени ответа нельзя было перебрать логины.
+	if !checkPassword(hash, password) || !known {
+		return domain.User{}, false
+	}
+	return domain.User{Name: login, Roles: a.roles[login]}, true
+}
+
+// checkPassword сверяет пароль с хэшем htpasswd: `{SHA}` (htpasswd -s) или пароль как есть.
+func checkPassword(hash, password string) bool {
+	if encoded, found := strings.CutPrefix(hash, htpasswdSHA); found {
+		sum := sha1.Sum([]byte(password))
+		password = base64.StdEncoding.EncodeToString(sum[:])
+		hash = encoded
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(hash)) == 1
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"file-manager/internal/domain"
+)
+
+func TestHandler_RequireAuth(t *testing.T) {
+	users := map[string]string{
+		"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", // password
+		"bob":   "s3cret",
+	}
+	roles := map[string][]string{"alice": {"admin"}, authTokenUser: {"ci"}}
+
+	tests := []struct {
+		name        string
+		noAuth      bool
+		token       string
+		setup       func(r *http.Request)
+		wantStatus  int
+		wantUser    domain.User
+		wantHeaders []string
+	}{
+		{name: "disabled", noAuth: true, wantStatus: http.StatusOK},
+		{
+			name:       "sha password",
+			setup:      func(r *http.Request) { r.SetBasicAuth("alice", "password") },
+			wantStatus: http.StatusOK,
+			wantUser:   domain.User{Name: "alice", Roles: []string{"admin"}},
+		},
+		{
+			name:       "plain password",
+			setup:      func(r *http.Request) { r.SetBasicAuth("bob", "s3cret") },
+			wantStatus: http.StatusOK,
+			wantUser:   domain.User{Name: "bob"},
+		},
+		{
+			name:        "wrong password",
+			setup:       func(r *http.Request) { r.SetBasicAuth("alice", "nope") },
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: []string{`Basic realm="file-manager", charset="UTF-8"`},
+		},
+		{
+			name:        "unknown user",
+			setup:       func(r *http.Request) { r.SetBasicAuth("eve", "") },
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: []string{`Basic realm="file-manager", charset="UTF-8"`},
+		},
+		{
+			name:        "no credentials",
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: []string{`Basic realm="file-manager", charset="UTF-8"`},
+		},
+		{
+			name:       "bearer token",
+			token:      "t0ken",
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer t0ken") },
+			wantStatus: http.StatusOK,
+			wantUser:   domain.User{Name: authTokenUser, Roles: []string{"ci"}},
+		},
+		{
+			name:       "wrong bearer token",
+			token:      "t0ken",
+			setup:      func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") },
+			wantStatus: http.StatusUnauthorized,
+			wantHeaders: []string{
+				`Basic realm="file-manager", charset="UTF-8"`,
+				`Bearer realm="file-manager"`,
+			},
+		},
+		{
+			name:        "bearer without configured token",
+			setup:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer ") },
+			wantStatus:  http.StatusUnauthorized,
+			wantHeaders: []string{`Basic realm="file-manager", charset="UTF-8"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler(&mockFileManagement{})
+			if !tt.noAuth {
+				WithAuth(users, tt.token, roles)(handler)
+			}
+			var gotUser domain.User
+			next := func(w http.ResponseWriter, r *http.Request) {
+				gotUser, _ = domain.UserFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			w := httptest.NewRecorder()
+			handler.RequireAuth(next)(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUser, gotUser)
+			assert.Equal(t, tt.wantHeaders, w.Header().Values("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestWithAuth_EmptyDisables(t *testing.T) {
+	handler := createTestHandler(&mockFileManagement{})
+	WithAuth(nil, "", nil)(handler)
+
+	assert.Nil(t, handler.auth)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// clientLimiter семафор на каждый IP клиента.
+// счётчики удаляются при освобождении последнего слота, чтобы map не росла бесконечно.
+type clientLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	active map[string]int
+}
+
+func newClientLimiter(limit int) *clientLimiter {
+	return &clientLimiter{
+		limit:  limit,
+		active: make(map[string]int),
+	}
+}
+
+func (l *clientLimiter) acquire(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[client] >= l.limit {
+		return false
+	}
+	l.active[client]++
+	return true
+}
+
+func (l *clientLimiter) release(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active[client]--
+	if l.active[client] <= 0 {
+		delete(l.active, client)
+	}
+}
+
+// clientIP берёт IP из RemoteAddr без порта, заголовкам прокси не доверяем.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientLimiter(t *testing.T) {
+	limiter := newClientLimiter(2)
+
+	assert.True(t, limiter.acquire("10.0.0.1"))
+	assert.True(t, limiter.acquire("10.0.0.1"))
+	assert.False(t, limiter.acquire("10.0.0.1"))
+	assert.True(t, limiter.acquire("10.0.0.2"), "other clients are not affected")
+
+	limiter.release("10.0.0.1")
+	assert.True(t, limiter.acquire("10.0.0.1"))
+
+	limiter.release("10.0.0.1")
+	limiter.release("10.0.0.1")
+	limiter.release("10.0.0.2")
+	assert.Empty(t, limiter.active, "released clients must not leak map entries")
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+
+	req.RemoteAddr = "192.168.1.10:54321"
+	assert.Equal(t, "192.168.1.10", clientIP(req))
+
+	req.RemoteAddr = "[::1]:8080"
+	assert.Equal(t, "::1", clientIP(req))
+
+	req.RemoteAddr = "garbage"
+	assert.Equal(t, "garbage", clientIP(req))
+}
@@ -1,19 +1,92 @@
 package server
 
 const (
-	OperationUpload        = "upload"
-	OperationCreateFolder  = "create_folder"
-	OperationDelete        = "delete"
-	OperationRename        = "rename"
-	LogFileUploaded        = "File uploaded"
-	LogFolderCreated       = "Folder created"
-	LogFileOrFolderDeleted = "File or folder deleted"
-	LogFileOrFolderRenamed = "File or folder renamed"
-	QueryParamPath         = "path"
-	FormParamFile          = "file"
-	FormParamName          = "name"
-	FormParamOld           = "old"
-	FormParamNew           = "new"
-	FormParamPath          = "path"
-	RedirectPathTemplate   = "/?path="
+	OperationUpload          = "upload"
+	OperationCreateFolder    = "create_folder"
+	OperationDelete          = "delete"
+	OperationRename          = "rename"
+	OperationPrune           = "prune"
+	OperationFetch           = "fetch"
+	OperationSwap            = "swap"
+	OperationRestore         = "restore"
+	OperationEmptyTrash      = "empty_trash"
+	OperationExtract         = "extract"
+	LogFileUploaded          = "File uploaded"
+	LogFolderCreated         = "Folder created"
+	LogFileOrFolderDeleted   = "File or folder deleted"
+	LogFileOrFolderRenamed   = "File or folder renamed"
+	LogEmptyDirsPruned       = "Empty directories pruned"
+	LogFileFetched           = "File fetched from URL"
+	LogFilesSwapped          = "Files swapped"
+	LogFileOrFolderRestored  = "File or folder restored from trash"
+	LogTrashEmptied          = "Trash emptied"
+	LogArchiveExtracted      = "Archive extracted"
+	LogSlowRequest           = "Slow request"
+	QueryParamPath           = "path"
+	QueryParamToken          = "token"
+	QueryParamFilter         = "filter"
+	QueryParamFilterDirs     = "filter_dirs"
+	QueryParamAgeMax         = "age_max"
+	QueryParamSort           = "sort"
+	QueryParamOrder          = "order"
+	QueryParamDirsFirst      = "dirs_first"
+	QueryParamPage           = "page"
+	QueryParamPerPage        = "per_page"
+	DefaultPerPage           = 100
+	MaxPerPage               = 1000
+	QueryValueAsc            = "asc"
+	QueryValueDesc           = "desc"
+	QueryValueTrue           = "true"
+	QueryValueFalse          = "false"
+	QueryParamCompareA       = "a"
+	QueryParamCompareB       = "b"
+	QueryParamLimit          = "limit"
+	QueryParamIfSize         = "if-size"
+	QueryParamIfMTime        = "if-mtime"
+	QueryParamUploadID       = "id"
+	QueryParamOffset         = "offset"
+	QueryParamExpectedSize   = "expected_size"
+	QueryParamExpectedChunks = "expected_chunks"
+	DefaultRecentLimit       = 20
+	FormParamFile            = "file"
+	FormParamName            = "name"
+	FormParamOld             = "old"
+	FormParamNew             = "new"
+	FormParamPath            = "path"
+	FormParamConflict        = "conflict"
+	FormParamOverwrite       = "overwrite"
+	FormParamMerge           = "merge"
+	FormParamTarget          = "target"
+	FormParamURL             = "url"
+	FormParamSwapA           = "a"
+	FormParamSwapB           = "b"
+	RedirectPathTemplate     = "/?path="
+	HeaderContentMD5         = "Content-MD5"
+	HeaderContentSHA256      = "X-Content-SHA256"
+	FormParamSHA256          = "sha256"
+	HeaderResultTruncated    = "X-Result-Truncated"
+	HeaderIfNoneMatch        = "If-None-Match"
+	HeaderStoredPath         = "X-Stored-Path"
+	HeaderCSRFToken          = "X-CSRF-Token"
+	FormParamCSRFToken       = "csrf_token"
+	MaxStatBatchBodySize     = 1 << 20
+	MaxVerifyBodySize        = 32 << 20
+	MaxSelectionBodySize     = 1 << 20
+	MaxUploadFiles           = 20
+	QueryParamFlatten        = "flatten"
+	QueryParamRefresh        = "refresh"
+	QueryParamCollapse       = "collapse"
+	AllowGetHead             = "GET, HEAD"
+	VerifyErrorTimeout       = "timeout"
+	VerifyErrorInternal      = "internal"
+	QueryParamLines          = "lines"
+	QueryParamMeta           = "meta"
+	QueryParamFormat         = "format"
+	FormatZip                = "zip"
+	FormatTarGz              = "tar.gz"
+	FormatJSON               = "json"
+	QueryParamFollow         = "follow"
+	QueryParamTTL            = "ttl"
+	QueryParamExpires        = "expires"
+	QueryParamSignature      = "sig"
 )
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	csrfCookieName = "csrf_token"
+	csrfTokenBytes = 32
+)
+
+// csrfToken токен страницы для форм: берётся из куки, а если её нет или она битая — выпускается новый
+// и ставится кукой. пустая строка — защита выключена.
+func (h *Handler) csrfToken(w http.ResponseWriter, r *http.Request) string {
+	if !h.csrfProtection {
+		return ""
+	}
+	if cookie, err := r.Cookie(csrfCookieName); err == nil && validCSRFToken(cookie.Value) {
+		return cookie.Value
+	}
+
+	buf := make([]byte, csrfTokenBytes)
+	_, _ = rand.Read(buf)
+	token := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return token
+}
+
+// checkCSRF double-submit: токен из заголовка или поля формы должен совпасть с кукой.
+// multipart-тело тут не разбираем, оно ещё не обёрнуто лимитами загрузки: поле берётся, только если
+// хендлер уже сам разобрал форму (Upload). в query токен не принимаем — он утекает в логи и Referer.
+// запросы с Bearer пропускаем: браузер сам такой заголовок не подставит.
+func (h *Handler) checkCSRF(r *http.Request) error {
+	if !h.csrfProtection || strings.HasPrefix(r.Header.Get(headerAuthorization), bearerPrefix) {
+		return nil
+	}
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil || !validCSRFToken(cookie.Value) {
+		return fmt.Errorf("missing csrf cookie: %w", domain.ErrInvalidCSRFToken)
+	}
+
+	token := r.Header.Get(HeaderCSRFToken)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); token == "" &&
+		mediaType == "application/x-www-form-urlencoded" {
+		token = r.PostFormValue(FormParamCSRFToken)
+	}
+	if token == "" && r.MultipartForm != nil {
+		if values := r.MultipartForm.Value[FormParamCSRFToken]; len(values) > 0 {
+			token = values[0]
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(cookie.Value)) != 1 {
+		return fmt.Errorf("csrf token mismatch: %w", domain.ErrInvalidCSRFToken)
+	}
+	return nil
+}
+
+func validCSRFToken(token string) bool {
+	if len(token) != hex.EncodedLen(csrfTokenBytes) {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
@@ -0,0 +1,279 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestHandler_Browse_CSRFToken(t *testing.T) {
+	t.Run("issues cookie and token", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithCSRFProtection(true)(handler)
+		w := httptest.NewRecorder()
+
+		handler.Browse(w, httptest.NewRequest("GET", "/?format=json", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, csrfCookieName, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		var resp browseData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, cookies[0].Value, resp.CSRFToken)
+	})
+
+	t.Run("reuses valid cookie", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithCSRFProtection(true)(handler)
+		token := strings.Repeat("ab", csrfTokenBytes)
+		req := httptest.NewRequest("GET", "/?format=json", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+		w := httptest.NewRecorder()
+
+		handler.Browse(w, req)
+
+		assert.Empty(t, w.Result().Cookies())
+		var resp browseData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, token, resp.CSRFToken)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		w := httptest.NewRecorder()
+
+		handler.Browse(w, httptest.NewRequest("GET", "/?format=json", nil))
+
+		assert.Empty(t, w.Result().Cookies())
+		assert.NotContains(t, w.Body.String(), "csrf_token")
+	})
+}
+
+func TestHandler_CheckCSRF(t *testing.T) {
+	token := strings.Repeat("ab", csrfTokenBytes)
+	other := strings.Repeat("cd", csrfTokenBytes)
+
+	tests := []struct {
+		name        string
+		disabled    bool
+		cookie      string
+		body        string
+		contentType string
+		query       string
+		header      map[string]string
+		wantStatus  int
+	}{
+		{name: "form field", cookie: token, body: "name=x&csrf_token=" + token, wantStatus: http.StatusFound},
+		{
+			name: "header", cookie: token, body: "name=x",
+			header: map[string]string{HeaderCSRFToken: token}, wantStatus: http.StatusFound,
+		},
+		{
+			name: "query is not accepted", cookie: token, body: "name=x",
+			query: "?csrf_token=" + token, wantStatus: http.StatusForbidden,
+		},
+		{name: "missing token", cookie: token, body: "name=x", wantStatus: http.StatusForbidden},
+		{name: "wrong token", cookie: token, body: "name=x&csrf_token=" + other, wantStatus: http.StatusForbidden},
+		{name: "no cookie", body: "name=x&csrf_token=" + token, wantStatus: http.StatusForbidden},
+		{
+			name: "multipart body is not read", cookie: token, body: "csrf_token=" + token,
+			contentType: "multipart/form-data; boundary=x", wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "bearer skips check", body: "name=x",
+			header: map[string]string{"Authorization": "Bearer t0ken"}, wantStatus: http.StatusFound,
+		},
+		{name: "disabled", disabled: true, body: "name=x", wantStatus: http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			handler := createTestHandler(&mockFileManagement{
+				createFolderFunc: func(path string) error {
+					created = true
+					return nil
+				},
+			})
+			WithCSRFProtection(!tt.disabled)(handler)
+
+			req := httptest.NewRequest("POST", "/create-folder"+tt.query, strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = "application/x-www-form-urlencoded"
+			}
+			req.Header.Set("Content-Type", contentType)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+
+			handler.CreateFolder(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantStatus == http.StatusFound, created)
+		})
+	}
+}
+
+func TestHandler_Delete_CSRF(t *testing.T) {
+	called := false
+	handler := createTestHandler(&mockFileManagement{
+		deleteFunc: func(path string, opts domain.DeleteOptions) error {
+			called = true
+			return nil
+		},
+	})
+	WithCSRFProtection(true)(handler)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, httptest.NewRequest("POST", "/delete?path=a.txt", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.False(t, called)
+}
+
+func TestHandler_Upload_CSRF(t *testing.T) {
+	token := strings.Repeat("ab", csrfTokenBytes)
+
+	tests := []struct {
+		name       string
+		field      string
+		wantStatus int
+	}{
+		{name: "form field", field: token, wantStatus: http.StatusFound},
+		{name: "missing field", wantStatus: http.StatusForbidden},
+		{name: "wrong field", field: strings.Repeat("cd", csrfTokenBytes), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploaded := false
+			handler := createTestHandler(&mockFileManagement{
+				uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+					uploaded = true
+					return path, nil
+				},
+			})
+			WithCSRFProtection(true)(handler)
+
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+			if tt.field != "" {
+				require.NoError(t, writer.WriteField(FormParamCSRFToken, tt.field))
+			}
+			fileWriter, err := writer.CreateFormFile(FormParamFile, "a.txt")
+			require.NoError(t, err)
+			_, err = fileWriter.Write([]byte("content"))
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			req := httptest.NewRequest("POST", "/upload", &buf)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+			w := httptest.NewRecorder()
+
+			handler.Upload(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantStatus == http.StatusFound, uploaded)
+		})
+	}
+}
+
+func TestHandler_PostEndpoints_CSRF(t *testing.T) {
+	token := strings.Repeat("ab", csrfTokenBytes)
+	called := false
+	handler := createTestHandler(&mockFileManagement{
+		renameFunc: func(oldPath, newPath string, policy domain.ConflictPolicy) (string, error) {
+			called = true
+			return newPath, nil
+		},
+		uploadChunkFunc: func(id string, offset int64, chunk io.Reader, _ domain.ChunkOptions) error {
+			called = true
+			return nil
+		},
+		finalizeUploadFunc: func(id, path string, opts domain.FinalizeOptions) (string, error) {
+			called = true
+			return path, nil
+		},
+		fetchURLFunc: func(destDir, url string) (string, error) {
+			called = true
+			return destDir, nil
+		},
+		extractArchiveFunc: func(zipPath, destPath string) error {
+			called = true
+			return nil
+		},
+		swapFunc: func(pathA, pathB string) error {
+			called = true
+			return nil
+		},
+		restoreFunc: func(path string) error {
+			called = true
+			return nil
+		},
+		emptyTrashFunc: func() error {
+			called = true
+			return nil
+		},
+	})
+	WithCSRFProtection(true)(handler)
+
+	tests := []struct {
+		name    string
+		target  string
+		handler http.HandlerFunc
+	}{
+		{name: "rename api", target: "/rename-api?old=a.txt&new=b.txt", handler: handler.RenameAPI},
+		{name: "upload chunk", target: "/upload-chunk?id=x&offset=0", handler: handler.UploadChunk},
+		{
+			name: "finalize upload", target: "/finalize-upload?id=x&path=a.txt&expected_size=4",
+			handler: handler.FinalizeUpload,
+		},
+		{name: "fetch", target: "/fetch?url=http://example.com/a&path=dir", handler: handler.Fetch},
+		{name: "extract", target: "/extract?path=a.zip", handler: handler.Extract},
+		{name: "swap", target: "/swap?a=a.txt&b=b.txt", handler: handler.Swap},
+		{name: "restore", target: "/restore?path=a.txt", handler: handler.Restore},
+		{name: "empty trash", target: "/empty-trash", handler: handler.EmptyTrash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" without token", func(t *testing.T) {
+			called = false
+			req := httptest.NewRequest("POST", tt.target, strings.NewReader("data"))
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.False(t, called)
+		})
+		t.Run(tt.name+" with header", func(t *testing.T) {
+			called = false
+			req := httptest.NewRequest("POST", tt.target, strings.NewReader("data"))
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+			req.Header.Set(HeaderCSRFToken, token)
+			w := httptest.NewRecorder()
+
+			tt.handler(w, req)
+
+			assert.NotEqual(t, http.StatusForbidden, w.Code)
+			assert.True(t, called)
+		})
+	}
+}
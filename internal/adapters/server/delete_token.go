@@ -0,0 +1,75 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	defaultDeleteTokenTTL = 5 * time.Minute
+	deleteTokenSecretSize = 32
+	deleteTokenSeparator  = "."
+)
+
+// deleteTokens выдаёт и проверяет короткоживущие токены подтверждения удаления.
+// токен привязан к конкретному пути, поэтому ссылка со старой страницы не удалит уже другой файл.
+type deleteTokens struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// newDeleteTokens без секрета генерирует случайный, тогда токены живут до перезапуска сервера.
+func newDeleteTokens(secret string, ttl time.Duration) *deleteTokens {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, deleteTokenSecretSize)
+		// начиная с Go 1.24 rand.Read никогда не возвращает ошибку.
+		_, _ = rand.Read(key)
+	}
+	if ttl <= 0 {
+		ttl = defaultDeleteTokenTTL
+	}
+	return &deleteTokens{secret: key, ttl: ttl, now: time.Now}
+}
+
+// issue возвращает токен вида `<unix-время истечения>.<hmac>`.
+func (d *deleteTokens) issue(path string) (string, time.Time) {
+	expires := d.now().Add(d.ttl).Truncate(time.Second)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + deleteTokenSeparator + d.sign(path, exp), expires
+}
+
+func (d *deleteTokens) validate(path, token string) error {
+	exp, mac, found := strings.Cut(token, deleteTokenSeparator)
+	if !found {
+		return fmt.Errorf("malformed delete token: %w", domain.ErrInvalidParameter)
+	}
+
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed delete token expiry: %w", domain.ErrInvalidParameter)
+	}
+	if d.now().After(time.Unix(expUnix, 0)) {
+		return fmt.Errorf("delete token expired: %w", domain.ErrInvalidParameter)
+	}
+
+	if !hmac.Equal([]byte(mac), []byte(d.sign(path, exp))) {
+		return fmt.Errorf("delete token does not match path '%s': %w", path, domain.ErrInvalidParameter)
+	}
+	return nil
+}
+
+func (d *deleteTokens) sign(path, exp string) string {
+	mac := hmac.New(sha256.New, d.secret)
+	mac.Write([]byte(path + "\n" + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
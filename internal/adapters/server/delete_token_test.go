@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestDeleteTokens(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := newDeleteTokens("secret", time.Minute)
+	tokens.now = func() time.Time { return now }
+
+	token, expires := tokens.issue("docs/report.pdf")
+	assert.Equal(t, now.Add(time.Minute), expires)
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, tokens.validate("docs/report.pdf", token))
+	})
+
+	t.Run("other path", func(t *testing.T) {
+		err := tokens.validate("docs/other.pdf", token)
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+	})
+
+	t.Run("tampered expiry", func(t *testing.T) {
+		_, mac, _ := strings.Cut(token, ".")
+		err := tokens.validate("docs/report.pdf", "9999999999."+mac)
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, bad := range []string{"", "nodot", "abc.def"} {
+			err := tokens.validate("docs/report.pdf", bad)
+			assert.True(t, errors.Is(err, domain.ErrInvalidParameter), bad)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		tokens.now = func() time.Time { return now.Add(2 * time.Minute) }
+		defer func() { tokens.now = func() time.Time { return now } }()
+
+		err := tokens.validate("docs/report.pdf", token)
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+	})
+
+	t.Run("random secret when empty", func(t *testing.T) {
+		a := newDeleteTokens("", 0)
+		b := newDeleteTokens("", 0)
+		require.Len(t, a.secret, deleteTokenSecretSize)
+		assert.NotEqual(t, a.secret, b.secret)
+		assert.Equal(t, defaultDeleteTokenTTL, a.ttl)
+	})
+}
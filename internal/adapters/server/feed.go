@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// FeedMaxEntries потолок ?limit для /feed, лента читается ридерами по расписанию, большая не нужна.
+	FeedMaxEntries = 100
+	feedAuthor     = "file-manager"
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+	mimeAtom       = "application/atom+xml; charset=utf-8"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Summary string    `xml:"summary"`
+}
+
+// Feed Atom-лента недавно изменённых файлов под path, свежие первыми: подписка на папку-инбокс в ридере.
+// файлы те же, что у /recent (скрытые и drop-box не попадают), запрещённые к скачиванию пропускаются.
+// id записи включает время изменения, так что перезалитый файл ридер покажет как новый.
+func (h *Handler) Feed(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultRecentLimit
+	if raw := r.URL.Query().Get(QueryParamLimit); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("limit '%s': %w", raw, domain.ErrInvalidParameter), h.messages.InternalError)
+			return
+		}
+		limit = min(parsed, FeedMaxEntries)
+	}
+
+	path := h.getPathFromQuery(r)
+	files, err := h.ucFor(r).RecentFiles(path, limit)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	origin := requestOrigin(r)
+	feed := atomFeed{
+		XMLNS:   atomNamespace,
+		ID:      origin + r.URL.RequestURI(),
+		Title:   "/" + path,
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: feedAuthor},
+		Link:    atomLink{Rel: "self", Href: origin + r.URL.RequestURI()},
+	}
+	if len(files) > 0 {
+		feed.Updated = files[0].ModTime.UTC().Format(time.RFC3339)
+	}
+	for _, f := range files {
+		if h.isForbidden(f.Name) {
+			continue
+		}
+		feed.Entries = append(feed.Entries, h.feedEntry(origin, f))
+	}
+
+	w.Header().Set("Content-Type", mimeAtom)
+	if _, writeErr := w.Write([]byte(xml.Header)); writeErr != nil {
+		return
+	}
+	if encodeErr := xml.NewEncoder(w).Encode(feed); encodeErr != nil {
+		logrus.Errorf("Failed to write feed for '%s': %v", path, encodeErr)
+	}
+}
+
+func (h *Handler) feedEntry(origin string, f domain.FileData) atomEntry {
+	entry := atomEntry{
+		ID:      "urn:file-manager:" + url.PathEscape(f.Path) + ":" + strconv.FormatInt(f.ModTime.UnixNano(), 10),
+		Title:   f.Name,
+		Updated: f.ModTime.UTC().Format(time.RFC3339),
+		Summary: fmt.Sprintf("%s, %d bytes", f.Path, f.Size),
+	}
+	if h.downloadRoute != "" {
+		query := url.Values{}
+		query.Set(QueryParamPath, f.Path)
+		entry.Link = &atomLink{Rel: "enclosure", Href: origin + h.downloadRoute + "?" + query.Encode(), Length: f.Size}
+	}
+	return entry
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestHandler_Feed(t *testing.T) {
+	newest := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	files := []domain.FileData{
+		{Name: "scan.pdf", Path: "inbox/scan.pdf", Size: 2048, ModTime: newest},
+		{Name: "run.exe", Path: "inbox/run.exe", Size: 10, ModTime: newest.Add(-time.Minute)},
+		{Name: "a b.txt", Path: "inbox/a b.txt", Size: 3, ModTime: newest.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		route       string
+		err         error
+		wantStatus  int
+		wantLimit   int
+		wantEntries []string
+	}{
+		{name: "default limit", url: "/feed?path=inbox", route: "/download", wantStatus: http.StatusOK,
+			wantLimit: DefaultRecentLimit, wantEntries: []string{"scan.pdf", "a b.txt"}},
+		{name: "limit is capped", url: "/feed?path=inbox&limit=100000", route: "/download",
+			wantStatus: http.StatusOK, wantLimit: FeedMaxEntries, wantEntries: []string{"scan.pdf", "a b.txt"}},
+		{name: "no download route", url: "/feed?path=inbox", wantStatus: http.StatusOK,
+			wantLimit: DefaultRecentLimit, wantEntries: []string{"scan.pdf", "a b.txt"}},
+		{name: "bad limit", url: "/feed?path=inbox&limit=many", wantStatus: http.StatusBadRequest},
+		{name: "missing folder", url: "/feed?path=inbox", err: domain.ErrFileNotFound,
+			wantStatus: http.StatusNotFound, wantLimit: DefaultRecentLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotLimit int
+			mockUC := &mockFileManagement{
+				recentFilesFunc: func(path string, limit int) ([]domain.FileData, error) {
+					assert.Equal(t, "inbox", path)
+					gotLimit = limit
+					return files, tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+			handler.forbiddenExt = []string{".exe"}
+			WithDownloadRoute(tt.route)(handler)
+
+			w := httptest.NewRecorder()
+			handler.Feed(w, httptest.NewRequest("GET", "http://files.local"+tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantLimit, gotLimit)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, mimeAtom, w.Header().Get("Content-Type"))
+
+			var feed atomFeed
+			require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+			assert.Equal(t, "2025-03-01T10:00:00Z", feed.Updated)
+			var titles []string
+			for _, e := range feed.Entries {
+				titles = append(titles, e.Title)
+			}
+			assert.Equal(t, tt.wantEntries, titles)
+
+			first := feed.Entries[0]
+			assert.Equal(t, "2025-03-01T10:00:00Z", first.Updated)
+			assert.Contains(t, first.Summary, "2048 bytes")
+			if tt.route == "" {
+				assert.Nil(t, first.Link)
+				return
+			}
+			require.NotNil(t, first.Link)
+			assert.Equal(t, "http://files.local/download?path=inbox%2Fscan.pdf", first.Link.Href)
+			assert.Equal(t, int64(2048), first.Link.Length)
+			assert.Equal(t, "http://files.local/download?path=inbox%2Fa+b.txt", feed.Entries[1].Link.Href)
+		})
+	}
+}
@@ -1,12 +1,24 @@
 package server
 
 import (
+	"context"
+	"crypto/md5" //nolint:gosec // только размер дайджеста для проверки заголовка.
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"math"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -14,19 +26,77 @@ import (
 	"file-manager/internal/domain"
 )
 
+// storageHealth источник флага доступности хранилища, в проде это usecases.StorageHealth.
+type storageHealth interface {
+	Healthy() bool
+}
+
+// trashPurger фоновая очистка корзины, в проде это usecases.TrashPurger.
+type trashPurger interface {
+	LastRun() (domain.TrashPurgeReport, bool)
+}
+
+// aclPolicy юзкейс, урезанный по правам пользователя запроса (usecases.ACL).
+type aclPolicy interface {
+	For(user domain.User) domain.FileManagement
+}
+
 type Handler struct {
-	uc            domain.FileManagement
-	staticPath    string
-	templateFile  string
-	maxUploadSize int64
-	forbiddenExt  []string
-	messages      config.Messages
+	uc                domain.FileManagement
+	staticPath        string
+	templateFile      string
+	maxUploadSize     int64
+	forbiddenExt      []string
+	messages          config.Messages
+	uploadLimiter     *clientLimiter
+	maxRequests       int
+	rateLimiter       *rateLimiter
+	maxUploadBPS      int64
+	maxDownloadBPS    int64
+	uploadTimeout     time.Duration
+	verifyTimeout     time.Duration
+	duplicatesTimeout time.Duration
+	slowOpThreshold   time.Duration
+	deleteTokens      *deleteTokens
+	shareLinks        *shareLinks
+	storageHealth     storageHealth
+	trashPurger       trashPurger
+	acl               aclPolicy
+	adminToken        string
+	// csrfProtection формы через handlePost требуют токен страницы (см. checkCSRF).
+	csrfProtection bool
+	// auth учётки RequireAuth, nil — аутентификация выключена.
+	auth *authSettings
+	// downloadRoute путь скачивания для ссылок в /feed, пусто — записи ленты без ссылок.
+	downloadRoute string
+	// folderRedirect куда отправлять /download папки (маршрут zip папки), пусто — отвечать 400.
+	folderRedirect string
+	// logFile путь к лог-файлу для /logs, пусто — логи пишутся не в файл.
+	logFile         string
+	logPollInterval time.Duration
+	// templateReload парсить шаблон на каждый запрос (разработка), иначе templateCache.
+	templateReload bool
+	templateMu     sync.Mutex
+	templateCache  *template.Template
+	buildInfo      BuildInfo
 }
 
+// json-теги для Browse в режиме JSON (SPA), шаблону они не мешают.
 type browseData struct {
-	Path   string
-	Parent string
-	Files  []domain.FileData
+	Path   string `json:"path"`
+	Parent string `json:"parent"`
+	Filter string `json:"filter,omitempty"`
+	AgeMax string `json:"age_max,omitempty"`
+	// Sort, Order, DirsFirst текущая сортировка, чтобы шаблон собрал ссылки заголовков.
+	Sort      string            `json:"sort,omitempty"`
+	Order     string            `json:"order"`
+	DirsFirst bool              `json:"dirs_first"`
+	Files     []domain.FileData `json:"files"`
+	// DeleteTokens токены подтверждения удаления по имени файла, пусто если подтверждение выключено.
+	DeleteTokens map[string]string `json:"delete_tokens,omitempty"`
+	Pagination   pagination        `json:"pagination"`
+	// CSRFToken для скрытых полей форм и заголовка X-CSRF-Token, пусто если защита выключена.
+	CSRFToken string `json:"csrf_token,omitempty"`
 }
 
 func NewHandler(
@@ -36,21 +106,40 @@ func NewHandler(
 	forbidden []string,
 	maxUploadSize int64,
 	messages config.Messages,
+	opts ...Option,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		uc:            uc,
 		staticPath:    staticPath,
 		templateFile:  templateFile,
 		maxUploadSize: maxUploadSize,
 		forbiddenExt:  forbidden,
 		messages:      messages,
+
+		logPollInterval: defaultLogPollInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 func (h *Handler) Browse(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Query().Get(QueryParamPath)
+	query := r.URL.Query()
+	path := query.Get(QueryParamPath)
+	opts, err := listOptions(query)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
 
-	files, err := h.uc.List(path)
+	page, perPage, err := parsePage(query)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	files, total, err := h.ucFor(r).ListPage(path, opts, (page-1)*perPage, perPage)
 	if err != nil {
 		h.handleError(w, err, h.messages.CannotListDirectory)
 		return
@@ -62,65 +151,378 @@ func (h *Handler) Browse(w http.ResponseWriter, r *http.Request) {
 		parent = h.normalizePath(filepath.Dir(path))
 	}
 
-	h.renderTemplate(w, browseData{
-		Path:   path,
-		Parent: parent,
-		Files:  files,
+	data := browseData{
+		Path:         path,
+		Parent:       parent,
+		Filter:       opts.Filter,
+		AgeMax:       query.Get(QueryParamAgeMax),
+		Sort:         string(opts.Sort),
+		Order:        sortOrder(opts.Desc),
+		DirsFirst:    opts.DirsFirst,
+		Files:        files,
+		DeleteTokens: h.listingDeleteTokens(path, files),
+		Pagination:   newPagination(query, page, perPage, total),
+		CSRFToken:    h.csrfToken(w, r),
+	}
+	// один адрес отдаёт и html, и json, кеши должны различать их по Accept.
+	w.Header().Add("Vary", "Accept")
+	if wantsJSON(r) {
+		if data.Files == nil {
+			data.Files = []domain.FileData{}
+		}
+		h.writeJSON(w, http.StatusOK, data)
+		return
+	}
+	h.renderTemplate(w, data)
+}
+
+// wantsJSON клиент просит JSON: `?format=json` или application/json в Accept. браузер его не шлёт,
+// так что обычная страница остаётся html.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get(QueryParamFormat) == FormatJSON {
+		return true
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if strings.TrimSpace(mediaType) == domain.MIMEJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// ListAPI листинг папки в JSON для клиентов, которые опрашивают его по таймеру. ETag считается по записям,
+// с совпавшим If-None-Match ответ 304 без тела, так что неизменившаяся папка не гоняется заново.
+func (h *Handler) ListAPI(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	path := query.Get(QueryParamPath)
+	opts, err := listOptions(query)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	files, err := h.ucFor(r).List(path, opts)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	etag := domain.ListingETag(files)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]any{"path": path, "files": files})
+}
+
+func listOptions(query url.Values) (domain.ListOptions, error) {
+	maxAge, err := parseAge(query.Get(QueryParamAgeMax))
+	if err != nil {
+		return domain.ListOptions{}, err
+	}
+	var desc bool
+	switch order := query.Get(QueryParamOrder); order {
+	case "", QueryValueAsc:
+	case QueryValueDesc:
+		desc = true
+	default:
+		return domain.ListOptions{}, fmt.Errorf("invalid %s '%s': %w",
+			QueryParamOrder, order, domain.ErrInvalidParameter)
+	}
+	// неизвестное поле сортировки отклонит юзкейс.
+	return domain.ListOptions{
+		Filter:     query.Get(QueryParamFilter),
+		FilterDirs: query.Get(QueryParamFilterDirs) == QueryValueTrue,
+		MaxAge:     maxAge,
+		Sort:       domain.SortField(query.Get(QueryParamSort)),
+		Desc:       desc,
+		DirsFirst:  query.Get(QueryParamDirsFirst) == QueryValueTrue,
+	}, nil
+}
+
+func sortOrder(desc bool) string {
+	if desc {
+		return QueryValueDesc
+	}
+	return QueryValueAsc
+}
+
+// etagMatches If-None-Match со списком через запятую и `*`. сравнение слабое, как требует RFC 9110 для GET.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAge понимает длительность Go (`36h`) и дни (`7d`), пусто — без ограничения.
+func parseAge(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	if days, found := strings.CutSuffix(raw, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if age, err := time.ParseDuration(raw); err == nil && age > 0 {
+		return age, nil
+	}
+	return 0, fmt.Errorf("invalid %s '%s': %w", QueryParamAgeMax, raw, domain.ErrInvalidParameter)
+}
+
+func (h *Handler) listingDeleteTokens(path string, files []domain.FileData) map[string]string {
+	if h.deleteTokens == nil {
+		return nil
+	}
+	tokens := make(map[string]string, len(files))
+	for _, f := range files {
+		tokens[f.Name], _ = h.deleteTokens.issue(h.buildFullPath(path, f.Name))
+	}
+	return tokens
+}
+
+// ConfirmDelete выдаёт токен подтверждения удаления для конкретного пути.
+func (h *Handler) ConfirmDelete(w http.ResponseWriter, r *http.Request) {
+	if h.deleteTokens == nil {
+		h.handleError(w, fmt.Errorf("delete confirmation is disabled: %w", domain.ErrUnsupportedOperation),
+			h.messages.InternalError)
+		return
+	}
+
+	path := h.getPathFromQuery(r)
+	token, expires := h.deleteTokens.issue(path)
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"path":       path,
+		"token":      token,
+		"expires_at": expires,
 	})
 }
 
 func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
-	h.handlePost(w, r, func() error {
-		r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	// слот освобождается через defer, так что ошибка загрузки или обрыв соединения его не потеряют.
+	if h.uploadLimiter != nil {
+		client := clientIP(r)
+		if !h.uploadLimiter.acquire(client) {
+			logrus.Warnf("Too many concurrent uploads from %s", client)
+			http.Error(w, h.messages.TooManyRequests, http.StatusTooManyRequests)
+			return
+		}
+		defer h.uploadLimiter.release(client)
+	}
+	h.setUploadDeadline(w)
+
+	h.handleMultipartPost(w, r, func() error {
+		// скорость режем по сети, то есть до распаковки gzip.
+		r.Body = h.throttleUpload(r.Body)
+		// лимит ставится на уже распакованный поток, иначе маленький gzip развернётся в гигабайты.
+		body, err := decodeRequestBody(r)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		// в одном запросе до MaxUploadFiles файлов, каждый со своим лимитом max_upload_size.
+		requestLimit := h.maxUploadSize
+		if requestLimit <= math.MaxInt64/MaxUploadFiles {
+			requestLimit *= MaxUploadFiles
+		}
+		r.Body = http.MaxBytesReader(w, body, requestLimit)
 
 		// роверяем ContentLength, чтобы отклонить слишком большие загрузки
 		// ContentLength может быть -1 при chunked-передаче, поэтому дополнительно проверяем header.Size.
-		if r.ContentLength > h.maxUploadSize {
-			return fmt.Errorf("file size %d exceeds maximum %d: %w",
-				r.ContentLength, h.maxUploadSize, domain.ErrUnsupportedOperation)
+		if r.ContentLength > requestLimit {
+			return fmt.Errorf("upload size %d exceeds maximum %d: %w",
+				r.ContentLength, requestLimit, domain.ErrUnsupportedOperation)
 		}
 
-		file, header, err := r.FormFile(FormParamFile)
+		// FormFile разбирает всю форму, дальше файлы берутся из r.MultipartForm.
+		first, _, err := r.FormFile(FormParamFile)
 		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return fmt.Errorf("upload exceeds maximum %d: %w", requestLimit, domain.ErrUnsupportedOperation)
+			}
 			return fmt.Errorf("failed to get form file: %w", err)
 		}
-		defer file.Close()
-
-		// дополнительная проверка размера, после разбора формы
-		if header.Size > h.maxUploadSize {
-			return fmt.Errorf("file size %d exceeds maximum %d: %w",
-				header.Size, h.maxUploadSize, domain.ErrUnsupportedOperation)
+		_ = first.Close()
+		if err = h.checkCSRF(r); err != nil {
+			return err
+		}
+		headers := r.MultipartForm.File[FormParamFile]
+		if len(headers) > MaxUploadFiles {
+			return fmt.Errorf("%d files in one upload, at most %d: %w",
+				len(headers), MaxUploadFiles, domain.ErrInvalidParameter)
 		}
 
-		if h.isForbidden(header.Filename) {
-			return domain.ErrUnsupportedOperation
+		contentMD5, err := parseContentMD5(r.Header.Get(HeaderContentMD5))
+		if err != nil {
+			return err
+		}
+		if contentMD5 != nil && len(headers) > 1 {
+			return fmt.Errorf("Content-MD5 with %d files is ambiguous: %w", len(headers), domain.ErrInvalidParameter)
+		}
+		contentSHA256, err := parseContentSHA256(r)
+		if err != nil {
+			return err
+		}
+		if contentSHA256 != nil && len(headers) > 1 {
+			return fmt.Errorf("%s with %d files is ambiguous: %w",
+				HeaderContentSHA256, len(headers), domain.ErrInvalidParameter)
 		}
 
 		currentPath := r.FormValue(FormParamPath)
-		targetPath := h.buildFullPath(currentPath, header.Filename)
-
-		if uploadErr := h.uc.UploadFile(targetPath, file); uploadErr != nil {
-			return uploadErr
+		user, _ := domain.UserFromContext(r.Context())
+		opts := domain.UploadOptions{
+			Conflict:      domain.ConflictPolicy(r.FormValue(FormParamConflict)),
+			ContentMD5:    contentMD5,
+			ContentSHA256: contentSHA256,
+			Uploader:      user.Name,
+		}
+		// If-None-Match: * только создание, как у PUT в HTTP: существующий файл не трогается, ответ 412.
+		// повтор такой загрузки безопасен, файл, созданный параллельно, не затрётся.
+		createOnly := r.Header.Get(HeaderIfNoneMatch) == "*"
+		if createOnly {
+			opts.Conflict = domain.ConflictError
 		}
 
-		logrus.WithFields(logrus.Fields{
-			"operation": OperationUpload,
-			"path":      targetPath,
-			"size":      header.Size,
-		}).Info(LogFileUploaded)
+		if len(headers) == 1 {
+			storedPath, uploadErr := h.uploadFormFile(r, currentPath, headers[0], opts)
+			if uploadErr != nil {
+				return createOnlyError(uploadErr, createOnly)
+			}
+			// при rename файл мог лечь как `file (1).txt`: итоговый путь в заголовке, а клиенту JSON — в теле.
+			w.Header().Set(HeaderStoredPath, storedPath)
+			if wantsJSON(r) {
+				h.writeJSON(w, http.StatusCreated, []uploadResult{
+					{Name: headers[0].Filename, Path: storedPath, Status: http.StatusCreated},
+				})
+				return nil
+			}
+			h.redirectToPath(w, r, currentPath)
+			return nil
+		}
 
+		// несколько файлов: каждый проверяется и грузится сам по себе, ошибка одного не отменяет остальные.
+		results := make([]uploadResult, 0, len(headers))
+		failed := false
+		for _, header := range headers {
+			result := uploadResult{Name: header.Filename}
+			storedPath, uploadErr := h.uploadFormFile(r, currentPath, header, opts)
+			if uploadErr != nil {
+				failed = true
+				uploadErr = createOnlyError(uploadErr, createOnly)
+				result.Status, result.Error = h.errorResponse(uploadErr, h.messages.InternalError)
+				logrus.Errorf("HTTP %d Error: %s. Details: %+v", result.Status, result.Error, uploadErr)
+			} else {
+				result.Path, result.Status = storedPath, http.StatusCreated
+			}
+			results = append(results, result)
+		}
+		if failed {
+			h.writeJSON(w, http.StatusMultiStatus, results)
+			return nil
+		}
+		if wantsJSON(r) {
+			h.writeJSON(w, http.StatusCreated, results)
+			return nil
+		}
 		h.redirectToPath(w, r, currentPath)
 		return nil
 	}, h.messages.InternalError)
 }
 
+// createOnlyError при If-None-Match: * существующий файл это несработавшее предусловие (412), а не конфликт (409).
+func createOnlyError(err error, createOnly bool) error {
+	if createOnly && errors.Is(err, domain.ErrAlreadyExists) {
+		return fmt.Errorf("%s, If-None-Match: *: %w", err.Error(), domain.ErrPreconditionFailed)
+	}
+	return err
+}
+
+// uploadResult итог одного файла из пакетной загрузки: Path при успехе, иначе Error с текстом для клиента.
+type uploadResult struct {
+	Name   string `json:"name"`
+	Path   string `json:"path,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// uploadFormFile проверки и загрузка одного файла формы, возвращает путь, под которым файл сохранён.
+func (h *Handler) uploadFormFile(
+	r *http.Request, currentPath string, header *multipart.FileHeader, opts domain.UploadOptions,
+) (string, error) {
+	// дополнительная проверка размера, после разбора формы
+	if header.Size > h.maxUploadSize {
+		return "", fmt.Errorf("file size %d exceeds maximum %d: %w",
+			header.Size, h.maxUploadSize, domain.ErrUnsupportedOperation)
+	}
+
+	if h.isForbidden(header.Filename) {
+		return "", domain.ErrUnsupportedOperation
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open form file: %w", err)
+	}
+	defer file.Close()
+
+	targetPath := h.buildFullPath(currentPath, header.Filename)
+	storedPath, err := h.ucFor(r).UploadFile(targetPath, file, opts)
+	if err != nil {
+		return "", err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationUpload,
+		"path":      storedPath,
+		"size":      header.Size,
+	}).Info(LogFileUploaded)
+	return storedPath, nil
+}
+
+// parseContentMD5 разбирает Content-MD5 (RFC 1864, base64 от 16 байт).
+// для multipart-загрузки это MD5 самого файла, а не всего тела запроса.
+func parseContentMD5(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	sum, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sum) != md5.Size {
+		return nil, fmt.Errorf("malformed Content-MD5 '%s': %w", value, domain.ErrInvalidParameter)
+	}
+	return sum, nil
+}
+
+// parseContentSHA256 ожидаемый sha256 загрузки в hex: заголовок X-Content-SHA256 или поле формы sha256
+// (его может прислать обычная html-форма). пусто — не проверять.
+func parseContentSHA256(r *http.Request) ([]byte, error) {
+	value := r.Header.Get(HeaderContentSHA256)
+	if value == "" {
+		value = r.FormValue(FormParamSHA256)
+	}
+	if value == "" {
+		return nil, nil
+	}
+	sum, err := hex.DecodeString(value)
+	if err != nil || len(sum) != sha256.Size {
+		return nil, fmt.Errorf("malformed %s '%s': %w", HeaderContentSHA256, value, domain.ErrInvalidParameter)
+	}
+	return sum, nil
+}
+
 func (h *Handler) CreateFolder(w http.ResponseWriter, r *http.Request) {
 	h.handlePost(w, r, func() error {
 		name := r.FormValue(FormParamName)
 		currentPath := r.FormValue(FormParamPath)
 		fullPath := h.buildFullPath(currentPath, name)
 
-		if err := h.uc.CreateFolder(fullPath); err != nil {
+		if err := h.ucFor(r).CreateFolder(fullPath); err != nil {
 			return err
 		}
 
@@ -134,19 +536,82 @@ func (h *Handler) CreateFolder(w http.ResponseWriter, r *http.Request) {
 	}, h.messages.InternalError)
 }
 
+// CreateTree создаёт структуру папок одним запросом: path — родитель, name повторяется для каждой подпапки.
+func (h *Handler) CreateTree(w http.ResponseWriter, r *http.Request) {
+	h.handlePost(w, r, func() error {
+		if err := r.ParseForm(); err != nil {
+			return fmt.Errorf("failed to parse form: %v: %w", err, domain.ErrInvalidParameter)
+		}
+		parent := r.FormValue(FormParamPath)
+		subdirs := r.Form[FormParamName]
+
+		if err := h.ucFor(r).CreateTree(h.normalizePath(parent), subdirs); err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"operation": OperationCreateFolder,
+			"path":      parent,
+			"subdirs":   subdirs,
+		}).Info(LogFolderCreated)
+
+		h.redirectToPath(w, r, parent)
+		return nil
+	}, h.messages.InternalError)
+}
+
+// Delete только POST, чтобы его закрывала проверка CSRF. параметры берутся из формы или query.
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	path := h.getPathFromQuery(r)
-	if err := h.uc.Delete(path); err != nil {
-		h.handleError(w, err, h.messages.CannotDelete)
-		return
+	h.handlePost(w, r, func() error {
+		path := r.FormValue(FormParamPath)
+		if h.deleteTokens != nil {
+			if err := h.deleteTokens.validate(path, r.FormValue(QueryParamToken)); err != nil {
+				return err
+			}
+		}
+
+		opts, err := parseDeleteOptions(r)
+		if err != nil {
+			return err
+		}
+
+		if err := h.ucFor(r).Delete(path, opts); err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"operation": OperationDelete,
+			"path":      path,
+		}).Info(LogFileOrFolderDeleted)
+
+		h.redirectToPath(w, r, h.normalizeParentPath(path))
+		return nil
+	}, h.messages.CannotDelete)
+}
+
+// parseDeleteOptions читает if-size (байты) и if-mtime (unix-секунды или RFC 3339).
+func parseDeleteOptions(r *http.Request) (domain.DeleteOptions, error) {
+	var opts domain.DeleteOptions
+
+	if raw := r.FormValue(QueryParamIfSize); raw != "" {
+		size, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return opts, fmt.Errorf("%s '%s': %w", QueryParamIfSize, raw, domain.ErrInvalidParameter)
+		}
+		opts.IfSize = &size
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"operation": OperationDelete,
-		"path":      path,
-	}).Info(LogFileOrFolderDeleted)
+	if raw := r.FormValue(QueryParamIfMTime); raw != "" {
+		var mtime time.Time
+		if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			mtime = time.Unix(unix, 0)
+		} else if mtime, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			return opts, fmt.Errorf("%s '%s': %w", QueryParamIfMTime, raw, domain.ErrInvalidParameter)
+		}
+		opts.IfModTime = &mtime
+	}
 
-	h.redirectToPath(w, r, h.normalizeParentPath(path))
+	return opts, nil
 }
 
 func (h *Handler) Rename(w http.ResponseWriter, r *http.Request) {
@@ -156,14 +621,24 @@ func (h *Handler) Rename(w http.ResponseWriter, r *http.Request) {
 
 		parentPath := h.normalizeParentPath(oldPath)
 		newFullPath := filepath.Join(parentPath, newName)
-		if err := h.uc.Rename(oldPath, newFullPath); err != nil {
+		// старый UI политику не шлёт, тогда как раньше — перезапись.
+		policy := domain.ConflictPolicy(r.FormValue(FormParamConflict))
+		finalPath := newFullPath
+		var err error
+		// merge=true: папка поверх существующей папки сливается с ней, а не падает на непустой цели.
+		if r.FormValue(FormParamMerge) == QueryValueTrue {
+			err = h.ucFor(r).Merge(oldPath, newFullPath, policy)
+		} else {
+			finalPath, err = h.ucFor(r).Rename(oldPath, newFullPath, policy)
+		}
+		if err != nil {
 			return err
 		}
 
 		logrus.WithFields(logrus.Fields{
 			"operation": OperationRename,
 			"old_path":  oldPath,
-			"new_path":  newFullPath,
+			"new_path":  finalPath,
 		}).Info(LogFileOrFolderRenamed)
 
 		h.redirectToPath(w, r, parentPath)
@@ -171,6 +646,52 @@ func (h *Handler) Rename(w http.ResponseWriter, r *http.Request) {
 	}, h.messages.InternalError)
 }
 
+// RenameAPI то же, что Rename, но отвечает JSON с итоговым путём, чтобы клиент обновил вид без перезагрузки.
+// по умолчанию политика rename: при совпадении имени получаем `file (1).txt`, а не затираем чужой файл.
+func (h *Handler) RenameAPI(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	oldPath := r.FormValue(FormParamOld)
+	newFullPath := filepath.Join(h.normalizeParentPath(oldPath), r.FormValue(FormParamNew))
+	policy := domain.ConflictPolicy(r.FormValue(FormParamConflict))
+	// overwrite=false — сначала спросить: при занятом имени 409 с описанием того, что там лежит,
+	// и повтор с overwrite=true после подтверждения пользователя.
+	switch r.FormValue(FormParamOverwrite) {
+	case QueryValueTrue:
+		policy = domain.ConflictOverwrite
+	case QueryValueFalse:
+		policy = domain.ConflictError
+	}
+	if policy == "" {
+		policy = domain.ConflictRename
+	}
+
+	finalPath, err := h.ucFor(r).Rename(oldPath, newFullPath, policy)
+	var conflict *domain.ExistsError
+	if errors.As(err, &conflict) {
+		logrus.Infof("Rename of '%s' stopped by existing '%s'", oldPath, conflict.Existing.Path)
+		h.writeJSON(w, http.StatusConflict, map[string]any{
+			"error":    h.messages.AlreadyExists,
+			"existing": conflict.Existing,
+		})
+		return
+	}
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationRename,
+		"old_path":  oldPath,
+		"new_path":  finalPath,
+	}).Info(LogFileOrFolderRenamed)
+
+	h.writeJSON(w, http.StatusOK, map[string]string{"path": finalPath})
+}
+
 func (h *Handler) getPathFromQuery(r *http.Request) string {
 	return r.URL.Query().Get(QueryParamPath)
 }
@@ -190,24 +711,50 @@ func (h *Handler) buildFullPath(currentPath, name string) string {
 	return name
 }
 
+// serve отдаёт файл или zip папки на GET и только заголовки на HEAD (размер, тип, ETag до скачивания).
 func (h *Handler) serve(w http.ResponseWriter, r *http.Request, path string, isFolder bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", AllowGetHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
 	if h.isForbidden(filepath.Base(path)) {
 		http.Error(w, h.messages.ForbiddenFile, http.StatusForbidden)
 		return
 	}
 
+	// ошибки пишем в исходный w: лимит нужен только самому содержимому.
+	out := h.throttleDownload(w)
 	var err error
 	if isFolder {
-		err = h.uc.ServeFolderAsZip(w, path)
+		err = h.serveFolder(out, r, path)
 	} else {
-		err = h.uc.ServeFile(w, r, path)
+		err = h.ucFor(r).ServeFile(out, r, path)
 	}
 
+	if errors.Is(err, domain.ErrIsDirectory) && h.folderRedirect != "" {
+		query := url.Values{FormParamPath: {path}}
+		http.Redirect(w, r, h.folderRedirect+"?"+query.Encode(), http.StatusFound)
+		return
+	}
 	if err != nil {
 		h.handleError(w, err, h.messages.CannotServe)
 	}
 }
 
+// serveFolder архив папки в формате из ?format: zip по умолчанию или tar.gz.
+func (h *Handler) serveFolder(w http.ResponseWriter, r *http.Request, path string) error {
+	switch format := r.URL.Query().Get(QueryParamFormat); format {
+	case "", FormatZip:
+		return h.ucFor(r).ServeFolderAsZip(w, r, path)
+	case FormatTarGz:
+		return h.ucFor(r).ServeFolderAsTarGz(w, path)
+	default:
+		return fmt.Errorf("unknown archive format '%s': %w", format, domain.ErrInvalidParameter)
+	}
+}
+
+// Download отдаёт файл с Range и условными запросами, `inline=true` открывает его в браузере вместо скачивания.
 func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
 	h.serve(w, r, h.getPathFromQuery(r), false)
 }
@@ -216,7 +763,568 @@ func (h *Handler) DownloadFolder(w http.ResponseWriter, r *http.Request) {
 	h.serve(w, r, h.getPathFromQuery(r), true)
 }
 
+// DownloadSelection POST-вариант скачивания выборки: JSON-массив путей в теле, а не в URL,
+// так что сотни файлов не упираются в длину строки запроса. ?flatten=true кладёт файлы в корень архива.
+func (h *Handler) DownloadSelection(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	paths, err := readSelection(w, r)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	flatten := r.URL.Query().Get(QueryParamFlatten) == QueryValueTrue
+	if err = h.ucFor(r).ServeSelectionAsZip(h.throttleDownload(w), paths, flatten); err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+	}
+}
+
+// Archive то же, что DownloadSelection, но первой записью в zip идёт MANIFEST.txt с sha256 и размером файлов.
+func (h *Handler) Archive(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	paths, err := readSelection(w, r)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	flatten := r.URL.Query().Get(QueryParamFlatten) == QueryValueTrue
+	if err = h.ucFor(r).ServeSelectionArchive(h.throttleDownload(w), paths, flatten); err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+	}
+}
+
+// readSelection JSON-массив путей из тела запроса выборки.
+func readSelection(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	var paths []string
+	body := http.MaxBytesReader(w, r.Body, MaxSelectionBodySize)
+	if err := json.NewDecoder(body).Decode(&paths); err != nil {
+		return nil, fmt.Errorf("bad selection body: %v: %w", err, domain.ErrInvalidParameter)
+	}
+	return paths, nil
+}
+
+// Capabilities отдаёт фронтенду сводку возможностей сервера, чтобы не хардкодить их в UI.
+func (h *Handler) Capabilities(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSON(w, http.StatusOK, h.uc.Capabilities())
+}
+
+// Compare сравнивает два пути на сервере, чтобы не качать оба файла ради диффа.
+func (h *Handler) Compare(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	pathA, pathB := query.Get(QueryParamCompareA), query.Get(QueryParamCompareB)
+
+	equal, err := h.ucFor(r).Compare(pathA, pathB)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"a":     pathA,
+		"b":     pathB,
+		"equal": equal,
+	})
+}
+
+// Preview первые строки текстового файла для быстрого просмотра, lines — сколько (0 — потолок из конфига).
+func (h *Handler) Preview(w http.ResponseWriter, r *http.Request) {
+	path := h.getPathFromQuery(r)
+	maxLines := 0
+	if raw := r.URL.Query().Get(QueryParamLines); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("lines '%s': %w", raw, domain.ErrInvalidParameter), h.messages.InternalError)
+			return
+		}
+		maxLines = parsed
+	}
+
+	lines, truncated, err := h.ucFor(r).PreviewText(path, maxLines)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"path":      path,
+		"lines":     lines,
+		"truncated": truncated,
+	})
+}
+
+// Hash sha256 содержимого файла, чтобы клиент сверил скачанное без повторной загрузки.
+func (h *Handler) Hash(w http.ResponseWriter, r *http.Request) {
+	path := h.getPathFromQuery(r)
+
+	sum, err := h.ucFor(r).HashFile(path)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]string{
+		"path":   path,
+		"sha256": sum,
+	})
+}
+
+// Recent отдаёт последние изменённые файлы под path для ленты активности.
+func (h *Handler) Recent(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultRecentLimit
+	if raw := r.URL.Query().Get(QueryParamLimit); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("limit '%s': %w", raw, domain.ErrInvalidParameter), h.messages.InternalError)
+			return
+		}
+		limit = parsed
+	}
+
+	files, err := h.ucFor(r).RecentFiles(h.getPathFromQuery(r), limit)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, files)
+}
+
+// ZipManifest показывает, что войдёт в zip папки и сколько это примерно весит, до тяжёлой сборки архива.
+func (h *Handler) ZipManifest(w http.ResponseWriter, r *http.Request) {
+	path := h.getPathFromQuery(r)
+	entries, err := h.ucFor(r).ZipManifest(path)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"path":       path,
+		"entries":    entries,
+		"count":      len(entries),
+		"total_size": total,
+	})
+}
+
+// UploadChunk принимает кусок файла сырым телом: ?id=...&offset=...
+// размер одного чанка ограничен тем же max_upload_size, что и обычная загрузка; id свой у каждого пользователя.
+func (h *Handler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	h.setUploadDeadline(w)
+	r.Body = http.MaxBytesReader(w, h.throttleUpload(r.Body), h.maxUploadSize)
+	id := r.URL.Query().Get(QueryParamUploadID)
+	offset, err := strconv.ParseInt(r.URL.Query().Get(QueryParamOffset), 10, 64)
+	if err != nil {
+		h.handleError(w, fmt.Errorf("invalid offset: %w", domain.ErrInvalidParameter), h.messages.InternalError)
+		return
+	}
+
+	user, _ := domain.UserFromContext(r.Context())
+	if err = h.ucFor(r).UploadChunk(id, offset, r.Body, domain.ChunkOptions{Owner: user.Name}); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FinalizeUpload склеивает чанки в файл path, если дошли все. иначе 400 с перечнем недостающих диапазонов,
+// чтобы клиент дослал их, а не получил обрезанный файл.
+func (h *Handler) FinalizeUpload(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	id := r.FormValue(QueryParamUploadID)
+	opts, err := parseFinalizeOptions(r)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	storedPath, err := h.ucFor(r).FinalizeUpload(id, r.FormValue(FormParamPath), opts)
+	var incomplete *domain.IncompleteUploadError
+	if errors.As(err, &incomplete) {
+		logrus.Warnf("Upload %s is incomplete: %v", id, incomplete)
+		h.writeJSON(w, http.StatusBadRequest, incomplete)
+		return
+	}
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationUpload,
+		"path":      storedPath,
+		"size":      opts.ExpectedSize,
+	}).Info(LogFileUploaded)
+
+	h.writeJSON(w, http.StatusOK, map[string]any{"path": storedPath, "size": opts.ExpectedSize})
+}
+
+func parseFinalizeOptions(r *http.Request) (domain.FinalizeOptions, error) {
+	user, _ := domain.UserFromContext(r.Context())
+	opts := domain.FinalizeOptions{
+		Owner: user.Name,
+		Upload: domain.UploadOptions{
+			Conflict: domain.ConflictPolicy(r.FormValue(FormParamConflict)),
+			Uploader: user.Name,
+		},
+	}
+	contentSHA256, err := parseContentSHA256(r)
+	if err != nil {
+		return opts, err
+	}
+	opts.Upload.ContentSHA256 = contentSHA256
+
+	size, err := strconv.ParseInt(r.FormValue(QueryParamExpectedSize), 10, 64)
+	if err != nil {
+		return opts, fmt.Errorf("invalid %s: %w", QueryParamExpectedSize, domain.ErrInvalidParameter)
+	}
+	opts.ExpectedSize = size
+
+	if raw := r.FormValue(QueryParamExpectedChunks); raw != "" {
+		chunks, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return opts, fmt.Errorf("invalid %s: %w", QueryParamExpectedChunks, domain.ErrInvalidParameter)
+		}
+		opts.ExpectedChunks = chunks
+	}
+	return opts, nil
+}
+
+// StatBatch принимает JSON-массив путей и отдаёт метаданные по каждому одним ответом,
+// чтобы фронтенду не делать N запросов. отсутствующие пути помечены полем error.
+func (h *Handler) StatBatch(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	var paths []string
+	body := http.MaxBytesReader(w, r.Body, MaxStatBatchBodySize)
+	if err := json.NewDecoder(body).Decode(&paths); err != nil {
+		h.handleError(w, fmt.Errorf("bad stat-batch body: %v: %w", err, domain.ErrInvalidParameter),
+			h.messages.InternalError)
+		return
+	}
+
+	files, err := h.ucFor(r).StatMany(paths)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, files)
+}
+
+// Info сведения об одном пути, meta=1 добавляет сайдкар загрузки (file.write_upload_metadata).
+func (h *Handler) Info(w http.ResponseWriter, r *http.Request) {
+	withMeta := r.URL.Query().Get(QueryParamMeta) == "1"
+	data, err := h.ucFor(r).Info(h.getPathFromQuery(r), withMeta)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, data)
+}
+
+// Ancestors цепочка папок от корня до path для хлебных крошек.
+func (h *Handler) Ancestors(w http.ResponseWriter, r *http.Request) {
+	chain, err := h.ucFor(r).Ancestors(h.getPathFromQuery(r))
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, chain)
+}
+
+// Verify сверяет папку path с манифестом из тела (JSON-массив {path, checksum}) для проверки бэкапа.
+// ответ NDJSON: строка на каждое расхождение по мере обхода, последней строкой {"report": итог}
+// или {"error": ...}, если проверка оборвалась посередине, когда статус уже отправлен.
+func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	var manifest []domain.ManifestEntry
+	body := http.MaxBytesReader(w, r.Body, MaxVerifyBodySize)
+	if err := json.NewDecoder(body).Decode(&manifest); err != nil {
+		h.handleError(w, fmt.Errorf("bad manifest body: %v: %w", err, domain.ErrInvalidParameter),
+			h.messages.InternalError)
+		return
+	}
+
+	ctx := r.Context()
+	if h.verifyTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.verifyTimeout)
+		defer cancel()
+	}
+
+	// заголовки шлём на первом расхождении: до него ошибка (нет папки, кривой манифест) ещё может стать 4xx.
+	enc := json.NewEncoder(w)
+	started := false
+	start := func() {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", domain.MIMENDJSON)
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+	onDiff := func(diff domain.ManifestDiff) error {
+		start()
+		if err := enc.Encode(diff); err != nil {
+			return err
+		}
+		// без поддержки Flush просто уйдёт одним куском.
+		_ = http.NewResponseController(w).Flush()
+		return nil
+	}
+
+	report, err := h.ucFor(r).VerifyManifest(ctx, h.getPathFromQuery(r), manifest, onDiff)
+	if err != nil && !started {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+	start()
+	if err != nil {
+		logrus.Errorf("Manifest verification interrupted: %v", err)
+		reason := VerifyErrorInternal
+		if errors.Is(err, context.DeadlineExceeded) {
+			reason = VerifyErrorTimeout
+		}
+		if encErr := enc.Encode(map[string]string{"error": reason}); encErr != nil {
+			logrus.Errorf("Failed to encode JSON response: %v", encErr)
+		}
+		return
+	}
+	if err = enc.Encode(map[string]domain.ManifestReport{"report": report}); err != nil {
+		logrus.Errorf("Failed to encode JSON response: %v", err)
+	}
+}
+
+// Duplicates отдаёт группы одинаковых файлов под path для чистки: {sha256: [пути]}.
+// по таймауту 504, частичный результат не отдаём — он вводит в заблуждение. обрыв по лимитам обхода
+// другое дело: тело остаётся тем же словарём, а неполноту показывает заголовок X-Result-Truncated.
+func (h *Handler) Duplicates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.duplicatesTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.duplicatesTimeout)
+		defer cancel()
+	}
+
+	groups, truncated, err := h.ucFor(r).FindDuplicates(ctx, h.getPathFromQuery(r))
+	if errors.Is(err, context.DeadlineExceeded) {
+		logrus.Errorf("Duplicate search timed out: %v", err)
+		http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+		return
+	}
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	if truncated {
+		w.Header().Set(HeaderResultTruncated, QueryValueTrue)
+	}
+	h.writeJSON(w, http.StatusOK, groups)
+}
+
+// Stats сводка по дереву для дашборда: файлы, папки, байты и топ расширений.
+// результат кешируется юзкейсом, `?refresh=true` пересчитывает.
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	refresh := r.URL.Query().Get(QueryParamRefresh) == QueryValueTrue
+	stats, err := h.ucFor(r).Stats(h.getPathFromQuery(r), refresh)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, stats)
+}
+
+// Tree отдаёт дерево папки одним JSON, `?collapse=true` склеивает цепочки вроде `com/example/project`.
+func (h *Handler) Tree(w http.ResponseWriter, r *http.Request) {
+	collapse := r.URL.Query().Get(QueryParamCollapse) == QueryValueTrue
+	tree, err := h.ucFor(r).Tree(h.getPathFromQuery(r), collapse)
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotListDirectory)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, tree)
+}
+
+// Fetch скачивает url в папку path на стороне сервера, клиенту не нужно гонять файл через себя.
+func (h *Handler) Fetch(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	remoteURL := r.FormValue(FormParamURL)
+	storedPath, err := h.ucFor(r).FetchURL(r.FormValue(FormParamPath), remoteURL)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationFetch,
+		"url":       remoteURL,
+		"path":      storedPath,
+	}).Info(LogFileFetched)
+
+	h.writeJSON(w, http.StatusOK, map[string]string{"path": storedPath})
+}
+
+// Extract распаковывает zip path в папку target, без target — рядом с архивом, в его же папку.
+func (h *Handler) Extract(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	archive, target := r.FormValue(FormParamPath), r.FormValue(FormParamTarget)
+	if target == "" {
+		target = filepath.Dir(archive)
+	}
+	if err := h.ucFor(r).ExtractArchive(archive, target); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationExtract,
+		"path":      archive,
+		"target":    target,
+	}).Info(LogArchiveExtracted)
+
+	h.writeJSON(w, http.StatusOK, map[string]string{"path": target})
+}
+
+// Swap меняет местами a и b одной папки (current.bin и staged.bin при выкладке), 204 без тела.
+func (h *Handler) Swap(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	pathA, pathB := r.FormValue(FormParamSwapA), r.FormValue(FormParamSwapB)
+	if err := h.ucFor(r).Swap(pathA, pathB); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationSwap,
+		"a":         pathA,
+		"b":         pathB,
+	}).Info(LogFilesSwapped)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Rebuild сбрасывает кеши без перезапуска: target — zip, template или all.
+// шаблон живёт в хендлере, остальное сбрасывает юзкейс. эндпоинт админский, см. RequireAdmin в main.
+func (h *Handler) Rebuild(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	target := r.FormValue(FormParamTarget)
+	var rebuilt []string
+	if target == domain.RebuildTemplate || target == domain.RebuildAll {
+		h.resetTemplate()
+		rebuilt = append(rebuilt, domain.RebuildTemplate)
+	}
+	if target != domain.RebuildTemplate {
+		cleared, err := h.uc.Rebuild(target)
+		if err != nil {
+			h.handleError(w, err, h.messages.InternalError)
+			return
+		}
+		rebuilt = append(rebuilt, cleared...)
+	}
+
+	logrus.WithFields(logrus.Fields{"target": target, "rebuilt": rebuilt}).Info("Caches rebuilt")
+	h.writeJSON(w, http.StatusOK, map[string]any{"target": target, "rebuilt": rebuilt})
+}
+
+// Bundle отдаёт маленькую папку одним JSON {путь: {content_type, data(base64)}} для офлайн-клиентов.
+// большие папки получают 413 с советом качать zip.
+func (h *Handler) Bundle(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.ucFor(r).Bundle(h.getPathFromQuery(r))
+	if err != nil {
+		h.handleError(w, err, h.messages.CannotServe)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, bundle)
+}
+
+// Prune удаляет пустые папки под path, которые остаются после массовых удалений.
+func (h *Handler) Prune(w http.ResponseWriter, r *http.Request) {
+	h.handlePost(w, r, func() error {
+		path := r.FormValue(FormParamPath)
+		removed, err := h.ucFor(r).PruneEmptyDirs(path)
+		if err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"operation": OperationPrune,
+			"path":      path,
+			"removed":   removed,
+		}).Info(LogEmptyDirsPruned)
+
+		h.writeJSON(w, http.StatusOK, map[string]any{"path": path, "removed": removed})
+		return nil
+	}, h.messages.InternalError)
+}
+
+// RequireStorage отвечает 503, пока хранилище недоступно, вместо пачки ложных 404.
+func (h *Handler) RequireStorage(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.storageHealth != nil && !h.storageHealth.Healthy() {
+			h.handleError(w, domain.ErrStorageUnavailable, h.messages.StorageUnavailable)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Ready readiness-проба для оркестратора: 200 если хранилище доступно, иначе 503.
+func (h *Handler) Ready(w http.ResponseWriter, _ *http.Request) {
+	if h.storageHealth != nil && !h.storageHealth.Healthy() {
+		h.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": h.messages.StorageUnavailable})
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
 func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request, handler func() error, message string) {
+	h.handleMultipartPost(w, r, func() error {
+		if err := h.checkCSRF(r); err != nil {
+			return err
+		}
+		return handler()
+	}, message)
+}
+
+// handleMultipartPost как handlePost, но csrf проверяет сам handler, когда разберёт форму под лимитами.
+func (h *Handler) handleMultipartPost(w http.ResponseWriter, r *http.Request, handler func() error, message string) {
 	if r.Method != http.MethodPost {
 		h.redirectToPath(w, r, "")
 		return
@@ -228,12 +1336,33 @@ func (h *Handler) handlePost(w http.ResponseWriter, r *http.Request, handler fun
 	}
 }
 
+// allowPost для JSON-эндпоинтов: не POST — 405, без csrf-токена — 403. false — ответ уже записан.
+func (h *Handler) allowPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := h.checkCSRF(r); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return false
+	}
+	return true
+}
+
 type errorType int
 
 const (
 	errorTypeBadRequest errorType = iota
 	errorTypeForbidden
 	errorTypeNotFound
+	errorTypeConflict
+	errorTypePreconditionFailed
+	errorTypeUnavailable
+	errorTypeTooLarge
+	errorTypeStorageFull
+	errorTypeBundleTooLarge
+	errorTypeChecksumMismatch
 	errorTypeInternal
 )
 
@@ -241,21 +1370,51 @@ const (
 // централизация преоброзования ошибок.
 func (h *Handler) getErrorType(err error) errorType {
 	switch {
-	case errors.Is(err, domain.ErrPathTraversal) || errors.Is(err, domain.ErrInvalidName) || errors.Is(err, domain.ErrPathTooLong):
+	// ErrStorageUnavailable и ErrArchiveTooLarge оборачивают ErrUnsupportedOperation, поэтому проверяются первыми.
+	case errors.Is(err, domain.ErrStorageUnavailable):
+		return errorTypeUnavailable
+	case errors.Is(err, domain.ErrArchiveTooLarge):
+		return errorTypeTooLarge
+	case errors.Is(err, domain.ErrChecksumMismatch):
+		return errorTypeChecksumMismatch
+	case errors.Is(err, domain.ErrPathTraversal) || errors.Is(err, domain.ErrInvalidName) ||
+		errors.Is(err, domain.ErrPathTooLong) || errors.Is(err, domain.ErrInvalidParameter):
 		return errorTypeBadRequest
 	case errors.Is(err, domain.ErrUnsupportedOperation) || errors.Is(err, domain.ErrPermissionDenied):
 		return errorTypeForbidden
 	case errors.Is(err, domain.ErrFileNotFound):
 		return errorTypeNotFound
+	case errors.Is(err, domain.ErrAlreadyExists):
+		return errorTypeConflict
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return errorTypePreconditionFailed
+	case errors.Is(err, domain.ErrStorageFull):
+		return errorTypeStorageFull
+	case errors.Is(err, domain.ErrBundleTooLarge):
+		return errorTypeBundleTooLarge
 	default:
 		return errorTypeInternal
 	}
 }
 
+// ucFor юзкейс для пользователя запроса: с acl операции над чужими папками дают ErrPermissionDenied.
+// запрос по подписанной ссылке acl не проверяет, см. sharedLinkKey.
+func (h *Handler) ucFor(r *http.Request) domain.FileManagement {
+	if shared, _ := r.Context().Value(sharedLinkKey{}).(bool); h.acl == nil || shared {
+		return h.uc
+	}
+	user, _ := domain.UserFromContext(r.Context())
+	return h.acl.For(user)
+}
+
 func (h *Handler) handleError(w http.ResponseWriter, err error, message string) {
-	var httpStatus int
-	var clientMessage string
+	httpStatus, clientMessage := h.errorResponse(err, message)
+	logrus.Errorf("HTTP %d Error: %s. Details: %+v", httpStatus, clientMessage, err)
+	http.Error(w, clientMessage, httpStatus)
+}
 
+// errorResponse статус и текст для клиента по ошибке, message — текст для внутренних ошибок.
+func (h *Handler) errorResponse(err error, message string) (httpStatus int, clientMessage string) {
 	switch h.getErrorType(err) {
 	case errorTypeBadRequest:
 		httpStatus = http.StatusBadRequest
@@ -266,13 +1425,40 @@ func (h *Handler) handleError(w http.ResponseWriter, err error, message string)
 	case errorTypeNotFound:
 		httpStatus = http.StatusNotFound
 		clientMessage = h.messages.InternalError
+	case errorTypeConflict:
+		httpStatus = http.StatusConflict
+		clientMessage = h.messages.AlreadyExists
+	case errorTypePreconditionFailed:
+		httpStatus = http.StatusPreconditionFailed
+		clientMessage = h.messages.PreconditionFailed
+	case errorTypeUnavailable:
+		httpStatus = http.StatusServiceUnavailable
+		clientMessage = h.messages.StorageUnavailable
+	case errorTypeTooLarge:
+		httpStatus = http.StatusForbidden
+		clientMessage = h.messages.ZipTooLarge
+	case errorTypeStorageFull:
+		httpStatus = http.StatusInsufficientStorage
+		clientMessage = h.messages.StorageFull
+	case errorTypeBundleTooLarge:
+		httpStatus = http.StatusRequestEntityTooLarge
+		clientMessage = h.messages.BundleTooLarge
+	case errorTypeChecksumMismatch:
+		httpStatus = http.StatusBadRequest
+		clientMessage = h.messages.ChecksumMismatch
 	case errorTypeInternal:
 		httpStatus = http.StatusInternalServerError
 		clientMessage = message
 	}
+	return httpStatus, clientMessage
+}
 
-	logrus.Errorf("HTTP %d Error: %s. Details: %+v", httpStatus, clientMessage, err)
-	http.Error(w, clientMessage, httpStatus)
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", domain.MIMEJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		logrus.Errorf("Failed to encode JSON response: %v", err)
+	}
 }
 
 func (h *Handler) redirectToPath(w http.ResponseWriter, r *http.Request, path string) {
@@ -289,7 +1475,7 @@ func (h *Handler) normalizePath(path string) string {
 }
 
 func (h *Handler) renderTemplate(w http.ResponseWriter, data browseData) {
-	tmpl, parseErr := template.ParseFiles(filepath.Join(h.staticPath, h.templateFile))
+	tmpl, parseErr := h.template()
 	if parseErr != nil {
 		logrus.Infoln(parseErr)
 		http.Error(w, h.messages.TemplateError, http.StatusInternalServerError)
@@ -302,13 +1488,41 @@ func (h *Handler) renderTemplate(w http.ResponseWriter, data browseData) {
 	}
 }
 
+// template в проде парсит шаблон один раз, с static.template_reload — на каждый запрос,
+// чтобы правки index.html были видны без перезапуска. неудачный парсинг не кешируется.
+func (h *Handler) template() (*template.Template, error) {
+	if h.templateReload {
+		return h.parseTemplate()
+	}
+
+	h.templateMu.Lock()
+	defer h.templateMu.Unlock()
+	if h.templateCache != nil {
+		return h.templateCache, nil
+	}
+	tmpl, err := h.parseTemplate()
+	if err != nil {
+		return nil, err
+	}
+	h.templateCache = tmpl
+	return tmpl, nil
+}
+
+// resetTemplate забывает разобранный шаблон, следующий рендер перечитает файл.
+func (h *Handler) resetTemplate() {
+	h.templateMu.Lock()
+	defer h.templateMu.Unlock()
+	h.templateCache = nil
+}
+
+func (h *Handler) parseTemplate() (*template.Template, error) {
+	// имя шаблона должно совпадать с базовым именем файла, иначе Execute не найдёт его.
+	return template.New(filepath.Base(h.templateFile)).
+		Funcs(templateFuncs()).
+		ParseFiles(filepath.Join(h.staticPath, h.templateFile))
+}
+
 // isForbidden проверяет расшрения файла, можно дальше масштабировать.
 func (h *Handler) isForbidden(fileName string) bool {
-	ext := strings.ToLower(filepath.Ext(fileName))
-	for _, forbidden := range h.forbiddenExt {
-		if ext == forbidden || strings.HasPrefix(fileName, forbidden) {
-			return true
-		}
-	}
-	return false
+	return domain.IsForbiddenName(fileName, h.forbiddenExt)
 }
@@ -2,15 +2,22 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -20,27 +27,69 @@ import (
 )
 
 type mockFileManagement struct {
-	listFunc             func(path string) ([]domain.FileData, error)
-	uploadFileFunc       func(path string, file io.Reader) error
+	listFunc             func(path string, opts domain.ListOptions) ([]domain.FileData, error)
+	restoreFunc          func(path string) error
+	emptyTrashFunc       func() error
+	mergeFunc            func(srcPath, dstPath string, policy domain.ConflictPolicy) error
+	listPageFunc         func(path string, opts domain.ListOptions, offset, limit int) ([]domain.FileData, int, error)
+	uploadFileFunc       func(path string, file io.Reader, opts domain.UploadOptions) (string, error)
 	createFolderFunc     func(path string) error
-	deleteFunc           func(path string) error
-	renameFunc           func(oldPath, newPath string) error
+	deleteFunc           func(path string, opts domain.DeleteOptions) error
+	renameFunc           func(oldPath, newPath string, policy domain.ConflictPolicy) (string, error)
 	serveFileFunc        func(w http.ResponseWriter, r *http.Request, path string) error
-	serveFolderAsZipFunc func(w http.ResponseWriter, path string) error
+	serveFolderAsZipFunc func(w http.ResponseWriter, r *http.Request, path string) error
+	serveSelectionFunc   func(w http.ResponseWriter, paths []string, flatten bool) error
+	serveArchiveFunc     func(w http.ResponseWriter, paths []string, flatten bool) error
+	capabilitiesFunc     func() domain.Capabilities
+	compareFunc          func(pathA, pathB string) (bool, error)
+	recentFilesFunc      func(path string, limit int) ([]domain.FileData, error)
+	pruneEmptyDirsFunc   func(path string) (int, error)
+	openWriterFunc       func(path string) (io.WriteCloser, error)
+	zipManifestFunc      func(path string) ([]domain.ZipEntry, error)
+	uploadChunkFunc      func(id string, offset int64, chunk io.Reader, opts domain.ChunkOptions) error
+	finalizeUploadFunc   func(id, path string, opts domain.FinalizeOptions) (string, error)
+	statManyFunc         func(paths []string) ([]domain.FileData, error)
+	createTreeFunc       func(parent string, subdirs []string) error
+	bundleFunc           func(path string) (map[string]domain.BundleFile, error)
+	ancestorsFunc        func(path string) ([]domain.FileData, error)
+	rebuildFunc          func(target string) ([]string, error)
+	verifyManifestFunc   func(ctx context.Context, root string, manifest []domain.ManifestEntry,
+		onDiff func(domain.ManifestDiff) error) (domain.ManifestReport, error)
+	findDuplicatesFunc func(ctx context.Context, path string) (map[string][]string, bool, error)
+	statsFunc          func(path string, refresh bool) (domain.StorageStats, error)
+	treeFunc           func(path string, collapse bool) (*domain.TreeNode, error)
+	fetchURLFunc       func(destDir, url string) (string, error)
+	swapFunc           func(pathA, pathB string) error
+	previewTextFunc    func(path string, maxLines int) ([]string, bool, error)
+	extractArchiveFunc func(zipPath, destPath string) error
+	infoFunc           func(path string, withMeta bool) (domain.FileData, error)
+	serveTarGzFunc     func(w http.ResponseWriter, path string) error
+	hashFileFunc       func(path string) (string, error)
 }
 
-func (m *mockFileManagement) List(path string) ([]domain.FileData, error) {
+func (m *mockFileManagement) List(path string, opts domain.ListOptions) ([]domain.FileData, error) {
 	if m.listFunc != nil {
-		return m.listFunc(path)
+		return m.listFunc(path, opts)
 	}
 	return nil, nil
 }
 
-func (m *mockFileManagement) UploadFile(path string, file io.Reader) error {
+// ListPage без listPageFunc отдаёт весь List одной страницей, чтобы старые тесты Browse не менялись.
+func (m *mockFileManagement) ListPage(
+	path string, opts domain.ListOptions, offset, limit int,
+) ([]domain.FileData, int, error) {
+	if m.listPageFunc != nil {
+		return m.listPageFunc(path, opts, offset, limit)
+	}
+	files, err := m.List(path, opts)
+	return files, len(files), err
+}
+
+func (m *mockFileManagement) UploadFile(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
 	if m.uploadFileFunc != nil {
-		return m.uploadFileFunc(path, file)
+		return m.uploadFileFunc(path, file, opts)
 	}
-	return nil
+	return path, nil
 }
 
 func (m *mockFileManagement) CreateFolder(path string) error {
@@ -50,18 +99,18 @@ func (m *mockFileManagement) CreateFolder(path string) error {
 	return nil
 }
 
-func (m *mockFileManagement) Delete(path string) error {
+func (m *mockFileManagement) Delete(path string, opts domain.DeleteOptions) error {
 	if m.deleteFunc != nil {
-		return m.deleteFunc(path)
+		return m.deleteFunc(path, opts)
 	}
 	return nil
 }
 
-func (m *mockFileManagement) Rename(oldPath, newPath string) error {
+func (m *mockFileManagement) Rename(oldPath, newPath string, policy domain.ConflictPolicy) (string, error) {
 	if m.renameFunc != nil {
-		return m.renameFunc(oldPath, newPath)
+		return m.renameFunc(oldPath, newPath, policy)
 	}
-	return nil
+	return newPath, nil
 }
 
 func (m *mockFileManagement) ServeFile(w http.ResponseWriter, r *http.Request, path string) error {
@@ -71,13 +120,218 @@ func (m *mockFileManagement) ServeFile(w http.ResponseWriter, r *http.Request, p
 	return nil
 }
 
-func (m *mockFileManagement) ServeFolderAsZip(w http.ResponseWriter, path string) error {
+func (m *mockFileManagement) ServeFolderAsZip(w http.ResponseWriter, r *http.Request, path string) error {
 	if m.serveFolderAsZipFunc != nil {
-		return m.serveFolderAsZipFunc(w, path)
+		return m.serveFolderAsZipFunc(w, r, path)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Capabilities() domain.Capabilities {
+	if m.capabilitiesFunc != nil {
+		return m.capabilitiesFunc()
+	}
+	return domain.Capabilities{}
+}
+
+func (m *mockFileManagement) Compare(pathA, pathB string) (bool, error) {
+	if m.compareFunc != nil {
+		return m.compareFunc(pathA, pathB)
+	}
+	return false, nil
+}
+
+func (m *mockFileManagement) RecentFiles(path string, limit int) ([]domain.FileData, error) {
+	if m.recentFilesFunc != nil {
+		return m.recentFilesFunc(path, limit)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) PruneEmptyDirs(path string) (int, error) {
+	if m.pruneEmptyDirsFunc != nil {
+		return m.pruneEmptyDirsFunc(path)
+	}
+	return 0, nil
+}
+
+func (m *mockFileManagement) OpenWriter(path string) (io.WriteCloser, error) {
+	if m.openWriterFunc != nil {
+		return m.openWriterFunc(path)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) ZipManifest(path string) ([]domain.ZipEntry, error) {
+	if m.zipManifestFunc != nil {
+		return m.zipManifestFunc(path)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) UploadChunk(id string, offset int64, chunk io.Reader, opts domain.ChunkOptions) error {
+	if m.uploadChunkFunc != nil {
+		return m.uploadChunkFunc(id, offset, chunk, opts)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) FinalizeUpload(id, path string, opts domain.FinalizeOptions) (string, error) {
+	if m.finalizeUploadFunc != nil {
+		return m.finalizeUploadFunc(id, path, opts)
+	}
+	return "", nil
+}
+
+func (m *mockFileManagement) StatMany(paths []string) ([]domain.FileData, error) {
+	if m.statManyFunc != nil {
+		return m.statManyFunc(paths)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) CreateTree(parent string, subdirs []string) error {
+	if m.createTreeFunc != nil {
+		return m.createTreeFunc(parent, subdirs)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Bundle(path string) (map[string]domain.BundleFile, error) {
+	if m.bundleFunc != nil {
+		return m.bundleFunc(path)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) Ancestors(path string) ([]domain.FileData, error) {
+	if m.ancestorsFunc != nil {
+		return m.ancestorsFunc(path)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) ServeSelectionAsZip(w http.ResponseWriter, paths []string, flatten bool) error {
+	if m.serveSelectionFunc != nil {
+		return m.serveSelectionFunc(w, paths, flatten)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Rebuild(target string) ([]string, error) {
+	if m.rebuildFunc != nil {
+		return m.rebuildFunc(target)
+	}
+	return nil, nil
+}
+
+func (m *mockFileManagement) VerifyManifest(
+	ctx context.Context, root string, manifest []domain.ManifestEntry, onDiff func(domain.ManifestDiff) error,
+) (domain.ManifestReport, error) {
+	if m.verifyManifestFunc != nil {
+		return m.verifyManifestFunc(ctx, root, manifest, onDiff)
+	}
+	return domain.ManifestReport{}, nil
+}
+
+func (m *mockFileManagement) FindDuplicates(ctx context.Context, path string) (map[string][]string, bool, error) {
+	if m.findDuplicatesFunc != nil {
+		return m.findDuplicatesFunc(ctx, path)
+	}
+	return nil, false, nil
+}
+
+func (m *mockFileManagement) Stats(path string, refresh bool) (domain.StorageStats, error) {
+	if m.statsFunc != nil {
+		return m.statsFunc(path, refresh)
+	}
+	return domain.StorageStats{}, nil
+}
+
+func (m *mockFileManagement) Tree(path string, collapse bool) (*domain.TreeNode, error) {
+	if m.treeFunc != nil {
+		return m.treeFunc(path, collapse)
+	}
+	return &domain.TreeNode{}, nil
+}
+
+func (m *mockFileManagement) FetchURL(destDir, url string) (string, error) {
+	if m.fetchURLFunc != nil {
+		return m.fetchURLFunc(destDir, url)
+	}
+	return "", nil
+}
+
+func (m *mockFileManagement) Swap(pathA, pathB string) error {
+	if m.swapFunc != nil {
+		return m.swapFunc(pathA, pathB)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) ServeSelectionArchive(w http.ResponseWriter, paths []string, flatten bool) error {
+	if m.serveArchiveFunc != nil {
+		return m.serveArchiveFunc(w, paths, flatten)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Merge(srcPath, dstPath string, policy domain.ConflictPolicy) error {
+	if m.mergeFunc != nil {
+		return m.mergeFunc(srcPath, dstPath, policy)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Restore(path string) error {
+	if m.restoreFunc != nil {
+		return m.restoreFunc(path)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) EmptyTrash() error {
+	if m.emptyTrashFunc != nil {
+		return m.emptyTrashFunc()
+	}
+	return nil
+}
+
+func (m *mockFileManagement) PreviewText(path string, maxLines int) ([]string, bool, error) {
+	if m.previewTextFunc != nil {
+		return m.previewTextFunc(path, maxLines)
+	}
+	return nil, false, nil
+}
+
+func (m *mockFileManagement) ExtractArchive(zipPath, destPath string) error {
+	if m.extractArchiveFunc != nil {
+		return m.extractArchiveFunc(zipPath, destPath)
+	}
+	return nil
+}
+
+func (m *mockFileManagement) Info(path string, withMeta bool) (domain.FileData, error) {
+	if m.infoFunc != nil {
+		return m.infoFunc(path, withMeta)
+	}
+	return domain.FileData{}, nil
+}
+
+func (m *mockFileManagement) ServeFolderAsTarGz(w http.ResponseWriter, path string) error {
+	if m.serveTarGzFunc != nil {
+		return m.serveTarGzFunc(w, path)
 	}
 	return nil
 }
 
+func (m *mockFileManagement) HashFile(path string) (string, error) {
+	if m.hashFileFunc != nil {
+		return m.hashFileFunc(path)
+	}
+	return "", nil
+}
+
 func TestNewHandler(t *testing.T) {
 	mockUC := &mockFileManagement{}
 	messages := config.Messages{
@@ -111,7 +365,7 @@ func TestHandler_Browse(t *testing.T) {
 		require.NoError(t, err)
 
 		mockUC := &mockFileManagement{
-			listFunc: func(path string) ([]domain.FileData, error) {
+			listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
 				return []domain.FileData{
 					{Name: "file1.txt", IsDir: false},
 					{Name: "dir1", IsDir: true},
@@ -143,9 +397,121 @@ func TestHandler_Browse(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("filter passed to use case", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "index.html"), []byte("<html>{{.Filter}}</html>"), 0o644)
+		require.NoError(t, err)
+
+		var gotOpts domain.ListOptions
+		mockUC := &mockFileManagement{
+			listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+				gotOpts = opts
+				return nil, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+		handler.staticPath = tmpDir
+
+		req := httptest.NewRequest("GET", "/?path=docs&filter=*.pdf&filter_dirs=true", nil)
+		w := httptest.NewRecorder()
+
+		handler.Browse(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.ListOptions{Filter: "*.pdf", FilterDirs: true}, gotOpts)
+		assert.Contains(t, w.Body.String(), "*.pdf")
+	})
+
+	t.Run("age filter", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "index.html"), []byte("<html>{{.AgeMax}}</html>"), 0o644)
+		require.NoError(t, err)
+
+		tests := []struct {
+			query      string
+			wantAge    time.Duration
+			wantStatus int
+		}{
+			{"7d", 7 * 24 * time.Hour, http.StatusOK},
+			{"36h", 36 * time.Hour, http.StatusOK},
+			{"0d", 0, http.StatusBadRequest},
+			{"-1h", 0, http.StatusBadRequest},
+			{"week", 0, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			var gotOpts domain.ListOptions
+			mockUC := &mockFileManagement{
+				listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+					gotOpts = opts
+					return nil, nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+			handler.staticPath = tmpDir
+
+			w := httptest.NewRecorder()
+			handler.Browse(w, httptest.NewRequest("GET", "/?path=inbox&age_max="+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, tt.query)
+			assert.Equal(t, tt.wantAge, gotOpts.MaxAge, tt.query)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), tt.query)
+			}
+		}
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "index.html"), []byte("<html>{{.Sort}} {{.Order}}</html>"), 0o644)
+		require.NoError(t, err)
+
+		tests := []struct {
+			query      string
+			wantOpts   domain.ListOptions
+			wantStatus int
+		}{
+			{"sort=size&order=desc&dirs_first=true",
+				domain.ListOptions{Sort: domain.SortBySize, Desc: true, DirsFirst: true}, http.StatusOK},
+			{"sort=name&order=asc", domain.ListOptions{Sort: domain.SortByName}, http.StatusOK},
+			{"sort=modtime", domain.ListOptions{Sort: domain.SortByModTime}, http.StatusOK},
+			{"order=sideways", domain.ListOptions{}, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			var gotOpts domain.ListOptions
+			mockUC := &mockFileManagement{
+				listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+					gotOpts = opts
+					return nil, nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+			handler.staticPath = tmpDir
+
+			w := httptest.NewRecorder()
+			handler.Browse(w, httptest.NewRequest("GET", "/?path=docs&"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, tt.query)
+			assert.Equal(t, tt.wantOpts, gotOpts, tt.query)
+		}
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+				return nil, domain.ErrInvalidParameter
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Browse(w, httptest.NewRequest("GET", "/?sort=owner", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("error listing", func(t *testing.T) {
 		mockUC := &mockFileManagement{
-			listFunc: func(path string) ([]domain.FileData, error) {
+			listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
 				return nil, domain.ErrFileNotFound
 			},
 		}
@@ -158,15 +524,82 @@ func TestHandler_Browse(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
+	t.Run("json", func(t *testing.T) {
+		modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		mockUC := &mockFileManagement{
+			listFunc: func(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+				if path == "docs/empty" {
+					return nil, nil
+				}
+				return []domain.FileData{{Name: "a.txt", Size: 3, ModTime: modTime}}, nil
+			},
+		}
+		tests := []struct {
+			name   string
+			url    string
+			accept string
+		}{
+			{name: "format param", url: "/?path=docs/sub&format=json"},
+			{name: "accept header", url: "/?path=docs/sub", accept: "application/json"},
+			{name: "accept with quality", url: "/?path=docs/sub", accept: "text/plain;q=0.5, application/json;q=0.9"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				handler := createTestHandler(mockUC)
+				req := httptest.NewRequest("GET", tt.url, nil)
+				if tt.accept != "" {
+					req.Header.Set("Accept", tt.accept)
+				}
+				w := httptest.NewRecorder()
+
+				handler.Browse(w, req)
+
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, domain.MIMEJSON, w.Header().Get("Content-Type"))
+				assert.Equal(t, "Accept", w.Header().Get("Vary"))
+				var got struct {
+					Path   string            `json:"path"`
+					Parent string            `json:"parent"`
+					Files  []domain.FileData `json:"files"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+				assert.Equal(t, "docs/sub", got.Path)
+				assert.Equal(t, "docs", got.Parent)
+				require.Len(t, got.Files, 1)
+				assert.Equal(t, int64(3), got.Files[0].Size)
+				assert.True(t, modTime.Equal(got.Files[0].ModTime))
+			})
+		}
+
+		t.Run("empty folder is an empty array", func(t *testing.T) {
+			handler := createTestHandler(mockUC)
+			w := httptest.NewRecorder()
+
+			handler.Browse(w, httptest.NewRequest("GET", "/?path=docs/empty&format=json", nil))
+
+			assert.Contains(t, w.Body.String(), `"files":[]`)
+		})
+
+		t.Run("browser accept stays html", func(t *testing.T) {
+			handler := createTestHandler(mockUC)
+			req := httptest.NewRequest("GET", "/?path=docs/sub", nil)
+			req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+			w := httptest.NewRecorder()
+
+			handler.Browse(w, req)
+
+			assert.NotEqual(t, domain.MIMEJSON, w.Header().Get("Content-Type"))
+		})
+	})
 }
 
 func TestHandler_Upload(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		var uploadedPath string
 		mockUC := &mockFileManagement{
-			uploadFileFunc: func(path string, file io.Reader) error {
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
 				uploadedPath = path
-				return nil
+				return path, nil
 			},
 		}
 		handler := createTestHandler(mockUC)
@@ -183,187 +616,2380 @@ func TestHandler_Upload(t *testing.T) {
 		assert.Contains(t, uploadedPath, "test.txt")
 	})
 
-	t.Run("forbidden extension", func(t *testing.T) {
-		handler := createTestHandler(&mockFileManagement{})
-		handler.forbiddenExt = []string{".env"}
+	t.Run("disk full", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				return "", fmt.Errorf("failed to upload file to '%s': %w", path, domain.ErrStorageFull)
+			},
+		}
+		handler := createTestHandler(mockUC)
+		handler.messages.StorageFull = "Not enough space"
 
 		var buf bytes.Buffer
-		writer := multipartWriter(t, &buf, "config.env", "secret", "")
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
 		req := httptest.NewRequest("POST", "/upload", &buf)
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 		w := httptest.NewRecorder()
 
 		handler.Upload(w, req)
 
-		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, http.StatusInsufficientStorage, w.Code)
+		assert.Contains(t, w.Body.String(), "Not enough space")
 	})
 
-	t.Run("file too large", func(t *testing.T) {
-		handler := createTestHandler(&mockFileManagement{})
-		handler.maxUploadSize = 10
+	t.Run("conflict policy from form", func(t *testing.T) {
+		var gotOpts domain.UploadOptions
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				gotOpts = opts
+				return path, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
 
 		var buf bytes.Buffer
-		writer := multipartWriter(t, &buf, "large.txt", strings.Repeat("a", 100), "")
-		req := httptest.NewRequest("POST", "/upload", &buf)
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+		req := httptest.NewRequest("POST", "/upload?conflict=version", &buf)
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 		w := httptest.NewRecorder()
 
 		handler.Upload(w, req)
 
-		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, domain.ConflictVersion, gotOpts.Conflict)
 	})
 
-	t.Run("wrong method", func(t *testing.T) {
-		handler := createTestHandler(&mockFileManagement{})
-
-		req := httptest.NewRequest("GET", "/upload", nil)
-		w := httptest.NewRecorder()
-
-		handler.Upload(w, req)
+	t.Run("stored path", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				return "test (1).txt", nil
+			},
+		}
+		handler := createTestHandler(mockUC)
 
-		assert.Equal(t, http.StatusFound, w.Code)
+		tests := []struct {
+			name       string
+			accept     string
+			wantStatus int
+		}{
+			{name: "redirect", wantStatus: http.StatusFound},
+			{name: "json", accept: domain.MIMEJSON, wantStatus: http.StatusCreated},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+				req := httptest.NewRequest("POST", "/upload", &buf)
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				req.Header.Set("Accept", tt.accept)
+				w := httptest.NewRecorder()
+
+				handler.Upload(w, req)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				assert.Equal(t, "test (1).txt", w.Header().Get(HeaderStoredPath))
+				if tt.accept == "" {
+					return
+				}
+				var results []uploadResult
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+				assert.Equal(t, []uploadResult{
+					{Name: "test.txt", Path: "test (1).txt", Status: http.StatusCreated},
+				}, results)
+			})
+		}
 	})
-}
 
-func TestHandler_CreateFolder(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		var createdPath string
+	t.Run("content md5 passed to usecase", func(t *testing.T) {
+		var gotOpts domain.UploadOptions
 		mockUC := &mockFileManagement{
-			createFolderFunc: func(path string) error {
-				createdPath = path
-				return nil
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				gotOpts = opts
+				return path, nil
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("POST", "/create-folder", strings.NewReader("name=newfolder&path="))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		// md5("test content")
+		req.Header.Set("Content-MD5", "lHP90NiApDwht3eNNIchVw==")
 		w := httptest.NewRecorder()
 
-		handler.CreateFolder(w, req)
+		handler.Upload(w, req)
 
 		assert.Equal(t, http.StatusFound, w.Code)
-		assert.Contains(t, createdPath, "newfolder")
+		assert.Equal(t, "9473fdd0d880a43c21b7778d34872157", hex.EncodeToString(gotOpts.ContentMD5))
 	})
 
-	t.Run("error creating", func(t *testing.T) {
-		mockUC := &mockFileManagement{
-			createFolderFunc: func(path string) error {
-				return domain.ErrInvalidName
-			},
+	t.Run("content sha256", func(t *testing.T) {
+		// sha256("test content")
+		const sum = "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		tests := []struct {
+			name       string
+			header     string
+			field      string
+			ucErr      error
+			wantStatus int
+			wantSum    string
+		}{
+			{name: "header", header: sum, wantStatus: http.StatusFound, wantSum: sum},
+			{name: "form field", field: sum, wantStatus: http.StatusFound, wantSum: sum},
+			{name: "malformed", header: "abc", wantStatus: http.StatusBadRequest},
+			{name: "mismatch", header: sum, ucErr: domain.ErrChecksumMismatch, wantStatus: http.StatusBadRequest},
 		}
-		handler := createTestHandler(mockUC)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var gotOpts domain.UploadOptions
+				mockUC := &mockFileManagement{
+					uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+						gotOpts = opts
+						return path, tt.ucErr
+					},
+				}
+				handler := createTestHandler(mockUC)
+				handler.messages.ChecksumMismatch = "corrupted"
+
+				var buf bytes.Buffer
+				writer := multipart.NewWriter(&buf)
+				fileWriter, err := writer.CreateFormFile(FormParamFile, "test.txt")
+				require.NoError(t, err)
+				_, err = fileWriter.Write([]byte("test content"))
+				require.NoError(t, err)
+				if tt.field != "" {
+					require.NoError(t, writer.WriteField(FormParamSHA256, tt.field))
+				}
+				require.NoError(t, writer.Close())
+				req := httptest.NewRequest("POST", "/upload", &buf)
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				if tt.header != "" {
+					req.Header.Set(HeaderContentSHA256, tt.header)
+				}
+				w := httptest.NewRecorder()
+
+				handler.Upload(w, req)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				if tt.ucErr != nil {
+					assert.Contains(t, w.Body.String(), "corrupted")
+				}
+				if tt.wantSum != "" {
+					assert.Equal(t, tt.wantSum, hex.EncodeToString(gotOpts.ContentSHA256))
+				}
+			})
+		}
+	})
 
-		req := httptest.NewRequest("POST", "/create-folder", strings.NewReader("name=invalid<>name&path="))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	t.Run("malformed content md5", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Content-MD5", "not-base64!")
 		w := httptest.NewRecorder()
 
-		handler.CreateFolder(w, req)
+		handler.Upload(w, req)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
-}
 
-func TestHandler_Delete(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		var deletedPath string
+	t.Run("already exists", func(t *testing.T) {
 		mockUC := &mockFileManagement{
-			deleteFunc: func(path string) error {
-				deletedPath = path
-				return nil
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				return "", domain.ErrAlreadyExists
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("GET", "/delete?path=test.txt", nil)
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+		req := httptest.NewRequest("POST", "/upload?conflict=error", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
 		w := httptest.NewRecorder()
 
-		handler.Delete(w, req)
+		handler.Upload(w, req)
 
-		assert.Equal(t, http.StatusFound, w.Code)
-		assert.Equal(t, "test.txt", deletedPath)
+		assert.Equal(t, http.StatusConflict, w.Code)
 	})
 
-	t.Run("error deleting", func(t *testing.T) {
-		mockUC := &mockFileManagement{
-			deleteFunc: func(path string) error {
-				return domain.ErrFileNotFound
+	t.Run("if-none-match star", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			exists     bool
+			wantStatus int
+		}{
+			{name: "creates missing file", exists: false, wantStatus: http.StatusFound},
+			{name: "existing file", exists: true, wantStatus: http.StatusPreconditionFailed},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var gotPolicy domain.ConflictPolicy
+				mockUC := &mockFileManagement{
+					uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+						gotPolicy = opts.Conflict
+						if tt.exists {
+							return "", domain.ErrAlreadyExists
+						}
+						return path, nil
+					},
+				}
+				handler := createTestHandler(mockUC)
+
+				var buf bytes.Buffer
+				writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+				// политика из формы не важна: заголовок всегда означает только создание.
+				req := httptest.NewRequest("POST", "/upload?conflict=overwrite", &buf)
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				req.Header.Set(HeaderIfNoneMatch, "*")
+				w := httptest.NewRecorder()
+
+				handler.Upload(w, req)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				assert.Equal(t, domain.ConflictError, gotPolicy)
+			})
+		}
+	})
+
+	t.Run("uploader from auth", func(t *testing.T) {
+		var gotUploader string
+		handler := createTestHandler(&mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				gotUploader = opts.Uploader
+				return path, nil
+			},
+		})
+
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req = req.WithContext(domain.WithUser(req.Context(), domain.User{Name: "alice"}))
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "alice", gotUploader)
+	})
+
+	t.Run("forbidden extension", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		handler.forbiddenExt = []string{".env"}
+
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "config.env", "secret", "")
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		handler.maxUploadSize = 10
+
+		var buf bytes.Buffer
+		writer := multipartWriter(t, &buf, "large.txt", strings.Repeat("a", 100), "")
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("too many concurrent uploads from one client", func(t *testing.T) {
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				close(started)
+				<-unblock
+				return "", errors.New("disk error")
+			},
+		}
+		handler := createTestHandler(mockUC)
+		WithMaxConcurrentUploadsPerClient(1)(handler)
+
+		newUploadRequest := func() *http.Request {
+			var buf bytes.Buffer
+			writer := multipartWriter(t, &buf, "test.txt", "test content", "")
+			req := httptest.NewRequest("POST", "/upload", &buf)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			return req
+		}
+
+		firstDone := make(chan int)
+		go func() {
+			w := httptest.NewRecorder()
+			handler.Upload(w, newUploadRequest())
+			firstDone <- w.Code
+		}()
+		<-started
+
+		w := httptest.NewRecorder()
+		handler.Upload(w, newUploadRequest())
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		close(unblock)
+		assert.Equal(t, http.StatusInternalServerError, <-firstDone)
+
+		// слот освобождён несмотря на ошибку загрузки.
+		handler.uc = &mockFileManagement{}
+		w = httptest.NewRecorder()
+		handler.Upload(w, newUploadRequest())
+		assert.Equal(t, http.StatusFound, w.Code)
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		req := httptest.NewRequest("GET", "/upload", nil)
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+	})
+}
+
+func TestHandler_TemplateReload(t *testing.T) {
+	tests := []struct {
+		name   string
+		reload bool
+		want   string
+	}{
+		{"cached by default", false, "v1 docs"},
+		{"reload picks up edits", true, "v2 docs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			templateFile := filepath.Join(tmpDir, "index.html")
+			require.NoError(t, os.WriteFile(templateFile, []byte("v1 {{.Path}}"), 0o644))
+			handler := NewHandler(&mockFileManagement{}, tmpDir, "index.html", nil, 1024, config.Messages{},
+				WithTemplateReload(tt.reload))
+
+			browse := func() string {
+				w := httptest.NewRecorder()
+				handler.Browse(w, httptest.NewRequest("GET", "/?path=docs", nil))
+				require.Equal(t, http.StatusOK, w.Code)
+				return w.Body.String()
+			}
+
+			assert.Equal(t, "v1 docs", browse())
+			require.NoError(t, os.WriteFile(templateFile, []byte("v2 {{.Path}}"), 0o644))
+			assert.Equal(t, tt.want, browse())
+		})
+	}
+
+	t.Run("parse error is not cached", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		templateFile := filepath.Join(tmpDir, "index.html")
+		require.NoError(t, os.WriteFile(templateFile, []byte("{{.Path"), 0o644))
+		handler := NewHandler(&mockFileManagement{}, tmpDir, "index.html", nil, 1024, config.Messages{})
+
+		w := httptest.NewRecorder()
+		handler.Browse(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		require.NoError(t, os.WriteFile(templateFile, []byte("ok"), 0o644))
+		w = httptest.NewRecorder()
+		handler.Browse(w, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestHandler_CreateTree(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var gotParent string
+		var gotSubdirs []string
+		mockUC := &mockFileManagement{
+			createTreeFunc: func(parent string, subdirs []string) error {
+				gotParent, gotSubdirs = parent, subdirs
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/create-tree",
+			strings.NewReader("path=project&name=src&name=docs&name=tests"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.CreateTree(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "project", gotParent)
+		assert.Equal(t, []string{"src", "docs", "tests"}, gotSubdirs)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			createTreeFunc: func(parent string, subdirs []string) error {
+				return domain.ErrInvalidName
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/create-tree", strings.NewReader("path=&name=bad*name"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.CreateTree(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_CreateFolder(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var createdPath string
+		mockUC := &mockFileManagement{
+			createFolderFunc: func(path string) error {
+				createdPath = path
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/create-folder", strings.NewReader("name=newfolder&path="))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.CreateFolder(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Contains(t, createdPath, "newfolder")
+	})
+
+	t.Run("error creating", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			createFolderFunc: func(path string) error {
+				return domain.ErrInvalidName
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/create-folder", strings.NewReader("name=invalid<>name&path="))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.CreateFolder(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_Delete(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var deletedPath string
+		mockUC := &mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				deletedPath = path
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/delete?path=test.txt", nil)
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "test.txt", deletedPath)
+	})
+
+	t.Run("error deleting", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				return domain.ErrFileNotFound
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/delete?path=nonexistent", nil)
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("form body", func(t *testing.T) {
+		var deletedPath string
+		handler := createTestHandler(&mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				deletedPath = path
+				return nil
+			},
+		})
+		req := httptest.NewRequest("POST", "/delete", strings.NewReader("path=docs%2Fa.txt"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "docs/a.txt", deletedPath)
+	})
+
+	t.Run("get does not delete", func(t *testing.T) {
+		called := false
+		handler := createTestHandler(&mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				called = true
+				return nil
+			},
+		})
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, httptest.NewRequest("GET", "/delete?path=test.txt", nil))
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.False(t, called)
+	})
+}
+
+func TestHandler_Delete_Preconditions(t *testing.T) {
+	t.Run("params parsed", func(t *testing.T) {
+		var gotOpts domain.DeleteOptions
+		mockUC := &mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				gotOpts = opts
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/delete?path=a.txt&if-size=42&if-mtime=1700000000", nil)
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		require.NotNil(t, gotOpts.IfSize)
+		assert.Equal(t, int64(42), *gotOpts.IfSize)
+		require.NotNil(t, gotOpts.IfModTime)
+		assert.Equal(t, int64(1700000000), gotOpts.IfModTime.Unix())
+	})
+
+	t.Run("rfc3339 mtime", func(t *testing.T) {
+		var gotOpts domain.DeleteOptions
+		mockUC := &mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				gotOpts = opts
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/delete?path=a.txt&if-mtime=2024-03-01T10:00:00Z", nil)
+		handler.Delete(httptest.NewRecorder(), req)
+
+		require.NotNil(t, gotOpts.IfModTime)
+		assert.Nil(t, gotOpts.IfSize)
+		assert.True(t, gotOpts.IfModTime.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("malformed params", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		for _, query := range []string{"if-size=big", "if-mtime=yesterday"} {
+			w := httptest.NewRecorder()
+			handler.Delete(w, httptest.NewRequest("POST", "/delete?path=a.txt&"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+
+	t.Run("precondition failed", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			deleteFunc: func(path string, opts domain.DeleteOptions) error {
+				return domain.ErrPreconditionFailed
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Delete(w, httptest.NewRequest("POST", "/delete?path=a.txt&if-size=1", nil))
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestHandler_DeleteConfirmation(t *testing.T) {
+	var deleted []string
+	mockUC := &mockFileManagement{
+		deleteFunc: func(path string, opts domain.DeleteOptions) error {
+			deleted = append(deleted, path)
+			return nil
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithDeleteConfirmation(true, "secret", time.Minute)(handler)
+
+	t.Run("missing token", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/delete?path=test.txt", nil)
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, deleted)
+	})
+
+	t.Run("token for another path", func(t *testing.T) {
+		token, _ := handler.deleteTokens.issue("other.txt")
+		req := httptest.NewRequest("POST", "/delete?path=test.txt&token="+url.QueryEscape(token), nil)
+		w := httptest.NewRecorder()
+
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, deleted)
+	})
+
+	t.Run("token from confirm endpoint", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/confirm-delete?path=test.txt", nil)
+		w := httptest.NewRecorder()
+		handler.ConfirmDelete(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Token string `json:"token"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		req = httptest.NewRequest("POST", "/delete?path=test.txt&token="+url.QueryEscape(resp.Token), nil)
+		w = httptest.NewRecorder()
+		handler.Delete(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, []string{"test.txt"}, deleted)
+	})
+
+	t.Run("confirm endpoint disabled", func(t *testing.T) {
+		plain := createTestHandler(mockUC)
+		req := httptest.NewRequest("GET", "/confirm-delete?path=test.txt", nil)
+		w := httptest.NewRecorder()
+
+		plain.ConfirmDelete(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_Rename(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var oldPath, newPath string
+		mockUC := &mockFileManagement{
+			renameFunc: func(old, new string, policy domain.ConflictPolicy) (string, error) {
+				oldPath = old
+				newPath = new
+				return new, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/rename", strings.NewReader("old=old.txt&new=new.txt"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.Rename(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "old.txt", oldPath)
+		assert.Contains(t, newPath, "new.txt")
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			mergeErr   error
+			wantStatus int
+		}{
+			{name: "merged", wantStatus: http.StatusFound},
+			{name: "entries left in source", mergeErr: domain.ErrAlreadyExists, wantStatus: http.StatusConflict},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var gotSrc, gotDst string
+				var gotPolicy domain.ConflictPolicy
+				mockUC := &mockFileManagement{
+					renameFunc: func(string, string, domain.ConflictPolicy) (string, error) {
+						t.Fatal("rename must not be called with merge=true")
+						return "", nil
+					},
+					mergeFunc: func(src, dst string, policy domain.ConflictPolicy) error {
+						gotSrc, gotDst, gotPolicy = src, dst, policy
+						return tt.mergeErr
+					},
+				}
+				handler := createTestHandler(mockUC)
+
+				req := httptest.NewRequest("POST", "/rename",
+					strings.NewReader("old=docs/a&new=b&merge=true&conflict=rename"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				w := httptest.NewRecorder()
+
+				handler.Rename(w, req)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				assert.Equal(t, "docs/a", gotSrc)
+				assert.Equal(t, filepath.Join("docs", "b"), gotDst)
+				assert.Equal(t, domain.ConflictRename, gotPolicy)
+			})
+		}
+	})
+}
+
+func TestHandler_RenameAPI(t *testing.T) {
+	t.Run("returns final path", func(t *testing.T) {
+		var gotPolicy domain.ConflictPolicy
+		var gotNew string
+		mockUC := &mockFileManagement{
+			renameFunc: func(old, new string, policy domain.ConflictPolicy) (string, error) {
+				gotPolicy, gotNew = policy, new
+				return "docs/report (1).txt", nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/api/rename", strings.NewReader("old=docs/draft.txt&new=report.txt"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.RenameAPI(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.ConflictRename, gotPolicy, "API defaults to collision-safe rename")
+		assert.Equal(t, "docs/report.txt", gotNew)
+		var resp map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "docs/report (1).txt", resp["path"])
+	})
+
+	t.Run("explicit policy", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			renameFunc: func(old, new string, policy domain.ConflictPolicy) (string, error) {
+				assert.Equal(t, domain.ConflictError, policy)
+				return "", domain.ErrAlreadyExists
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/api/rename", strings.NewReader("old=a.txt&new=b.txt&conflict=error"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.RenameAPI(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("overwrite flag", func(t *testing.T) {
+		modTime := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+		tests := []struct {
+			overwrite  string
+			wantPolicy domain.ConflictPolicy
+			err        error
+			wantStatus int
+			wantBody   string
+		}{
+			{
+				overwrite: "false", wantPolicy: domain.ConflictError,
+				err: fmt.Errorf("file 'docs/b.txt': %w", &domain.ExistsError{Existing: domain.FileData{
+					Name: "b.txt", Path: "docs/b.txt", Size: 42, ModTime: modTime,
+				}}),
+				wantStatus: http.StatusConflict,
+				wantBody: `{"error":"Already exists","existing":{"name":"b.txt","is_dir":false,"path":"docs/b.txt",
+					"size":42,"mod_time":"2025-03-04T05:06:07Z"}}`,
+			},
+			{overwrite: "true", wantPolicy: domain.ConflictOverwrite, wantStatus: http.StatusOK},
+		}
+		for _, tt := range tests {
+			t.Run(tt.overwrite, func(t *testing.T) {
+				mockUC := &mockFileManagement{
+					renameFunc: func(_, _ string, policy domain.ConflictPolicy) (string, error) {
+						assert.Equal(t, tt.wantPolicy, policy)
+						return "docs/b.txt", tt.err
+					},
+				}
+				handler := createTestHandler(mockUC)
+				handler.messages.AlreadyExists = "Already exists"
+
+				body := "old=docs/a.txt&new=b.txt&conflict=rename&overwrite=" + tt.overwrite
+				req := httptest.NewRequest("POST", "/api/rename", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				w := httptest.NewRecorder()
+
+				handler.RenameAPI(w, req)
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				if tt.wantBody != "" {
+					assert.JSONEq(t, tt.wantBody, w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("GET not allowed", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		w := httptest.NewRecorder()
+		handler.RenameAPI(w, httptest.NewRequest("GET", "/api/rename", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestHandler_Upload_MultipleFiles(t *testing.T) {
+	buildRequest := func(t *testing.T, files map[string]string, order []string) *http.Request {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, name := range order {
+			fileWriter, err := writer.CreateFormFile(FormParamFile, name)
+			require.NoError(t, err)
+			_, err = fileWriter.Write([]byte(files[name]))
+			require.NoError(t, err)
+		}
+		require.NoError(t, writer.WriteField(FormParamPath, "docs"))
+		require.NoError(t, writer.Close())
+		req := httptest.NewRequest("POST", "/upload", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req
+	}
+	files := map[string]string{
+		"a.txt":      "aaa",
+		"config.env": "secret",
+		"big.txt":    strings.Repeat("b", 100),
+		"taken.txt":  "ttt",
+	}
+	many := make([]string, MaxUploadFiles+1)
+	for i := range many {
+		many[i] = "a.txt"
+	}
+
+	tests := []struct {
+		name         string
+		order        []string
+		wantStatus   int
+		wantUploaded []string
+		wantResults  []uploadResult
+	}{
+		{
+			name:         "all succeed",
+			order:        []string{"a.txt", "taken.txt"},
+			wantStatus:   http.StatusFound,
+			wantUploaded: []string{"docs/a.txt", "docs/taken.txt"},
+		},
+		{
+			name:         "failures reported per file",
+			order:        []string{"a.txt", "config.env", "big.txt", "taken.txt", "a.txt"},
+			wantStatus:   http.StatusMultiStatus,
+			wantUploaded: []string{"docs/a.txt", "docs/a.txt"},
+			wantResults: []uploadResult{
+				{Name: "a.txt", Path: "docs/a.txt", Status: http.StatusCreated},
+				{Name: "config.env", Status: http.StatusForbidden, Error: "Forbidden file"},
+				{Name: "big.txt", Status: http.StatusForbidden, Error: "Forbidden file"},
+				{Name: "taken.txt", Status: http.StatusConflict, Error: "Already exists"},
+				{Name: "a.txt", Path: "docs/a.txt", Status: http.StatusCreated},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var uploaded []string
+			mockUC := &mockFileManagement{
+				uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+					if filepath.Base(path) == "taken.txt" && tt.wantResults != nil {
+						return "", domain.ErrAlreadyExists
+					}
+					uploaded = append(uploaded, filepath.ToSlash(path))
+					return filepath.ToSlash(path), nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+			handler.forbiddenExt = []string{".env"}
+			// лимит на файл: big.txt больше, а все файлы вместе больше лимита одного.
+			handler.maxUploadSize = 64
+			handler.messages.ForbiddenFile = "Forbidden file"
+			handler.messages.AlreadyExists = "Already exists"
+			w := httptest.NewRecorder()
+
+			handler.Upload(w, buildRequest(t, files, tt.order))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUploaded, uploaded)
+			if tt.wantResults != nil {
+				var results []uploadResult
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+				assert.Equal(t, tt.wantResults, results)
+			}
+		})
+	}
+
+	t.Run("too many files", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, buildRequest(t, files, many))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("content md5 with several files", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		req := buildRequest(t, files, []string{"a.txt", "taken.txt"})
+		req.Header.Set("Content-MD5", "lHP90NiApDwht3eNNIchVw==")
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_UploadChunk(t *testing.T) {
+	t.Run("stores chunk", func(t *testing.T) {
+		var gotID string
+		var gotOffset int64
+		var gotData []byte
+		mockUC := &mockFileManagement{
+			uploadChunkFunc: func(id string, offset int64, chunk io.Reader, _ domain.ChunkOptions) error {
+				gotID, gotOffset = id, offset
+				gotData, _ = io.ReadAll(chunk)
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/upload/chunk?id=u1&offset=1024", strings.NewReader("data"))
+		handler.UploadChunk(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "u1", gotID)
+		assert.Equal(t, int64(1024), gotOffset)
+		assert.Equal(t, "data", string(gotData))
+	})
+
+	t.Run("owner from auth", func(t *testing.T) {
+		var gotOwner string
+		handler := createTestHandler(&mockFileManagement{
+			uploadChunkFunc: func(_ string, _ int64, _ io.Reader, opts domain.ChunkOptions) error {
+				gotOwner = opts.Owner
+				return nil
+			},
+		})
+
+		req := httptest.NewRequest("POST", "/upload/chunk?id=u1&offset=0", strings.NewReader("data"))
+		req = req.WithContext(domain.WithUser(req.Context(), domain.User{Name: "alice"}))
+		w := httptest.NewRecorder()
+		handler.UploadChunk(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "alice", gotOwner)
+	})
+
+	t.Run("bad offset", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		w := httptest.NewRecorder()
+		handler.UploadChunk(w, httptest.NewRequest("POST", "/upload/chunk?id=u1&offset=x", strings.NewReader("data")))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_FinalizeUpload(t *testing.T) {
+	t.Run("complete", func(t *testing.T) {
+		var gotOpts domain.FinalizeOptions
+		mockUC := &mockFileManagement{
+			finalizeUploadFunc: func(id, path string, opts domain.FinalizeOptions) (string, error) {
+				gotOpts = opts
+				assert.Equal(t, "u1", id)
+				assert.Equal(t, "docs/big.iso", path)
+				return path, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST",
+			"/upload/finalize?id=u1&path=docs/big.iso&expected_size=100&expected_chunks=4", nil)
+		req = req.WithContext(domain.WithUser(req.Context(), domain.User{Name: "alice"}))
+		handler.FinalizeUpload(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(100), gotOpts.ExpectedSize)
+		assert.Equal(t, 4, gotOpts.ExpectedChunks)
+		assert.Equal(t, "alice", gotOpts.Owner)
+		var resp map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "docs/big.iso", resp["path"])
+	})
+
+	t.Run("incomplete returns gaps", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			finalizeUploadFunc: func(id, path string, opts domain.FinalizeOptions) (string, error) {
+				return "", &domain.IncompleteUploadError{
+					Missing:      []domain.ByteRange{{Start: 50, End: 100}},
+					Chunks:       1,
+					Size:         50,
+					ExpectedSize: 100,
+				}
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.FinalizeUpload(w, httptest.NewRequest("POST", "/upload/finalize?id=u1&path=a&expected_size=100", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp domain.IncompleteUploadError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, []domain.ByteRange{{Start: 50, End: 100}}, resp.Missing)
+	})
+
+	t.Run("missing expected size", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		w := httptest.NewRecorder()
+		handler.FinalizeUpload(w, httptest.NewRequest("POST", "/upload/finalize?id=u1&path=a", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandler_StatBatch(t *testing.T) {
+	t.Run("returns per-path results", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			statManyFunc: func(paths []string) ([]domain.FileData, error) {
+				assert.Equal(t, []string{"a.txt", "gone.txt"}, paths)
+				return []domain.FileData{
+					{Name: "a.txt", Path: "a.txt", Size: 3, Checksum: "abc"},
+					{Path: "gone.txt", Error: domain.StatErrorNotFound},
+				}, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.StatBatch(w, httptest.NewRequest("POST", "/stat-batch", strings.NewReader(`["a.txt","gone.txt"]`)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp []domain.FileData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 2)
+		assert.Equal(t, "abc", resp[0].Checksum)
+		assert.Equal(t, domain.StatErrorNotFound, resp[1].Error)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"not an array", "POST", `{"paths":1}`, http.StatusBadRequest},
+		{"broken json", "POST", `[`, http.StatusBadRequest},
+		{"GET not allowed", "GET", ``, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler(&mockFileManagement{})
+
+			w := httptest.NewRecorder()
+			handler.StatBatch(w, httptest.NewRequest(tt.method, "/stat-batch", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestHandler_Ancestors(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			ancestorsFunc: func(path string) ([]domain.FileData, error) {
+				assert.Equal(t, "a/b", path)
+				return []domain.FileData{
+					{Name: "a", IsDir: true, Path: "a"},
+					{Name: "b", IsDir: true, Path: "a/b"},
+				}, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Ancestors(w, httptest.NewRequest("GET", "/ancestors?path=a/b", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp []domain.FileData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 2)
+		assert.Equal(t, "a/b", resp[1].Path)
+	})
+
+	t.Run("missing intermediate", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			ancestorsFunc: func(path string) ([]domain.FileData, error) {
+				return nil, fmt.Errorf("folder 'a' does not exist: %w", domain.ErrFileNotFound)
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Ancestors(w, httptest.NewRequest("GET", "/ancestors?path=a/b", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandler_Verify(t *testing.T) {
+	manifestBody := `[{"path":"a.txt","checksum":"00"}]`
+
+	t.Run("streams diffs then report", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			verifyManifestFunc: func(_ context.Context, root string, manifest []domain.ManifestEntry,
+				onDiff func(domain.ManifestDiff) error,
+			) (domain.ManifestReport, error) {
+				assert.Equal(t, "backup", root)
+				assert.Equal(t, []domain.ManifestEntry{{Path: "a.txt", Checksum: "00"}}, manifest)
+				require.NoError(t, onDiff(domain.ManifestDiff{Path: "a.txt", Status: domain.ManifestMissing}))
+				return domain.ManifestReport{Checked: 1, Missing: 1}, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Verify(w, httptest.NewRequest("POST", "/verify?path=backup", strings.NewReader(manifestBody)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.MIMENDJSON, w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.JSONEq(t, `{"path":"a.txt","status":"missing"}`, lines[0])
+		assert.JSONEq(t, `{"report":{"checked":1,"matched":0,"missing":1,"changed":0,"extra":0}}`, lines[1])
+	})
+
+	t.Run("timeout after streaming started", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			verifyManifestFunc: func(ctx context.Context, _ string, _ []domain.ManifestEntry,
+				onDiff func(domain.ManifestDiff) error,
+			) (domain.ManifestReport, error) {
+				_, hasDeadline := ctx.Deadline()
+				assert.True(t, hasDeadline)
+				require.NoError(t, onDiff(domain.ManifestDiff{Path: "x", Status: domain.ManifestExtra}))
+				return domain.ManifestReport{}, context.DeadlineExceeded
+			},
+		}
+		handler := createTestHandler(mockUC)
+		WithVerifyTimeout(time.Minute)(handler)
+
+		w := httptest.NewRecorder()
+		handler.Verify(w, httptest.NewRequest("POST", "/verify", strings.NewReader(manifestBody)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.JSONEq(t, `{"error":"timeout"}`, lines[1])
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"wrong method", "GET", "", nil, http.StatusMethodNotAllowed},
+		{"malformed body", "POST", "{", nil, http.StatusBadRequest},
+		{"missing root", "POST", manifestBody, domain.ErrFileNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := &mockFileManagement{
+				verifyManifestFunc: func(context.Context, string, []domain.ManifestEntry,
+					func(domain.ManifestDiff) error,
+				) (domain.ManifestReport, error) {
+					return domain.ManifestReport{}, tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.Verify(w, httptest.NewRequest(tt.method, "/verify", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_DownloadSelection(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		err         error
+		wantStatus  int
+		wantPaths   []string
+		wantFlatten bool
+	}{
+		{
+			name: "paths from body", method: "POST", url: "/download-selection", body: `["a.txt","docs/b.txt"]`,
+			wantStatus: http.StatusOK, wantPaths: []string{"a.txt", "docs/b.txt"},
+		},
+		{
+			name: "flatten", method: "POST", url: "/download-selection?flatten=true", body: `["a.txt"]`,
+			wantStatus: http.StatusOK, wantPaths: []string{"a.txt"}, wantFlatten: true,
+		},
+		{name: "wrong method", method: "GET", url: "/download-selection", wantStatus: http.StatusMethodNotAllowed},
+		{name: "malformed body", method: "POST", url: "/download-selection", body: `"a.txt"`, wantStatus: 400},
+		{
+			name: "missing file", method: "POST", url: "/download-selection", body: `["nope.txt"]`,
+			err: domain.ErrFileNotFound, wantStatus: http.StatusNotFound, wantPaths: []string{"nope.txt"},
+		},
+		{
+			name: "too large", method: "POST", url: "/download-selection", body: `["big.iso"]`,
+			err: domain.ErrArchiveTooLarge, wantStatus: http.StatusForbidden, wantPaths: []string{"big.iso"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPaths []string
+			var gotFlatten bool
+			mockUC := &mockFileManagement{
+				serveSelectionFunc: func(w http.ResponseWriter, paths []string, flatten bool) error {
+					gotPaths, gotFlatten = paths, flatten
+					return tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.DownloadSelection(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPaths, gotPaths)
+			assert.Equal(t, tt.wantFlatten, gotFlatten)
+		})
+	}
+}
+
+func TestHandler_Duplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		groups     map[string][]string
+		truncated  bool
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "groups",
+			groups:     map[string][]string{"abc": {"docs/a.txt", "docs/b.txt"}},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"abc":["docs/a.txt","docs/b.txt"]}`,
+		},
+		{name: "nothing found", groups: map[string][]string{}, wantStatus: http.StatusOK, wantBody: `{}`},
+		{
+			name:       "truncated by walk limits",
+			groups:     map[string][]string{"abc": {"docs/a.txt", "docs/b.txt"}},
+			truncated:  true,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"abc":["docs/a.txt","docs/b.txt"]}`,
+		},
+		{name: "missing folder", err: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+		{name: "timeout", err: context.DeadlineExceeded, wantStatus: http.StatusGatewayTimeout},
+		{name: "walk failure", err: errors.New("io error"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			mockUC := &mockFileManagement{
+				findDuplicatesFunc: func(ctx context.Context, path string) (map[string][]string, bool, error) {
+					_, hasDeadline := ctx.Deadline()
+					assert.True(t, hasDeadline)
+					gotPath = path
+					return tt.groups, tt.truncated, tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+			WithDuplicatesTimeout(time.Minute)(handler)
+
+			w := httptest.NewRecorder()
+			handler.Duplicates(w, httptest.NewRequest("GET", "/duplicates?path=docs", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "docs", gotPath)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			if tt.truncated {
+				assert.Equal(t, QueryValueTrue, w.Header().Get(HeaderResultTruncated))
+			} else {
+				assert.Empty(t, w.Header().Get(HeaderResultTruncated))
+			}
+		})
+	}
+}
+
+func TestHandler_Stats(t *testing.T) {
+	computed := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		url         string
+		err         error
+		wantStatus  int
+		wantPath    string
+		wantRefresh bool
+	}{
+		{name: "cached", url: "/stats?path=docs", wantStatus: http.StatusOK, wantPath: "docs"},
+		{name: "refresh", url: "/stats?refresh=true", wantStatus: http.StatusOK, wantRefresh: true},
+		{
+			name: "missing folder", url: "/stats?path=nope", err: domain.ErrFileNotFound,
+			wantStatus: http.StatusNotFound, wantPath: "nope",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var gotRefresh bool
+			mockUC := &mockFileManagement{
+				statsFunc: func(path string, refresh bool) (domain.StorageStats, error) {
+					gotPath, gotRefresh = path, refresh
+					return domain.StorageStats{
+						Files: 3, Dirs: 1, Bytes: 42,
+						Extensions: []domain.ExtensionStats{{Ext: ".txt", Files: 3, Bytes: 42}},
+						ComputedAt: computed,
+					}, tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.Stats(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPath, gotPath)
+			assert.Equal(t, tt.wantRefresh, gotRefresh)
+			if tt.err == nil {
+				assert.JSONEq(t, `{"files":3,"dirs":1,"bytes":42,"computed_at":"2025-01-02T03:04:05Z",
+					"extensions":[{"ext":".txt","files":3,"bytes":42}]}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Tree(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		err          error
+		wantStatus   int
+		wantCollapse bool
+	}{
+		{name: "plain", url: "/tree?path=src", wantStatus: http.StatusOK},
+		{name: "collapse", url: "/tree?path=src&collapse=true", wantStatus: http.StatusOK, wantCollapse: true},
+		{name: "missing folder", url: "/tree?path=src", err: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCollapse bool
+			mockUC := &mockFileManagement{
+				treeFunc: func(path string, collapse bool) (*domain.TreeNode, error) {
+					assert.Equal(t, "src", path)
+					gotCollapse = collapse
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &domain.TreeNode{Name: "src", Path: "src", IsDir: true, Children: []*domain.TreeNode{
+						{Name: "com/example", Path: "src/com/example", IsDir: true},
+					}}, nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.Tree(w, httptest.NewRequest("GET", tt.url, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCollapse, gotCollapse)
+			if tt.err == nil {
+				assert.JSONEq(t, `{"name":"src","path":"src","is_dir":true,
+					"children":[{"name":"com/example","path":"src/com/example","is_dir":true}]}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Fetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "fetched", method: http.MethodPost, wantStatus: http.StatusOK, wantBody: `{"path":"inbox/a (1).txt"}`},
+		{name: "host not allowed", method: http.MethodPost, err: domain.ErrPermissionDenied,
+			wantStatus: http.StatusForbidden},
+		{name: "bad scheme", method: http.MethodPost, err: domain.ErrInvalidParameter,
+			wantStatus: http.StatusBadRequest},
+		{name: "get is not allowed", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := &mockFileManagement{
+				fetchURLFunc: func(destDir, url string) (string, error) {
+					assert.Equal(t, "inbox", destDir)
+					assert.Equal(t, "https://example.com/a.txt", url)
+					return "inbox/a (1).txt", tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			form := strings.NewReader("path=inbox&url=" + url.QueryEscape("https://example.com/a.txt"))
+			req := httptest.NewRequest(tt.method, "/fetch", form)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.Fetch(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ListAPI(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	files := []domain.FileData{{Name: "a.txt", Size: 3, ModTime: modTime}}
+	mockUC := &mockFileManagement{
+		listFunc: func(path string, _ domain.ListOptions) ([]domain.FileData, error) {
+			if path == "missing" {
+				return nil, domain.ErrFileNotFound
+			}
+			return files, nil
+		},
+	}
+	handler := createTestHandler(mockUC)
+	etag := domain.ListingETag(files)
+
+	tests := []struct {
+		name        string
+		url         string
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    bool
+	}{
+		{name: "first poll", url: "/api/list?path=docs", wantStatus: http.StatusOK, wantBody: true},
+		{name: "unchanged", url: "/api/list?path=docs", ifNoneMatch: etag, wantStatus: http.StatusNotModified},
+		{name: "one of several, weak", url: "/api/list?path=docs", ifNoneMatch: `"old", W/` + etag,
+			wantStatus: http.StatusNotModified},
+		{name: "stale etag", url: "/api/list?path=docs", ifNoneMatch: `"old"`,
+			wantStatus: http.StatusOK, wantBody: true},
+		{name: "missing folder", url: "/api/list?path=missing", wantStatus: http.StatusNotFound},
+		{name: "bad age", url: "/api/list?path=docs&age_max=soon", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			handler.ListAPI(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusNotModified {
+				assert.Equal(t, etag, w.Header().Get("ETag"))
+			}
+			if tt.wantBody {
+				assert.JSONEq(t, `{"path":"docs","files":[
+					{"name":"a.txt","is_dir":false,"size":3,"mod_time":"2024-01-02T03:04:05Z"}]}`, w.Body.String())
+			} else if tt.wantStatus == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Swap(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		err        error
+		wantStatus int
+	}{
+		{name: "swapped", method: http.MethodPost, wantStatus: http.StatusNoContent},
+		{name: "different folders", method: http.MethodPost, err: domain.ErrInvalidParameter,
+			wantStatus: http.StatusBadRequest},
+		{name: "missing file", method: http.MethodPost, err: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+		{name: "get is not allowed", method: http.MethodGet, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := &mockFileManagement{
+				swapFunc: func(pathA, pathB string) error {
+					assert.Equal(t, "app/current.bin", pathA)
+					assert.Equal(t, "app/staged.bin", pathB)
+					return tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			req := httptest.NewRequest(tt.method, "/swap", strings.NewReader("a=app/current.bin&b=app/staged.bin"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			handler.Swap(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_Archive(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		body        string
+		err         error
+		wantStatus  int
+		wantPaths   []string
+		wantFlatten bool
+	}{
+		{
+			name: "paths from body", method: "POST", url: "/archive", body: `["a.txt","docs/b.txt"]`,
+			wantStatus: http.StatusOK, wantPaths: []string{"a.txt", "docs/b.txt"},
+		},
+		{
+			name: "flatten", method: "POST", url: "/archive?flatten=true", body: `["a.txt"]`,
+			wantStatus: http.StatusOK, wantPaths: []string{"a.txt"}, wantFlatten: true,
+		},
+		{name: "wrong method", method: "GET", url: "/archive", wantStatus: http.StatusMethodNotAllowed},
+		{name: "malformed body", method: "POST", url: "/archive", body: `{}`, wantStatus: http.StatusBadRequest},
+		{
+			name: "forbidden selection", method: "POST", url: "/archive", body: `["private/a.txt"]`,
+			err: domain.ErrPermissionDenied, wantStatus: http.StatusForbidden, wantPaths: []string{"private/a.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPaths []string
+			var gotFlatten bool
+			mockUC := &mockFileManagement{
+				serveArchiveFunc: func(w http.ResponseWriter, paths []string, flatten bool) error {
+					gotPaths, gotFlatten = paths, flatten
+					return tt.err
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.Archive(w, httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPaths, gotPaths)
+			assert.Equal(t, tt.wantFlatten, gotFlatten)
+		})
+	}
+}
+
+func TestHandler_Rebuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "index.html"), []byte("v1"), 0o644))
+
+	tests := []struct {
+		name         string
+		target       string
+		ucRebuilt    []string
+		ucErr        error
+		wantStatus   int
+		wantRebuilt  []string
+		wantUCCalled bool
+	}{
+		{
+			name: "template only", target: domain.RebuildTemplate,
+			wantStatus: http.StatusOK, wantRebuilt: []string{domain.RebuildTemplate},
+		},
+		{
+			name: "zip", target: domain.RebuildZip, ucRebuilt: []string{domain.RebuildZip},
+			wantStatus: http.StatusOK, wantRebuilt: []string{domain.RebuildZip}, wantUCCalled: true,
+		},
+		{
+			name: "all", target: domain.RebuildAll, ucRebuilt: []string{domain.RebuildZip},
+			wantStatus: http.StatusOK, wantRebuilt: []string{domain.RebuildTemplate, domain.RebuildZip},
+			wantUCCalled: true,
+		},
+		{
+			name: "unknown target", target: "thumbs", ucErr: domain.ErrInvalidParameter,
+			wantStatus: http.StatusBadRequest, wantUCCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ucCalled := false
+			mockUC := &mockFileManagement{
+				rebuildFunc: func(target string) ([]string, error) {
+					ucCalled = true
+					assert.Equal(t, tt.target, target)
+					return tt.ucRebuilt, tt.ucErr
+				},
+			}
+			handler := createTestHandler(mockUC)
+			handler.staticPath = tmpDir
+			handler.templateFile = "index.html"
+			_, err := handler.template()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			handler.Rebuild(w, httptest.NewRequest("POST", "/admin/rebuild?target="+tt.target, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUCCalled, ucCalled)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Rebuilt []string `json:"rebuilt"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantRebuilt, resp.Rebuilt)
+			if slices.Contains(tt.wantRebuilt, domain.RebuildTemplate) {
+				assert.Nil(t, handler.templateCache)
+			} else {
+				assert.NotNil(t, handler.templateCache)
+			}
+		})
+	}
+
+	t.Run("wrong method", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		createTestHandler(&mockFileManagement{}).Rebuild(w, httptest.NewRequest("GET", "/admin/rebuild", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestHandler_Bundle(t *testing.T) {
+	t.Run("returns base64 content", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			bundleFunc: func(path string) (map[string]domain.BundleFile, error) {
+				assert.Equal(t, "assets", path)
+				return map[string]domain.BundleFile{
+					"app.css": {ContentType: "text/css", Data: []byte("body{}")},
+				}, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Bundle(w, httptest.NewRequest("GET", "/bundle?path=assets", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp map[string]map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "text/css", resp["app.css"]["content_type"])
+		assert.Equal(t, "Ym9keXt9", resp["app.css"]["data"])
+	})
+
+	t.Run("too large suggests zip", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			bundleFunc: func(path string) (map[string]domain.BundleFile, error) {
+				return nil, domain.ErrBundleTooLarge
+			},
+		}
+		handler := createTestHandler(mockUC)
+		handler.messages.BundleTooLarge = "download it as zip instead"
+
+		w := httptest.NewRecorder()
+		handler.Bundle(w, httptest.NewRequest("GET", "/bundle?path=big", nil))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "zip")
+	})
+}
+
+func TestHandler_Download(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			serveFileFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("file content"))
+				return nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("GET", "/download?path=test.txt", nil)
+		w := httptest.NewRecorder()
+
+		handler.Download(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "file content")
+	})
+
+	t.Run("folder path", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			redirect     string
+			wantStatus   int
+			wantLocation string
+		}{
+			{name: "error by default", wantStatus: http.StatusBadRequest},
+			{
+				name: "redirect to folder zip", redirect: "/download-folder",
+				wantStatus: http.StatusFound, wantLocation: "/download-folder?path=docs%2Freports",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockUC := &mockFileManagement{
+					serveFileFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
+						return fmt.Errorf("'%s' is a folder: %w", path, domain.ErrIsDirectory)
+					},
+				}
+				handler := createTestHandler(mockUC)
+				WithFolderDownloadRedirect(tt.redirect)(handler)
+
+				w := httptest.NewRecorder()
+				handler.Download(w, httptest.NewRequest("GET", "/download?path=docs/reports", nil))
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				assert.Equal(t, tt.wantLocation, w.Header().Get("Location"))
+			})
+		}
+	})
+}
+
+// fakeACL пускает только пользователя allowed, остальным отдаёт юзкейс с запретом.
+type fakeACL struct {
+	allowed string
+	uc      domain.FileManagement
+	seen    []domain.User
+}
+
+func (a *fakeACL) For(user domain.User) domain.FileManagement {
+	a.seen = append(a.seen, user)
+	if user.Name == a.allowed {
+		return a.uc
+	}
+	return &mockFileManagement{
+		serveFileFunc: func(http.ResponseWriter, *http.Request, string) error {
+			return domain.ErrPermissionDenied
+		},
+	}
+}
+
+func TestHandler_ACL(t *testing.T) {
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, _ *http.Request, _ string) error {
+			_, err := w.Write([]byte("file content"))
+			return err
+		},
+	}
+	tests := []struct {
+		name       string
+		user       *domain.User
+		wantStatus int
+	}{
+		{name: "allowed user", user: &domain.User{Name: "alice"}, wantStatus: http.StatusOK},
+		{name: "other user", user: &domain.User{Name: "bob"}, wantStatus: http.StatusForbidden},
+		{name: "anonymous", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acl := &fakeACL{allowed: "alice", uc: mockUC}
+			handler := createTestHandler(mockUC)
+			WithACL(acl)(handler)
+
+			req := httptest.NewRequest("GET", "/download?path=private/a.txt", nil)
+			if tt.user != nil {
+				req = req.WithContext(domain.WithUser(req.Context(), *tt.user))
+			}
+			w := httptest.NewRecorder()
+			handler.Download(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			require.Len(t, acl.seen, 1)
+			if tt.user != nil {
+				assert.Equal(t, *tt.user, acl.seen[0])
+			}
+		})
+	}
+}
+
+func TestHandler_Download_Methods(t *testing.T) {
+	tests := []struct {
+		method     string
+		wantStatus int
+		wantCalled bool
+	}{
+		{method: "GET", wantStatus: http.StatusOK, wantCalled: true},
+		{method: "HEAD", wantStatus: http.StatusOK, wantCalled: true},
+		{method: "POST", wantStatus: http.StatusMethodNotAllowed},
+		{method: "DELETE", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			called := false
+			mockUC := &mockFileManagement{
+				serveFileFunc: func(w http.ResponseWriter, r *http.Request, _ string) error {
+					called = true
+					assert.Equal(t, tt.method, r.Method)
+					return nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+
+			w := httptest.NewRecorder()
+			handler.Download(w, httptest.NewRequest(tt.method, "/download?path=a.txt", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+			if !tt.wantCalled {
+				assert.Equal(t, AllowGetHead, w.Header().Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestHandler_DownloadFolder(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			query      string
+			wantStatus int
+			wantBody   string
+		}{
+			{name: "default zip", query: "", wantStatus: http.StatusOK, wantBody: "zip"},
+			{name: "explicit zip", query: "&format=zip", wantStatus: http.StatusOK, wantBody: "zip"},
+			{name: "tar.gz", query: "&format=tar.gz", wantStatus: http.StatusOK, wantBody: "tar"},
+			{name: "unknown", query: "&format=rar", wantStatus: http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				handler := createTestHandler(&mockFileManagement{
+					serveFolderAsZipFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
+						_, err := w.Write([]byte("zip"))
+						return err
+					},
+					serveTarGzFunc: func(w http.ResponseWriter, path string) error {
+						assert.Equal(t, "testdir", path)
+						_, err := w.Write([]byte("tar"))
+						return err
+					},
+				})
+				w := httptest.NewRecorder()
+
+				handler.DownloadFolder(w, httptest.NewRequest("GET", "/download-folder?path=testdir"+tt.query, nil))
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				if tt.wantBody != "" {
+					assert.Equal(t, tt.wantBody, w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			serveFolderAsZipFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("zip content"))
+				return nil
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("GET", "/delete?path=nonexistent", nil)
+		req := httptest.NewRequest("GET", "/download-folder?path=testdir", nil)
 		w := httptest.NewRecorder()
 
-		handler.Delete(w, req)
+		handler.DownloadFolder(w, req)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "zip content")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			serveFolderAsZipFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
+				return fmt.Errorf("folder '%s' has more than 10 bytes: %w", path, domain.ErrArchiveTooLarge)
+			},
+		}
+		handler := createTestHandler(mockUC)
+		handler.messages.ZipTooLarge = "download subfolders instead"
+
+		w := httptest.NewRecorder()
+		handler.DownloadFolder(w, httptest.NewRequest("GET", "/download-folder?path=huge", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "download subfolders instead")
 	})
 }
 
-func TestHandler_Rename(t *testing.T) {
+func TestHandler_Capabilities(t *testing.T) {
+	mockUC := &mockFileManagement{
+		capabilitiesFunc: func() domain.Capabilities {
+			return domain.Capabilities{
+				StorageType:         "local",
+				MaxUploadSize:       1024,
+				ForbiddenExtensions: []string{".env"},
+				ZipDownload:         true,
+			}
+		},
+	}
+	handler := createTestHandler(mockUC)
+
+	req := httptest.NewRequest("GET", "/capabilities", nil)
+	w := httptest.NewRecorder()
+
+	handler.Capabilities(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var got domain.Capabilities
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, "local", got.StorageType)
+	assert.Equal(t, int64(1024), got.MaxUploadSize)
+	assert.Equal(t, []string{".env"}, got.ForbiddenExtensions)
+	assert.True(t, got.ZipDownload)
+}
+
+func TestHandler_Compare(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		var oldPath, newPath string
 		mockUC := &mockFileManagement{
-			renameFunc: func(old, new string) error {
-				oldPath = old
-				newPath = new
-				return nil
+			compareFunc: func(pathA, pathB string) (bool, error) {
+				return pathA == "a.txt" && pathB == "b.txt", nil
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("POST", "/rename", strings.NewReader("old=old.txt&new=new.txt"))
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req := httptest.NewRequest("GET", "/compare?a=a.txt&b=b.txt", nil)
 		w := httptest.NewRecorder()
 
-		handler.Rename(w, req)
+		handler.Compare(w, req)
 
-		assert.Equal(t, http.StatusFound, w.Code)
-		assert.Equal(t, "old.txt", oldPath)
-		assert.Contains(t, newPath, "new.txt")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Equal bool `json:"equal"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Equal)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			compareFunc: func(pathA, pathB string) (bool, error) {
+				return false, domain.ErrFileNotFound
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("GET", "/compare?a=a.txt&b=missing.txt", nil)
+		w := httptest.NewRecorder()
+
+		handler.Compare(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
 
-func TestHandler_Download(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
+func TestHandler_Extract(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		form       string
+		ucErr      error
+		wantStatus int
+		wantZip    string
+		wantDest   string
+	}{
+		{name: "next to archive", method: "POST", form: "path=inbox/photos.zip",
+			wantStatus: http.StatusOK, wantZip: "inbox/photos.zip", wantDest: "inbox"},
+		{name: "explicit target", method: "POST", form: "path=photos.zip&target=albums/2024",
+			wantStatus: http.StatusOK, wantZip: "photos.zip", wantDest: "albums/2024"},
+		{name: "zip slip", method: "POST", form: "path=evil.zip", ucErr: domain.ErrPathTraversal,
+			wantStatus: http.StatusBadRequest, wantZip: "evil.zip", wantDest: "."},
+		{name: "get", method: "GET", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotZip, gotDest string
+			handler := createTestHandler(&mockFileManagement{
+				extractArchiveFunc: func(zipPath, destPath string) error {
+					gotZip, gotDest = zipPath, destPath
+					return tt.ucErr
+				},
+			})
+			req := httptest.NewRequest(tt.method, "/extract", strings.NewReader(tt.form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.Extract(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantZip, gotZip)
+			assert.Equal(t, tt.wantDest, gotDest)
+		})
+	}
+}
+
+func TestHandler_Info(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		ucErr      error
+		wantStatus int
+		wantMeta   bool
+	}{
+		{name: "plain", query: "path=a.txt", wantStatus: http.StatusOK},
+		{name: "with meta", query: "path=a.txt&meta=1", wantStatus: http.StatusOK, wantMeta: true},
+		{name: "missing", query: "path=nope.txt", ucErr: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMeta bool
+			handler := createTestHandler(&mockFileManagement{
+				infoFunc: func(path string, withMeta bool) (domain.FileData, error) {
+					gotMeta = withMeta
+					data := domain.FileData{Name: "a.txt", Path: path}
+					if withMeta {
+						data.UploadMetadata = &domain.UploadMetadata{OriginalName: "a.txt", Uploader: "alice"}
+					}
+					return data, tt.ucErr
+				},
+			})
+			w := httptest.NewRecorder()
+
+			handler.Info(w, httptest.NewRequest("GET", "/info?"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantMeta, gotMeta)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got domain.FileData
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			assert.Equal(t, tt.wantMeta, got.UploadMetadata != nil)
+		})
+	}
+}
+
+func TestHandler_Preview(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		ucErr         error
+		wantStatus    int
+		wantMaxLines  int
+		wantTruncated bool
+	}{
+		{name: "default lines", query: "path=a.go", wantStatus: http.StatusOK, wantTruncated: true},
+		{name: "explicit lines", query: "path=a.go&lines=5", wantStatus: http.StatusOK, wantMaxLines: 5,
+			wantTruncated: true},
+		{name: "bad lines", query: "path=a.go&lines=many", wantStatus: http.StatusBadRequest},
+		{name: "binary", query: "path=a.bin", ucErr: domain.ErrUnsupportedOperation,
+			wantStatus: http.StatusForbidden},
+		{name: "missing", query: "path=nope.txt", ucErr: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMaxLines := -1
+			handler := createTestHandler(&mockFileManagement{
+				previewTextFunc: func(path string, maxLines int) ([]string, bool, error) {
+					gotMaxLines = maxLines
+					return []string{"package main", ""}, true, tt.ucErr
+				},
+			})
+			w := httptest.NewRecorder()
+
+			handler.Preview(w, httptest.NewRequest("GET", "/preview?"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantMaxLines, gotMaxLines)
+			var resp struct {
+				Lines     []string `json:"lines"`
+				Truncated bool     `json:"truncated"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, []string{"package main", ""}, resp.Lines)
+			assert.Equal(t, tt.wantTruncated, resp.Truncated)
+		})
+	}
+}
+
+func TestHandler_Hash(t *testing.T) {
+	tests := []struct {
+		name       string
+		ucErr      error
+		wantStatus int
+	}{
+		{name: "ok", wantStatus: http.StatusOK},
+		{name: "missing", ucErr: domain.ErrFileNotFound, wantStatus: http.StatusNotFound},
+		{name: "folder", ucErr: domain.ErrUnsupportedOperation, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			handler := createTestHandler(&mockFileManagement{
+				hashFileFunc: func(path string) (string, error) {
+					gotPath = path
+					return "abc123", tt.ucErr
+				},
+			})
+			w := httptest.NewRecorder()
+
+			handler.Hash(w, httptest.NewRequest("GET", "/hash?path=docs/a.txt", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, "docs/a.txt", gotPath)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, map[string]string{"path": "docs/a.txt", "sha256": "abc123"}, resp)
+		})
+	}
+}
+
+func TestHandler_Recent(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		var gotPath string
+		var gotLimit int
 		mockUC := &mockFileManagement{
-			serveFileFunc: func(w http.ResponseWriter, r *http.Request, path string) error {
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("file content"))
-				return nil
+			recentFilesFunc: func(path string, limit int) ([]domain.FileData, error) {
+				gotPath, gotLimit = path, limit
+				return []domain.FileData{{Name: "new.txt", Path: "docs/new.txt", Size: 3}}, nil
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("GET", "/download?path=test.txt", nil)
+		req := httptest.NewRequest("GET", "/recent?path=docs", nil)
 		w := httptest.NewRecorder()
 
-		handler.Download(w, req)
+		handler.Recent(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "file content")
+		assert.Equal(t, "docs", gotPath)
+		assert.Equal(t, DefaultRecentLimit, gotLimit)
+		var resp []domain.FileData
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp, 1)
+		assert.Equal(t, "docs/new.txt", resp[0].Path)
+	})
+
+	t.Run("explicit limit", func(t *testing.T) {
+		var gotLimit int
+		mockUC := &mockFileManagement{
+			recentFilesFunc: func(path string, limit int) ([]domain.FileData, error) {
+				gotLimit = limit
+				return nil, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("GET", "/recent?limit=5", nil)
+		w := httptest.NewRecorder()
+
+		handler.Recent(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 5, gotLimit)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		req := httptest.NewRequest("GET", "/recent?limit=abc", nil)
+		w := httptest.NewRecorder()
+
+		handler.Recent(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 }
 
-func TestHandler_DownloadFolder(t *testing.T) {
+func TestHandler_ZipManifest(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockUC := &mockFileManagement{
-			serveFolderAsZipFunc: func(w http.ResponseWriter, path string) error {
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("zip content"))
-				return nil
+			zipManifestFunc: func(path string) ([]domain.ZipEntry, error) {
+				return []domain.ZipEntry{{Name: "a.txt", Size: 10}, {Name: "sub/b.txt", Size: 5}, {Name: "empty/"}}, nil
 			},
 		}
 		handler := createTestHandler(mockUC)
 
-		req := httptest.NewRequest("GET", "/download-folder?path=testdir", nil)
 		w := httptest.NewRecorder()
+		handler.ZipManifest(w, httptest.NewRequest("GET", "/zip-manifest?path=docs", nil))
 
-		handler.DownloadFolder(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Entries   []domain.ZipEntry `json:"entries"`
+			Count     int               `json:"count"`
+			TotalSize int64             `json:"total_size"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Entries, 3)
+		assert.Equal(t, 3, resp.Count)
+		assert.Equal(t, int64(15), resp.TotalSize)
+	})
+
+	t.Run("drop-box", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			zipManifestFunc: func(path string) ([]domain.ZipEntry, error) {
+				return nil, domain.ErrPermissionDenied
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.ZipManifest(w, httptest.NewRequest("GET", "/zip-manifest?path=inbox", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_Prune(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		var gotPath string
+		mockUC := &mockFileManagement{
+			pruneEmptyDirsFunc: func(path string) (int, error) {
+				gotPath = path
+				return 3, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/prune", strings.NewReader("path=docs"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.Prune(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "zip content")
+		assert.Equal(t, "docs", gotPath)
+		var resp struct {
+			Removed int `json:"removed"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 3, resp.Removed)
+	})
+
+	t.Run("GET redirects", func(t *testing.T) {
+		called := false
+		mockUC := &mockFileManagement{
+			pruneEmptyDirsFunc: func(path string) (int, error) {
+				called = true
+				return 0, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		handler.Prune(w, httptest.NewRequest("GET", "/prune", nil))
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.False(t, called)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUC := &mockFileManagement{
+			pruneEmptyDirsFunc: func(path string) (int, error) {
+				return 0, domain.ErrFileNotFound
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		req := httptest.NewRequest("POST", "/prune", strings.NewReader("path=nope"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+
+		handler.Prune(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+type stubStorageHealth bool
+
+func (s stubStorageHealth) Healthy() bool { return bool(s) }
+
+func TestHandler_RequireStorage(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantStatus int
+		wantCalled bool
+	}{
+		{"no health check", nil, http.StatusOK, true},
+		{"healthy", []Option{WithStorageHealth(stubStorageHealth(true))}, http.StatusOK, true},
+		{"unavailable", []Option{WithStorageHealth(stubStorageHealth(false))}, http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler(&mockFileManagement{})
+			for _, opt := range tt.opts {
+				opt(handler)
+			}
+			called := false
+			next := func(w http.ResponseWriter, _ *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}
+
+			req := httptest.NewRequest("GET", "/?path=docs", nil)
+			w := httptest.NewRecorder()
+
+			handler.RequireStorage(next)(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
+
+func TestHandler_Ready(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithStorageHealth(stubStorageHealth(true))(handler)
+
+		w := httptest.NewRecorder()
+		handler.Ready(w, httptest.NewRequest("GET", "/ready", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "ok")
+	})
+
+	t.Run("storage unavailable", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		handler.messages.StorageUnavailable = "Storage unavailable"
+		WithStorageHealth(stubStorageHealth(false))(handler)
+
+		w := httptest.NewRecorder()
+		handler.Ready(w, httptest.NewRequest("GET", "/ready", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "Storage unavailable")
 	})
 }
 
@@ -405,6 +3031,14 @@ func TestHandler_getErrorType(t *testing.T) {
 		{"unsupported operation", domain.ErrUnsupportedOperation, http.StatusForbidden},
 		{"permission denied", domain.ErrPermissionDenied, http.StatusForbidden},
 		{"file not found", domain.ErrFileNotFound, http.StatusNotFound},
+		{"already exists", domain.ErrAlreadyExists, http.StatusConflict},
+		{"invalid parameter", domain.ErrInvalidParameter, http.StatusBadRequest},
+		{"storage unavailable", domain.ErrStorageUnavailable, http.StatusServiceUnavailable},
+		{"precondition failed", domain.ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{"archive too large", domain.ErrArchiveTooLarge, http.StatusForbidden},
+		{"storage full", fmt.Errorf("upload: %w", domain.ErrStorageFull), http.StatusInsufficientStorage},
+		{"bundle too large", domain.ErrBundleTooLarge, http.StatusRequestEntityTooLarge},
+		{"checksum mismatch", fmt.Errorf("upload: %w", domain.ErrChecksumMismatch), http.StatusBadRequest},
 		{"unknown error", errors.New("unknown"), http.StatusInternalServerError},
 	}
 
@@ -419,6 +3053,20 @@ func TestHandler_getErrorType(t *testing.T) {
 				status = http.StatusForbidden
 			case errorTypeNotFound:
 				status = http.StatusNotFound
+			case errorTypeConflict:
+				status = http.StatusConflict
+			case errorTypePreconditionFailed:
+				status = http.StatusPreconditionFailed
+			case errorTypeUnavailable:
+				status = http.StatusServiceUnavailable
+			case errorTypeTooLarge:
+				status = http.StatusForbidden
+			case errorTypeStorageFull:
+				status = http.StatusInsufficientStorage
+			case errorTypeBundleTooLarge:
+				status = http.StatusRequestEntityTooLarge
+			case errorTypeChecksumMismatch:
+				status = http.StatusBadRequest
 			case errorTypeInternal:
 				status = http.StatusInternalServerError
 			}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLogBacklogLines = 100
+	maxLogBacklogLines     = 1000
+	// maxLogBacklogBytes сколько хвоста файла читается для backlog, чтобы огромный лог не читать целиком.
+	maxLogBacklogBytes     = 1 << 20
+	defaultLogPollInterval = 500 * time.Millisecond
+)
+
+// Logs отдаёт хвост лог-файла и дальше стримит новые строки через SSE, пока клиент не отключится.
+// ?lines=N размер backlog, ?follow=false — только backlog без ожидания новых строк.
+// если логи пишутся не в файл (log.file пустой), отвечает 501.
+func (h *Handler) Logs(w http.ResponseWriter, r *http.Request) {
+	if h.logFile == "" {
+		http.Error(w, http.StatusText(http.StatusNotImplemented), http.StatusNotImplemented)
+		return
+	}
+
+	lines := defaultLogBacklogLines
+	if raw := r.URL.Query().Get(QueryParamLines); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, h.messages.InternalError, http.StatusBadRequest)
+			return
+		}
+		lines = min(n, maxLogBacklogLines)
+	}
+
+	f, err := os.Open(h.logFile)
+	if err != nil {
+		h.handleError(w, fmt.Errorf("failed to open log file: %w", err), h.messages.InternalError)
+		return
+	}
+	defer closeLogFile(f)
+
+	backlog, offset, err := tailLines(f, lines)
+	if err != nil {
+		h.handleError(w, fmt.Errorf("failed to read log file: %w", err), h.messages.InternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	for _, line := range backlog {
+		writeSSELine(w, line)
+	}
+	flush(w)
+
+	if r.URL.Query().Get(QueryParamFollow) == "false" {
+		return
+	}
+	h.followLog(w, r, f, offset)
+}
+
+// followLog опрашивает файл и досылает дописанные строки. при ротации (файл стал короче) читает с начала.
+func (h *Handler) followLog(w http.ResponseWriter, r *http.Request, f *os.File, offset int64) {
+	ticker := time.NewTicker(h.logPollInterval)
+	defer ticker.Stop()
+
+	var partial []byte
+	buf := make([]byte, 32*1024)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			logrus.Warnf("Failed to stat log file: %v", err)
+			return
+		}
+		if info.Size() < offset {
+			offset, partial = 0, nil
+		}
+
+		for {
+			n, readErr := f.ReadAt(buf, offset)
+			offset += int64(n)
+			partial = append(partial, buf[:n]...)
+			if readErr != nil || n == 0 {
+				break
+			}
+		}
+
+		sent := false
+		for {
+			idx := bytes.IndexByte(partial, '\n')
+			if idx < 0 {
+				break
+			}
+			writeSSELine(w, string(partial[:idx]))
+			partial = partial[idx+1:]
+			sent = true
+		}
+		if sent {
+			flush(w)
+		}
+	}
+}
+
+// tailLines последние n строк файла (из не больше чем maxLogBacklogBytes хвоста) и смещение конца файла.
+func tailLines(f *os.File, n int) ([]string, int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	start := max(size-maxLogBacklogBytes, 0)
+
+	data, err := io.ReadAll(io.NewSectionReader(f, start, size-start))
+	if err != nil {
+		return nil, 0, err
+	}
+	// недописанная последняя строка уйдёт в стрим, когда допишется.
+	end := bytes.LastIndexByte(data, '\n') + 1
+	offset := start + int64(end)
+	data = data[:end]
+	// первая строка при обрезке по байтам может быть неполной.
+	if start > 0 {
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogBacklogBytes)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, offset, scanner.Err()
+}
+
+func writeSSELine(w io.Writer, line string) {
+	fmt.Fprintf(w, "data: %s\n\n", strings.TrimRight(line, "\r"))
+}
+
+func flush(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func closeLogFile(f *os.File) {
+	if err := f.Close(); err != nil {
+		logrus.Warnf("Failed to close log file: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, lines int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	var sb strings.Builder
+	for i := 1; i <= lines; i++ {
+		fmt.Fprintf(&sb, "line %d\n", i)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o600))
+	return path
+}
+
+func TestHandler_Logs(t *testing.T) {
+	t.Run("not file based", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+
+		w := httptest.NewRecorder()
+		handler.Logs(w, httptest.NewRequest("GET", "/logs", nil))
+
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+
+	t.Run("backlog is bounded", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithLogFile(writeLog(t, 10))(handler)
+
+		w := httptest.NewRecorder()
+		handler.Logs(w, httptest.NewRequest("GET", "/logs?lines=3&follow=false", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		assert.Equal(t, "data: line 8\n\ndata: line 9\n\ndata: line 10\n\n", w.Body.String())
+	})
+
+	t.Run("bad lines", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithLogFile(writeLog(t, 1))(handler)
+
+		w := httptest.NewRecorder()
+		handler.Logs(w, httptest.NewRequest("GET", "/logs?lines=x", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("follows new lines", func(t *testing.T) {
+		path := writeLog(t, 2)
+		handler := createTestHandler(&mockFileManagement{})
+		WithLogFile(path)(handler)
+		handler.logPollInterval = 5 * time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			handler.Logs(w, httptest.NewRequest("GET", "/logs?lines=5", nil).WithContext(ctx))
+			close(done)
+		}()
+
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString("line 3\nline 4 partial")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		<-done
+
+		assert.Equal(t, "data: line 1\n\ndata: line 2\n\ndata: line 3\n\n", w.Body.String(),
+			"each line sent once, unfinished line is held back")
+	})
+}
+
+func TestTailLines(t *testing.T) {
+	f, err := os.Open(writeLog(t, 5))
+	require.NoError(t, err)
+	defer f.Close()
+
+	lines, offset, err := tailLines(f, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line 4", "line 5"}, lines)
+	assert.Equal(t, int64(len("line 1\nline 2\nline 3\nline 4\nline 5\n")), offset)
+}
@@ -0,0 +1,170 @@
+package server
+
+import "time"
+
+// Option необязательная настройка Handler, чтобы не раздувать список аргументов NewHandler.
+type Option func(*Handler)
+
+// WithMaxConcurrentUploadsPerClient ограничивает число одновременных загрузок с одного IP, 0 — без ограничения.
+func WithMaxConcurrentUploadsPerClient(limit int) Option {
+	return func(h *Handler) {
+		if limit > 0 {
+			h.uploadLimiter = newClientLimiter(limit)
+		}
+	}
+}
+
+// WithRateLimit частота запросов с одного IP для RateLimit: rps в секунду, burst подряд. rps 0 — без ограничения.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(h *Handler) {
+		if rps > 0 {
+			h.rateLimiter = newRateLimiter(rps, burst)
+		}
+	}
+}
+
+// WithMaxConcurrentRequests общий потолок одновременных запросов для LimitRequests, 0 — без ограничения.
+func WithMaxConcurrentRequests(limit int) Option {
+	return func(h *Handler) {
+		h.maxRequests = limit
+	}
+}
+
+// WithUploadThrottle скорость одной загрузки в байтах в секунду и срок на всю загрузку, 0 — без ограничения.
+// срок нужен вместе с лимитом скорости: иначе медленный клиент держит слот загрузки сколько угодно.
+func WithUploadThrottle(bps int64, timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.maxUploadBPS = bps
+		h.uploadTimeout = timeout
+	}
+}
+
+// WithDownloadThrottle скорость одной отдачи файла или zip в байтах в секунду, 0 — без ограничения.
+func WithDownloadThrottle(bps int64) Option {
+	return func(h *Handler) {
+		h.maxDownloadBPS = bps
+	}
+}
+
+// WithVerifyTimeout сколько может идти одна сверка с манифестом (/verify), 0 — пока клиент ждёт.
+func WithVerifyTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.verifyTimeout = timeout
+	}
+}
+
+// WithDuplicatesTimeout сколько может идти один поиск дубликатов (/duplicates), 0 — пока клиент ждёт.
+func WithDuplicatesTimeout(timeout time.Duration) Option {
+	return func(h *Handler) {
+		h.duplicatesTimeout = timeout
+	}
+}
+
+// WithSlowOpThreshold порог для LogSlowRequests, 0 — медленные запросы не логируются.
+func WithSlowOpThreshold(threshold time.Duration) Option {
+	return func(h *Handler) {
+		h.slowOpThreshold = threshold
+	}
+}
+
+// WithACL включает проверку доступа к папкам по пользователю из контекста запроса.
+func WithACL(acl aclPolicy) Option {
+	return func(h *Handler) {
+		h.acl = acl
+	}
+}
+
+// WithStorageHealth включает 503 на запросы, пока хранилище недоступно (см. RequireStorage и Ready).
+func WithStorageHealth(health storageHealth) Option {
+	return func(h *Handler) {
+		h.storageHealth = health
+	}
+}
+
+// WithTrashPurger включает /trash-purge с итогом последней фоновой очистки корзины.
+func WithTrashPurger(purger trashPurger) Option {
+	return func(h *Handler) {
+		h.trashPurger = purger
+	}
+}
+
+// WithAdminToken токен для эндпоинтов за RequireAdmin, пустой — они выключены.
+func WithAdminToken(token string) Option {
+	return func(h *Handler) {
+		h.adminToken = token
+	}
+}
+
+// WithAuth включает RequireAuth: users логин -> хэш htpasswd, token статический bearer,
+// roles роли пользователей для acl. без users и token аутентификация выключена.
+func WithAuth(users map[string]string, token string, roles map[string][]string) Option {
+	return func(h *Handler) {
+		if len(users) == 0 && token == "" {
+			h.auth = nil
+			return
+		}
+		h.auth = &authSettings{users: users, token: token, roles: roles}
+	}
+}
+
+// WithCSRFProtection требует токен страницы (кука + поле формы или X-CSRF-Token) на формах через handlePost.
+func WithCSRFProtection(enabled bool) Option {
+	return func(h *Handler) {
+		h.csrfProtection = enabled
+	}
+}
+
+// WithLogFile файл, который /logs показывает и стримит.
+func WithLogFile(path string) Option {
+	return func(h *Handler) {
+		h.logFile = path
+	}
+}
+
+// WithTemplateReload перечитывать шаблон на каждый запрос, для правки index.html без перезапуска.
+func WithTemplateReload(reload bool) Option {
+	return func(h *Handler) {
+		h.templateReload = reload
+	}
+}
+
+// WithShareLinks включает подписанные ссылки (/sign и /shared). пустой secret — случайный ключ
+// на время жизни процесса, maxTTL 0 — неделя. route путь /shared, из которого собирается ссылка.
+func WithShareLinks(secret string, maxTTL time.Duration, route string) Option {
+	return func(h *Handler) {
+		if route != "" {
+			h.shareLinks = newShareLinks(secret, maxTTL, route)
+		}
+	}
+}
+
+// WithDeleteConfirmation требует для удаления токен, выданный /confirm-delete или встроенный в листинг.
+// пустой secret — случайный ключ на время жизни процесса.
+func WithDeleteConfirmation(required bool, secret string, ttl time.Duration) Option {
+	return func(h *Handler) {
+		if required {
+			h.deleteTokens = newDeleteTokens(secret, ttl)
+		}
+	}
+}
+
+// WithFolderDownloadRedirect /download папки отправляет редиректом на route (zip папки), а не отвечает 400.
+func WithFolderDownloadRedirect(route string) Option {
+	return func(h *Handler) {
+		h.folderRedirect = route
+	}
+}
+
+// WithBuildInfo версия сборки и API для /version.
+func WithBuildInfo(info BuildInfo) Option {
+	return func(h *Handler) {
+		h.buildInfo = info
+	}
+}
+
+// WithDownloadRoute путь /download для абсолютных ссылок в Atom-ленте.
+func WithDownloadRoute(route string) Option {
+	return func(h *Handler) {
+		h.downloadRoute = route
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"net/url"
+	"strconv"
+
+	"file-manager/internal/domain"
+)
+
+// pagination данные для ссылок по страницам в шаблоне. PerPage 0 — листинг целиком, ссылок нет.
+// PrevURL и NextURL ссылки на соседние страницы с теми же path, фильтром и сортировкой, пусто — страницы нет.
+// template.URL: запрос уже закодирован url.Values, иначе шаблон экранирует `&` и `=` ещё раз.
+type pagination struct {
+	Page    int          `json:"page"`
+	PerPage int          `json:"per_page"`
+	Total   int          `json:"total"`
+	Pages   int          `json:"pages"`
+	PrevURL template.URL `json:"prev_url,omitempty"`
+	NextURL template.URL `json:"next_url,omitempty"`
+}
+
+// parsePage page с 1 и per_page. без обоих параметров листинг целиком, как раньше,
+// только page — страницы по DefaultPerPage.
+func parsePage(query url.Values) (page, perPage int, err error) {
+	rawPage, rawPerPage := query.Get(QueryParamPage), query.Get(QueryParamPerPage)
+	if rawPage == "" && rawPerPage == "" {
+		return 1, 0, nil
+	}
+	page, perPage = 1, DefaultPerPage
+	if rawPage != "" {
+		if page, err = strconv.Atoi(rawPage); err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("invalid %s '%s': %w", QueryParamPage, rawPage, domain.ErrInvalidParameter)
+		}
+	}
+	if rawPerPage != "" {
+		perPage, err = strconv.Atoi(rawPerPage)
+		if err != nil || perPage < 1 || perPage > MaxPerPage {
+			return 0, 0, fmt.Errorf("invalid %s '%s', expected 1..%d: %w",
+				QueryParamPerPage, rawPerPage, MaxPerPage, domain.ErrInvalidParameter)
+		}
+	}
+	// offset (page-1)*perPage не должен переполниться, дальше такой страницы всё равно пусто.
+	page = min(page, math.MaxInt/perPage)
+	return page, perPage, nil
+}
+
+func newPagination(query url.Values, page, perPage, total int) pagination {
+	p := pagination{Page: page, PerPage: perPage, Total: total}
+	if perPage == 0 {
+		return p
+	}
+	p.Pages = (total + perPage - 1) / perPage
+	pageURL := func(n int) template.URL {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set(QueryParamPage, strconv.Itoa(n))
+		q.Set(QueryParamPerPage, strconv.Itoa(perPage))
+		return template.URL("/?" + q.Encode())
+	}
+	if page > 1 {
+		// со страницы за концом назад ведём на последнюю, а не на page-1.
+		p.PrevURL = pageURL(min(page-1, max(p.Pages, 1)))
+	}
+	if page < p.Pages {
+		p.NextURL = pageURL(page + 1)
+	}
+	return p
+}
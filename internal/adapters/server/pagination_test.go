@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantPage    int
+		wantPerPage int
+		wantErr     bool
+	}{
+		{query: "", wantPage: 1, wantPerPage: 0},
+		{query: "page=3", wantPage: 3, wantPerPage: DefaultPerPage},
+		{query: "per_page=25", wantPage: 1, wantPerPage: 25},
+		{query: "page=2&per_page=50", wantPage: 2, wantPerPage: 50},
+		{query: "page=0", wantErr: true},
+		{query: "page=two", wantErr: true},
+		{query: "per_page=0", wantErr: true},
+		{query: "per_page=100000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			page, perPage, err := parsePage(query)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPage, page)
+			assert.Equal(t, tt.wantPerPage, perPage)
+		})
+	}
+}
+
+func TestHandler_Browse_Pagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		total      int
+		wantOffset int
+		wantLimit  int
+		wantStatus int
+		wantPrev   string
+		wantNext   string
+	}{
+		{
+			name: "middle page", query: "page=2&per_page=10&sort=size", total: 35,
+			wantOffset: 10, wantLimit: 10, wantStatus: http.StatusOK,
+			wantPrev: "page=1&amp;path=docs&amp;per_page=10&amp;sort=size",
+			wantNext: "page=3&amp;path=docs&amp;per_page=10&amp;sort=size",
+		},
+		{
+			name: "last page", query: "page=4&per_page=10", total: 35,
+			wantOffset: 30, wantLimit: 10, wantStatus: http.StatusOK,
+			wantPrev: "page=3&amp;path=docs&amp;per_page=10",
+		},
+		{
+			name: "past the end", query: "page=9&per_page=10", total: 35,
+			wantOffset: 80, wantLimit: 10, wantStatus: http.StatusOK,
+			wantPrev: "page=4&amp;path=docs&amp;per_page=10",
+		},
+		{name: "whole listing", query: "", total: 35, wantStatus: http.StatusOK},
+		{name: "bad page", query: "page=-1", wantOffset: -1, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOffset, gotLimit := -1, -1
+			mockUC := &mockFileManagement{
+				listPageFunc: func(_ string, _ domain.ListOptions, offset, limit int) ([]domain.FileData, int, error) {
+					gotOffset, gotLimit = offset, limit
+					return []domain.FileData{{Name: "a.txt"}}, tt.total, nil
+				},
+			}
+			handler := createTestHandler(mockUC)
+			// настоящий шаблон, чтобы ссылки страниц проверялись в том виде, в каком их увидит браузер.
+			handler.staticPath = "../../../static"
+
+			w := httptest.NewRecorder()
+			handler.Browse(w, httptest.NewRequest("GET", "/?path=docs&"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantOffset, gotOffset)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantLimit, gotLimit)
+			body := w.Body.String()
+			if tt.wantPrev != "" {
+				assert.Contains(t, body, `href="/?`+tt.wantPrev+`"`)
+			}
+			if tt.wantNext != "" {
+				assert.Contains(t, body, `href="/?`+tt.wantNext+`"`)
+			} else {
+				assert.NotContains(t, body, "Next")
+			}
+			if tt.wantLimit == 0 {
+				assert.NotContains(t, body, "Page ")
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rateSweepInterval как часто выкидывать из map корзины, которые успели наполниться: такой клиент
+// ничем не отличается от нового, а без чистки map росла бы на каждый IP.
+const rateSweepInterval = time.Minute
+
+// rateLimiter token bucket на каждый IP клиента: корзина ёмкостью burst пополняется rate токенов в секунду,
+// запрос забирает один токен.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*rateBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*rateBucket),
+		now:     time.Now,
+	}
+}
+
+// allow забирает токен клиента. false — токенов нет, wait — через сколько появится следующий.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) refill(b *rateBucket, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+}
+
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimit ограничивает частоту запросов с одного IP (server.rate_limit). сверх лимита 429 с Retry-After
+// в секундах до следующего токена. пути из exempt (проба готовности) не считаются.
+// без WithRateLimit возвращает next как есть.
+func (h *Handler) RateLimit(next http.Handler, exempt ...string) http.Handler {
+	if h.rateLimiter == nil {
+		return next
+	}
+
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		if path != "" {
+			skip[path] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skip[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client := clientIP(r)
+		if ok, wait := h.rateLimiter.allow(client); !ok {
+			logrus.Warnf("Rate limit exceeded by %s, rejecting %s %s", client, r.Method, r.URL.Path)
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(math.Ceil(wait.Seconds())), 1)))
+			http.Error(w, h.messages.TooManyRequests, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := range 3 {
+		ok, _ := l.allow("a")
+		assert.True(t, ok, "burst request %d", i)
+	}
+	ok, wait := l.allow("a")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	ok, _ = l.allow("b")
+	assert.True(t, ok, "other client has its own bucket")
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.allow("a")
+	assert.True(t, ok, "one token refilled")
+	ok, _ = l.allow("a")
+	assert.False(t, ok)
+
+	now = now.Add(time.Hour)
+	for range 3 {
+		ok, _ = l.allow("a")
+		assert.True(t, ok, "refill is capped by burst")
+	}
+	ok, _ = l.allow("a")
+	assert.False(t, ok)
+}
+
+func TestRateLimiter_SweepsFullBuckets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := newRateLimiter(1, 1)
+	l.now = func() time.Time { return now }
+
+	l.allow("a")
+	l.allow("b")
+	assert.Len(t, l.buckets, 2)
+
+	now = now.Add(rateSweepInterval)
+	l.allow("c")
+
+	assert.Len(t, l.buckets, 1, "idle clients dropped")
+	assert.Contains(t, l.buckets, "c")
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	serve := func(h http.Handler, path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithRateLimit(0, 0)(handler)
+		limited := handler.RateLimit(ok)
+
+		for range 10 {
+			assert.Equal(t, http.StatusOK, serve(limited, "/download", "10.0.0.1:1").Code)
+		}
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		handler := createTestHandler(&mockFileManagement{})
+		WithRateLimit(0.5, 2)(handler)
+		limited := handler.RateLimit(ok, "/ready")
+
+		assert.Equal(t, http.StatusOK, serve(limited, "/download", "10.0.0.1:1").Code)
+		assert.Equal(t, http.StatusOK, serve(limited, "/upload", "10.0.0.1:2").Code)
+		w := serve(limited, "/download", "10.0.0.1:3")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, serve(limited, "/ready", "10.0.0.1:4").Code, "health check bypasses the limit")
+		assert.Equal(t, http.StatusOK, serve(limited, "/download", "10.0.0.2:1").Code, "keyed by client IP")
+	})
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HeaderRequestID заголовок с id запроса: берём от прокси, если он есть, иначе генерируем сами.
+const HeaderRequestID = "X-Request-ID"
+
+// validRequestID чужой id пишем в логи как есть, поэтому пускаем только безопасные символы.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// RequestID id текущего запроса, пустая строка вне Recover.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Recover ставит запросу id (в контекст и в ответ) и ловит панику обработчика:
+// стек уходит в лог вместе с id, клиент получает обычный 500, а не оборванное соединение.
+func (h *Handler) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// ErrAbortHandler это штатный способ оборвать ответ, net/http его сам глушит.
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			logrus.WithField("request_id", id).
+				Errorf("Panic in %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+			http.Error(w, h.messages.InternalError, http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	// crypto/rand.Read не возвращает ошибку с go 1.24.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Recover(t *testing.T) {
+	handler := createTestHandler(&mockFileManagement{})
+
+	var seenID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenID = RequestID(r.Context())
+		switch r.URL.Path {
+		case "/panic":
+			var m map[string]int
+			m["boom"]++
+		case "/abort":
+			panic(http.ErrAbortHandler)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	recovered := handler.Recover(next)
+
+	var logs bytes.Buffer
+	out := logrus.StandardLogger().Out
+	logrus.SetOutput(&logs)
+	t.Cleanup(func() { logrus.SetOutput(out) })
+
+	tests := []struct {
+		name       string
+		path       string
+		requestID  string
+		wantStatus int
+		wantID     string
+	}{
+		{name: "ok, generated id", path: "/browse", wantStatus: http.StatusOK},
+		{
+			name: "ok, incoming id", path: "/browse", requestID: "abc-123",
+			wantStatus: http.StatusOK, wantID: "abc-123",
+		},
+		{name: "unsafe incoming id replaced", path: "/browse", requestID: "bad id\n", wantStatus: http.StatusOK},
+		{
+			name: "panic", path: "/panic", requestID: "req-1",
+			wantStatus: http.StatusInternalServerError, wantID: "req-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(HeaderRequestID, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			assert.NotPanics(t, func() { recovered.ServeHTTP(w, req) })
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			id := w.Header().Get(HeaderRequestID)
+			assert.Equal(t, seenID, id)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, id)
+			} else {
+				assert.Regexp(t, `^[0-9a-f]{16}$`, id)
+			}
+			if tt.wantStatus == http.StatusInternalServerError {
+				assert.Contains(t, w.Body.String(), "Internal error")
+				assert.Contains(t, logs.String(), "request_id="+tt.wantID)
+				assert.Contains(t, logs.String(), "recovery_test.go")
+			}
+		})
+	}
+
+	t.Run("abort handler is re-raised", func(t *testing.T) {
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			recovered.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+		})
+	})
+}
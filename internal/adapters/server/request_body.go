@@ -0,0 +1,49 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	headerContentEncoding = "Content-Encoding"
+	encodingGzip          = "gzip"
+	encodingIdentity      = "identity"
+)
+
+// decodeRequestBody распаковывает тело с Content-Encoding: gzip, чтобы в хранилище лёг исходный файл.
+// другие кодировки не поддерживаются: лучше 400, чем сохранить сжатые байты под видом файла.
+func decodeRequestBody(r *http.Request) (io.ReadCloser, error) {
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get(headerContentEncoding)))
+	switch encoding {
+	case "", encodingIdentity:
+		return r.Body, nil
+	case encodingGzip:
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("malformed gzip body: %v: %w", err, domain.ErrInvalidParameter)
+		}
+		return &gzipBody{Reader: zr, body: r.Body}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding '%s': %w", encoding, domain.ErrInvalidParameter)
+	}
+}
+
+// gzipBody закрывает и распаковщик, и исходное тело.
+type gzipBody struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (b *gzipBody) Close() error {
+	zErr := b.Reader.Close()
+	if err := b.body.Close(); err != nil {
+		return err
+	}
+	return zErr
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func gzipBytes(t *testing.T, data []byte) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return &buf
+}
+
+func TestHandler_Upload_ContentEncoding(t *testing.T) {
+	t.Run("gzip body is stored decompressed", func(t *testing.T) {
+		var stored string
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				data, err := io.ReadAll(file)
+				require.NoError(t, err)
+				stored = string(data)
+				return path, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		var form bytes.Buffer
+		writer := multipartWriter(t, &form, "notes.txt", "plain content", "")
+		req := httptest.NewRequest("POST", "/upload", gzipBytes(t, form.Bytes()))
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "plain content", stored)
+	})
+
+	t.Run("limit applies to decompressed size", func(t *testing.T) {
+		called := false
+		mockUC := &mockFileManagement{
+			uploadFileFunc: func(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+				called = true
+				return path, nil
+			},
+		}
+		handler := createTestHandler(mockUC)
+
+		var form bytes.Buffer
+		writer := multipartWriter(t, &form, "bomb.bin", strings.Repeat("\x00", 2*int(handler.maxUploadSize)), "")
+		compressed := gzipBytes(t, form.Bytes())
+		require.Less(t, int64(compressed.Len()), handler.maxUploadSize)
+		req := httptest.NewRequest("POST", "/upload", compressed)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		handler.Upload(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.False(t, called)
+	})
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     string
+	}{
+		{"malformed gzip", "gzip", "not gzip at all"},
+		{"unsupported encoding", "br", "whatever"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler(&mockFileManagement{})
+
+			req := httptest.NewRequest("POST", "/upload", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+			req.Header.Set("Content-Encoding", tt.encoding)
+			w := httptest.NewRecorder()
+
+			handler.Upload(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// requestRetryAfter подсказка клиенту в Retry-After, секунд.
+const requestRetryAfter = 1
+
+// LimitRequests общий потолок одновременных запросов ко всем эндпоинтам (server.max_concurrent_requests).
+// когда все слоты заняты, сразу 503 с Retry-After, а не очередь. пути из exempt (проба готовности)
+// пропускаются без слота, чтобы балансировщик не снял инстанс из-за нагрузки.
+// слот освобождается через defer, то есть и при панике в обработчике.
+func (h *Handler) LimitRequests(next http.Handler, exempt ...string) http.Handler {
+	if h.maxRequests <= 0 {
+		return next
+	}
+
+	slots := make(chan struct{}, h.maxRequests)
+	skip := make(map[string]bool, len(exempt))
+	for _, path := range exempt {
+		if path != "" {
+			skip[path] = true
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if skip[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			logrus.Warnf("Request limit of %d reached, rejecting %s %s", h.maxRequests, r.Method, r.URL.Path)
+			w.Header().Set("Retry-After", strconv.Itoa(requestRetryAfter))
+			http.Error(w, h.messages.TooManyRequests, http.StatusServiceUnavailable)
+			return
+		}
+		defer func() { <-slots }()
+
+		next.ServeHTTP(w, r)
+	})
+}
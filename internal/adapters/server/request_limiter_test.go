@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_LimitRequests(t *testing.T) {
+	handler := createTestHandler(&mockFileManagement{})
+	WithMaxConcurrentRequests(2)(handler)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		if r.URL.Path == "/slow" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	limited := handler.LimitRequests(slow, "/ready")
+
+	serve := func(path string) int {
+		w := httptest.NewRecorder()
+		limited.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve("/slow")
+		}()
+		<-entered
+	}
+
+	w := httptest.NewRecorder()
+	limited.ServeHTTP(w, httptest.NewRequest("GET", "/browse", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "all slots busy")
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("/ready"), "health check bypasses the limit")
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, serve("/browse"), "slots released")
+
+	t.Run("slot released on panic", func(t *testing.T) {
+		for range 3 {
+			assert.Panics(t, func() { serve("/panic") })
+		}
+		assert.Equal(t, http.StatusOK, serve("/browse"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		plain := createTestHandler(&mockFileManagement{})
+		mux := http.NewServeMux()
+		assert.Same(t, mux, plain.LimitRequests(mux))
+	})
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	defaultShareTTL    = 24 * time.Hour
+	defaultShareMaxTTL = 7 * 24 * time.Hour
+	shareSecretSize    = 32
+)
+
+// sharedLinkKey в контексте запроса по проверенной подписанной ссылке: доступ уже дала подпись, выданная
+// админом, поэтому ucFor отдаёт юзкейс без acl — иначе анонимный запрос упирался бы в acl.default: deny.
+type sharedLinkKey struct{}
+
+// shareLinks подписанные ссылки на скачивание файла без учётной записи: путь и срок под HMAC.
+// ключ отдельный от токенов удаления, чтобы подпись одного нельзя было выдать за другое.
+type shareLinks struct {
+	secret []byte
+	maxTTL time.Duration
+	route  string
+	now    func() time.Time
+}
+
+// newShareLinks без секрета генерирует случайный, тогда ссылки живут до перезапуска сервера.
+func newShareLinks(secret string, maxTTL time.Duration, route string) *shareLinks {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, shareSecretSize)
+		// начиная с Go 1.24 rand.Read никогда не возвращает ошибку.
+		_, _ = rand.Read(key)
+	}
+	if maxTTL <= 0 {
+		maxTTL = defaultShareMaxTTL
+	}
+	return &shareLinks{secret: key, maxTTL: maxTTL, route: route, now: time.Now}
+}
+
+// link относительная ссылка вида `<route>?path=...&expires=<unix>&sig=<hmac>`.
+func (s *shareLinks) link(path string, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 || ttl > s.maxTTL {
+		return "", time.Time{}, fmt.Errorf("share ttl %s must be in (0, %s]: %w",
+			ttl, s.maxTTL, domain.ErrInvalidParameter)
+	}
+	expires := s.now().Add(ttl).Truncate(time.Second)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	query := url.Values{}
+	query.Set(QueryParamPath, path)
+	query.Set(QueryParamExpires, exp)
+	query.Set(QueryParamSignature, s.sign(path, exp))
+	return s.route + "?" + query.Encode(), expires, nil
+}
+
+func (s *shareLinks) validate(path, exp, sig string) error {
+	expUnix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed share link expiry: %w", domain.ErrPermissionDenied)
+	}
+	if s.now().After(time.Unix(expUnix, 0)) {
+		return fmt.Errorf("share link expired: %w", domain.ErrPermissionDenied)
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(path, exp))) {
+		return fmt.Errorf("share link signature does not match path '%s': %w", path, domain.ErrPermissionDenied)
+	}
+	return nil
+}
+
+func (s *shareLinks) sign(path, exp string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("share\n" + path + "\n" + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign выдаёт подписанную ссылку на path со сроком ttl (по умолчанию сутки, не больше server.share_max_ttl).
+// сам эндпоинт админский, см. RequireAdmin в main.
+func (h *Handler) Sign(w http.ResponseWriter, r *http.Request) {
+	if h.shareLinks == nil {
+		h.handleError(w, fmt.Errorf("share links are disabled: %w", domain.ErrUnsupportedOperation),
+			h.messages.InternalError)
+		return
+	}
+
+	ttl := defaultShareTTL
+	if raw := r.URL.Query().Get(QueryParamTTL); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			h.handleError(w, fmt.Errorf("invalid ttl '%s': %w", raw, domain.ErrInvalidParameter),
+				h.messages.InternalError)
+			return
+		}
+		ttl = parsed
+	}
+
+	path := h.getPathFromQuery(r)
+	link, expires, err := h.shareLinks.link(path, ttl)
+	if err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"path": path, "expires_at": expires}).Info("Share link issued")
+	h.writeJSON(w, http.StatusOK, map[string]any{
+		"path":       path,
+		"url":        requestOrigin(r) + link,
+		"expires_at": expires,
+	})
+}
+
+// Shared отдаёт файл по подписанной ссылке. просроченная или подделанная ссылка — 403.
+func (h *Handler) Shared(w http.ResponseWriter, r *http.Request) {
+	if h.shareLinks == nil {
+		http.Error(w, h.messages.ForbiddenFile, http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	path := query.Get(QueryParamPath)
+	if err := h.shareLinks.validate(path, query.Get(QueryParamExpires), query.Get(QueryParamSignature)); err != nil {
+		logrus.Warnf("Rejected share link from %s: %v", clientIP(r), err)
+		http.Error(w, h.messages.ForbiddenFile, http.StatusForbidden)
+		return
+	}
+	h.serve(w, r.WithContext(context.WithValue(r.Context(), sharedLinkKey{}, true)), path, false)
+}
+
+// requestOrigin `scheme://host` запроса для абсолютных ссылок, которые уходят за пределы страницы.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
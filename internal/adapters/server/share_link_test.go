@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestShareLinks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	links := newShareLinks("secret", time.Hour, "/shared")
+	links.now = func() time.Time { return now }
+
+	link, expires, err := links.link("docs/report.pdf", 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(30*time.Minute), expires)
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/shared", u.Path)
+	q := u.Query()
+
+	tests := []struct {
+		name    string
+		path    string
+		exp     string
+		sig     string
+		at      time.Time
+		wantErr bool
+	}{
+		{name: "valid", path: "docs/report.pdf", exp: q.Get("expires"), sig: q.Get("sig"), at: now},
+		{name: "other path", path: "docs/other.pdf", exp: q.Get("expires"), sig: q.Get("sig"), at: now, wantErr: true},
+		{
+			name: "tampered expiry", path: "docs/report.pdf", exp: "9999999999", sig: q.Get("sig"),
+			at: now, wantErr: true,
+		},
+		{name: "malformed expiry", path: "docs/report.pdf", exp: "soon", sig: q.Get("sig"), at: now, wantErr: true},
+		{
+			name: "expired", path: "docs/report.pdf", exp: q.Get("expires"), sig: q.Get("sig"),
+			at: now.Add(time.Hour), wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links.now = func() time.Time { return tt.at }
+			err := links.validate(tt.path, tt.exp, tt.sig)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("ttl above maximum", func(t *testing.T) {
+		_, _, err := links.link("docs/report.pdf", 2*time.Hour)
+		assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+	})
+
+	t.Run("random secret differs", func(t *testing.T) {
+		a := newShareLinks("", 0, "/shared")
+		b := newShareLinks("", 0, "/shared")
+		assert.NotEqual(t, a.sign("p", "1"), b.sign("p", "1"))
+		assert.Equal(t, defaultShareMaxTTL, a.maxTTL)
+	})
+}
+
+func TestHandler_SignAndShared(t *testing.T) {
+	var served string
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, _ *http.Request, path string) error {
+			served = path
+			_, err := w.Write([]byte("content"))
+			return err
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithShareLinks("secret", time.Hour, "/shared")(handler)
+
+	w := httptest.NewRecorder()
+	handler.Sign(w, httptest.NewRequest("GET", "http://files.local/sign?path=docs/a.txt&ttl=10m", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		URL string `json:"url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, strings.HasPrefix(resp.URL, "http://files.local/shared?"), resp.URL)
+
+	t.Run("valid link served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Shared(w, httptest.NewRequest("GET", resp.URL, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "content", w.Body.String())
+		assert.Equal(t, "docs/a.txt", served)
+	})
+
+	t.Run("tampered path", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Shared(w, httptest.NewRequest("GET", strings.Replace(resp.URL, "a.txt", "b.txt", 1), nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("bad ttl", func(t *testing.T) {
+		for _, ttl := range []string{"forever", "-1m", "2h"} {
+			w := httptest.NewRecorder()
+			handler.Sign(w, httptest.NewRequest("GET", "/sign?path=a.txt&ttl="+ttl, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, ttl)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		plain := createTestHandler(mockUC)
+
+		w := httptest.NewRecorder()
+		plain.Shared(w, httptest.NewRequest("GET", resp.URL, nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		plain.Sign(w, httptest.NewRequest("GET", "/sign?path=a.txt", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandler_Shared_ACLDenyAll(t *testing.T) {
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, _ *http.Request, _ string) error {
+			_, err := w.Write([]byte("content"))
+			return err
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithShareLinks("secret", time.Hour, "/shared")(handler)
+	// как acl.default: deny без правил: никому, включая анонима, ничего нельзя.
+	acl := &fakeACL{allowed: "nobody", uc: mockUC}
+	WithACL(acl)(handler)
+	link, _, err := handler.shareLinks.link("private/a.txt", time.Minute)
+	require.NoError(t, err)
+
+	t.Run("valid signature bypasses acl", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Shared(w, httptest.NewRequest("GET", link, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "content", w.Body.String())
+		assert.Empty(t, acl.seen, "acl is not consulted for a signed link")
+	})
+
+	t.Run("regular download still denied", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Download(w, httptest.NewRequest("GET", "/download?path=private/a.txt", nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
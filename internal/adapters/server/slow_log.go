@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSlowRequests пишет warn про запросы дольше server.slow_op_threshold: так видно медленный nfs
+// или огромную папку без лога на каждый запрос. быстрые запросы молчат, 0 — выключено.
+// ставится внутрь Recover, иначе id запроса в контексте ещё нет.
+func (h *Handler) LogSlowRequests(next http.Handler) http.Handler {
+	if h.slowOpThreshold <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(sw, r)
+
+		elapsed := time.Since(start)
+		if elapsed < h.slowOpThreshold {
+			return
+		}
+		// путь у загрузки в теле формы, у остальных в query; форму к этому моменту уже разобрал обработчик.
+		path := r.URL.Query().Get(FormParamPath)
+		if r.Form != nil {
+			path = r.Form.Get(FormParamPath)
+		}
+		logrus.WithFields(logrus.Fields{
+			"request_id": RequestID(r.Context()),
+			"method":     r.Method,
+			"route":      r.URL.Path,
+			"path":       path,
+			"status":     sw.status,
+			"duration":   elapsed.Round(time.Millisecond).String(),
+		}).Warn(LogSlowRequest)
+	})
+}
+
+// statusWriter запоминает код ответа для лога медленных запросов.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush явно, а не только через Unwrap: /logs проверяет http.Flusher приведением типа.
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap для http.ResponseController (дедлайны) поверх обёртки.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_LogSlowRequests(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		_ = r.ParseForm()
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var logs bytes.Buffer
+	out := logrus.StandardLogger().Out
+	logrus.SetOutput(&logs)
+	t.Cleanup(func() { logrus.SetOutput(out) })
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		target    string
+		wantLog   bool
+	}{
+		{name: "slow request logged", threshold: 10 * time.Millisecond, target: "/slow?path=big/dir", wantLog: true},
+		{name: "fast request silent", threshold: 10 * time.Millisecond, target: "/fast?path=big/dir"},
+		{name: "disabled", target: "/slow?path=big/dir"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			handler := createTestHandler(&mockFileManagement{})
+			WithSlowOpThreshold(tt.threshold)(handler)
+			wrapped := handler.Recover(handler.LogSlowRequests(next))
+
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.Header.Set(HeaderRequestID, "req-42")
+			w := httptest.NewRecorder()
+			wrapped.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			if !tt.wantLog {
+				assert.NotContains(t, logs.String(), LogSlowRequest)
+				return
+			}
+			line := logs.String()
+			assert.Contains(t, line, "level=warning")
+			assert.Contains(t, line, LogSlowRequest)
+			assert.Contains(t, line, "request_id=req-42")
+			assert.Contains(t, line, "path=big/dir")
+			assert.Contains(t, line, "route=/slow")
+			assert.Contains(t, line, "status=404")
+		})
+	}
+}
+
+func TestStatusWriter_Flush(t *testing.T) {
+	w := httptest.NewRecorder()
+	var sw http.ResponseWriter = &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+	flusher, ok := sw.(http.Flusher)
+	assert.True(t, ok)
+	flusher.Flush()
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// templateFuncs хелперы для index.html: {{humanSize .Size}}, {{since .ModTime}}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"humanSize": humanSize,
+		"since": func(t time.Time) string {
+			return sinceAt(t, time.Now())
+		},
+	}
+}
+
+// humanSize размер в двоичных единицах (1 KB = 1024 B), как показывают файловые менеджеры.
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// sinceAt относительное время "2 hours ago". now передаётся снаружи, чтобы функция была чистой.
+func sinceAt(t, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	d := now.Sub(t)
+	if d < 0 {
+		// часы клиента/сервера разошлись, будущее время не показываем как "-5 minutes ago".
+		return "just now"
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	case d < 30*24*time.Hour:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d < 365*24*time.Hour:
+		return plural(int(d/(30*24*time.Hour)), "month")
+	default:
+		return plural(int(d/(365*24*time.Hour)), "year")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s ago", unit)
+	}
+	return fmt.Sprintf("%d %ss ago", n, unit)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536, "1.5 KB"},
+		{10 * 1024 * 1024, "10.0 MB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GB"},
+		{3 << 40, "3.0 TB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, humanSize(tt.size))
+		})
+	}
+}
+
+func TestSinceAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"zero time", time.Time{}, ""},
+		{"future", now.Add(time.Hour), "just now"},
+		{"seconds", now.Add(-30 * time.Second), "just now"},
+		{"one minute", now.Add(-time.Minute), "1 minute ago"},
+		{"minutes", now.Add(-5 * time.Minute), "5 minutes ago"},
+		{"hours", now.Add(-2 * time.Hour), "2 hours ago"},
+		{"one day", now.Add(-25 * time.Hour), "1 day ago"},
+		{"months", now.Add(-65 * 24 * time.Hour), "2 months ago"},
+		{"years", now.Add(-800 * 24 * time.Hour), "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sinceAt(tt.t, now))
+		})
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl, err := template.New("t").Funcs(templateFuncs()).Parse(`{{humanSize .Size}}|{{since .ModTime}}`)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, struct {
+		Size    int64
+		ModTime time.Time
+	}{2048, time.Now().Add(-3 * time.Hour)}))
+
+	assert.Equal(t, "2.0 KB|3 hours ago", buf.String())
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tokenBucket ограничитель скорости в байтах в секунду, запас — одна секунда трафика.
+// свой, а не x/time/rate, ради одной функции тянуть зависимость незачем.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+
+	// подменяются в тестах, чтобы не ждать по-настоящему.
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newTokenBucket(bps int64) *tokenBucket {
+	b := &tokenBucket{
+		rate:  float64(bps),
+		burst: int(max(bps, 1)),
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+	b.tokens = float64(b.burst)
+	b.last = b.now()
+	return b
+}
+
+// take списывает n байт и спит, если их не хватило: в долг, зато без лишнего копирования.
+func (b *tokenBucket) take(n int) {
+	b.mu.Lock()
+	now := b.now()
+	b.tokens = min(float64(b.burst), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait > 0 {
+		b.sleep(wait)
+	}
+}
+
+// throttledReader читает не быстрее bucket, за раз не больше запаса, чтобы не было рывков.
+type throttledReader struct {
+	io.ReadCloser
+	bucket *tokenBucket
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > r.bucket.burst {
+		p = p[:r.bucket.burst]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.bucket.take(n)
+	}
+	return n, err
+}
+
+// throttledWriter отдаёт ответ не быстрее bucket. обёртка над ResponseWriter, а не над копированием,
+// так лимит работает и для http.ServeFile, и для потокового zip без своих путей отдачи.
+type throttledWriter struct {
+	http.ResponseWriter
+	bucket *tokenBucket
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), w.bucket.burst)]
+		w.bucket.take(len(chunk))
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Unwrap для http.ResponseController (Flush, дедлайны) поверх обёртки.
+func (w *throttledWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// throttleUpload ограничивает скорость чтения тела загрузки (server.max_upload_bps), лимит свой на каждую загрузку.
+func (h *Handler) throttleUpload(body io.ReadCloser) io.ReadCloser {
+	if h.maxUploadBPS <= 0 {
+		return body
+	}
+	return &throttledReader{ReadCloser: body, bucket: newTokenBucket(h.maxUploadBPS)}
+}
+
+// setUploadDeadline срок на чтение тела (server.upload_timeout): с урезанной скоростью
+// или просто медленный клиент не держит соединение и слот загрузки бесконечно.
+func (h *Handler) setUploadDeadline(w http.ResponseWriter) {
+	if h.uploadTimeout <= 0 {
+		return
+	}
+	if err := http.NewResponseController(w).SetReadDeadline(time.Now().Add(h.uploadTimeout)); err != nil {
+		logrus.Debugf("Upload deadline not supported: %v", err)
+	}
+}
+
+// throttleDownload ограничивает скорость отдачи файла или zip (server.max_download_bps), лимит свой на каждый запрос.
+func (h *Handler) throttleDownload(w http.ResponseWriter) http.ResponseWriter {
+	if h.maxDownloadBPS <= 0 {
+		return w
+	}
+	return &throttledWriter{ResponseWriter: w, bucket: newTokenBucket(h.maxDownloadBPS)}
+}
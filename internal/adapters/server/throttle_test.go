@@ -0,0 +1,166 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock время идёт только когда bucket спит.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) install(b *tokenBucket) {
+	b.now = func() time.Time { return c.now }
+	b.sleep = func(d time.Duration) {
+		c.slept += d
+		c.now = c.now.Add(d)
+	}
+	b.last = c.now
+}
+
+func TestThrottledReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		bps       int64
+		size      int
+		wantSlept time.Duration
+	}{
+		{name: "within burst", bps: 1024, size: 1024, wantSlept: 0},
+		{name: "ten seconds of data", bps: 1024, size: 10 * 1024, wantSlept: 9 * time.Second},
+		{name: "tiny rate", bps: 1, size: 3, wantSlept: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte("x"), tt.size)
+			bucket := newTokenBucket(tt.bps)
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			clock.install(bucket)
+			r := &throttledReader{ReadCloser: io.NopCloser(bytes.NewReader(data)), bucket: bucket}
+
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+			assert.InDelta(t, tt.wantSlept.Seconds(), clock.slept.Seconds(), 0.01)
+		})
+	}
+}
+
+func TestHandler_throttleUpload(t *testing.T) {
+	body := io.NopCloser(bytes.NewReader(nil))
+
+	handler := createTestHandler(&mockFileManagement{})
+	assert.Equal(t, body, handler.throttleUpload(body), "disabled by default")
+
+	WithUploadThrottle(1024, 0)(handler)
+	assert.IsType(t, &throttledReader{}, handler.throttleUpload(body))
+}
+
+func TestThrottledWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		bps       int64
+		writes    []int
+		wantSlept time.Duration
+	}{
+		{name: "within burst", bps: 1024, writes: []int{512, 512}, wantSlept: 0},
+		{name: "one big write", bps: 1024, writes: []int{4 * 1024}, wantSlept: 3 * time.Second},
+		{name: "many small writes", bps: 100, writes: []int{50, 50, 50, 50}, wantSlept: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			bucket := newTokenBucket(tt.bps)
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			clock.install(bucket)
+			w := &throttledWriter{ResponseWriter: rec, bucket: bucket}
+
+			total := 0
+			for _, size := range tt.writes {
+				n, err := w.Write(bytes.Repeat([]byte("x"), size))
+				require.NoError(t, err)
+				assert.Equal(t, size, n)
+				total += size
+			}
+			assert.Equal(t, total, rec.Body.Len())
+			assert.InDelta(t, tt.wantSlept.Seconds(), clock.slept.Seconds(), 0.01)
+			assert.Same(t, rec, w.Unwrap())
+		})
+	}
+}
+
+func TestHandler_Download_Throttled(t *testing.T) {
+	content := "throttled content"
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, _ *http.Request, _ string) error {
+			assert.IsType(t, &throttledWriter{}, w)
+			_, err := io.WriteString(w, content)
+			return err
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithDownloadThrottle(1 << 20)(handler)
+
+	w := httptest.NewRecorder()
+	handler.Download(w, httptest.NewRequest("GET", "/download?path=a.txt", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, content, w.Body.String())
+}
+
+func TestHandler_Download_UnsatisfiableRange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "small.txt")
+	require.NoError(t, os.WriteFile(file, []byte("small"), 0o644))
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, r *http.Request, _ string) error {
+			http.ServeFile(w, r, file)
+			return nil
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithDownloadThrottle(1 << 20)(handler)
+
+	r := httptest.NewRequest("GET", "/download?path=small.txt", nil)
+	r.Header.Set("Range", "bytes=99999999-")
+	w := httptest.NewRecorder()
+	handler.Download(w, r)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	assert.Equal(t, "bytes */5", w.Header().Get("Content-Range"))
+}
+
+func TestHandler_Download_MultiRange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "small.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hello world"), 0o644))
+	mockUC := &mockFileManagement{
+		serveFileFunc: func(w http.ResponseWriter, r *http.Request, _ string) error {
+			http.ServeFile(w, r, file)
+			return nil
+		},
+	}
+	handler := createTestHandler(mockUC)
+	WithDownloadThrottle(1 << 20)(handler)
+
+	r := httptest.NewRequest("GET", "/download?path=small.txt", nil)
+	r.Header.Set("Range", "bytes=0-2,6-10")
+	w := httptest.NewRecorder()
+	handler.Download(w, r)
+
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges; boundary="))
+	assert.Contains(t, w.Body.String(), "Content-Range: bytes 0-2/11")
+	assert.Contains(t, w.Body.String(), "Content-Range: bytes 6-10/11")
+}
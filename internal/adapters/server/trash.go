@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// Restore возвращает запись из корзины на прежнее место, path — путь внутри корзины.
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	path := r.FormValue(FormParamPath)
+	if err := h.ucFor(r).Restore(path); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"operation": OperationRestore,
+		"path":      path,
+	}).Info(LogFileOrFolderRestored)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EmptyTrash стирает корзину насовсем.
+func (h *Handler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	if !h.allowPost(w, r) {
+		return
+	}
+
+	if err := h.ucFor(r).EmptyTrash(); err != nil {
+		h.handleError(w, err, h.messages.InternalError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{"operation": OperationEmptyTrash}).Info(LogTrashEmptied)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// trashPurgeResponse ответ /trash-purge, last_run null — фоновая очистка ещё ни разу не проходила.
+type trashPurgeResponse struct {
+	LastRun *domain.TrashPurgeReport `json:"last_run"`
+}
+
+// TrashPurge итог последнего прохода фоновой очистки корзины (file.trash_purge_interval).
+func (h *Handler) TrashPurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", AllowGetHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if h.trashPurger == nil {
+		h.handleError(w, fmt.Errorf("trash purge is disabled: %w", domain.ErrUnsupportedOperation),
+			h.messages.InternalError)
+		return
+	}
+
+	var resp trashPurgeResponse
+	if report, ok := h.trashPurger.LastRun(); ok {
+		resp.LastRun = &report
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
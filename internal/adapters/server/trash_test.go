@@ -0,0 +1,136 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestHandler_Restore(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		ucErr      error
+		wantStatus int
+		wantPath   string
+	}{
+		{name: "restored", method: "POST", wantStatus: http.StatusNoContent, wantPath: "docs/a.txt"},
+		{name: "place taken", method: "POST", ucErr: domain.ErrAlreadyExists, wantStatus: http.StatusConflict,
+			wantPath: "docs/a.txt"},
+		{name: "not in trash", method: "POST", ucErr: domain.ErrFileNotFound, wantStatus: http.StatusNotFound,
+			wantPath: "docs/a.txt"},
+		{name: "trash disabled", method: "POST", ucErr: domain.ErrUnsupportedOperation,
+			wantStatus: http.StatusForbidden, wantPath: "docs/a.txt"},
+		{name: "get", method: "GET", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			handler := createTestHandler(&mockFileManagement{
+				restoreFunc: func(path string) error {
+					gotPath = path
+					return tt.ucErr
+				},
+			})
+			req := httptest.NewRequest(tt.method, "/restore", strings.NewReader("path=docs/a.txt"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			handler.Restore(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPath, gotPath)
+		})
+	}
+}
+
+func TestHandler_EmptyTrash(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		ucErr      error
+		wantStatus int
+		wantCalled bool
+	}{
+		{name: "emptied", method: "POST", wantStatus: http.StatusNoContent, wantCalled: true},
+		{name: "trash disabled", method: "POST", ucErr: domain.ErrUnsupportedOperation,
+			wantStatus: http.StatusForbidden, wantCalled: true},
+		{name: "get", method: "GET", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := createTestHandler(&mockFileManagement{
+				emptyTrashFunc: func() error {
+					called = true
+					return tt.ucErr
+				},
+			})
+			w := httptest.NewRecorder()
+
+			handler.EmptyTrash(w, httptest.NewRequest(tt.method, "/empty-trash", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
+
+// stubTrashPurger отдаёт заранее заданный итог, nil — проходов ещё не было.
+type stubTrashPurger struct{ report *domain.TrashPurgeReport }
+
+func (s stubTrashPurger) LastRun() (domain.TrashPurgeReport, bool) {
+	if s.report == nil {
+		return domain.TrashPurgeReport{}, false
+	}
+	return *s.report, true
+}
+
+func TestHandler_TrashPurge(t *testing.T) {
+	report := &domain.TrashPurgeReport{
+		StartedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Purged:     3,
+		BytesFreed: 2048,
+		Skipped:    1,
+	}
+	tests := []struct {
+		name       string
+		method     string
+		opts       []Option
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "last run", method: "GET", opts: []Option{WithTrashPurger(stubTrashPurger{report: report})},
+			wantStatus: http.StatusOK,
+			wantBody: `{"last_run":{"started_at":"2024-05-01T12:00:00Z","purged":3,"bytes_freed":2048,` +
+				`"skipped":1}}`},
+		{name: "no runs yet", method: "GET", opts: []Option{WithTrashPurger(stubTrashPurger{})},
+			wantStatus: http.StatusOK, wantBody: `{"last_run":null}`},
+		{name: "purge disabled", method: "GET", wantStatus: http.StatusForbidden},
+		{name: "post", method: "POST", opts: []Option{WithTrashPurger(stubTrashPurger{})},
+			wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createTestHandler(&mockFileManagement{})
+			for _, opt := range tt.opts {
+				opt(handler)
+			}
+			w := httptest.NewRecorder()
+
+			handler.TrashPurge(w, httptest.NewRequest(tt.method, "/trash-purge", nil))
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
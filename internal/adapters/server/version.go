@@ -0,0 +1,21 @@
+package server
+
+import "net/http"
+
+// BuildInfo ответ /version: данные сборки из ldflags и версия API из конфига.
+// вместе с /capabilities клиент понимает, какая сборка где развёрнута и что она умеет.
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildDate  string `json:"build_date"`
+	APIVersion string `json:"api_version"`
+}
+
+func (h *Handler) Version(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", AllowGetHead)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, h.buildInfo)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Version(t *testing.T) {
+	info := BuildInfo{Version: "v1.4.0", Commit: "abc1234", BuildDate: "2024-05-01T10:00:00Z", APIVersion: "2"}
+	handler := createTestHandler(&mockFileManagement{})
+	WithBuildInfo(info)(handler)
+
+	t.Run("get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		handler.Version(w, httptest.NewRequest("GET", "/version", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		var got map[string]string
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, map[string]string{
+			"version":     "v1.4.0",
+			"commit":      "abc1234",
+			"build_date":  "2024-05-01T10:00:00Z",
+			"api_version": "2",
+		}, got)
+	})
+
+	t.Run("post not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		handler.Version(w, httptest.NewRequest("POST", "/version", nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, AllowGetHead, w.Header().Get("Allow"))
+	})
+}
@@ -0,0 +1,114 @@
+package storagefactory
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"file-manager/internal/adapters/encryptedstorage"
+	"file-manager/internal/adapters/localstorage"
+	"file-manager/internal/adapters/memstorage"
+	"file-manager/internal/adapters/mountstorage"
+	"file-manager/internal/adapters/retrystorage"
+	"file-manager/internal/adapters/timeoutstorage"
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+// EncryptionKeyEnv перекрывает storage.encryption.key.
+const EncryptionKeyEnv = "FILE_MANAGER_ENCRYPTION_KEY"
+
+// New собирает хранилище по storage.type: сам бэкенд, монтирования и обёртки таймаута/ретраев.
+// обязательные для бэкенда поля проверяются здесь, а не в config, чтобы новый тип добавлялся одним case.
+func New(cfg *config.Config) (domain.FileStorage, error) {
+	var (
+		fileStorage domain.FileStorage
+		err         error
+	)
+	switch cfg.Storage.Type {
+	case domain.StorageTypeLocal:
+		fileStorage, err = newLocal(cfg)
+	case domain.StorageTypeMemory:
+		fileStorage, err = newMemory(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage.type %q (supported: %s, %s): %w",
+			cfg.Storage.Type, domain.StorageTypeLocal, domain.StorageTypeMemory, domain.ErrInvalidParameter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// шифрование сразу над бэкендом: таймауты и ретраи оборачивают уже его.
+	if cfg.Storage.Encryption.Enabled {
+		if fileStorage, err = newEncrypted(fileStorage, cfg.Storage.Encryption.Key); err != nil {
+			return nil, err
+		}
+	}
+	// таймаут внутри ретраев: зависший вызов не повторяется, а сразу отдаёт 503.
+	if cfg.Storage.OperationTimeout > 0 {
+		fileStorage = timeoutstorage.NewTimeoutStorage(fileStorage, cfg.Storage.OperationTimeout)
+	}
+	if cfg.Storage.Retry.Enabled {
+		fileStorage = retrystorage.NewRetryStorage(fileStorage, cfg.Storage.Retry.Attempts, cfg.Storage.Retry.Backoff)
+	}
+	return fileStorage, nil
+}
+
+func newLocal(cfg *config.Config) (domain.FileStorage, error) {
+	if cfg.Storage.BasePath == "" {
+		return nil, fmt.Errorf("storage.base_path is required for %q storage: %w",
+			domain.StorageTypeLocal, domain.ErrInvalidParameter)
+	}
+	// надо убедиться, что директория существует прежде чем запускать сервер.
+	// грубо говоря, чтобы нам было куда записывать.
+	if err := os.MkdirAll(cfg.Storage.BasePath, cfg.File.DirPermissions); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	build := func(basePath string) domain.FileStorage {
+		return localstorage.NewLocalStorageService(
+			basePath,
+			cfg.File.DirPermissions,
+			localstorage.WithFilePermissions(cfg.File.FilePermissions),
+			localstorage.WithExactPermissions(cfg.File.ExactPermissions),
+			localstorage.WithAllowSymlinkMove(cfg.File.AllowSymlinkMove),
+		)
+	}
+	root := build(cfg.Storage.BasePath)
+	if len(cfg.Storage.Mounts) == 0 {
+		return root, nil
+	}
+	mounts := make(map[string]domain.FileStorage, len(cfg.Storage.Mounts))
+	for name, path := range cfg.Storage.Mounts {
+		if path == "" {
+			return nil, fmt.Errorf("storage.mounts.%s: path is required: %w", name, domain.ErrInvalidParameter)
+		}
+		mounts[name] = build(path)
+	}
+	return mountstorage.NewMountStorage(root, mounts), nil
+}
+
+// newMemory base_path не нужен, монтирования — это каталоги на диске, с памятью они не сочетаются.
+func newMemory(cfg *config.Config) (domain.FileStorage, error) {
+	if len(cfg.Storage.Mounts) > 0 {
+		return nil, fmt.Errorf("storage.mounts are not supported for %q storage: %w",
+			domain.StorageTypeMemory, domain.ErrInvalidParameter)
+	}
+	return memstorage.NewMemStorageService(), nil
+}
+
+func newEncrypted(next domain.FileStorage, configKey string) (domain.FileStorage, error) {
+	key := configKey
+	if env := os.Getenv(EncryptionKeyEnv); env != "" {
+		key = env
+	}
+	if key == "" {
+		return nil, fmt.Errorf("storage.encryption.key or %s is required when encryption is enabled: %w",
+			EncryptionKeyEnv, domain.ErrInvalidParameter)
+	}
+	raw, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("storage.encryption.key must be hex: %w", domain.ErrInvalidParameter)
+	}
+	return encryptedstorage.NewEncryptedStorage(next, raw)
+}
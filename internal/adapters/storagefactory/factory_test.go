@@ -0,0 +1,163 @@
+package storagefactory
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/adapters/encryptedstorage"
+	"file-manager/internal/adapters/localstorage"
+	"file-manager/internal/adapters/memstorage"
+	"file-manager/internal/adapters/mountstorage"
+	"file-manager/internal/adapters/retrystorage"
+	"file-manager/internal/adapters/timeoutstorage"
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func newConfig(t *testing.T) *config.Config {
+	cfg := &config.Config{}
+	cfg.Storage.Type = domain.StorageTypeLocal
+	cfg.Storage.BasePath = filepath.Join(t.TempDir(), "storage")
+	cfg.File.DirPermissions = 0o755
+	cfg.File.FilePermissions = 0o644
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(cfg *config.Config)
+		envKey  string
+		check   func(t *testing.T, s any)
+		wantErr string
+	}{
+		{
+			name: "local",
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &localstorage.LocalStorageService{}, s)
+			},
+		},
+		{
+			name: "local with mounts",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Mounts = map[string]string{"photos": cfg.Storage.BasePath + "-photos"}
+			},
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &mountstorage.MountStorage{}, s)
+			},
+		},
+		{
+			name: "decorators wrap the backend",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.OperationTimeout = time.Second
+				cfg.Storage.Retry.Enabled = true
+				cfg.Storage.Retry.Attempts = 2
+			},
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &retrystorage.RetryStorage{}, s)
+			},
+		},
+		{
+			name: "timeout only",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.OperationTimeout = time.Second
+			},
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &timeoutstorage.TimeoutStorage{}, s)
+			},
+		},
+		{
+			name: "encryption",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Encryption = config.EncryptionConfig{Enabled: true, Key: strings.Repeat("ab", 32)}
+			},
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &encryptedstorage.EncryptedStorage{}, s)
+			},
+		},
+		{
+			name: "encryption key from env",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Encryption = config.EncryptionConfig{Enabled: true, Key: "not hex"}
+			},
+			envKey: strings.Repeat("cd", 32),
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &encryptedstorage.EncryptedStorage{}, s)
+			},
+		},
+		{
+			name:    "encryption without key",
+			mutate:  func(cfg *config.Config) { cfg.Storage.Encryption.Enabled = true },
+			wantErr: "storage.encryption.key or " + EncryptionKeyEnv,
+		},
+		{
+			name: "encryption key of wrong size",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Encryption = config.EncryptionConfig{Enabled: true, Key: "abcd"}
+			},
+			wantErr: "encryption key must be 32 bytes",
+		},
+		{
+			name: "memory",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Type = domain.StorageTypeMemory
+				cfg.Storage.BasePath = ""
+			},
+			check: func(t *testing.T, s any) {
+				assert.IsType(t, &memstorage.MemStorageService{}, s)
+			},
+		},
+		{
+			name: "memory with mounts",
+			mutate: func(cfg *config.Config) {
+				cfg.Storage.Type = domain.StorageTypeMemory
+				cfg.Storage.Mounts = map[string]string{"photos": "/mnt/photos"}
+			},
+			wantErr: "storage.mounts are not supported",
+		},
+		{
+			name:    "unknown type",
+			mutate:  func(cfg *config.Config) { cfg.Storage.Type = "s3" },
+			wantErr: `unknown storage.type "s3"`,
+		},
+		{
+			name:    "local without base path",
+			mutate:  func(cfg *config.Config) { cfg.Storage.BasePath = "" },
+			wantErr: "storage.base_path is required",
+		},
+		{
+			name:    "mount without path",
+			mutate:  func(cfg *config.Config) { cfg.Storage.Mounts = map[string]string{"photos": ""} },
+			wantErr: "storage.mounts.photos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig(t)
+			t.Setenv(EncryptionKeyEnv, tt.envKey)
+			if tt.mutate != nil {
+				tt.mutate(cfg)
+			}
+
+			s, err := New(cfg)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if cfg.Storage.Type == domain.StorageTypeLocal {
+				assert.DirExists(t, cfg.Storage.BasePath)
+			}
+			tt.check(t, s)
+		})
+	}
+}
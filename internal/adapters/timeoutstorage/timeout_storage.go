@@ -0,0 +1,107 @@
+package timeoutstorage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// TimeoutStorage декоратор над FileStorage: вызов, который висит дольше timeout (замёрзший NFS-маунт),
+// бросается с domain.ErrStorageUnavailable, и запрос не ждёт его вечно.
+// сам системный вызов прервать нельзя, его горутина завершится, когда ядро вернёт управление.
+// запись потоком (WriteFile, OpenWriter) не ограничивается: её длительность зависит от клиента, а не от диска.
+type TimeoutStorage struct {
+	next    domain.FileStorage
+	timeout time.Duration
+}
+
+// NewTimeoutStorage timeout <= 0 — вызовы идут как есть.
+func NewTimeoutStorage(next domain.FileStorage, timeout time.Duration) *TimeoutStorage {
+	return &TimeoutStorage{next: next, timeout: timeout}
+}
+
+func (s *TimeoutStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	return withTimeout(s, "ReadDirectory", relPath, func() ([]os.FileInfo, error) {
+		return s.next.ReadDirectory(relPath)
+	})
+}
+
+func (s *TimeoutStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	return withTimeout(s, "Open", relPath, func() (io.ReadSeekCloser, error) {
+		return s.next.Open(relPath)
+	})
+}
+
+func (s *TimeoutStorage) Stat(relPath string) (os.FileInfo, error) {
+	return withTimeout(s, "Stat", relPath, func() (os.FileInfo, error) {
+		return s.next.Stat(relPath)
+	})
+}
+
+func (s *TimeoutStorage) GetAbsolutePath(relPath string) string {
+	return s.next.GetAbsolutePath(relPath)
+}
+
+func (s *TimeoutStorage) WriteFile(relPath string, file io.Reader) error {
+	return s.next.WriteFile(relPath, file)
+}
+
+func (s *TimeoutStorage) OpenWriter(relPath string) (io.WriteCloser, error) {
+	return s.next.OpenWriter(relPath)
+}
+
+func (s *TimeoutStorage) Remove(relPath string) error {
+	_, err := withTimeout(s, "Remove", relPath, func() (struct{}, error) {
+		return struct{}{}, s.next.Remove(relPath)
+	})
+	return err
+}
+
+func (s *TimeoutStorage) Move(oldRel, newRel string) error {
+	_, err := withTimeout(s, "Move", oldRel, func() (struct{}, error) {
+		return struct{}{}, s.next.Move(oldRel, newRel)
+	})
+	return err
+}
+
+func (s *TimeoutStorage) CreateDirectory(relPath string) error {
+	_, err := withTimeout(s, "CreateDirectory", relPath, func() (struct{}, error) {
+		return struct{}{}, s.next.CreateDirectory(relPath)
+	})
+	return err
+}
+
+type result[T any] struct {
+	value T
+	err   error
+}
+
+func withTimeout[T any](s *TimeoutStorage, op, relPath string, fn func() (T, error)) (T, error) {
+	if s.timeout <= 0 {
+		return fn()
+	}
+
+	// буфер на один элемент: брошенная горутина допишет результат и завершится, а не повиснет на канале.
+	done := make(chan result[T], 1)
+	go func() {
+		value, err := fn()
+		done <- result[T]{value: value, err: err}
+	}()
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-timer.C:
+		logrus.Errorf("Storage call %s '%s' did not finish in %v, abandoning it", op, relPath, s.timeout)
+		var zero T
+		return zero, fmt.Errorf("%s '%s' timed out after %v: %w", op, relPath, s.timeout, domain.ErrStorageUnavailable)
+	}
+}
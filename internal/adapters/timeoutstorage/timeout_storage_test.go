@@ -0,0 +1,88 @@
+package timeoutstorage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+// fakeStorage каждый вызов ждёт release (если он задан), имитируя замёрзший маунт.
+type fakeStorage struct {
+	release chan struct{}
+	err     error
+}
+
+func (f *fakeStorage) wait() error {
+	if f.release != nil {
+		<-f.release
+	}
+	return f.err
+}
+
+func (f *fakeStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
+	return []os.FileInfo{}, f.wait()
+}
+func (f *fakeStorage) Open(relPath string) (io.ReadSeekCloser, error)    { return nil, f.wait() }
+func (f *fakeStorage) Stat(relPath string) (os.FileInfo, error)          { return nil, f.wait() }
+func (f *fakeStorage) WriteFile(relPath string, file io.Reader) error    { return f.wait() }
+func (f *fakeStorage) OpenWriter(relPath string) (io.WriteCloser, error) { return nil, f.wait() }
+func (f *fakeStorage) Remove(relPath string) error                       { return f.wait() }
+func (f *fakeStorage) Move(oldRel, newRel string) error                  { return f.wait() }
+func (f *fakeStorage) CreateDirectory(relPath string) error              { return f.wait() }
+func (f *fakeStorage) GetAbsolutePath(relPath string) string             { return "/base/" + relPath }
+
+func TestTimeoutStorage(t *testing.T) {
+	t.Run("fast calls pass through", func(t *testing.T) {
+		inner := &fakeStorage{err: os.ErrNotExist}
+		s := NewTimeoutStorage(inner, time.Second)
+
+		entries, err := s.ReadDirectory("docs")
+		assert.NotNil(t, entries)
+		assert.True(t, errors.Is(err, os.ErrNotExist), "original error is kept")
+	})
+
+	t.Run("hung calls are abandoned", func(t *testing.T) {
+		inner := &fakeStorage{release: make(chan struct{})}
+		defer close(inner.release)
+		s := NewTimeoutStorage(inner, 10*time.Millisecond)
+
+		calls := map[string]func() error{
+			"ReadDirectory": func() error {
+				_, err := s.ReadDirectory("docs")
+				return err
+			},
+			"Open": func() error {
+				_, err := s.Open("a")
+				return err
+			},
+			"Stat": func() error {
+				_, err := s.Stat("a")
+				return err
+			},
+			"Remove":          func() error { return s.Remove("a") },
+			"Move":            func() error { return s.Move("a", "b") },
+			"CreateDirectory": func() error { return s.CreateDirectory("a") },
+		}
+		for name, call := range calls {
+			start := time.Now()
+			err := call()
+			require.Error(t, err, name)
+			assert.True(t, errors.Is(err, domain.ErrStorageUnavailable), "%s: got %v", name, err)
+			assert.Less(t, time.Since(start), time.Second, name)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		release := make(chan struct{})
+		close(release)
+		s := NewTimeoutStorage(&fakeStorage{release: release}, 0)
+		assert.NoError(t, s.Move("a", "b"))
+	})
+}
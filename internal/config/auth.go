@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shaPrefix хэш как у `htpasswd -s`: base64 от sha1 пароля.
+const shaPrefix = "{SHA}"
+
+// AuthConfig аутентификация запросов. без users и token выключена, сервер открыт как раньше.
+type AuthConfig struct {
+	// Users строки htpasswd `логин:хэш`, хэш `{SHA}...` (htpasswd -s) или пароль как есть.
+	Users []string `yaml:"users"`
+	// Token статический bearer-токен, запрос с ним идёт от пользователя `token`.
+	Token string `yaml:"token"`
+	// Roles роли пользователей для acl.rules (`role:admin`).
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// Enabled задан хоть один способ входа.
+func (c AuthConfig) Enabled() bool {
+	return len(c.Users) > 0 || c.Token != ""
+}
+
+// Credentials логин -> хэш из Users. bcrypt и apr1 ($2y$, $apr1$) не поддерживаются,
+// такие строки отклоняются, а не молча не пускают пользователя.
+func (c AuthConfig) Credentials() (map[string]string, error) {
+	creds := make(map[string]string, len(c.Users))
+	for _, line := range c.Users {
+		login, hash, found := strings.Cut(line, ":")
+		if !found || login == "" || hash == "" {
+			return nil, validationError{
+				field: "auth.users",
+				msg:   fmt.Sprintf("expected login:hash, got %q", line),
+			}
+		}
+		if strings.HasPrefix(hash, "$") {
+			return nil, validationError{
+				field: "auth.users",
+				msg:   fmt.Sprintf("unsupported hash for %q, use {SHA} (htpasswd -s) or plain", login),
+			}
+		}
+		if _, dup := creds[login]; dup {
+			return nil, validationError{field: "auth.users", msg: fmt.Sprintf("duplicate login %q", login)}
+		}
+		creds[login] = hash
+	}
+	return creds, nil
+}
+
+func validateAuth(c AuthConfig) error {
+	_, err := c.Credentials()
+	return err
+}
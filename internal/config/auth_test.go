@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthConfig_Credentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		users   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", want: map[string]string{}},
+		{
+			name:  "sha and plain",
+			users: []string{"alice:{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", "bob:s3cret"},
+			want:  map[string]string{"alice": "{SHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g=", "bob": "s3cret"},
+		},
+		{name: "colon in password", users: []string{"bob:a:b"}, want: map[string]string{"bob": "a:b"}},
+		{name: "no colon", users: []string{"alice"}, wantErr: true},
+		{name: "empty login", users: []string{":pass"}, wantErr: true},
+		{name: "empty hash", users: []string{"alice:"}, wantErr: true},
+		{name: "bcrypt", users: []string{"alice:$2y$05$abcdefghijklmnopqrstuv"}, wantErr: true},
+		{name: "duplicate", users: []string{"alice:a", "alice:b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AuthConfig{Users: tt.users}.Credentials()
+
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "auth.users")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthConfig_Enabled(t *testing.T) {
+	assert.False(t, AuthConfig{}.Enabled())
+	assert.False(t, AuthConfig{Roles: map[string][]string{"alice": {"admin"}}}.Enabled())
+	assert.True(t, AuthConfig{Users: []string{"alice:a"}}.Enabled())
+	assert.True(t, AuthConfig{Token: "t"}.Enabled())
+}
@@ -3,42 +3,243 @@ package config
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"file-manager/internal/domain"
 )
 
 type ServerConfig struct {
-	Port          int   `yaml:"port"`
-	MaxUploadSize int64 `yaml:"max_upload_size"`
+	Port                          int             `yaml:"port"`
+	MaxUploadSize                 int64           `yaml:"max_upload_size"`
+	MaxConcurrentUploadsPerClient int             `yaml:"max_concurrent_uploads_per_client"`
+	MaxConcurrentRequests         int             `yaml:"max_concurrent_requests"`
+	RequireDeleteConfirmation     bool            `yaml:"require_delete_confirmation"`
+	DeleteTokenSecret             string          `yaml:"delete_token_secret"`
+	DeleteTokenTTL                time.Duration   `yaml:"delete_token_ttl"`
+	TLS                           TLSConfig       `yaml:"tls"`
+	RateLimit                     RateLimitConfig `yaml:"rate_limit"`
+	// CSRFProtection формы загрузки, создания, удаления и переименования требуют токен со страницы.
+	CSRFProtection bool `yaml:"csrf_protection"`
+	// AdminToken bearer-токен для админских эндпоинтов (/logs), пустой — они выключены.
+	AdminToken string `yaml:"admin_token"`
+	// MaxUploadBPS скорость одной загрузки в байтах в секунду, 0 — без ограничения.
+	MaxUploadBPS int64 `yaml:"max_upload_bps"`
+	// UploadTimeout срок на чтение тела загрузки целиком, 0 — без ограничения.
+	UploadTimeout time.Duration `yaml:"upload_timeout"`
+	// MaxDownloadBPS скорость одной отдачи файла или zip в байтах в секунду, 0 — без ограничения.
+	MaxDownloadBPS int64 `yaml:"max_download_bps"`
+	// VerifyTimeout сколько может идти одна сверка с манифестом, 0 — без ограничения.
+	VerifyTimeout time.Duration `yaml:"verify_timeout"`
+	// DuplicatesTimeout сколько может идти один поиск дубликатов, 0 — без ограничения.
+	DuplicatesTimeout time.Duration `yaml:"duplicates_timeout"`
+	// FetchTimeout сколько может идти одно скачивание /fetch целиком, 0 — без ограничения.
+	FetchTimeout time.Duration `yaml:"fetch_timeout"`
+	// FetchAllowedHosts с каких хостов /fetch может качать. пусто — с любого, но только по публичным адресам.
+	FetchAllowedHosts []string `yaml:"fetch_allowed_hosts"`
+	// ShareSecret ключ подписи ссылок /shared, пустой — случайный до перезапуска. ShareMaxTTL 0 — неделя.
+	ShareSecret string        `yaml:"share_secret"`
+	ShareMaxTTL time.Duration `yaml:"share_max_ttl"`
+	// RedirectFolderDownload /download по пути папки отдаёт 302 на zip папки, иначе 400.
+	RedirectFolderDownload bool `yaml:"redirect_folder_download"`
+	// SlowOpThreshold запросы дольше пишутся в лог warn с длительностью и путём, 0 — не логировать.
+	SlowOpThreshold time.Duration `yaml:"slow_op_threshold"`
+	// APIVersion версия API, которую объявляет сервер в /version, меняется руками при несовместимых изменениях.
+	APIVersion string `yaml:"api_version"`
 }
 
 type StorageConfig struct {
-	BasePath string `yaml:"base_path"`
+	Type     string      `yaml:"type"`
+	BasePath string      `yaml:"base_path"`
+	Retry    RetryConfig `yaml:"retry"`
+	// HealthCheckInterval как часто проверять, что base_path на месте и доступен на запись, 0 — не проверять.
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+	// OperationTimeout сколько ждать один вызов хранилища (чтение папки, перенос, удаление), 0 — без ограничения.
+	OperationTimeout time.Duration `yaml:"operation_timeout"`
+	// Mounts подключает другие каталоги первым сегментом пути: photos: /mnt/photos даёт /photos в дереве.
+	Mounts map[string]string `yaml:"mounts"`
+	// Encryption шифрование содержимого файлов на диске, по умолчанию выключено.
+	Encryption EncryptionConfig `yaml:"encryption"`
+}
+
+// EncryptionConfig AES-256-GCM для содержимого файлов, имена и папки остаются открытыми.
+// Key 32 байта в hex; переменная окружения FILE_MANAGER_ENCRYPTION_KEY его перекрывает, чтобы ключ не лежал в yaml.
+type EncryptionConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Key     string `yaml:"key"`
+}
+
+// RateLimitConfig token bucket на IP клиента: RequestsPerSecond пополнение, Burst ёмкость.
+// RequestsPerSecond 0 — без ограничения, Burst 0 — округлённый вверх RequestsPerSecond.
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+// RetryConfig повтор идемпотентных чтений при временных ошибках сетевого хранилища.
+type RetryConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+}
+
+// ACLConfig доступ к папкам по пользователям: префикс пути -> имена и роли (`role:admin`).
+// действует самое длинное совпавшее правило, пути вне правил — по Default (allow или deny).
+type ACLConfig struct {
+	Default string              `yaml:"default"`
+	Rules   map[string][]string `yaml:"rules"`
+}
+
+// LogConfig куда писать лог. File пустой — только stderr.
+type LogConfig struct {
+	File string `yaml:"file"`
 }
 
 type StaticConfig struct {
 	Path         string `yaml:"path"`
 	TemplateFile string `yaml:"template_file"`
+	// TemplateReload перечитывать шаблон на каждый запрос (для разработки), по умолчанию он парсится один раз.
+	TemplateReload bool `yaml:"template_reload"`
 }
 
 type FileConfig struct {
 	MaxNameLength       int         `yaml:"max_name_length"`
 	DirPermissions      os.FileMode `yaml:"dir_permissions"`
+	FilePermissions     os.FileMode `yaml:"file_permissions"`
+	ExactPermissions    bool        `yaml:"exact_permissions"`
+	AllowSymlinkMove    bool        `yaml:"allow_symlink_move"`
 	ForbiddenExtensions []string    `yaml:"forbidden_extensions"`
 	ValidNameRegex      string      `yaml:"valid_name_regex"`
+	NormalizeUnicode    bool        `yaml:"normalize_unicode"`
+	// NormalizeBackslashes `a\b\c.txt` от windows-клиентов раскладывать по папкам, а не хранить с `\` в имени.
+	NormalizeBackslashes bool           `yaml:"normalize_backslashes"`
+	DropBoxDirs          []string       `yaml:"dropbox_dirs"`
+	MarkForbidden        bool           `yaml:"mark_forbidden"`
+	ZipCache             ZipCacheConfig `yaml:"zip_cache"`
+	ZipIncludeEmptyDirs  bool           `yaml:"zip_include_empty_dirs"`
+	// FMIgnore учитывать `.fmignore` в папках при листинге и сборке zip. каждый листинг читает файлы
+	// всех родительских папок, поэтому по умолчанию выключено.
+	FMIgnore         bool          `yaml:"fmignore"`
+	ZipName          ZipNameConfig `yaml:"zip_name"`
+	ContentTypeCheck string        `yaml:"content_type_check"`
+	// CaseInsensitive auto, on или off (по умолчанию): сравнивать ли имена без учёта регистра при проверке конфликтов.
+	// auto пишет при старте пробный файл в корень хранилища, поэтому только по явному выбору.
+	CaseInsensitive string `yaml:"case_insensitive"`
+	// MaxZipSourceBytes 0 — без ограничения размера папки для zip.
+	MaxZipSourceBytes int64 `yaml:"max_zip_source_bytes"`
+	MaxZipScanFiles   int   `yaml:"max_zip_scan_files"`
+	// MaxZipEntries 0 — без ограничения числа записей в zip.
+	MaxZipEntries int `yaml:"max_zip_entries"`
+	// MaxWalkDepth и MaxWalkEntries потолок обхода дерева для tree, stats и duplicates:
+	// глубже и дальше обход не идёт, ответ помечается как усечённый.
+	MaxWalkDepth   int `yaml:"max_walk_depth"`
+	MaxWalkEntries int `yaml:"max_walk_entries"`
+	// BlockHiddenDownload не отдавать скрытые файлы и папки напрямую, как их не отдаёт zip.
+	BlockHiddenDownload bool `yaml:"block_hidden_download"`
+	// AutoExtractDir папка (от корня хранилища), zip в которой распаковываются фоном, пустая — выключено.
+	AutoExtractDir      string        `yaml:"auto_extract_dir"`
+	AutoExtractInterval time.Duration `yaml:"auto_extract_interval"`
+	// AutoExtractDelete удалять архив после удачной распаковки.
+	AutoExtractDelete bool `yaml:"auto_extract_delete"`
+	// AutoExtractMaxBytes потолок распакованного размера одного архива, и для /extract тоже.
+	AutoExtractMaxBytes int64 `yaml:"auto_extract_max_bytes"`
+	// GzipStatic отдавать готовый `file.txt.gz` с Content-Encoding: gzip, если клиент принимает gzip.
+	GzipStatic bool `yaml:"gzip_static"`
+	// RequireExtension отклонять загрузку файлов без расширения (`README`), списки расширений тут ни при чём.
+	RequireExtension bool `yaml:"require_extension"`
+	// StripImageMetadata перекодировать загруженные jpeg/png без EXIF/GPS и текстовых чанков.
+	StripImageMetadata bool `yaml:"strip_image_metadata"`
+	// BundleMaxBytes и BundleMaxFiles лимиты /bundle, папка целиком читается в память.
+	BundleMaxBytes int64 `yaml:"bundle_max_bytes"`
+	BundleMaxFiles int   `yaml:"bundle_max_files"`
+	// TarPreserveOwnership писать в tar uid/gid и полный режим из stat (для бэкапов), вне unix ничего не меняет.
+	TarPreserveOwnership bool `yaml:"tar_preserve_ownership"`
+	// TrashEnabled удаление переносит в корзину TrashDir (от корня хранилища), а не стирает.
+	TrashEnabled bool   `yaml:"trash_enabled"`
+	TrashDir     string `yaml:"trash_dir"`
+	// TrashPurgeInterval как часто фоном стирать из корзины записи старше TrashRetention, 0 — не стирать.
+	TrashPurgeInterval time.Duration `yaml:"trash_purge_interval"`
+	TrashRetention     time.Duration `yaml:"trash_retention"`
+	// ChunkTTL через сколько без новых чанков загрузка считается брошенной и стирается,
+	// ChunkExpiryInterval как часто это проверять.
+	ChunkTTL            time.Duration `yaml:"chunk_ttl"`
+	ChunkExpiryInterval time.Duration `yaml:"chunk_expiry_interval"`
+	// WriteUploadMetadata писать рядом с каждой загрузкой сайдкар `<file>.meta.json`, листинг его не показывает.
+	WriteUploadMetadata bool `yaml:"write_upload_metadata"`
+	// PreviewMaxLines потолок строк /preview, запрос больше урезается до него.
+	PreviewMaxLines int `yaml:"preview_max_lines"`
+	// OverwritePolicy overwrite, reject или rename: загрузка поверх существующего файла без параметра conflict.
+	OverwritePolicy string `yaml:"overwrite_policy"`
+	// DisableVersioning запрещает conflict=version (старый файл под `.vN`), /capabilities это показывает.
+	DisableVersioning bool `yaml:"disable_versioning"`
+}
+
+// ZipCacheConfig кеш собранных zip-архивов папок для докачки через Range.
+// ZipNameConfig как назвать zip папки: `alpha.zip` по умолчанию, FullPath даёт `projects_alpha.zip`,
+// Timestamp — layout time.Format, добавляется через дефис (`alpha-20240101.zip`).
+type ZipNameConfig struct {
+	FullPath  bool   `yaml:"full_path"`
+	Prefix    string `yaml:"prefix"`
+	Suffix    string `yaml:"suffix"`
+	Timestamp string `yaml:"timestamp"`
+}
+
+type ZipCacheConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Dir      string `yaml:"dir"`
+	MaxBytes int64  `yaml:"max_bytes"`
 }
 
 type RoutesConfig struct {
-	Browse         string `yaml:"browse"`
-	BrowseAlt      string `yaml:"browse_alt"`
-	Upload         string `yaml:"upload"`
-	CreateFolder   string `yaml:"create_folder"`
-	Delete         string `yaml:"delete"`
-	Rename         string `yaml:"rename"`
-	Download       string `yaml:"download"`
-	DownloadFolder string `yaml:"download_folder"`
+	Browse            string `yaml:"browse"`
+	BrowseAlt         string `yaml:"browse_alt"`
+	Upload            string `yaml:"upload"`
+	CreateFolder      string `yaml:"create_folder"`
+	Delete            string `yaml:"delete"`
+	Rename            string `yaml:"rename"`
+	Download          string `yaml:"download"`
+	DownloadFolder    string `yaml:"download_folder"`
+	DownloadSelection string `yaml:"download_selection"`
+	Archive           string `yaml:"archive"`
+	Capabilities      string `yaml:"capabilities"`
+	ConfirmDelete     string `yaml:"confirm_delete"`
+	Compare           string `yaml:"compare"`
+	Recent            string `yaml:"recent"`
+	Ready             string `yaml:"ready"`
+	Prune             string `yaml:"prune"`
+	ZipManifest       string `yaml:"zip_manifest"`
+	RenameAPI         string `yaml:"rename_api"`
+	ListAPI           string `yaml:"list_api"`
+	UploadChunk       string `yaml:"upload_chunk"`
+	UploadFinalize    string `yaml:"upload_finalize"`
+	StatBatch         string `yaml:"stat_batch"`
+	CreateTree        string `yaml:"create_tree"`
+	Logs              string `yaml:"logs"`
+	Bundle            string `yaml:"bundle"`
+	Ancestors         string `yaml:"ancestors"`
+	Verify            string `yaml:"verify"`
+	Duplicates        string `yaml:"duplicates"`
+	Stats             string `yaml:"stats"`
+	Tree              string `yaml:"tree"`
+	Fetch             string `yaml:"fetch"`
+	Swap              string `yaml:"swap"`
+	Feed              string `yaml:"feed"`
+	Sign              string `yaml:"sign"`
+	Shared            string `yaml:"shared"`
+	AdminRebuild      string `yaml:"admin_rebuild"`
+	Version           string `yaml:"version"`
+	Restore           string `yaml:"restore"`
+	EmptyTrash        string `yaml:"empty_trash"`
+	TrashPurge        string `yaml:"trash_purge"`
+	Preview           string `yaml:"preview"`
+	Extract           string `yaml:"extract"`
+	Info              string `yaml:"info"`
+	Hash              string `yaml:"hash"`
 }
 
 type Messages struct {
@@ -49,6 +250,15 @@ type Messages struct {
 	CannotServe         string `yaml:"cannot_serve"`
 	CannotDelete        string `yaml:"cannot_delete"`
 	InternalError       string `yaml:"internal_error"`
+	AlreadyExists       string `yaml:"already_exists"`
+	TooManyRequests     string `yaml:"too_many_requests"`
+	StorageUnavailable  string `yaml:"storage_unavailable"`
+	PreconditionFailed  string `yaml:"precondition_failed"`
+	ZipTooLarge         string `yaml:"zip_too_large"`
+	Unauthorized        string `yaml:"unauthorized"`
+	StorageFull         string `yaml:"storage_full"`
+	BundleTooLarge      string `yaml:"bundle_too_large"`
+	ChecksumMismatch    string `yaml:"checksum_mismatch"`
 }
 
 type Config struct {
@@ -58,6 +268,9 @@ type Config struct {
 	File     FileConfig    `yaml:"file"`
 	Routes   RoutesConfig  `yaml:"routes"`
 	Messages Messages      `yaml:"messages"`
+	Log      LogConfig     `yaml:"log"`
+	ACL      ACLConfig     `yaml:"acl"`
+	Auth     AuthConfig    `yaml:"auth"`
 }
 
 func LoadConfig(filename string) *Config {
@@ -85,6 +298,14 @@ func LoadConfigWithError(filename string) (*Config, error) {
 		"static path":       &cfg.Static.Path,
 	}
 
+	// директория кеша необязательна, пустая значит системный temp.
+	if cfg.File.ZipCache.Dir != "" {
+		paths["zip cache dir"] = &cfg.File.ZipCache.Dir
+	}
+	if cfg.Log.File != "" {
+		paths["log file"] = &cfg.Log.File
+	}
+
 	for name, path := range paths {
 		absPath, absErr := filepath.Abs(*path)
 		if absErr != nil {
@@ -92,6 +313,15 @@ func LoadConfigWithError(filename string) (*Config, error) {
 		}
 		*path = absPath
 	}
+	for name, path := range cfg.Storage.Mounts {
+		absPath, absErr := filepath.Abs(path)
+		if absErr != nil {
+			return nil, fmt.Errorf("failed to resolve mount %s: %w", name, absErr)
+		}
+		cfg.Storage.Mounts[name] = absPath
+	}
+
+	applyDefaults(&cfg)
 
 	// валидация конфига
 	if validationErr := validateConfig(&cfg); validationErr != nil {
@@ -101,6 +331,81 @@ func LoadConfigWithError(filename string) (*Config, error) {
 	return &cfg, nil
 }
 
+const (
+	// DefaultMaxZipScanFiles сколько файлов максимум просматривать при оценке размера папки перед zip.
+	DefaultMaxZipScanFiles = 10000
+	DefaultBundleMaxBytes  = 1 << 20
+	DefaultBundleMaxFiles  = 100
+	// DefaultAutoExtractMaxBytes распакованный размер одного архива из file.auto_extract_dir.
+	DefaultAutoExtractMaxBytes = 1 << 30
+	DefaultAutoExtractInterval = 30 * time.Second
+	DefaultMaxWalkDepth        = 64
+	DefaultMaxWalkEntries      = 1_000_000
+	DefaultTrashDir            = ".trash"
+	DefaultTrashRetention      = 30 * 24 * time.Hour
+	DefaultChunkTTL            = 24 * time.Hour
+	DefaultChunkExpiryInterval = time.Hour
+	DefaultPreviewMaxLines     = 200
+)
+
+// applyDefaults заполняет необязательные поля, которых нет в старых config.yaml.
+func applyDefaults(cfg *Config) {
+	if cfg.Server.RateLimit.RequestsPerSecond > 0 && cfg.Server.RateLimit.Burst == 0 {
+		cfg.Server.RateLimit.Burst = int(math.Ceil(cfg.Server.RateLimit.RequestsPerSecond))
+	}
+	if cfg.Storage.Type == "" {
+		cfg.Storage.Type = domain.StorageTypeLocal
+	}
+	if cfg.File.MaxZipScanFiles == 0 {
+		cfg.File.MaxZipScanFiles = DefaultMaxZipScanFiles
+	}
+	if cfg.File.BundleMaxBytes == 0 {
+		cfg.File.BundleMaxBytes = DefaultBundleMaxBytes
+	}
+	if cfg.File.BundleMaxFiles == 0 {
+		cfg.File.BundleMaxFiles = DefaultBundleMaxFiles
+	}
+	if cfg.File.ContentTypeCheck == "" {
+		cfg.File.ContentTypeCheck = domain.ContentCheckOff
+	}
+	if cfg.File.CaseInsensitive == "" {
+		cfg.File.CaseInsensitive = domain.CaseInsensitiveOff
+	}
+	if cfg.File.AutoExtractInterval == 0 {
+		cfg.File.AutoExtractInterval = DefaultAutoExtractInterval
+	}
+	if cfg.File.AutoExtractMaxBytes == 0 {
+		cfg.File.AutoExtractMaxBytes = DefaultAutoExtractMaxBytes
+	}
+	if cfg.File.MaxWalkDepth == 0 {
+		cfg.File.MaxWalkDepth = DefaultMaxWalkDepth
+	}
+	if cfg.File.MaxWalkEntries == 0 {
+		cfg.File.MaxWalkEntries = DefaultMaxWalkEntries
+	}
+	if cfg.ACL.Default == "" {
+		cfg.ACL.Default = domain.ACLAllow
+	}
+	if cfg.File.TrashDir == "" {
+		cfg.File.TrashDir = DefaultTrashDir
+	}
+	if cfg.File.TrashRetention == 0 {
+		cfg.File.TrashRetention = DefaultTrashRetention
+	}
+	if cfg.File.ChunkTTL == 0 {
+		cfg.File.ChunkTTL = DefaultChunkTTL
+	}
+	if cfg.File.ChunkExpiryInterval == 0 {
+		cfg.File.ChunkExpiryInterval = DefaultChunkExpiryInterval
+	}
+	if cfg.File.PreviewMaxLines == 0 {
+		cfg.File.PreviewMaxLines = DefaultPreviewMaxLines
+	}
+	if cfg.File.OverwritePolicy == "" {
+		cfg.File.OverwritePolicy = domain.OverwritePolicyOverwrite
+	}
+}
+
 type validationError struct {
 	field string
 	msg   string
@@ -114,13 +419,178 @@ func validateConfig(cfg *Config) error {
 	type validator func() error
 
 	validators := []validator{
-		func() error { return validateRequiredString("storage.base_path", cfg.Storage.BasePath) },
+		func() error {
+			// хранилищу в памяти каталог не нужен.
+			if cfg.Storage.Type == domain.StorageTypeMemory {
+				return nil
+			}
+			return validateRequiredString("storage.base_path", cfg.Storage.BasePath)
+		},
 		func() error { return validateRequiredString("static.path", cfg.Static.Path) },
 		func() error { return validateRequiredString("static.template_file", cfg.Static.TemplateFile) },
 		func() error { return validateRequiredString("file.valid_name_regex", cfg.File.ValidNameRegex) },
 		func() error { return validatePort(cfg.Server.Port) },
 		func() error { return validatePositiveInt64("server.max_upload_size", cfg.Server.MaxUploadSize) },
 		func() error { return validatePositiveInt("file.max_name_length", cfg.File.MaxNameLength) },
+		func() error {
+			return validateNonNegativeInt(
+				"server.max_concurrent_uploads_per_client", cfg.Server.MaxConcurrentUploadsPerClient)
+		},
+		func() error {
+			return validateNonNegativeInt("server.max_concurrent_requests", cfg.Server.MaxConcurrentRequests)
+		},
+		func() error { return validateRateLimit(cfg.Server.RateLimit) },
+		func() error { return validateRetry(cfg.Storage.Retry) },
+		func() error { return validateMounts(cfg.Storage.Mounts) },
+		func() error { return validateTLS(cfg.Server.TLS) },
+		func() error { return validateAuth(cfg.Auth) },
+		func() error {
+			switch cfg.File.ContentTypeCheck {
+			case domain.ContentCheckOff, domain.ContentCheckLenient, domain.ContentCheckStrict:
+				return nil
+			default:
+				return validationError{
+					field: "file.content_type_check",
+					msg:   fmt.Sprintf("must be one of off, lenient, strict, got %q", cfg.File.ContentTypeCheck),
+				}
+			}
+		},
+		func() error {
+			switch cfg.File.CaseInsensitive {
+			case domain.CaseInsensitiveAuto, domain.CaseInsensitiveOn, domain.CaseInsensitiveOff:
+				return nil
+			default:
+				return validationError{
+					field: "file.case_insensitive",
+					msg:   fmt.Sprintf("must be one of auto, on, off, got %q", cfg.File.CaseInsensitive),
+				}
+			}
+		},
+		func() error {
+			switch cfg.File.OverwritePolicy {
+			case domain.OverwritePolicyOverwrite, domain.OverwritePolicyReject, domain.OverwritePolicyRename:
+				return nil
+			default:
+				return validationError{
+					field: "file.overwrite_policy",
+					msg:   fmt.Sprintf("must be one of overwrite, reject, rename, got %q", cfg.File.OverwritePolicy),
+				}
+			}
+		},
+		func() error {
+			if cfg.File.AutoExtractInterval < 0 {
+				return validationError{field: "file.auto_extract_interval", msg: "must not be negative"}
+			}
+			if cfg.File.AutoExtractMaxBytes < 0 {
+				return validationError{field: "file.auto_extract_max_bytes", msg: "must not be negative"}
+			}
+			return nil
+		},
+		func() error {
+			if cfg.ACL.Default != domain.ACLAllow && cfg.ACL.Default != domain.ACLDeny {
+				return validationError{
+					field: "acl.default",
+					msg:   fmt.Sprintf("must be one of allow, deny, got %q", cfg.ACL.Default),
+				}
+			}
+			return nil
+		},
+		func() error {
+			if cfg.File.MaxZipSourceBytes < 0 {
+				return validationError{field: "file.max_zip_source_bytes", msg: "must not be negative"}
+			}
+			if err := validateNonNegativeInt("file.max_zip_entries", cfg.File.MaxZipEntries); err != nil {
+				return err
+			}
+			return validatePositiveInt("file.max_zip_scan_files", cfg.File.MaxZipScanFiles)
+		},
+		func() error {
+			if err := validatePositiveInt("file.max_walk_depth", cfg.File.MaxWalkDepth); err != nil {
+				return err
+			}
+			return validatePositiveInt("file.max_walk_entries", cfg.File.MaxWalkEntries)
+		},
+		func() error {
+			if err := validatePositiveInt64("file.bundle_max_bytes", cfg.File.BundleMaxBytes); err != nil {
+				return err
+			}
+			return validatePositiveInt("file.bundle_max_files", cfg.File.BundleMaxFiles)
+		},
+		func() error { return validatePositiveInt("file.preview_max_lines", cfg.File.PreviewMaxLines) },
+		func() error {
+			if cfg.Server.MaxUploadBPS < 0 {
+				return validationError{field: "server.max_upload_bps", msg: "must not be negative"}
+			}
+			if cfg.Server.MaxDownloadBPS < 0 {
+				return validationError{field: "server.max_download_bps", msg: "must not be negative"}
+			}
+			if cfg.Server.UploadTimeout < 0 {
+				return validationError{field: "server.upload_timeout", msg: "must not be negative"}
+			}
+			if cfg.Server.VerifyTimeout < 0 {
+				return validationError{field: "server.verify_timeout", msg: "must not be negative"}
+			}
+			if cfg.Server.DuplicatesTimeout < 0 {
+				return validationError{field: "server.duplicates_timeout", msg: "must not be negative"}
+			}
+			if cfg.Server.FetchTimeout < 0 {
+				return validationError{field: "server.fetch_timeout", msg: "must not be negative"}
+			}
+			if cfg.Server.ShareMaxTTL < 0 {
+				return validationError{field: "server.share_max_ttl", msg: "must not be negative"}
+			}
+			if cfg.Server.SlowOpThreshold < 0 {
+				return validationError{field: "server.slow_op_threshold", msg: "must not be negative"}
+			}
+			return nil
+		},
+		func() error {
+			if cfg.Storage.HealthCheckInterval < 0 {
+				return validationError{field: "storage.health_check_interval", msg: "must not be negative"}
+			}
+			if cfg.Storage.OperationTimeout < 0 {
+				return validationError{field: "storage.operation_timeout", msg: "must not be negative"}
+			}
+			return nil
+		},
+		func() error {
+			if !cfg.File.ZipCache.Enabled {
+				return nil
+			}
+			return validatePositiveInt64("file.zip_cache.max_bytes", cfg.File.ZipCache.MaxBytes)
+		},
+		func() error {
+			// корзина внутри хранилища и не сам корень, иначе удаление переносило бы папку в саму себя.
+			dir := filepath.Clean(cfg.File.TrashDir)
+			if filepath.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+				return validationError{
+					field: "file.trash_dir",
+					msg:   fmt.Sprintf("must be a folder inside storage, got %q", cfg.File.TrashDir),
+				}
+			}
+			return nil
+		},
+		func() error {
+			if cfg.File.TrashPurgeInterval < 0 {
+				return validationError{field: "file.trash_purge_interval", msg: "must not be negative"}
+			}
+			if cfg.File.TrashRetention < 0 {
+				return validationError{field: "file.trash_retention", msg: "must not be negative"}
+			}
+			if cfg.File.TrashPurgeInterval > 0 && !cfg.File.TrashEnabled {
+				return validationError{field: "file.trash_purge_interval", msg: "requires file.trash_enabled"}
+			}
+			return nil
+		},
+		func() error {
+			if cfg.File.ChunkTTL < 0 {
+				return validationError{field: "file.chunk_ttl", msg: "must not be negative"}
+			}
+			if cfg.File.ChunkExpiryInterval < 0 {
+				return validationError{field: "file.chunk_expiry_interval", msg: "must not be negative"}
+			}
+			return nil
+		},
 	}
 
 	for _, v := range validators {
@@ -146,6 +616,13 @@ func validatePositiveInt(field string, value int) error {
 	return nil
 }
 
+func validateNonNegativeInt(field string, value int) error {
+	if value < 0 {
+		return validationError{field: field, msg: "must not be negative"}
+	}
+	return nil
+}
+
 func validatePositiveInt64(field string, value int64) error {
 	if value <= 0 {
 		return validationError{field: field, msg: "must be greater than 0"}
@@ -153,6 +630,41 @@ func validatePositiveInt64(field string, value int64) error {
 	return nil
 }
 
+func validateRateLimit(limit RateLimitConfig) error {
+	if limit.RequestsPerSecond < 0 || math.IsNaN(limit.RequestsPerSecond) || math.IsInf(limit.RequestsPerSecond, 0) {
+		return validationError{field: "server.rate_limit.requests_per_second", msg: "must be a non-negative number"}
+	}
+	return validateNonNegativeInt("server.rate_limit.burst", limit.Burst)
+}
+
+func validateRetry(retry RetryConfig) error {
+	if !retry.Enabled {
+		return nil
+	}
+	if err := validatePositiveInt("storage.retry.attempts", retry.Attempts); err != nil {
+		return err
+	}
+	if retry.Backoff < 0 {
+		return validationError{field: "storage.retry.backoff", msg: "must not be negative"}
+	}
+	return nil
+}
+
+// validateMounts имя точки монтирования — один обычный сегмент пути, иначе маршрутизация по первому сегменту сломается.
+func validateMounts(mounts map[string]string) error {
+	for name, path := range mounts {
+		field := "storage.mounts." + name
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) ||
+			strings.HasPrefix(name, domain.HiddenFilePrefix) {
+			return validationError{field: field, msg: "must be a single non-hidden path segment"}
+		}
+		if err := validateRequiredString(field, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validatePort(port int) error {
 	if port <= 0 || port > 65535 {
 		return validationError{
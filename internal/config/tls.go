@@ -0,0 +1,94 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+const defaultTLSMinVersion = "1.2"
+
+// TLSConfig настройки HTTPS. cipher_suites применяются только к TLS 1.0–1.2,
+// набор шифров TLS 1.3 в Go не настраивается.
+type TLSConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	CertFile     string   `yaml:"cert_file"`
+	KeyFile      string   `yaml:"key_file"`
+	MinVersion   string   `yaml:"min_version"`
+	CipherSuites []string `yaml:"cipher_suites"`
+}
+
+// Build собирает tls.Config для http.Server. пустой список шифров — дефолт Go.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	minVersion, err := parseTLSVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	ciphers, err := parseCipherSuites(c.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: ciphers,
+	}, nil
+}
+
+func parseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		version = defaultTLSMinVersion
+	}
+	versions := map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+	v, ok := versions[version]
+	if !ok {
+		return 0, validationError{
+			field: "server.tls.min_version",
+			msg:   fmt.Sprintf("must be one of 1.0, 1.1, 1.2, 1.3, got %q", version),
+		}
+	}
+	return v, nil
+}
+
+// parseCipherSuites принимает только имена из tls.CipherSuites(),
+// небезопасные наборы из tls.InsecureCipherSuites() отклоняются так же, как неизвестные.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, validationError{
+				field: "server.tls.cipher_suites",
+				msg:   fmt.Sprintf("unknown or insecure cipher suite %q", name),
+			}
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func validateTLS(c TLSConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if err := validateRequiredString("server.tls.cert_file", c.CertFile); err != nil {
+		return err
+	}
+	if err := validateRequiredString("server.tls.key_file", c.KeyFile); err != nil {
+		return err
+	}
+	_, err := c.Build()
+	return err
+}
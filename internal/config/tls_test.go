@@ -0,0 +1,52 @@
+package config
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfig_Build(t *testing.T) {
+	t.Run("defaults to TLS 1.2", func(t *testing.T) {
+		tlsCfg, err := TLSConfig{}.Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), tlsCfg.MinVersion)
+		assert.Nil(t, tlsCfg.CipherSuites)
+	})
+
+	t.Run("explicit version and ciphers", func(t *testing.T) {
+		tlsCfg, err := TLSConfig{
+			MinVersion:   "1.3",
+			CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		}.Build()
+
+		require.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS13), tlsCfg.MinVersion)
+		assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, tlsCfg.CipherSuites)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := TLSConfig{MinVersion: "1.4"}.Build()
+		assert.ErrorContains(t, err, "server.tls.min_version")
+	})
+
+	t.Run("insecure cipher rejected", func(t *testing.T) {
+		_, err := TLSConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}.Build()
+		assert.ErrorContains(t, err, "server.tls.cipher_suites")
+	})
+
+	t.Run("unknown cipher rejected", func(t *testing.T) {
+		_, err := TLSConfig{CipherSuites: []string{"TLS_MADE_UP"}}.Build()
+		assert.ErrorContains(t, err, "TLS_MADE_UP")
+	})
+}
+
+func TestValidateTLS(t *testing.T) {
+	assert.NoError(t, validateTLS(TLSConfig{MinVersion: "bogus"}), "disabled TLS is not validated")
+	assert.ErrorContains(t, validateTLS(TLSConfig{Enabled: true, KeyFile: "key.pem"}), "server.tls.cert_file")
+	assert.ErrorContains(t, validateTLS(TLSConfig{Enabled: true, CertFile: "cert.pem"}), "server.tls.key_file")
+	assert.NoError(t, validateTLS(TLSConfig{Enabled: true, CertFile: "cert.pem", KeyFile: "key.pem"}))
+}
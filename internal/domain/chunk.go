@@ -0,0 +1,44 @@
+package domain
+
+import "fmt"
+
+// ByteRange полуинтервал байт [Start, End).
+type ByteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// ChunkOptions чанк загрузки. Owner — пользователь запроса (пустой — анонимный): id видны только
+// в пределах владельца, чужой id с тем же именем — другая загрузка.
+type ChunkOptions struct {
+	Owner string
+}
+
+// FinalizeOptions что клиент ожидает получить после склейки чанков.
+type FinalizeOptions struct {
+	ExpectedSize int64
+	// ExpectedChunks 0 — число чанков не проверяется, только покрытие байт.
+	ExpectedChunks int
+	// Owner чей id склеиваем, как в ChunkOptions.
+	Owner  string
+	Upload UploadOptions
+}
+
+// IncompleteUploadError не все чанки дошли: склейка не делается, чтобы не получить обрезанный файл.
+// errors.Is(err, ErrInvalidParameter) == true, так что хендлер по умолчанию отдаёт 400.
+type IncompleteUploadError struct {
+	Missing        []ByteRange `json:"missing"`
+	Chunks         int         `json:"chunks"`
+	ExpectedChunks int         `json:"expected_chunks,omitempty"`
+	Size           int64       `json:"size"`
+	ExpectedSize   int64       `json:"expected_size"`
+}
+
+func (e *IncompleteUploadError) Error() string {
+	return fmt.Sprintf("upload incomplete: got %d bytes in %d chunks, expected %d bytes, missing ranges %v",
+		e.Size, e.Chunks, e.ExpectedSize, e.Missing)
+}
+
+func (e *IncompleteUploadError) Unwrap() error {
+	return ErrInvalidParameter
+}
@@ -7,6 +7,74 @@ const (
 	PathTraversalPrefix = ".."
 	HiddenFilePrefix    = "."
 	ExtensionZip        = ".zip"
+	ExtensionTarGz      = ".tar.gz"
 	MIMEOctetStream     = "application/octet-stream"
 	MIMEZip             = "application/zip"
+	MIMEGzip            = "application/gzip"
+	MIMEJSON            = "application/json"
+	MIMENDJSON          = "application/x-ndjson"
+	StorageTypeLocal    = "local"
+	StorageTypeMemory   = "memory"
+	// IgnoreFileName файл с шаблонами исключений в синтаксисе gitignore (file.fmignore).
+	IgnoreFileName = ".fmignore"
+)
+
+// подсистемы с кешами для /admin/rebuild.
+const (
+	// RebuildZip кеш собранных zip-архивов папок (file.zip_cache).
+	RebuildZip = "zip"
+	// RebuildTemplate разобранный шаблон страницы.
+	RebuildTemplate = "template"
+	// RebuildAll все кеши сразу.
+	RebuildAll = "all"
+)
+
+// имя zip папки (file.zip_name и параметр запроса zip_name, который перекрывает full_path).
+const (
+	QueryParamZipName = "zip_name"
+	// ZipNameFull `projects/alpha` -> `projects_alpha.zip`.
+	ZipNameFull = "full"
+	// ZipNameBase только последняя папка: `alpha.zip`.
+	ZipNameBase = "base"
+)
+
+// QueryParamInline `inline=true` на /download: открыть файл в браузере (видео, аудио с перемоткой), а не скачать.
+const QueryParamInline = "inline"
+
+// поведение ACL для путей, которые не попали ни под одно правило (acl.default).
+const (
+	ACLAllow = "allow"
+	ACLDeny  = "deny"
+	// ACLRolePrefix отличает роль от имени пользователя в списке правила: `role:admin`.
+	ACLRolePrefix = "role:"
+)
+
+// учёт регистра имён в хранилище (file.case_insensitive).
+const (
+	// CaseInsensitiveAuto определить по поведению ФС хранилища при старте.
+	CaseInsensitiveAuto = "auto"
+	// CaseInsensitiveOn считать `File.txt` и `file.txt` одним файлом (macOS, Windows).
+	CaseInsensitiveOn = "on"
+	// CaseInsensitiveOff имена различаются регистром, как на linux.
+	CaseInsensitiveOff = "off"
+)
+
+// что делать с загрузкой поверх существующего файла, если запрос не передал conflict (file.overwrite_policy).
+const (
+	// OverwritePolicyOverwrite перезаписать, как раньше.
+	OverwritePolicyOverwrite = "overwrite"
+	// OverwritePolicyReject отказать, как conflict=error.
+	OverwritePolicyReject = "reject"
+	// OverwritePolicyRename сохранить как `file (1).txt`, как conflict=rename.
+	OverwritePolicyRename = "rename"
+)
+
+// режимы сверки содержимого загружаемого файла с его расширением (file.content_type_check).
+const (
+	// ContentCheckOff не проверять.
+	ContentCheckOff = "off"
+	// ContentCheckLenient отклонять только "приложение под видом" картинки/текста/медиа.
+	ContentCheckLenient = "lenient"
+	// ContentCheckStrict отклонять любое расхождение категорий.
+	ContentCheckStrict = "strict"
 )
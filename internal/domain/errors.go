@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrPathTraversal        = errors.New("path traversal is not allowed")
@@ -9,4 +12,36 @@ var (
 	ErrFileNotFound         = errors.New("file or folder not found")
 	ErrPermissionDenied     = errors.New("permission denied")
 	ErrUnsupportedOperation = errors.New("unsupported operation")
+	ErrAlreadyExists        = errors.New("file or folder already exists")
+	ErrInvalidParameter     = errors.New("invalid parameter")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrStorageFull          = errors.New("no space left in storage")
+	ErrBundleTooLarge       = errors.New("folder too large for bundle")
+	// ErrChecksumMismatch загрузка пришла битой: хеш содержимого не совпал с Content-MD5 или X-Content-SHA256.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrStorageUnavailable базовый путь хранилища пропал или read-only (отмонтировали том).
+	// оборачивает ErrUnsupportedOperation, но хендлер проверяет его раньше и отдаёт 503.
+	ErrStorageUnavailable = fmt.Errorf("storage unavailable: %w", ErrUnsupportedOperation)
+	// ErrArchiveTooLarge папка слишком большая для zip целиком (file.max_zip_source_bytes).
+	ErrArchiveTooLarge = fmt.Errorf("archive too large: %w", ErrUnsupportedOperation)
+	// ErrIsDirectory скачивание файла по пути папки. это 400, но хендлер может вместо ошибки отправить на zip папки.
+	ErrIsDirectory = fmt.Errorf("path is a folder: %w", ErrInvalidParameter)
+	// ErrInvalidCSRFToken форма пришла без токена страницы или с чужим, отвечаем как на запрет доступа.
+	ErrInvalidCSRFToken = fmt.Errorf("invalid csrf token: %w", ErrPermissionDenied)
+	// ErrPathBusy путь занят другой операцией (запись корзины восстанавливают или стирают), это 409.
+	ErrPathBusy = fmt.Errorf("path is busy: %w", ErrAlreadyExists)
 )
+
+// ExistsError ErrAlreadyExists с описанием того, что уже лежит по пути: клиент показывает его
+// пользователю и решает, перезаписывать ли.
+type ExistsError struct {
+	Existing FileData
+}
+
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("'%s': %v", e.Existing.Path, ErrAlreadyExists)
+}
+
+func (e *ExistsError) Unwrap() error {
+	return ErrAlreadyExists
+}
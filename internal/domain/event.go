@@ -0,0 +1,25 @@
+package domain
+
+// Event событие для подписчиков, например чтобы обновить открытые листинги у других клиентов.
+type Event interface {
+	EventType() string
+}
+
+// EventPublisher получатель событий. Publish зовётся прямо из записи файла, поэтому не должен блокировать.
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+// EventUploadProgress тип события UploadProgress.
+const EventUploadProgress = "upload_progress"
+
+// UploadProgress сколько байт загрузки в Path уже принято. Done — последнее событие для этой загрузки.
+type UploadProgress struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Done  bool   `json:"done"`
+}
+
+func (UploadProgress) EventType() string {
+	return EventUploadProgress
+}
@@ -1,21 +1,145 @@
 package domain
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"os"
+	"time"
 )
 
 // FileData информация о файле или директории.
 type FileData struct {
-	Name  string
-	IsDir bool
+	Name  string `json:"name"`
+	IsDir bool   `json:"is_dir"`
+	// Forbidden файл виден в листинге, но скачать его нельзя (file.forbidden_extensions).
+	Forbidden bool `json:"forbidden,omitempty"`
+	// Path путь относительно корня хранилища, заполняется там, где Name недостаточно (RecentFiles).
+	Path    string    `json:"path,omitempty"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	// HasThumbnail для файла можно получить превью, клиенту не нужно угадывать по расширению.
+	HasThumbnail bool `json:"has_thumbnail,omitempty"`
+	// Checksum hex sha256 содержимого, заполняется только StatMany и только для файлов.
+	Checksum string `json:"checksum,omitempty"`
+	// UploadMetadata сайдкар загрузки, только в /info с meta=1.
+	UploadMetadata *UploadMetadata `json:"upload_metadata,omitempty"`
+	// Error маркер ошибки для конкретного пути в пакетных ответах (StatError*), остальные поля тогда пустые.
+	Error string `json:"error,omitempty"`
+}
+
+// маркеры ошибок в FileData.Error.
+const (
+	StatErrorNotFound    = "not_found"
+	StatErrorInvalidPath = "invalid_path"
+	StatErrorForbidden   = "forbidden"
+	StatErrorInternal    = "internal"
+)
+
+// ConflictPolicy что делать, если по целевому пути уже лежит файл.
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite перезаписать существующий файл (поведение по умолчанию).
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename сохранить новый файл под свободным именем, например `file (1).txt`.
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictError отказать с ErrAlreadyExists.
+	ConflictError ConflictPolicy = "error"
+	// ConflictVersion переименовать старый файл в `file.v1.txt`, `file.v2.txt` и записать новый на его место.
+	ConflictVersion ConflictPolicy = "version"
+)
+
+// UploadOptions дополнительные параметры загрузки.
+type UploadOptions struct {
+	Conflict ConflictPolicy
+	// ContentMD5 ожидаемый MD5 содержимого (из заголовка Content-MD5), пусто — не проверять.
+	ContentMD5 []byte
+	// ContentSHA256 ожидаемый sha256 содержимого (X-Content-SHA256), пусто — не проверять.
+	ContentSHA256 []byte
+	// Uploader кто загружает, для хуков после загрузки (сайдкар метаданных).
+	Uploader string
+}
+
+// DeleteOptions предусловия удаления: удалить, только если файл не менялся с тех пор, как его видел клиент.
+// nil — не проверять.
+type DeleteOptions struct {
+	IfSize *int64
+	// IfModTime сравнивается с точностью до секунды, как Last-Modified в HTTP.
+	IfModTime *time.Time
+}
+
+// ListOptions параметры чтения директории.
+type ListOptions struct {
+	// Filter glob-шаблон в синтаксисе filepath.Match, применяется к имени записи.
+	Filter string
+	// FilterDirs применять Filter и MaxAge и к директориям,
+	// по умолчанию они показываются всегда, чтобы работала навигация.
+	FilterDirs bool
+	// MaxAge скрыть записи, изменённые раньше, чем MaxAge назад (обработанное во входящих), 0 — не скрывать.
+	// по прямому пути такие файлы по-прежнему доступны.
+	MaxAge time.Duration
+	// Sort поле сортировки, пусто — порядок, в котором отдало хранилище. Desc — по убыванию.
+	Sort SortField
+	Desc bool
+	// DirsFirst папки перед файлами при любом поле и направлении.
+	DirsFirst bool
+}
+
+// SortField поле сортировки листинга.
+type SortField string
+
+const (
+	SortByName    SortField = "name"
+	SortBySize    SortField = "size"
+	SortByModTime SortField = "modtime"
+)
+
+// Valid пустое поле тоже допустимо: без сортировки.
+func (f SortField) Valid() bool {
+	switch f {
+	case "", SortByName, SortBySize, SortByModTime:
+		return true
+	}
+	return false
+}
+
+// Capabilities несекретная сводка возможностей сервера для фронтенда.
+type Capabilities struct {
+	StorageType         string           `json:"storage_type"`
+	MaxUploadSize       int64            `json:"max_upload_size"`
+	MaxNameLength       int              `json:"max_name_length"`
+	ForbiddenExtensions []string         `json:"forbidden_extensions"`
+	ConflictPolicies    []ConflictPolicy `json:"conflict_policies"`
+	Versioning          bool             `json:"versioning"`
+	Trash               bool             `json:"trash"`
+	ZipDownload         bool             `json:"zip_download"`
+	// MaxZipSourceBytes потолок размера папки для zip, 0 — без ограничения.
+	MaxZipSourceBytes int64 `json:"max_zip_source_bytes"`
+}
+
+// ZipEntry запись, которая попадёт в zip папки. у директорий имя заканчивается на `/`, размер 0.
+type ZipEntry struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// BundleFile файл в ответе /bundle. Data в JSON кодируется как base64.
+type BundleFile struct {
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
 }
 
 // FileStorage для операций работы с файловым хранилищем.
 type FileStorage interface {
 	ReadDirectory(relPath string) ([]os.FileInfo, error)
+	// Open чтение файла. с Seek, чтобы отдача через http.ServeContent держала Range.
+	Open(relPath string) (io.ReadSeekCloser, error)
+	// Stat информация о файле или папке. симлинки разворачиваются, как у os.Stat.
+	Stat(relPath string) (os.FileInfo, error)
 	WriteFile(relPath string, file io.Reader) error
+	// OpenWriter запись "толканием": файл публикуется атомарно на Close.
+	OpenWriter(relPath string) (io.WriteCloser, error)
 	Remove(relPath string) error
 	Move(oldRel, newRel string) error
 	CreateDirectory(relPath string) error
@@ -24,11 +148,71 @@ type FileStorage interface {
 
 // FileManagement для сценариев управления файлами.
 type FileManagement interface {
-	List(path string) ([]FileData, error)
-	UploadFile(path string, file io.Reader) error
+	List(path string, opts ListOptions) ([]FileData, error)
+	// ListPage страница листинга: limit записей с offset и общее число записей, limit 0 — до конца.
+	// offset за концом даёт пустую страницу, а не ошибку.
+	ListPage(path string, opts ListOptions, offset, limit int) (page []FileData, total int, err error)
+	UploadFile(path string, file io.Reader, opts UploadOptions) (string, error)
 	CreateFolder(path string) error
-	Delete(path string) error
-	Rename(oldPath, newPath string) error
+	Delete(path string, opts DeleteOptions) error
+	// Restore возвращает из корзины запись по пути внутри неё, EmptyTrash стирает корзину.
+	Restore(path string) error
+	EmptyTrash() error
+	Rename(oldPath, newPath string, policy ConflictPolicy) (string, error)
+	// Merge сливает папку src в папку dst, policy применяется к каждому совпавшему файлу.
+	Merge(srcPath, dstPath string, policy ConflictPolicy) error
 	ServeFile(w http.ResponseWriter, r *http.Request, path string) error
-	ServeFolderAsZip(w http.ResponseWriter, path string) error
+	ServeFolderAsZip(w http.ResponseWriter, r *http.Request, path string) error
+	// ServeFolderAsTarGz то же содержимое, что у zip, но tar.gz с правами файлов.
+	ServeFolderAsTarGz(w http.ResponseWriter, path string) error
+	ServeSelectionAsZip(w http.ResponseWriter, paths []string, flatten bool) error
+	ServeSelectionArchive(w http.ResponseWriter, paths []string, flatten bool) error
+	Capabilities() Capabilities
+	Compare(pathA, pathB string) (bool, error)
+	RecentFiles(path string, limit int) ([]FileData, error)
+	PruneEmptyDirs(path string) (int, error)
+	OpenWriter(path string) (io.WriteCloser, error)
+	ZipManifest(path string) ([]ZipEntry, error)
+	UploadChunk(id string, offset int64, chunk io.Reader, opts ChunkOptions) error
+	FinalizeUpload(id, path string, opts FinalizeOptions) (string, error)
+	StatMany(paths []string) ([]FileData, error)
+	CreateTree(parent string, subdirs []string) error
+	Bundle(path string) (map[string]BundleFile, error)
+	Ancestors(path string) ([]FileData, error)
+	// Rebuild сбрасывает кеши юзкейса (RebuildZip или RebuildAll), возвращает, какие подсистемы сброшены.
+	Rebuild(target string) ([]string, error)
+	VerifyManifest(
+		ctx context.Context, root string, manifest []ManifestEntry, onDiff func(ManifestDiff) error,
+	) (ManifestReport, error)
+	// FindDuplicates sha256 -> пути одинаковых файлов под path, только группы из двух и больше.
+	// truncated — обход остановлен лимитом, дубликаты среди непросмотренных файлов не найдены.
+	FindDuplicates(ctx context.Context, path string) (groups map[string][]string, truncated bool, err error)
+	// Stats сводка по дереву под path, refresh игнорирует кеш.
+	Stats(path string, refresh bool) (StorageStats, error)
+	// Tree дерево папки, collapse склеивает цепочки папок с единственной подпапкой.
+	Tree(path string, collapse bool) (*TreeNode, error)
+	// FetchURL скачивает url в папку destDir, возвращает путь сохранённого файла.
+	FetchURL(destDir, url string) (string, error)
+	// Swap меняет местами два файла (или папки) одной директории.
+	Swap(pathA, pathB string) error
+	// PreviewText первые maxLines строк текстового файла, truncated — в файле есть продолжение.
+	PreviewText(path string, maxLines int) (lines []string, truncated bool, err error)
+	// ExtractArchive распаковывает zip из хранилища в папку destPath, существующие файлы не затираются.
+	ExtractArchive(zipPath, destPath string) error
+	// Info сведения об одном пути, withMeta добавляет сайдкар загрузки.
+	Info(path string, withMeta bool) (FileData, error)
+	// HashFile sha256 содержимого файла в hex.
+	HashFile(path string) (string, error)
+}
+
+// TreeNode узел дерева /tree. в режиме collapse у цепочки папок с единственной подпапкой
+// Name склеен через `/` (`com/example/project`), а Path указывает на последнюю папку цепочки.
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"`
+	IsDir    bool        `json:"is_dir"`
+	Size     int64       `json:"size,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+	// Truncated только у корня: обход остановлен лимитом, часть дерева не попала в ответ.
+	Truncated bool `json:"truncated,omitempty"`
 }
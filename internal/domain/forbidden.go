@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsForbiddenName проверяет имя по списку запрещённых расширений/префиксов.
+// общая логика для handler (загрузка и скачивание) и use case (пометка в листинге).
+func IsForbiddenName(fileName string, forbidden []string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, f := range forbidden {
+		if ext == f || strings.HasPrefix(fileName, f) {
+			return true
+		}
+	}
+	return false
+}
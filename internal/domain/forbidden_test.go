@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsForbiddenName(t *testing.T) {
+	forbidden := []string{".env", ".gitignore"}
+
+	tests := []struct {
+		name      string
+		fileName  string
+		forbidden []string
+		want      bool
+	}{
+		{"forbidden extension", "config.env", forbidden, true},
+		{"forbidden extension case insensitive", "CONFIG.ENV", forbidden, true},
+		{"forbidden prefix", ".gitignore", forbidden, true},
+		{"allowed file", "test.txt", forbidden, false},
+		{"empty list", "config.env", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsForbiddenName(tt.fileName, tt.forbidden))
+		})
+	}
+}
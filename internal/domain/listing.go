@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// ListingETag сильный ETag листинга по именам, типам, размерам и времени изменения записей.
+// считается по тому, что реально уходит клиенту, так что фильтры и acl меняют его сами.
+// порядок записей на результат не влияет.
+func ListingETag(files []FileData) string {
+	lines := make([]string, len(files))
+	for i, f := range files {
+		lines[i] = fmt.Sprintf("%s\x00%t\x00%d\x00%d\n", f.Name, f.IsDir, f.Size, f.ModTime.UnixNano())
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingETag(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := []FileData{
+		{Name: "a.txt", Size: 3, ModTime: now},
+		{Name: "docs", IsDir: true, ModTime: now},
+	}
+	etag := ListingETag(base)
+
+	tests := []struct {
+		name  string
+		files []FileData
+		same  bool
+	}{
+		{name: "same entries", files: []FileData{base[0], base[1]}, same: true},
+		{name: "order does not matter", files: []FileData{base[1], base[0]}, same: true},
+		{name: "entry added", files: append([]FileData{{Name: "b.txt", ModTime: now}}, base...)},
+		{name: "entry removed", files: base[:1]},
+		{name: "size changed", files: []FileData{{Name: "a.txt", Size: 4, ModTime: now}, base[1]}},
+		{name: "modified", files: []FileData{{Name: "a.txt", Size: 3, ModTime: now.Add(time.Nanosecond)}, base[1]}},
+		{name: "renamed", files: []FileData{{Name: "c.txt", Size: 3, ModTime: now}, base[1]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ListingETag(tt.files)
+			if tt.same {
+				assert.Equal(t, etag, got)
+			} else {
+				assert.NotEqual(t, etag, got)
+			}
+		})
+	}
+
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+	assert.NotEqual(t, etag, ListingETag(nil))
+}
@@ -0,0 +1,32 @@
+package domain
+
+// ManifestEntry файл в манифесте для VerifyManifest: путь относительно проверяемой папки (через `/`) и hex sha256.
+type ManifestEntry struct {
+	Path     string `json:"path"`
+	Checksum string `json:"checksum"`
+}
+
+// статусы расхождения хранилища с манифестом.
+const (
+	// ManifestMissing файл есть в манифесте, но не в хранилище.
+	ManifestMissing = "missing"
+	// ManifestChanged файл на месте, но содержимое другое.
+	ManifestChanged = "changed"
+	// ManifestExtra файл есть в хранилище, но не в манифесте.
+	ManifestExtra = "extra"
+)
+
+// ManifestDiff одно расхождение, отдаётся по мере обхода, не дожидаясь конца проверки.
+type ManifestDiff struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// ManifestReport итог проверки: сколько файлов сверено и сколько расхождений каждого вида.
+type ManifestReport struct {
+	Checked int `json:"checked"`
+	Matched int `json:"matched"`
+	Missing int `json:"missing"`
+	Changed int `json:"changed"`
+	Extra   int `json:"extra"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// StorageStats сводка по дереву для дашборда, см. FileManagement.Stats.
+type StorageStats struct {
+	Files int64 `json:"files"`
+	Dirs  int64 `json:"dirs"`
+	Bytes int64 `json:"bytes"`
+	// Extensions самые частые расширения (в нижнем регистре, "" — без расширения), не больше top N.
+	Extensions []ExtensionStats `json:"extensions"`
+	// ComputedAt когда дерево обходилось, у ответа из кеша это время прошлого обхода.
+	ComputedAt time.Time `json:"computed_at"`
+	// Truncated обход остановлен лимитом (file.max_walk_depth/max_walk_entries), цифры неполные.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ExtensionStats сколько файлов с расширением и сколько они весят.
+type ExtensionStats struct {
+	Ext   string `json:"ext"`
+	Files int64  `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// TrashPurgeReport итог одного прохода автоочистки корзины (file.trash_purge_interval).
+type TrashPurgeReport struct {
+	StartedAt time.Time `json:"started_at"`
+	// Purged сколько записей корзины стёрто, BytesFreed их суммарный размер.
+	Purged     int   `json:"purged"`
+	BytesFreed int64 `json:"bytes_freed"`
+	// Skipped записи, которым пора, но их как раз восстанавливают: уйдут в следующий проход.
+	Skipped int `json:"skipped"`
+	// Error проход прервался, цифры выше — то, что успели до ошибки.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// UploadMetaSuffix сайдкар с метаданными загрузки лежит рядом с файлом: `report.pdf.meta.json`.
+const UploadMetaSuffix = ".meta.json"
+
+// PostUploadHook обработка файла сразу после удачной загрузки (UploadFile и finalize чанков).
+// ошибка хука загрузку не отменяет: файл уже лежит в хранилище, хук сам решает, что логировать.
+type PostUploadHook interface {
+	AfterUpload(relPath string, info UploadInfo)
+}
+
+// UploadInfo что известно о загрузке кроме самого файла.
+type UploadInfo struct {
+	// OriginalName имя, с которым файл прислали: после conflict=rename он лежит под другим.
+	OriginalName string
+	// Uploader имя пользователя из аутентификации, пусто — анонимная загрузка.
+	Uploader string
+}
+
+// UploadMetadata содержимое сайдкара file.write_upload_metadata.
+type UploadMetadata struct {
+	OriginalName string    `json:"original_name"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+	Uploader     string    `json:"uploader,omitempty"`
+	Size         int64     `json:"size"`
+	// Checksum hex sha256 содержимого.
+	Checksum string `json:"checksum"`
+}
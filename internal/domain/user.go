@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// User кто делает запрос. кладётся в контекст аутентификацией, без неё запрос анонимный (нулевой User).
+type User struct {
+	Name  string
+	Roles []string
+}
+
+type userContextKey struct{}
+
+// WithUser контекст с пользователем запроса.
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext пользователь запроса, false — анонимный.
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey{}).(User)
+	return user, ok
+}
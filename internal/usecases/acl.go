@@ -0,0 +1,397 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+// ACL доступ к папкам по пользователям (секция acl в конфиге). пути правил и запросов приводятся
+// тем же sanitizePath, что и в юзкейсе, иначе `private\x` или `./private` обходили бы правило.
+// префиксы сравниваются без учёта регистра: на регистронезависимой ФС `Private` та же папка.
+type ACL struct {
+	uc           *FileManagementUseCase
+	rules        []aclRule
+	defaultAllow bool
+}
+
+type aclRule struct {
+	prefix  string
+	allowed []string
+}
+
+func NewACL(uc *FileManagementUseCase, cfg config.ACLConfig) (*ACL, error) {
+	acl := &ACL{uc: uc, defaultAllow: cfg.Default != domain.ACLDeny}
+	for prefix, allowed := range cfg.Rules {
+		clean, err := uc.sanitizePath(strings.TrimPrefix(prefix, domain.PathRoot))
+		if err != nil {
+			return nil, fmt.Errorf("acl rule '%s': %w", prefix, err)
+		}
+		acl.rules = append(acl.rules, aclRule{prefix: filepath.ToSlash(clean), allowed: allowed})
+	}
+	return acl, nil
+}
+
+// For юзкейс глазами пользователя: операции над запрещёнными ему путями дают ErrPermissionDenied.
+func (a *ACL) For(user domain.User) domain.FileManagement {
+	return &aclGuard{acl: a, next: a.uc, user: user}
+}
+
+// allowed решает самое длинное правило, под которое попал path.
+func (a *ACL) allowed(user domain.User, path string) bool {
+	best := -1
+	allow := a.defaultAllow
+	for _, rule := range a.rules {
+		if len(rule.prefix) > best && underPrefix(path, rule.prefix) {
+			best = len(rule.prefix)
+			allow = rule.permits(user)
+		}
+	}
+	return allow
+}
+
+// allowedTree для рекурсивных операций (zip, дерево, поиск): нужен доступ к path и ко всем правилам под ним,
+// иначе zip корня унёс бы и закрытую папку.
+func (a *ACL) allowedTree(user domain.User, path string) bool {
+	if !a.allowed(user, path) {
+		return false
+	}
+	for _, rule := range a.rules {
+		if underPrefix(rule.prefix, path) && !a.allowed(user, rule.prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r aclRule) permits(user domain.User) bool {
+	for _, who := range r.allowed {
+		if role, isRole := strings.CutPrefix(who, domain.ACLRolePrefix); isRole {
+			if slices.Contains(user.Roles, role) {
+				return true
+			}
+		} else if user.Name != "" && who == user.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// underPrefix path совпадает с prefix или лежит внутри него. "." — корень, под ним всё.
+func underPrefix(path, prefix string) bool {
+	if prefix == domain.PathCurrent {
+		return true
+	}
+	if len(path) < len(prefix) || !strings.EqualFold(path[:len(prefix)], prefix) {
+		return false
+	}
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// aclGuard проверяет пути и отдаёт вызов дальше. неразбираемый путь пропускаем как есть:
+// юзкейс отклонит его той же ошибкой, что и без ACL.
+type aclGuard struct {
+	acl  *ACL
+	next domain.FileManagement
+	user domain.User
+}
+
+func (g *aclGuard) check(paths ...string) error {
+	return g.checkWith(g.acl.allowed, paths...)
+}
+
+func (g *aclGuard) checkTree(paths ...string) error {
+	return g.checkWith(g.acl.allowedTree, paths...)
+}
+
+func (g *aclGuard) checkWith(allowed func(domain.User, string) bool, paths ...string) error {
+	for _, p := range paths {
+		clean, err := g.acl.uc.sanitizePath(p)
+		if err != nil {
+			continue
+		}
+		if !allowed(g.user, filepath.ToSlash(clean)) {
+			return fmt.Errorf("acl denies '%s' to '%s': %w", clean, g.user.Name, domain.ErrPermissionDenied)
+		}
+		// запись корзины судим ещё и по месту, откуда её удалили: иначе `.trash/private/x` обходила бы
+		// правило `private`. вся корзина — это корень, и её листинг или zip требуют того же, что и корень.
+		if origin, inTrash := g.acl.uc.trashOrigin(clean); inTrash && !allowed(g.user, filepath.ToSlash(origin)) {
+			return fmt.Errorf("acl denies '%s' to '%s': %w", clean, g.user.Name, domain.ErrPermissionDenied)
+		}
+	}
+	return nil
+}
+
+func (g *aclGuard) List(path string, opts domain.ListOptions) ([]domain.FileData, error) {
+	if err := g.check(path); err != nil {
+		return nil, err
+	}
+	return g.next.List(path, opts)
+}
+
+func (g *aclGuard) UploadFile(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
+	if err := g.check(path); err != nil {
+		return "", err
+	}
+	return g.next.UploadFile(path, file, opts)
+}
+
+func (g *aclGuard) CreateFolder(path string) error {
+	if err := g.check(path); err != nil {
+		return err
+	}
+	return g.next.CreateFolder(path)
+}
+
+func (g *aclGuard) Delete(path string, opts domain.DeleteOptions) error {
+	if err := g.checkTree(path); err != nil {
+		return err
+	}
+	return g.next.Delete(path, opts)
+}
+
+func (g *aclGuard) Rename(oldPath, newPath string, policy domain.ConflictPolicy) (string, error) {
+	if err := g.checkTree(oldPath, newPath); err != nil {
+		return "", err
+	}
+	return g.next.Rename(oldPath, newPath, policy)
+}
+
+func (g *aclGuard) ServeFile(w http.ResponseWriter, r *http.Request, path string) error {
+	if err := g.check(path); err != nil {
+		return err
+	}
+	return g.next.ServeFile(w, r, path)
+}
+
+func (g *aclGuard) ServeFolderAsZip(w http.ResponseWriter, r *http.Request, path string) error {
+	if err := g.checkTree(path); err != nil {
+		return err
+	}
+	return g.next.ServeFolderAsZip(w, r, path)
+}
+
+func (g *aclGuard) ServeFolderAsTarGz(w http.ResponseWriter, path string) error {
+	if err := g.checkTree(path); err != nil {
+		return err
+	}
+	return g.next.ServeFolderAsTarGz(w, path)
+}
+
+func (g *aclGuard) ServeSelectionAsZip(w http.ResponseWriter, paths []string, flatten bool) error {
+	if err := g.check(paths...); err != nil {
+		return err
+	}
+	return g.next.ServeSelectionAsZip(w, paths, flatten)
+}
+
+func (g *aclGuard) Capabilities() domain.Capabilities {
+	return g.next.Capabilities()
+}
+
+func (g *aclGuard) Compare(pathA, pathB string) (bool, error) {
+	if err := g.check(pathA, pathB); err != nil {
+		return false, err
+	}
+	return g.next.Compare(pathA, pathB)
+}
+
+func (g *aclGuard) RecentFiles(path string, limit int) ([]domain.FileData, error) {
+	if err := g.checkTree(path); err != nil {
+		return nil, err
+	}
+	return g.next.RecentFiles(path, limit)
+}
+
+func (g *aclGuard) PruneEmptyDirs(path string) (int, error) {
+	if err := g.checkTree(path); err != nil {
+		return 0, err
+	}
+	return g.next.PruneEmptyDirs(path)
+}
+
+func (g *aclGuard) OpenWriter(path string) (io.WriteCloser, error) {
+	if err := g.check(path); err != nil {
+		return nil, err
+	}
+	return g.next.OpenWriter(path)
+}
+
+func (g *aclGuard) ZipManifest(path string) ([]domain.ZipEntry, error) {
+	if err := g.checkTree(path); err != nil {
+		return nil, err
+	}
+	return g.next.ZipManifest(path)
+}
+
+// UploadChunk путь появится только в FinalizeUpload, там и проверяем. владелец всегда пользователь guard'а,
+// так что дописать или склеить чужую загрузку, угадав её id, нельзя.
+func (g *aclGuard) UploadChunk(id string, offset int64, chunk io.Reader, opts domain.ChunkOptions) error {
+	opts.Owner = g.user.Name
+	return g.next.UploadChunk(id, offset, chunk, opts)
+}
+
+func (g *aclGuard) FinalizeUpload(id, path string, opts domain.FinalizeOptions) (string, error) {
+	if err := g.check(path); err != nil {
+		return "", err
+	}
+	opts.Owner = g.user.Name
+	return g.next.FinalizeUpload(id, path, opts)
+}
+
+func (g *aclGuard) StatMany(paths []string) ([]domain.FileData, error) {
+	if err := g.check(paths...); err != nil {
+		return nil, err
+	}
+	return g.next.StatMany(paths)
+}
+
+func (g *aclGuard) CreateTree(parent string, subdirs []string) error {
+	paths := []string{parent}
+	for _, sub := range subdirs {
+		paths = append(paths, filepath.Join(parent, sub))
+	}
+	if err := g.check(paths...); err != nil {
+		return err
+	}
+	return g.next.CreateTree(parent, subdirs)
+}
+
+func (g *aclGuard) Bundle(path string) (map[string]domain.BundleFile, error) {
+	if err := g.checkTree(path); err != nil {
+		return nil, err
+	}
+	return g.next.Bundle(path)
+}
+
+func (g *aclGuard) Ancestors(path string) ([]domain.FileData, error) {
+	if err := g.check(path); err != nil {
+		return nil, err
+	}
+	return g.next.Ancestors(path)
+}
+
+func (g *aclGuard) Rebuild(target string) ([]string, error) {
+	return g.next.Rebuild(target)
+}
+
+func (g *aclGuard) VerifyManifest(
+	ctx context.Context,
+	root string,
+	manifest []domain.ManifestEntry,
+	onDiff func(domain.ManifestDiff) error,
+) (domain.ManifestReport, error) {
+	if err := g.checkTree(root); err != nil {
+		return domain.ManifestReport{}, err
+	}
+	return g.next.VerifyManifest(ctx, root, manifest, onDiff)
+}
+
+func (g *aclGuard) FindDuplicates(ctx context.Context, path string) (map[string][]string, bool, error) {
+	if err := g.checkTree(path); err != nil {
+		return nil, false, err
+	}
+	return g.next.FindDuplicates(ctx, path)
+}
+
+func (g *aclGuard) Stats(path string, refresh bool) (domain.StorageStats, error) {
+	if err := g.checkTree(path); err != nil {
+		return domain.StorageStats{}, err
+	}
+	return g.next.Stats(path, refresh)
+}
+
+func (g *aclGuard) Tree(path string, collapse bool) (*domain.TreeNode, error) {
+	if err := g.checkTree(path); err != nil {
+		return nil, err
+	}
+	return g.next.Tree(path, collapse)
+}
+
+func (g *aclGuard) FetchURL(destDir, url string) (string, error) {
+	if err := g.check(destDir); err != nil {
+		return "", err
+	}
+	return g.next.FetchURL(destDir, url)
+}
+
+func (g *aclGuard) Swap(pathA, pathB string) error {
+	if err := g.checkTree(pathA, pathB); err != nil {
+		return err
+	}
+	return g.next.Swap(pathA, pathB)
+}
+
+func (g *aclGuard) ListPage(path string, opts domain.ListOptions, offset, limit int) ([]domain.FileData, int, error) {
+	if err := g.check(path); err != nil {
+		return nil, 0, err
+	}
+	return g.next.ListPage(path, opts, offset, limit)
+}
+
+func (g *aclGuard) ServeSelectionArchive(w http.ResponseWriter, paths []string, flatten bool) error {
+	if err := g.check(paths...); err != nil {
+		return err
+	}
+	return g.next.ServeSelectionArchive(w, paths, flatten)
+}
+
+func (g *aclGuard) Merge(srcPath, dstPath string, policy domain.ConflictPolicy) error {
+	if err := g.checkTree(srcPath, dstPath); err != nil {
+		return err
+	}
+	return g.next.Merge(srcPath, dstPath, policy)
+}
+
+func (g *aclGuard) Restore(path string) error {
+	if err := g.checkTree(path); err != nil {
+		return err
+	}
+	return g.next.Restore(path)
+}
+
+// EmptyTrash в корзине записи из всех папок, поэтому нужен доступ ко всему дереву.
+func (g *aclGuard) EmptyTrash() error {
+	if err := g.checkTree(domain.PathEmpty); err != nil {
+		return err
+	}
+	return g.next.EmptyTrash()
+}
+
+func (g *aclGuard) PreviewText(path string, maxLines int) ([]string, bool, error) {
+	if err := g.check(path); err != nil {
+		return nil, false, err
+	}
+	return g.next.PreviewText(path, maxLines)
+}
+
+func (g *aclGuard) ExtractArchive(zipPath, destPath string) error {
+	if err := g.check(zipPath); err != nil {
+		return err
+	}
+	if err := g.checkTree(destPath); err != nil {
+		return err
+	}
+	return g.next.ExtractArchive(zipPath, destPath)
+}
+
+func (g *aclGuard) Info(path string, withMeta bool) (domain.FileData, error) {
+	if err := g.check(path); err != nil {
+		return domain.FileData{}, err
+	}
+	return g.next.Info(path, withMeta)
+}
+
+func (g *aclGuard) HashFile(path string) (string, error) {
+	if err := g.check(path); err != nil {
+		return "", err
+	}
+	return g.next.HashFile(path)
+}
@@ -0,0 +1,220 @@
+package usecases
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func TestACL_Allowed(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	acl, err := NewACL(uc, config.ACLConfig{
+		Default: domain.ACLAllow,
+		Rules: map[string][]string{
+			"/private":        {"alice", "role:admin"},
+			"private/shared":  {"bob"},
+			"teams/red/inbox": {"role:red"},
+		},
+	})
+	require.NoError(t, err)
+
+	alice := domain.User{Name: "alice"}
+	bob := domain.User{Name: "bob"}
+	admin := domain.User{Name: "carol", Roles: []string{"admin"}}
+	anonymous := domain.User{}
+	red := domain.User{Name: "x", Roles: []string{"red"}}
+
+	tests := []struct {
+		name string
+		user domain.User
+		path string
+		want bool
+	}{
+		{name: "unlisted path", user: anonymous, path: "public/a.txt", want: true},
+		{name: "listed user", user: alice, path: "private/a.txt", want: true},
+		{name: "listed role", user: admin, path: "private", want: true},
+		{name: "not listed", user: bob, path: "private/a.txt", want: false},
+		{name: "anonymous", user: anonymous, path: "private/a.txt", want: false},
+		{name: "longest rule wins", user: bob, path: "private/shared/x.txt", want: true},
+		{name: "longest rule wins for others too", user: alice, path: "private/shared/x.txt", want: false},
+		{name: "prefix is a path component", user: anonymous, path: "private-notes.txt", want: true},
+		{name: "case-insensitive prefix", user: bob, path: "PRIVATE/a.txt", want: false},
+		{name: "role rule", user: red, path: "teams/red/inbox/a", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, acl.allowed(tt.user, tt.path))
+		})
+	}
+
+	t.Run("tree needs every rule below", func(t *testing.T) {
+		assert.False(t, acl.allowedTree(alice, "."), "teams/red/inbox is closed to alice")
+		assert.True(t, acl.allowedTree(admin, "public"))
+		assert.False(t, acl.allowedTree(admin, "private"), "private/shared is bob only")
+		assert.True(t, acl.allowedTree(bob, "private/shared"))
+	})
+}
+
+func TestACL_DefaultDeny(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	acl, err := NewACL(uc, config.ACLConfig{
+		Default: domain.ACLDeny,
+		Rules:   map[string][]string{"public": {"alice"}},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, acl.allowed(domain.User{Name: "alice"}, "."))
+	assert.False(t, acl.allowed(domain.User{Name: "alice"}, "other/a.txt"))
+	assert.True(t, acl.allowed(domain.User{Name: "alice"}, "public/a.txt"))
+}
+
+func TestNewACL_BadRule(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+
+	_, err := NewACL(uc, config.ACLConfig{Rules: map[string][]string{"../etc": {"alice"}}})
+
+	assert.ErrorIs(t, err, domain.ErrPathTraversal)
+}
+
+func TestACL_For(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"private/secret.txt": "secret",
+		"public/a.txt":       "a",
+	})
+	acl, err := NewACL(uc, config.ACLConfig{
+		Default: domain.ACLAllow,
+		Rules:   map[string][]string{"private": {"alice"}},
+	})
+	require.NoError(t, err)
+	bob := acl.For(domain.User{Name: "bob"})
+	alice := acl.For(domain.User{Name: "alice"})
+
+	t.Run("denied operations", func(t *testing.T) {
+		_, err := bob.List("private", domain.ListOptions{})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		// `./private` и `a/../private` не обходят правило.
+		_, err = bob.List("./public/../private", domain.ListOptions{})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		_, err = bob.UploadFile("private/new.txt", strings.NewReader("x"), domain.UploadOptions{})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		_, err = bob.Rename("public/a.txt", "private/a.txt", "")
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		_, err = bob.StatMany([]string{"public/a.txt", "private/secret.txt"})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		// корень целиком унёс бы и закрытую папку.
+		err = bob.ServeFolderAsZip(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "")
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+
+		err = bob.Delete("", domain.DeleteOptions{})
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+		assert.FileExists(t, tmpDir+"/private/secret.txt")
+	})
+
+	t.Run("allowed operations pass through", func(t *testing.T) {
+		files, err := bob.List("public", domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, files, 1)
+
+		files, err = alice.List("private", domain.ListOptions{})
+		require.NoError(t, err)
+		assert.Len(t, files, 1)
+
+		tree, err := alice.Tree("", false)
+		require.NoError(t, err)
+		assert.Len(t, tree.Children, 2)
+	})
+
+	t.Run("bad path keeps usecase error", func(t *testing.T) {
+		_, err := bob.List("../etc", domain.ListOptions{})
+		assert.ErrorIs(t, err, domain.ErrPathTraversal)
+	})
+}
+
+func TestACL_For_ChunksScopedToUser(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	acl, err := NewACL(uc, config.ACLConfig{Default: domain.ACLAllow})
+	require.NoError(t, err)
+	bob := acl.For(domain.User{Name: "bob"})
+	alice := acl.For(domain.User{Name: "alice"})
+
+	// владелец из опций не в счёт, guard подставляет своего пользователя.
+	require.NoError(t, alice.UploadChunk("u1", 0, strings.NewReader("data"), domain.ChunkOptions{Owner: "bob"}))
+
+	_, err = bob.FinalizeUpload("u1", "stolen.txt", domain.FinalizeOptions{Owner: "alice", ExpectedSize: 4})
+	assert.ErrorIs(t, err, domain.ErrFileNotFound)
+
+	_, err = alice.FinalizeUpload("u1", "mine.txt", domain.FinalizeOptions{ExpectedSize: 4})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"mine.txt": "data"}, readTree(t, tmpDir))
+}
+
+func TestACL_For_Trash(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.TrashEnabled = true
+	uc.cfg.File.TrashDir = ".trash"
+	writeTree(t, tmpDir, map[string]string{
+		".trash/private/secret.txt": "secret",
+		".trash/public/a.txt":       "a",
+	})
+	acl, err := NewACL(uc, config.ACLConfig{
+		Default: domain.ACLAllow,
+		Rules:   map[string][]string{"private": {"alice"}},
+	})
+	require.NoError(t, err)
+	bob := acl.For(domain.User{Name: "bob"})
+	alice := acl.For(domain.User{Name: "alice"})
+
+	tests := []struct {
+		name string
+		call func(fm domain.FileManagement) error
+	}{
+		{name: "list", call: func(fm domain.FileManagement) error {
+			_, err := fm.List(".trash/private", domain.ListOptions{})
+			return err
+		}},
+		{name: "download", call: func(fm domain.FileManagement) error {
+			r := httptest.NewRequest("GET", "/", nil)
+			return fm.ServeFile(httptest.NewRecorder(), r, ".trash/private/secret.txt")
+		}},
+		{name: "preview", call: func(fm domain.FileManagement) error {
+			_, _, err := fm.PreviewText(".trash/private/secret.txt", 0)
+			return err
+		}},
+		{name: "rename out of trash", call: func(fm domain.FileManagement) error {
+			_, err := fm.Rename(".trash/private/secret.txt", "public/secret.txt", "")
+			return err
+		}},
+		{name: "restore", call: func(fm domain.FileManagement) error {
+			return fm.Restore("private/secret.txt")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(bob), domain.ErrPermissionDenied)
+		})
+	}
+
+	t.Run("whole trash needs the whole tree", func(t *testing.T) {
+		err := bob.ServeFolderAsZip(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), ".trash")
+		assert.ErrorIs(t, err, domain.ErrPermissionDenied)
+	})
+
+	t.Run("open entries and owners pass", func(t *testing.T) {
+		_, err := bob.List(".trash/public", domain.ListOptions{})
+		require.NoError(t, err)
+		_, err = alice.List(".trash/private", domain.ListOptions{})
+		require.NoError(t, err)
+	})
+}
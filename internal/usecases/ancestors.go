@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// Ancestors цепочка папок от корня до path включительно, для хлебных крошек при переходе по прямой ссылке.
+// корень в цепочку не входит; каждая папка проверяется, так что клиент сразу видит, какое звено пропало.
+func (uc *FileManagementUseCase) Ancestors(path string) ([]domain.FileData, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if sanitizedPath == domain.PathCurrent {
+		return []domain.FileData{}, nil
+	}
+
+	components := strings.Split(filepath.ToSlash(sanitizedPath), domain.PathRoot)
+	chain := make([]domain.FileData, 0, len(components))
+	current := ""
+	for _, component := range components {
+		current = filepath.Join(current, component)
+
+		info, statErr := uc.storage.Stat(current)
+		if statErr != nil {
+			if os.IsNotExist(statErr) {
+				return nil, fmt.Errorf("folder '%s' does not exist: %w", current, domain.ErrFileNotFound)
+			}
+			return nil, fmt.Errorf("failed to stat '%s': %w", current, statErr)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("'%s' is not a folder: %w", current, domain.ErrInvalidParameter)
+		}
+
+		chain = append(chain, domain.FileData{
+			Name:    info.Name(),
+			IsDir:   true,
+			Path:    filepath.ToSlash(current),
+			ModTime: info.ModTime(),
+		})
+	}
+	return chain, nil
+}
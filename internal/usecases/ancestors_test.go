@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Ancestors(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"projects/2024/report/summary.txt": "x",
+	})
+
+	t.Run("full chain", func(t *testing.T) {
+		chain, err := uc.Ancestors("projects/2024/report")
+
+		require.NoError(t, err)
+		var paths []string
+		for _, dir := range chain {
+			assert.True(t, dir.IsDir)
+			paths = append(paths, dir.Path)
+		}
+		assert.Equal(t, []string{"projects", "projects/2024", "projects/2024/report"}, paths)
+		assert.Equal(t, "2024", chain[1].Name)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		chain, err := uc.Ancestors("")
+		require.NoError(t, err)
+		assert.Empty(t, chain)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+		wantMsg string
+	}{
+		{"missing intermediate", "projects/2023/report", domain.ErrFileNotFound, "projects/2023"},
+		{"file in chain", "projects/2024/report/summary.txt", domain.ErrInvalidParameter, "summary.txt"},
+		{"path traversal", "../etc", domain.ErrPathTraversal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Ancestors(tt.path)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestFileManagementUseCase_Ancestors_ThroughStorage(t *testing.T) {
+	// на диске пусто: у memory-хранилища путь виртуальный, os.Stat по нему ничего не найдёт.
+	uc, _ := newLocalUseCase(t)
+	uc.storage = newMapStorage(t, fstest.MapFS{"projects/2024/report.txt": {Data: []byte("x")}})
+
+	chain, err := uc.Ancestors("projects/2024")
+
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "projects/2024", chain[1].Path)
+}
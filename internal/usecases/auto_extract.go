@@ -0,0 +1,88 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// AutoExtractor распаковывает zip, брошенные в file.auto_extract_dir, в соседнюю папку с тем же именем
+// (`inbox/photos.zip` -> `inbox/photos/`). уже существующая папка значит, что архив распакован, его пропускаем.
+// записи проверяются как загрузки: имена и обход путей, запрещённые расширения, содержимое, размер.
+// архив, который не распаковался, запоминается по времени изменения и до перезаливки не трогается,
+// иначе ошибка писалась бы в лог каждые auto_extract_interval.
+type AutoExtractor struct {
+	uc  *FileManagementUseCase
+	dir string
+	// failed путь архива -> modtime неудачной попытки. Scan зовётся из одной горутины, мьютекс не нужен.
+	failed map[string]time.Time
+}
+
+func NewAutoExtractor(uc *FileManagementUseCase) (*AutoExtractor, error) {
+	dir, err := uc.sanitizePath(uc.cfg.File.AutoExtractDir)
+	if err != nil {
+		return nil, fmt.Errorf("auto extract dir: %w", err)
+	}
+	return &AutoExtractor{uc: uc, dir: dir, failed: make(map[string]time.Time)}, nil
+}
+
+// Run сканирует папку каждые interval, пока не отменят ctx. ошибки только логируются.
+func (e *AutoExtractor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Scan(time.Now().Add(-interval))
+		}
+	}
+}
+
+// Scan распаковывает архивы, изменённые не позже settledBefore: более свежий архив, возможно, ещё дописывается.
+func (e *AutoExtractor) Scan(settledBefore time.Time) {
+	entries, err := e.uc.storage.ReadDirectory(e.dir)
+	if err != nil {
+		logrus.Errorf("Auto extract: failed to read '%s': %v", e.dir, err)
+		return
+	}
+
+	for _, info := range entries {
+		name := info.Name()
+		if info.IsDir() || strings.HasPrefix(name, domain.HiddenFilePrefix) ||
+			!strings.EqualFold(filepath.Ext(name), domain.ExtensionZip) || info.ModTime().After(settledBefore) {
+			continue
+		}
+
+		archive := filepath.Join(e.dir, name)
+		if failedAt, ok := e.failed[archive]; ok && failedAt.Equal(info.ModTime()) {
+			continue
+		}
+		target := strings.TrimSuffix(archive, filepath.Ext(name))
+		if _, statErr := e.uc.storage.Stat(target); statErr == nil {
+			continue
+		}
+
+		if extractErr := e.uc.extractZip(archive, info.Size(), target); extractErr != nil {
+			logrus.Errorf("Auto extract: '%s' skipped until it changes: %v", archive, extractErr)
+			e.failed[archive] = info.ModTime()
+			continue
+		}
+		delete(e.failed, archive)
+		logrus.Infof("Auto extract: '%s' extracted to '%s'", archive, target)
+
+		if e.uc.cfg.File.AutoExtractDelete {
+			if removeErr := e.uc.storage.Remove(archive); removeErr != nil {
+				logrus.Errorf("Auto extract: failed to remove '%s': %v", archive, removeErr)
+			}
+		}
+	}
+}
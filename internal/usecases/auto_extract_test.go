@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+)
+
+// writeZip кладёт в хранилище архив с записями name -> содержимое, имя с `/` на конце — папка.
+func writeZip(t *testing.T, full string, entries map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+	require.NoError(t, os.WriteFile(full, buf.Bytes(), 0o644))
+}
+
+func newExtractor(t *testing.T) (*AutoExtractor, string) {
+	t.Helper()
+	uc, tmpDir := newLocalUseCase(t, extractLimits, func(f *config.FileConfig) { f.AutoExtractDir = "inbox" })
+	extractor, err := NewAutoExtractor(uc)
+	require.NoError(t, err)
+	return extractor, tmpDir
+}
+
+func TestAutoExtractor_Scan(t *testing.T) {
+	settled := time.Now().Add(time.Hour)
+
+	t.Run("extracted next to the archive", func(t *testing.T) {
+		extractor, tmpDir := newExtractor(t)
+		writeZip(t, filepath.Join(tmpDir, "inbox/photos.zip"), map[string]string{
+			"a.txt":       "a",
+			"sub/b.txt":   "b",
+			"empty/":      "",
+			"secrets.env": "forbidden",
+		})
+
+		extractor.Scan(settled)
+
+		assert.FileExists(t, filepath.Join(tmpDir, "inbox/photos/a.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, "inbox/photos/sub/b.txt"))
+		assert.DirExists(t, filepath.Join(tmpDir, "inbox/photos/empty"))
+		assert.NoFileExists(t, filepath.Join(tmpDir, "inbox/photos/secrets.env"))
+		assert.FileExists(t, filepath.Join(tmpDir, "inbox/photos.zip"), "kept without auto_extract_delete")
+		assert.NoDirExists(t, filepath.Join(tmpDir, "inbox/.extracting-photos"))
+
+		// повторный проход не трогает уже распакованное.
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "inbox/photos/a.txt"), []byte("edited"), 0o644))
+		extractor.Scan(settled)
+		data, err := os.ReadFile(filepath.Join(tmpDir, "inbox/photos/a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "edited", string(data))
+	})
+
+	t.Run("archive deleted when configured", func(t *testing.T) {
+		extractor, tmpDir := newExtractor(t)
+		extractor.uc.cfg.File.AutoExtractDelete = true
+		writeZip(t, filepath.Join(tmpDir, "inbox/docs.zip"), map[string]string{"a.txt": "a"})
+
+		extractor.Scan(settled)
+
+		assert.FileExists(t, filepath.Join(tmpDir, "inbox/docs/a.txt"))
+		assert.NoFileExists(t, filepath.Join(tmpDir, "inbox/docs.zip"))
+	})
+
+	t.Run("fresh archive waits", func(t *testing.T) {
+		extractor, tmpDir := newExtractor(t)
+		writeZip(t, filepath.Join(tmpDir, "inbox/docs.zip"), map[string]string{"a.txt": "a"})
+
+		extractor.Scan(time.Now().Add(-time.Hour))
+
+		assert.NoDirExists(t, filepath.Join(tmpDir, "inbox/docs"))
+	})
+
+	rejected := []struct {
+		name    string
+		entries map[string]string
+		setup   func(e *AutoExtractor)
+	}{
+		{name: "zip slip", entries: map[string]string{"ok.txt": "x", "../../evil.txt": "x"}},
+		{name: "absolute entry", entries: map[string]string{"/etc/evil.txt": "x"}},
+		{name: "invalid name", entries: map[string]string{"bad|name.txt": "x"}},
+		{
+			name:    "over unpacked budget",
+			entries: map[string]string{"big.bin": string(make([]byte, 2048))},
+			setup:   func(e *AutoExtractor) { e.uc.cfg.File.AutoExtractMaxBytes = 1024 },
+		},
+		{
+			name:    "over upload size",
+			entries: map[string]string{"big.bin": string(make([]byte, 2048))},
+			setup:   func(e *AutoExtractor) { e.uc.cfg.Server.MaxUploadSize = 1024 },
+		},
+		{
+			name:    "too many entries",
+			entries: map[string]string{"a.txt": "a", "b.txt": "b"},
+			setup:   func(e *AutoExtractor) { e.uc.cfg.File.MaxZipEntries = 1 },
+		},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, tmpDir := newExtractor(t)
+			if tt.setup != nil {
+				tt.setup(extractor)
+			}
+			writeZip(t, filepath.Join(tmpDir, "inbox/bad.zip"), tt.entries)
+
+			extractor.Scan(settled)
+
+			assert.NoDirExists(t, filepath.Join(tmpDir, "inbox/bad"))
+			assert.NoDirExists(t, filepath.Join(tmpDir, "inbox/.extracting-bad"))
+			assert.NoFileExists(t, filepath.Join(tmpDir, "evil.txt"))
+			assert.FileExists(t, filepath.Join(tmpDir, "inbox/bad.zip"))
+		})
+	}
+
+	t.Run("corrupt archive is logged and skipped", func(t *testing.T) {
+		extractor, tmpDir := newExtractor(t)
+		writeTree(t, tmpDir, map[string]string{"inbox/broken.zip": "not a zip"})
+
+		extractor.Scan(settled)
+
+		assert.NoDirExists(t, filepath.Join(tmpDir, "inbox/broken"))
+	})
+
+	t.Run("failed archive is retried only after it changes", func(t *testing.T) {
+		extractor, tmpDir := newExtractor(t)
+		writeTree(t, tmpDir, map[string]string{"inbox/broken.zip": "not a zip"})
+		full := filepath.Join(tmpDir, "inbox/broken.zip")
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		require.NoError(t, os.Chtimes(full, old, old))
+		opens := 0
+		storage := hookStorage(extractor.uc)
+		storage.openFunc = func(relPath string) (io.ReadSeekCloser, error) {
+			opens++
+			return storage.FileStorage.Open(relPath)
+		}
+
+		extractor.Scan(settled)
+		extractor.Scan(settled)
+		assert.Equal(t, 1, opens, "second scan skips the known-bad archive")
+
+		// перезалили исправленный архив: новое время изменения, пробуем снова.
+		writeZip(t, full, map[string]string{"a.txt": "a"})
+		require.NoError(t, os.Chtimes(full, old.Add(time.Minute), old.Add(time.Minute)))
+		extractor.Scan(settled)
+
+		assert.Equal(t, 2, opens)
+		assert.FileExists(t, filepath.Join(tmpDir, "inbox/broken/a.txt"))
+		assert.Empty(t, extractor.failed)
+	})
+}
+
+func TestNewAutoExtractor_BadDir(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	uc.cfg.File.AutoExtractDir = "../outside"
+
+	_, err := NewAutoExtractor(uc)
+
+	assert.Error(t, err)
+}
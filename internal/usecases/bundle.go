@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"file-manager/internal/domain"
+)
+
+// errBundleLimit прерывает обход, как только папка вышла за лимиты bundle.
+var errBundleLimit = errors.New("bundle limit reached")
+
+// Bundle читает небольшую папку целиком: относительный путь -> содержимое и тип.
+// правила обхода те же, что у zip (скрытые и дроп-боксы пропускаются). всё держится в памяти,
+// поэтому лимиты file.bundle_max_bytes и file.bundle_max_files строгие: за ними ErrBundleTooLarge.
+func (uc *FileManagementUseCase) Bundle(path string) (map[string]domain.BundleFile, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, fmt.Errorf("bundle of drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return nil, fmt.Errorf("bundle of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return nil, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	maxBytes, maxFiles := uc.cfg.File.BundleMaxBytes, uc.cfg.File.BundleMaxFiles
+	bundle := make(map[string]domain.BundleFile)
+	var total int64
+	err = uc.walkArchive(sanitizedPath, func(file, rel string, info os.FileInfo) error {
+		total += info.Size()
+		if len(bundle) >= maxFiles || total > maxBytes {
+			return errBundleLimit
+		}
+
+		data, readErr := uc.readStored(file)
+		if readErr != nil {
+			return readErr
+		}
+		// файл мог вырасти между Walk и чтением.
+		total += int64(len(data)) - info.Size()
+		if total > maxBytes {
+			return errBundleLimit
+		}
+
+		contentType := mime.TypeByExtension(filepath.Ext(file))
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		bundle[filepath.ToSlash(rel)] = domain.BundleFile{ContentType: contentType, Data: data}
+		return nil
+	})
+	if errors.Is(err, errBundleLimit) {
+		return nil, fmt.Errorf("folder '%s' exceeds %d files or %d bytes: %w",
+			sanitizedPath, maxFiles, maxBytes, domain.ErrBundleTooLarge)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bundle '%s': %w", sanitizedPath, err)
+	}
+	return bundle, nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Bundle(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.BundleMaxBytes = 64
+	uc.cfg.File.BundleMaxFiles = 3
+	writeTree(t, tmpDir, map[string]string{
+		"assets/app.css":        "body{}",
+		"assets/img/logo.svg":   "<svg/>",
+		"assets/data":           "plain text",
+		"assets/.cache/big.bin": "hidden files do not count against limits at all",
+		"many/1.txt":            "1",
+		"many/2.txt":            "2",
+		"many/3.txt":            "3",
+		"many/4.txt":            "4",
+		"big/blob.bin":          string(make([]byte, 65)),
+	})
+
+	t.Run("small folder", func(t *testing.T) {
+		bundle, err := uc.Bundle("assets")
+
+		require.NoError(t, err)
+		assert.Len(t, bundle, 3)
+		assert.Equal(t, []byte("body{}"), bundle["app.css"].Data)
+		assert.Contains(t, bundle["app.css"].ContentType, "text/css")
+		assert.Equal(t, "image/svg+xml", bundle["img/logo.svg"].ContentType)
+		assert.Contains(t, bundle["data"].ContentType, "text/plain", "sniffed when extension is unknown")
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"too many files", "many", domain.ErrBundleTooLarge},
+		{"too many bytes", "big", domain.ErrBundleTooLarge},
+		{"file instead of folder", "assets/app.css", domain.ErrFileNotFound},
+		{"path traversal", "../x", domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Bundle(tt.path)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// caseProbePrefix имя пробного файла для определения регистронезависимой ФС, в нижнем регистре.
+const caseProbePrefix = ".case-probe-"
+
+// detectCaseInsensitive кладёт в корень хранилища пробный файл и ищет его же под именем в верхнем регистре.
+// если найти не вышло (хранилище только на чтение и т.п.), считаем ФС регистрозависимой, как на linux.
+func detectCaseInsensitive(storage domain.FileStorage) bool {
+	name := probeName(caseProbePrefix)
+	if err := storage.WriteFile(name, strings.NewReader("")); err != nil {
+		logrus.Warnf("Could not detect filesystem case sensitivity in %s: %v", storage.GetAbsolutePath(""), err)
+		return false
+	}
+	defer func() {
+		if removeErr := storage.Remove(name); removeErr != nil {
+			logrus.Warnf("Failed to remove case probe %s: %v", name, removeErr)
+		}
+	}()
+
+	_, err := storage.Stat(strings.ToUpper(name))
+	return err == nil
+}
+
+func caseInsensitiveMode(mode string, storage domain.FileStorage) bool {
+	switch mode {
+	case domain.CaseInsensitiveOn:
+		return true
+	case domain.CaseInsensitiveAuto:
+		insensitive := detectCaseInsensitive(storage)
+		logrus.Infof("Storage %s is case-insensitive: %t", storage.GetAbsolutePath(""), insensitive)
+		return insensitive
+	default:
+		return false
+	}
+}
+
+// caseCollision имя записи рядом с relPath, которое отличается от него только регистром ("File.txt" для "file.txt").
+// на регистронезависимой ФС это тот же файл, и запись по relPath молча его заменит.
+// пусто — такой записи нет или режим выключен.
+func (uc *FileManagementUseCase) caseCollision(relPath string) (string, error) {
+	if !uc.caseInsensitive {
+		return "", nil
+	}
+
+	base := filepath.Base(relPath)
+	entries, err := uc.storage.ReadDirectory(filepath.Dir(relPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Name() != base && strings.EqualFold(entry.Name(), base) {
+			return entry.Name(), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+// caseInsensitiveOn на linux ФС различает регистр, поэтому режим включается явно,
+// и проверки идут через сравнение имён в папке, как на macOS или Windows.
+func caseInsensitiveOn(f *config.FileConfig) {
+	f.CaseInsensitive = domain.CaseInsensitiveOn
+}
+
+func TestFileManagementUseCase_UploadFile_CaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   domain.ConflictPolicy
+		wantPath string
+		wantErr  error
+	}{
+		{name: "overwrite refused", policy: domain.ConflictOverwrite, wantErr: domain.ErrAlreadyExists},
+		{name: "default policy refused", policy: "", wantErr: domain.ErrAlreadyExists},
+		{name: "error policy", policy: domain.ConflictError, wantErr: domain.ErrAlreadyExists},
+		{name: "rename picks free name", policy: domain.ConflictRename, wantPath: "file (1).txt"},
+		{name: "version moves existing file", policy: domain.ConflictVersion, wantPath: "file.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t, caseInsensitiveOn)
+			writeTree(t, tmpDir, map[string]string{"File.txt": "original"})
+
+			opts := domain.UploadOptions{Conflict: tt.policy}
+			storedPath, err := uc.UploadFile("file.txt", strings.NewReader("new"), opts)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "File.txt")
+				assert.NoFileExists(t, filepath.Join(tmpDir, "file.txt"))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, storedPath)
+			if tt.policy == domain.ConflictVersion {
+				assert.NoFileExists(t, filepath.Join(tmpDir, "File.txt"))
+				assert.FileExists(t, filepath.Join(tmpDir, "file.v1.txt"))
+			} else {
+				assert.FileExists(t, filepath.Join(tmpDir, "File.txt"))
+			}
+		})
+	}
+
+	t.Run("same name still overwritten", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, caseInsensitiveOn)
+		writeTree(t, tmpDir, map[string]string{"File.txt": "original"})
+
+		_, err := uc.UploadFile("File.txt", strings.NewReader("new"), domain.UploadOptions{})
+
+		require.NoError(t, err)
+		data, _ := os.ReadFile(filepath.Join(tmpDir, "File.txt"))
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("case-sensitive storage keeps both", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, map[string]string{"File.txt": "original"})
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("new"), domain.UploadOptions{})
+
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(tmpDir, "file.txt"))
+		assert.FileExists(t, filepath.Join(tmpDir, "File.txt"))
+	})
+}
+
+func TestFileManagementUseCase_Rename_CaseInsensitive(t *testing.T) {
+	t.Run("case-only rename allowed", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, caseInsensitiveOn)
+		writeTree(t, tmpDir, map[string]string{"File.txt": "original"})
+
+		newPath, err := uc.Rename("File.txt", "FILE.txt", domain.ConflictError)
+
+		require.NoError(t, err)
+		assert.Equal(t, "FILE.txt", newPath)
+		assert.FileExists(t, filepath.Join(tmpDir, "FILE.txt"))
+	})
+
+	t.Run("rename onto differently cased file refused", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, caseInsensitiveOn)
+		writeTree(t, tmpDir, map[string]string{"File.txt": "original"})
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "other.txt"), []byte("x"), 0o644))
+
+		_, err := uc.Rename("other.txt", "file.txt", domain.ConflictOverwrite)
+
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		assert.FileExists(t, filepath.Join(tmpDir, "other.txt"))
+	})
+}
+
+func TestCaseInsensitiveMode(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	storage := uc.storage
+
+	assert.True(t, caseInsensitiveMode(domain.CaseInsensitiveOn, storage))
+	assert.False(t, caseInsensitiveMode(domain.CaseInsensitiveOff, storage))
+	readOnly := &mockFileStorage{
+		writeFileFunc: func(relPath string, file io.Reader) error { return os.ErrPermission },
+	}
+	assert.False(t, caseInsensitiveMode(domain.CaseInsensitiveAuto, readOnly), "probe failure means case-sensitive")
+
+	caseInsensitiveMode(domain.CaseInsensitiveAuto, storage)
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "probe file removed")
+
+	t.Run("probe goes through storage", func(t *testing.T) {
+		var written string
+		foldingStorage := &mockFileStorage{
+			basePath: t.TempDir(),
+			writeFileFunc: func(relPath string, file io.Reader) error {
+				written = relPath
+				return nil
+			},
+			statFunc: func(relPath string) (os.FileInfo, error) {
+				if strings.EqualFold(relPath, written) {
+					return nil, nil
+				}
+				return nil, os.ErrNotExist
+			},
+		}
+
+		assert.True(t, caseInsensitiveMode(domain.CaseInsensitiveAuto, foldingStorage))
+		assert.True(t, strings.HasPrefix(written, caseProbePrefix))
+	})
+}
@@ -0,0 +1,47 @@
+package usecases
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // MD5 тут только для сверки с Content-MD5, не для безопасности.
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"file-manager/internal/domain"
+)
+
+// checksumReader считает хеш (MD5 или sha256) по мере чтения и на EOF сверяет его с ожидаемым.
+// при несовпадении вместо EOF отдаёт ошибку, и атомарная запись в хранилище
+// выбрасывает временный файл — битая загрузка на диск не попадает.
+type checksumReader struct {
+	r        io.Reader
+	hash     hash.Hash
+	algo     string
+	expected []byte
+}
+
+func newChecksumReader(r io.Reader, expected []byte) *checksumReader {
+	return newHashReader(r, md5.New(), "md5", expected) //nolint:gosec
+}
+
+func newSHA256Reader(r io.Reader, expected []byte) *checksumReader {
+	return newHashReader(r, sha256.New(), "sha256", expected)
+}
+
+func newHashReader(r io.Reader, h hash.Hash, algo string, expected []byte) *checksumReader {
+	return &checksumReader{r: io.TeeReader(r, h), hash: h, algo: algo, expected: expected}
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		if sum := c.hash.Sum(nil); !bytes.Equal(sum, c.expected) {
+			return n, fmt.Errorf("content %s: got %s, want %s: %w",
+				c.algo, hex.EncodeToString(sum), hex.EncodeToString(c.expected), domain.ErrChecksumMismatch)
+		}
+	}
+	return n, err
+}
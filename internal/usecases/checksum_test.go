@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestChecksumReader(t *testing.T) {
+	content := strings.Repeat("payload", 1000)
+	md5Sum := md5.Sum([]byte(content)) //nolint:gosec
+	sha256Sum := sha256.Sum256([]byte(content))
+
+	tests := []struct {
+		name      string
+		newReader func(r io.Reader, expected []byte) *checksumReader
+		sum       []byte
+	}{
+		{name: "md5", newReader: newChecksumReader, sum: md5Sum[:]},
+		{name: "sha256", newReader: newSHA256Reader, sum: sha256Sum[:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" match", func(t *testing.T) {
+			data, err := io.ReadAll(tt.newReader(strings.NewReader(content), tt.sum))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+		})
+
+		t.Run(tt.name+" mismatch", func(t *testing.T) {
+			_, err := io.ReadAll(tt.newReader(strings.NewReader(content+"x"), tt.sum))
+			assert.True(t, errors.Is(err, domain.ErrChecksumMismatch))
+		})
+	}
+}
+
+func TestFileManagementUseCase_UploadFile_ContentMD5(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	sum := md5.Sum([]byte("good")) //nolint:gosec
+
+	t.Run("mismatch is not persisted", func(t *testing.T) {
+		_, err := uc.UploadFile("bad.txt", strings.NewReader("corrupted"), domain.UploadOptions{ContentMD5: sum[:]})
+
+		assert.True(t, errors.Is(err, domain.ErrChecksumMismatch))
+		assert.NoFileExists(t, filepath.Join(tmpDir, "bad.txt"))
+	})
+
+	t.Run("match", func(t *testing.T) {
+		stored, err := uc.UploadFile("good.txt", strings.NewReader("good"), domain.UploadOptions{ContentMD5: sum[:]})
+
+		require.NoError(t, err)
+		assert.Equal(t, "good.txt", stored)
+		assert.FileExists(t, filepath.Join(tmpDir, "good.txt"))
+	})
+}
+
+func TestFileManagementUseCase_UploadFile_ContentSHA256(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	sum := sha256.Sum256([]byte("good"))
+
+	t.Run("mismatch is not persisted", func(t *testing.T) {
+		_, err := uc.UploadFile("bad.txt", strings.NewReader("corrupted"), domain.UploadOptions{ContentSHA256: sum[:]})
+
+		assert.ErrorIs(t, err, domain.ErrChecksumMismatch)
+		assert.NoFileExists(t, filepath.Join(tmpDir, "bad.txt"))
+	})
+
+	t.Run("match", func(t *testing.T) {
+		stored, err := uc.UploadFile("good.txt", strings.NewReader("good"), domain.UploadOptions{ContentSHA256: sum[:]})
+
+		require.NoError(t, err)
+		assert.Equal(t, "good.txt", stored)
+		assert.FileExists(t, filepath.Join(tmpDir, "good.txt"))
+	})
+}
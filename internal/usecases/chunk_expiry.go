@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ChunkExpirer фоном стирает брошенные загрузки: папки `.chunks/<владелец>/<id>`, в которые ничего
+// не дописывали дольше file.chunk_ttl. без него клиент, не дошедший до finalize, держал бы место вечно.
+type ChunkExpirer struct {
+	uc  *FileManagementUseCase
+	ttl time.Duration
+}
+
+func NewChunkExpirer(uc *FileManagementUseCase, ttl time.Duration) *ChunkExpirer {
+	return &ChunkExpirer{uc: uc, ttl: ttl}
+}
+
+// Run чистит сразу и дальше каждые interval, пока не отменят ctx.
+func (e *ChunkExpirer) Run(ctx context.Context, interval time.Duration) {
+	e.Expire(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.Expire(now)
+		}
+	}
+}
+
+// Expire один проход, возвращает число стёртых загрузок. ошибка только в лог: следующий проход попробует снова.
+func (e *ChunkExpirer) Expire(now time.Time) int {
+	removed, err := e.uc.expireChunks(now.Add(-e.ttl))
+	if err != nil {
+		logrus.Errorf("Failed to expire abandoned uploads: %v", err)
+	}
+	if removed > 0 {
+		logrus.WithField("uploads", removed).Info("Expired abandoned uploads")
+	}
+	return removed
+}
+
+// expireChunks стирает загрузки, последний чанк которых пришёл не позже cutoff. чанк, который дописывают
+// прямо во время прохода в уже просроченную загрузку, может пропасть — finalize тогда вернёт недостающий диапазон.
+func (uc *FileManagementUseCase) expireChunks(cutoff time.Time) (int, error) {
+	owners, err := uc.storage.ReadDirectory(chunkRootDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	for _, owner := range owners {
+		if !owner.IsDir() {
+			continue
+		}
+		ownerDir := filepath.Join(chunkRootDir, owner.Name())
+		uploads, readErr := uc.storage.ReadDirectory(ownerDir)
+		if readErr != nil {
+			return removed, readErr
+		}
+		for _, upload := range uploads {
+			dir := filepath.Join(ownerDir, upload.Name())
+			last, activityErr := uc.lastChunkActivity(dir, upload)
+			if activityErr != nil {
+				return removed, activityErr
+			}
+			if last.After(cutoff) {
+				continue
+			}
+			if err = uc.storage.Remove(dir); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		uc.removeEmptyChunkDirs(ownerDir)
+	}
+	return removed, nil
+}
+
+// lastChunkActivity когда в загрузку последний раз что-то писали: самое свежее время изменения
+// её папки и чанков в ней.
+func (uc *FileManagementUseCase) lastChunkActivity(dir string, info os.FileInfo) (time.Time, error) {
+	last := info.ModTime()
+	if !info.IsDir() {
+		return last, nil
+	}
+	entries, err := uc.storage.ReadDirectory(dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, entry := range entries {
+		if entry.ModTime().After(last) {
+			last = entry.ModTime()
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestChunkExpirer_Expire(t *testing.T) {
+	const ttl = time.Hour
+
+	t.Run("abandoned upload removed, active kept", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, uc.UploadChunk("old", 0, strings.NewReader("aaaa"), domain.ChunkOptions{Owner: "alice"}))
+		require.NoError(t, uc.UploadChunk("new", 0, strings.NewReader("bbbb"), domain.ChunkOptions{Owner: "alice"}))
+		oldDir, err := chunkDir("alice", "old")
+		require.NoError(t, err)
+		stale := time.Now().Add(-2 * ttl)
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, oldDir, "00000000000000000000"), stale, stale))
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, oldDir), stale, stale))
+
+		removed := NewChunkExpirer(uc, ttl).Expire(time.Now())
+
+		assert.Equal(t, 1, removed)
+		assert.NoDirExists(t, filepath.Join(tmpDir, oldDir))
+		newDir, err := chunkDir("alice", "new")
+		require.NoError(t, err)
+		assert.DirExists(t, filepath.Join(tmpDir, newDir))
+	})
+
+	t.Run("fresh chunk keeps an old upload alive", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, uc.UploadChunk("u1", 0, strings.NewReader("aaaa"), domain.ChunkOptions{}))
+		dir, err := chunkDir("", "u1")
+		require.NoError(t, err)
+		stale := time.Now().Add(-2 * ttl)
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, dir), stale, stale))
+
+		assert.Zero(t, NewChunkExpirer(uc, ttl).Expire(time.Now()))
+		assert.DirExists(t, filepath.Join(tmpDir, dir))
+	})
+
+	t.Run("empty chunk dirs removed", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, map[string]string{"keep.txt": "k"})
+		require.NoError(t, uc.UploadChunk("u1", 0, strings.NewReader("aaaa"), domain.ChunkOptions{Owner: "bob"}))
+
+		removed := NewChunkExpirer(uc, ttl).Expire(time.Now().Add(2 * ttl))
+
+		assert.Equal(t, 1, removed)
+		assert.Equal(t, map[string]string{"keep.txt": "k"}, readTree(t, tmpDir))
+	})
+
+	t.Run("no uploads", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		assert.Zero(t, NewChunkExpirer(uc, ttl).Expire(time.Now()))
+	})
+}
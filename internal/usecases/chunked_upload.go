@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// chunkRootDir куда складываются чанки незавершённых загрузок: `.chunks/<владелец>/<id>`. скрытая директория,
+// поэтому в zip и /recent не попадает, а /prune её не трогает.
+const chunkRootDir = ".chunks"
+
+// uploadIDPattern id задаёт клиент, так что он станет именем директории — только безопасные символы.
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type chunkInfo struct {
+	offset int64
+	size   int64
+	name   string
+}
+
+// UploadChunk сохраняет кусок файла по смещению offset. порядок прихода чанков не важен,
+// повторная отправка того же смещения перезаписывает чанк.
+// все чанки загрузки вместе не больше max_upload_size, и ни один не заходит за него: иначе перекрывающимися
+// чанками можно было бы занять диск далеко сверх лимита ещё до finalize.
+func (uc *FileManagementUseCase) UploadChunk(id string, offset int64, chunk io.Reader, opts domain.ChunkOptions) error {
+	dir, err := chunkDir(opts.Owner, id)
+	if err != nil {
+		return err
+	}
+	if offset < 0 {
+		return fmt.Errorf("chunk offset %d: %w", offset, domain.ErrInvalidParameter)
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%020d", offset))
+	if maxSize := uc.cfg.Server.MaxUploadSize; maxSize > 0 {
+		stored, storedErr := uc.storedChunkBytes(dir, filepath.Base(name))
+		if storedErr != nil {
+			return fmt.Errorf("upload '%s': %w", id, storedErr)
+		}
+		limit := min(maxSize-offset, maxSize-stored)
+		if limit <= 0 {
+			return fmt.Errorf("chunk %d of upload '%s' beyond maximum upload size %d: %w",
+				offset, id, maxSize, domain.ErrUnsupportedOperation)
+		}
+		chunk = &sizeLimitReader{r: chunk, limit: limit}
+	}
+	if writeErr := uc.storage.WriteFile(name, chunk); writeErr != nil {
+		return fmt.Errorf("failed to store chunk %d of upload '%s': %w", offset, id, writeErr)
+	}
+	return nil
+}
+
+// FinalizeUpload проверяет, что чанки покрывают [0, ExpectedSize) без дыр, и только тогда
+// склеивает их в path через обычный UploadFile (политика конфликтов, проверки содержимого).
+// при неполной загрузке возвращает *domain.IncompleteUploadError и ничего не пишет.
+func (uc *FileManagementUseCase) FinalizeUpload(id, path string, opts domain.FinalizeOptions) (string, error) {
+	dir, err := chunkDir(opts.Owner, id)
+	if err != nil {
+		return "", err
+	}
+	if opts.ExpectedSize < 0 || opts.ExpectedChunks < 0 {
+		return "", fmt.Errorf("expected size %d, chunks %d: %w",
+			opts.ExpectedSize, opts.ExpectedChunks, domain.ErrInvalidParameter)
+	}
+	if maxSize := uc.cfg.Server.MaxUploadSize; maxSize > 0 && opts.ExpectedSize > maxSize {
+		return "", fmt.Errorf("expected size %d exceeds maximum %d: %w",
+			opts.ExpectedSize, maxSize, domain.ErrUnsupportedOperation)
+	}
+
+	chunks, err := uc.listChunks(dir)
+	if err != nil {
+		return "", fmt.Errorf("upload '%s': %w", id, err)
+	}
+	if err = checkChunks(chunks, opts); err != nil {
+		return "", err
+	}
+
+	reader := &chunkReader{uc: uc, dir: dir, chunks: chunks}
+	defer reader.close()
+
+	storedPath, err := uc.UploadFile(path, reader, opts.Upload)
+	if err != nil {
+		// чанки оставляем: клиент может повторить finalize, например с другой политикой конфликтов.
+		return "", err
+	}
+
+	if removeErr := uc.storage.Remove(dir); removeErr != nil {
+		logrus.Warnf("Failed to remove chunks of upload %s: %v", id, removeErr)
+	}
+	uc.removeEmptyChunkDirs(filepath.Dir(dir))
+
+	return storedPath, nil
+}
+
+// chunkDir папка загрузки id владельца owner. имя пользователя в путь не годится (любые символы, регистр),
+// поэтому владелец — префикс его sha256.
+func chunkDir(owner, id string) (string, error) {
+	if !uploadIDPattern.MatchString(id) {
+		return "", fmt.Errorf("upload id '%s': %w", id, domain.ErrInvalidParameter)
+	}
+	sum := sha256.Sum256([]byte(owner))
+	return filepath.Join(chunkRootDir, hex.EncodeToString(sum[:8]), id), nil
+}
+
+// removeEmptyChunkDirs убирает папку владельца и корень чанков, если загрузок в них не осталось;
+// непустые removeEmptyDir не удалит.
+func (uc *FileManagementUseCase) removeEmptyChunkDirs(ownerDir string) {
+	_ = uc.removeEmptyDir(ownerDir)
+	_ = uc.removeEmptyDir(chunkRootDir)
+}
+
+// storedChunkBytes сколько уже лежит в чанках загрузки, кроме чанка except (его как раз перезаписывают).
+func (uc *FileManagementUseCase) storedChunkBytes(dir, except string) (int64, error) {
+	chunks, err := uc.listChunks(dir)
+	if errors.Is(err, domain.ErrFileNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, c := range chunks {
+		if c.name != except {
+			total += c.size
+		}
+	}
+	return total, nil
+}
+
+func (uc *FileManagementUseCase) listChunks(dir string) ([]chunkInfo, error) {
+	entries, err := uc.storage.ReadDirectory(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no chunks received: %w", domain.ErrFileNotFound)
+		}
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	chunks := make([]chunkInfo, 0, len(entries))
+	for _, entry := range entries {
+		offset, parseErr := strconv.ParseInt(entry.Name(), 10, 64)
+		// временные файлы недописанных чанков и прочий мусор пропускаем.
+		if parseErr != nil || entry.IsDir() {
+			continue
+		}
+		chunks = append(chunks, chunkInfo{offset: offset, size: entry.Size(), name: entry.Name()})
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].offset < chunks[j].offset })
+	return chunks, nil
+}
+
+// checkChunks ищет дыры между чанками и в хвосте. перекрытие — ошибка клиента, а не дыра.
+func checkChunks(chunks []chunkInfo, opts domain.FinalizeOptions) error {
+	var missing []domain.ByteRange
+	var pos, total int64
+	for _, c := range chunks {
+		if c.offset < pos {
+			return fmt.Errorf("chunk at %d overlaps previous chunk ending at %d: %w",
+				c.offset, pos, domain.ErrInvalidParameter)
+		}
+		if c.offset > pos {
+			missing = append(missing, domain.ByteRange{Start: pos, End: c.offset})
+		}
+		pos = c.offset + c.size
+		total += c.size
+	}
+
+	if pos > opts.ExpectedSize {
+		return fmt.Errorf("chunks end at %d, beyond expected size %d: %w",
+			pos, opts.ExpectedSize, domain.ErrInvalidParameter)
+	}
+	if pos < opts.ExpectedSize {
+		missing = append(missing, domain.ByteRange{Start: pos, End: opts.ExpectedSize})
+	}
+
+	countMismatch := opts.ExpectedChunks > 0 && len(chunks) != opts.ExpectedChunks
+	if len(missing) == 0 && !countMismatch {
+		return nil
+	}
+	return &domain.IncompleteUploadError{
+		Missing:        missing,
+		Chunks:         len(chunks),
+		ExpectedChunks: opts.ExpectedChunks,
+		Size:           total,
+		ExpectedSize:   opts.ExpectedSize,
+	}
+}
+
+// chunkReader читает чанки подряд, открывая по одному файлу за раз. открывает через хранилище, а не с диска:
+// в зашифрованном хранилище чанки лежат шифротекстом.
+type chunkReader struct {
+	uc          *FileManagementUseCase
+	dir         string
+	chunks      []chunkInfo
+	current     io.ReadCloser
+	currentName string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.chunks) == 0 {
+				return 0, io.EOF
+			}
+			name := filepath.Join(r.dir, r.chunks[0].name)
+			f, err := r.uc.storage.Open(name)
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.currentName = f, name
+			r.chunks = r.chunks[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.close()
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) close() {
+	if r.current == nil {
+		return
+	}
+	if err := r.current.Close(); err != nil {
+		logrus.Warnf("Failed to close chunk %s: %v", r.currentName, err)
+	}
+	r.current = nil
+}
@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func sendChunks(t *testing.T, uc *FileManagementUseCase, id string, chunks map[int64]string) {
+	t.Helper()
+	for offset, data := range chunks {
+		require.NoError(t, uc.UploadChunk(id, offset, strings.NewReader(data), domain.ChunkOptions{}))
+	}
+}
+
+func TestFileManagementUseCase_FinalizeUpload(t *testing.T) {
+	t.Run("assembles out-of-order chunks and cleans up", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		sendChunks(t, uc, "abc", map[int64]string{6: "world", 0: "hello "})
+
+		stored, err := uc.FinalizeUpload("abc", "greeting.txt",
+			domain.FinalizeOptions{ExpectedSize: 11, ExpectedChunks: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, "greeting.txt", stored)
+		data, err := os.ReadFile(filepath.Join(tmpDir, "greeting.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(data))
+		_, err = os.Stat(filepath.Join(tmpDir, chunkRootDir))
+		assert.True(t, os.IsNotExist(err), "chunk dir should be removed")
+	})
+
+	t.Run("reports gaps and writes nothing", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		sendChunks(t, uc, "gap", map[int64]string{0: "aaaa", 8: "cccc"})
+
+		_, err := uc.FinalizeUpload("gap", "out.bin", domain.FinalizeOptions{ExpectedSize: 16})
+
+		var incomplete *domain.IncompleteUploadError
+		require.True(t, errors.As(err, &incomplete), "got %v", err)
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+		assert.Equal(t, []domain.ByteRange{{Start: 4, End: 8}, {Start: 12, End: 16}}, incomplete.Missing)
+		assert.Equal(t, int64(8), incomplete.Size)
+		_, err = os.Stat(filepath.Join(tmpDir, "out.bin"))
+		assert.True(t, os.IsNotExist(err), "no truncated file")
+		dir, err := chunkDir("", "gap")
+		require.NoError(t, err)
+		_, err = os.Stat(filepath.Join(tmpDir, dir))
+		assert.NoError(t, err, "chunks kept for retry")
+	})
+
+	t.Run("chunk count mismatch", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+		sendChunks(t, uc, "cnt", map[int64]string{0: "ab", 2: "cd"})
+
+		_, err := uc.FinalizeUpload("cnt", "out.bin", domain.FinalizeOptions{ExpectedSize: 4, ExpectedChunks: 3})
+
+		var incomplete *domain.IncompleteUploadError
+		require.True(t, errors.As(err, &incomplete), "got %v", err)
+		assert.Empty(t, incomplete.Missing)
+		assert.Equal(t, 2, incomplete.Chunks)
+	})
+
+	tests := []struct {
+		name    string
+		id      string
+		chunks  map[int64]string
+		opts    domain.FinalizeOptions
+		wantErr error
+	}{
+		{"overlap", "ovl", map[int64]string{0: "abcd", 2: "cdef"},
+			domain.FinalizeOptions{ExpectedSize: 6}, domain.ErrInvalidParameter},
+		{"larger than expected", "big", map[int64]string{0: "abcdef"},
+			domain.FinalizeOptions{ExpectedSize: 4}, domain.ErrInvalidParameter},
+		{"unknown upload", "nope", nil, domain.FinalizeOptions{ExpectedSize: 1}, domain.ErrFileNotFound},
+		{"bad id", "../x", nil, domain.FinalizeOptions{ExpectedSize: 1}, domain.ErrInvalidParameter},
+		{"negative size", "neg", nil, domain.FinalizeOptions{ExpectedSize: -1}, domain.ErrInvalidParameter},
+		{"beyond max upload size", "max", map[int64]string{0: "abcd"},
+			domain.FinalizeOptions{ExpectedSize: 1 << 20}, domain.ErrUnsupportedOperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _ := newLocalUseCase(t)
+			uc.cfg.Server.MaxUploadSize = 1024
+			sendChunks(t, uc, tt.id, tt.chunks)
+
+			_, err := uc.FinalizeUpload(tt.id, "out.bin", tt.opts)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
+
+func TestFileManagementUseCase_UploadChunk(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	opts := domain.ChunkOptions{}
+
+	assert.True(t, errors.Is(uc.UploadChunk("a/b", 0, strings.NewReader("x"), opts), domain.ErrInvalidParameter))
+	assert.True(t, errors.Is(uc.UploadChunk("ok", -1, strings.NewReader("x"), opts), domain.ErrInvalidParameter))
+	assert.NoError(t, uc.UploadChunk("ok", 0, strings.NewReader("x"), opts))
+}
+
+func TestFileManagementUseCase_UploadChunk_MaxSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		sent    map[int64]string
+		offset  int64
+		data    string
+		wantErr error
+	}{
+		{name: "fits", offset: 4, data: "abcd"},
+		{name: "offset at limit", offset: 8, data: "a", wantErr: domain.ErrUnsupportedOperation},
+		{name: "crosses limit", offset: 6, data: "abcd", wantErr: domain.ErrUnsupportedOperation},
+		{name: "resend same offset", sent: map[int64]string{0: "abcdefgh"}, offset: 0, data: "ABCDEFGH"},
+		// перекрывающиеся чанки по отдельности влезают, вместе — нет.
+		{name: "overlapping chunks over total", sent: map[int64]string{0: "abcdef"}, offset: 2, data: "cdef",
+			wantErr: domain.ErrUnsupportedOperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.Server.MaxUploadSize = 8
+			sendChunks(t, uc, "u1", tt.sent)
+
+			err := uc.UploadChunk("u1", tt.offset, strings.NewReader(tt.data), domain.ChunkOptions{})
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			dir, dirErr := chunkDir("", "u1")
+			require.NoError(t, dirErr)
+			assert.NoFileExists(t, filepath.Join(tmpDir, dir, fmt.Sprintf("%020d", tt.offset)),
+				"rejected chunk is not kept")
+		})
+	}
+}
+
+func TestFileManagementUseCase_Chunks_PerOwner(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	alice := domain.ChunkOptions{Owner: "alice"}
+	require.NoError(t, uc.UploadChunk("u1", 0, strings.NewReader("alice"), alice))
+	require.NoError(t, uc.UploadChunk("u1", 0, strings.NewReader("bob!!"), domain.ChunkOptions{Owner: "bob"}))
+
+	_, err := uc.FinalizeUpload("u1", "a.txt", domain.FinalizeOptions{Owner: "carol", ExpectedSize: 5})
+	assert.ErrorIs(t, err, domain.ErrFileNotFound, "someone else's id is not visible")
+
+	_, err = uc.FinalizeUpload("u1", "a.txt", domain.FinalizeOptions{Owner: "alice", ExpectedSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", readTree(t, tmpDir)["a.txt"])
+
+	_, err = uc.FinalizeUpload("u1", "b.txt", domain.FinalizeOptions{Owner: "bob", ExpectedSize: 5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a.txt": "alice", "b.txt": "bob!!"}, readTree(t, tmpDir),
+		"chunk dirs removed after the last upload")
+}
@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// compareChunkSize размер буфера при побайтовом сравнении файлов.
+const compareChunkSize = 32 * 1024
+
+// Compare сравнивает два файла или две директории на сервере.
+// файлы: сначала размер, потом содержимое потоком. директории: по хешу дерева (dirHash).
+// всё читается через хранилище, так что у зашифрованного сравнивается открытый текст.
+func (uc *FileManagementUseCase) Compare(pathA, pathB string) (bool, error) {
+	relA, infoA, err := uc.statForCompare(pathA)
+	if err != nil {
+		return false, err
+	}
+	relB, infoB, err := uc.statForCompare(pathB)
+	if err != nil {
+		return false, err
+	}
+
+	if infoA.IsDir() != infoB.IsDir() {
+		return false, nil
+	}
+
+	if infoA.IsDir() {
+		hashA, hashErr := uc.dirHash(relA)
+		if hashErr != nil {
+			return false, fmt.Errorf("failed to hash folder '%s': %w", pathA, hashErr)
+		}
+		hashB, hashErr := uc.dirHash(relB)
+		if hashErr != nil {
+			return false, fmt.Errorf("failed to hash folder '%s': %w", pathB, hashErr)
+		}
+		return bytes.Equal(hashA, hashB), nil
+	}
+
+	// короткий путь: разный размер — точно разные файлы, читать ничего не надо.
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	equal, err := uc.compareFiles(relA, relB)
+	if err != nil {
+		return false, fmt.Errorf("failed to compare '%s' and '%s': %w", pathA, pathB, err)
+	}
+	return equal, nil
+}
+
+func (uc *FileManagementUseCase) statForCompare(path string) (string, os.FileInfo, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return "", nil, fmt.Errorf("compare in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("could not stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return "", nil, fmt.Errorf("failed to stat '%s': %w", sanitizedPath, err)
+	}
+	return sanitizedPath, info, nil
+}
+
+func (uc *FileManagementUseCase) compareFiles(pathA, pathB string) (bool, error) {
+	fileA, err := uc.storage.Open(pathA)
+	if err != nil {
+		return false, err
+	}
+	defer closeLogged(fileA, pathA)
+
+	fileB, err := uc.storage.Open(pathB)
+	if err != nil {
+		return false, err
+	}
+	defer closeLogged(fileB, pathB)
+
+	bufA := make([]byte, compareChunkSize)
+	bufB := make([]byte, compareChunkSize)
+	for {
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		if !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+
+		doneA := errors.Is(errA, io.EOF) || errors.Is(errA, io.ErrUnexpectedEOF)
+		doneB := errors.Is(errB, io.EOF) || errors.Is(errB, io.ErrUnexpectedEOF)
+		switch {
+		case doneA && doneB:
+			return true, nil
+		case doneA != doneB:
+			// файл поменяли между stat и чтением.
+			return false, nil
+		case errA != nil:
+			return false, errA
+		case errB != nil:
+			return false, errB
+		}
+	}
+}
+
+// dirHash считает хеш дерева: относительные пути в лексикографическом порядке обхода
+// плюс sha256 содержимого каждого файла. скрытые файлы пропускаются, как в zip.
+func (uc *FileManagementUseCase) dirHash(relRoot string) ([]byte, error) {
+	tree := sha256.New()
+	fmt.Fprintf(tree, "d %s\x00", domain.PathCurrent)
+	if err := uc.hashDirInto(tree, relRoot, ""); err != nil {
+		return nil, err
+	}
+	return tree.Sum(nil), nil
+}
+
+func (uc *FileManagementUseCase) hashDirInto(tree io.Writer, dir, relDir string) error {
+	entries, err := uc.storage.ReadDirectory(dir)
+	if err != nil {
+		return err
+	}
+	// порядок как у filepath.Walk, хранилище сортировку не обещает.
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, info := range entries {
+		if uc.shouldSkipFile(info) {
+			continue
+		}
+		storageRel := filepath.Join(dir, info.Name())
+		rel := path.Join(relDir, info.Name())
+		if info.IsDir() {
+			fmt.Fprintf(tree, "d %s\x00", rel)
+			if err = uc.hashDirInto(tree, storageRel, rel); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sum, err := uc.hashStored(storageRel)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tree, "f %s\x00%x\x00", rel, sum)
+	}
+	return nil
+}
+
+// hashStored sha256 файла хранилища: у зашифрованного это sha256 открытого текста.
+func (uc *FileManagementUseCase) hashStored(relPath string) ([]byte, error) {
+	f, err := uc.storage.Open(relPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLogged(f, relPath)
+	return hashReader(f)
+}
+
+// readStored файл хранилища целиком.
+func (uc *FileManagementUseCase) readStored(relPath string) ([]byte, error) {
+	f, err := uc.storage.Open(relPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closeLogged(f, relPath)
+	return io.ReadAll(f)
+}
+
+func hashReader(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func closeLogged(c io.Closer, path string) {
+	if err := c.Close(); err != nil {
+		logrus.Warnf("Failed to close file %s: %v", path, err)
+	}
+}
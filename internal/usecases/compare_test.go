@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		full := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+}
+
+func TestFileManagementUseCase_Compare(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	big := strings.Repeat("x", compareChunkSize*2+10)
+	writeTree(t, tmpDir, map[string]string{
+		"a.txt":          "same content",
+		"b.txt":          "same content",
+		"c.txt":          "diff content",
+		"short.txt":      "short",
+		"big1.bin":       big + "A",
+		"big2.bin":       big + "B",
+		"dir1/x.txt":     "x",
+		"dir1/sub/y.txt": "y",
+		"dir1/.hidden":   "ignored",
+		"dir2/x.txt":     "x",
+		"dir2/sub/y.txt": "y",
+		"dir3/x.txt":     "x",
+		"dir3/sub/y.txt": "changed",
+		"dir4/x.txt":     "x",
+		"dir4/y.txt":     "y",
+	})
+
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"equal files", "a.txt", "b.txt", true},
+		{"same size different content", "a.txt", "c.txt", false},
+		{"different size", "a.txt", "short.txt", false},
+		{"difference in later chunk", "big1.bin", "big2.bin", false},
+		{"equal folders ignoring hidden", "dir1", "dir2", true},
+		{"folders with changed file", "dir1", "dir3", false},
+		{"folders with moved file", "dir1", "dir4", false},
+		{"file vs folder", "a.txt", "dir1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := uc.Compare(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.equal, equal)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := uc.Compare("a.txt", "missing.txt")
+		assert.True(t, errors.Is(err, domain.ErrFileNotFound))
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		_, err := uc.Compare("a.txt", "../etc/passwd")
+		assert.True(t, errors.Is(err, domain.ErrPathTraversal))
+	})
+}
+
+func TestFileManagementUseCase_Compare_ThroughStorage(t *testing.T) {
+	// на диске пусто: у зашифрованного там шифротекст со случайным nonce, сравнивать надо открытый текст.
+	uc, _ := newLocalUseCase(t)
+	uc.storage = newMapStorage(t, fstest.MapFS{
+		"a.txt":      {Data: []byte("same")},
+		"b.txt":      {Data: []byte("same")},
+		"c.txt":      {Data: []byte("diff")},
+		"d1/x.txt":   {Data: []byte("x")},
+		"d1/s/y.txt": {Data: []byte("y")},
+		"d2/x.txt":   {Data: []byte("x")},
+		"d2/s/y.txt": {Data: []byte("y")},
+		"d3/x.txt":   {Data: []byte("x")},
+		"d3/s/y.txt": {Data: []byte("z")},
+	})
+
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"a.txt", "b.txt", true},
+		{"a.txt", "c.txt", false},
+		{"d1", "d2", true},
+		{"d1", "d3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			equal, err := uc.Compare(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.equal, equal)
+		})
+	}
+
+	_, err := uc.Compare("a.txt", "nope.txt")
+	assert.ErrorIs(t, err, domain.ErrFileNotFound)
+}
@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// maxConflictSuffix ограничивает перебор свободных имён, чтобы не крутиться бесконечно
+// в директории, забитой `file (1).txt` ... `file (N).txt`.
+const maxConflictSuffix = 1000
+
+// uploadConflict политика загрузки: из запроса, а если там пусто — file.overwrite_policy.
+func (uc *FileManagementUseCase) uploadConflict(policy domain.ConflictPolicy) domain.ConflictPolicy {
+	if policy != "" {
+		return policy
+	}
+	switch uc.cfg.File.OverwritePolicy {
+	case domain.OverwritePolicyReject:
+		return domain.ConflictError
+	case domain.OverwritePolicyRename:
+		return domain.ConflictRename
+	default:
+		return domain.ConflictOverwrite
+	}
+}
+
+// conflictPolicies политики, которые принимает сервер: version только без file.disable_versioning.
+func (uc *FileManagementUseCase) conflictPolicies() []domain.ConflictPolicy {
+	policies := []domain.ConflictPolicy{domain.ConflictOverwrite, domain.ConflictRename, domain.ConflictError}
+	if !uc.cfg.File.DisableVersioning {
+		policies = append(policies, domain.ConflictVersion)
+	}
+	return policies
+}
+
+// checkConflictPolicy пустая политика — умолчание, неизвестная — 400, выключенная в конфиге — 403.
+func (uc *FileManagementUseCase) checkConflictPolicy(policy domain.ConflictPolicy) error {
+	if policy == "" || slices.Contains(uc.conflictPolicies(), policy) {
+		return nil
+	}
+	if policy == domain.ConflictVersion {
+		return fmt.Errorf("conflict policy '%s' is disabled: %w", policy, domain.ErrUnsupportedOperation)
+	}
+	return fmt.Errorf("unknown conflict policy '%s': %w", policy, domain.ErrInvalidParameter)
+}
+
+// conflictPlan куда писать файл и, для политики version, какой файл и куда отодвинуть.
+// versioned пустой — отодвигать нечего.
+type conflictPlan struct {
+	target    string
+	current   string
+	versioned string
+}
+
+// resolveConflict применяет политику конфликтов и возвращает путь, куда надо писать файл.
+// с политикой version старый файл отодвигается сразу; загрузка делает это сама после записи, см. UploadFile.
+func (uc *FileManagementUseCase) resolveConflict(path string, policy domain.ConflictPolicy) (string, error) {
+	plan, err := uc.planConflict(path, policy)
+	if err != nil {
+		return "", err
+	}
+	if err = uc.moveAside(plan); err != nil {
+		return "", err
+	}
+	return plan.target, nil
+}
+
+// moveAside отодвигает старый файл под версионное имя по плану.
+func (uc *FileManagementUseCase) moveAside(plan conflictPlan) error {
+	if plan.versioned == "" {
+		return nil
+	}
+	if err := uc.storage.Move(plan.current, plan.versioned); err != nil {
+		return fmt.Errorf("could not version '%s' as '%s': %w", plan.current, plan.versioned, err)
+	}
+	return nil
+}
+
+// planConflict решает, куда писать по политике, ничего не трогая в хранилище.
+func (uc *FileManagementUseCase) planConflict(path string, policy domain.ConflictPolicy) (conflictPlan, error) {
+	if err := uc.checkConflictPolicy(policy); err != nil {
+		return conflictPlan{}, err
+	}
+
+	collision, err := uc.caseCollision(path)
+	if err != nil {
+		return conflictPlan{}, fmt.Errorf("failed to check '%s': %w", path, err)
+	}
+	// перезапись того же имени — обычное поведение, а вот `file.txt` поверх `File.txt` скорее ошибка.
+	overwrite := policy == "" || policy == domain.ConflictOverwrite
+	if collision != "" && overwrite {
+		return conflictPlan{}, fmt.Errorf("file '%s' would replace '%s' on a case-insensitive storage: %w",
+			path, collision, domain.ErrAlreadyExists)
+	}
+	if collision != "" && policy == domain.ConflictError {
+		return conflictPlan{}, fmt.Errorf("file '%s' would replace '%s' on a case-insensitive storage: %w",
+			path, collision, uc.conflictError(filepath.Join(filepath.Dir(path), collision)))
+	}
+	if overwrite {
+		return conflictPlan{target: path}, nil
+	}
+
+	exists, err := uc.exists(path)
+	if err != nil {
+		return conflictPlan{}, fmt.Errorf("failed to check '%s': %w", path, err)
+	}
+	if !exists {
+		return conflictPlan{target: path}, nil
+	}
+
+	switch policy {
+	case domain.ConflictError:
+		return conflictPlan{}, fmt.Errorf("file '%s': %w", path, uc.conflictError(path))
+	case domain.ConflictRename:
+		target, freeErr := uc.freeName(path, func(base, ext string, n int) string {
+			return fmt.Sprintf("%s (%d)%s", base, n, ext)
+		})
+		return conflictPlan{target: target}, freeErr
+	default:
+		// старый файл уезжает под версионное имя, новый ложится на его место.
+		versioned, freeErr := uc.freeName(path, func(base, ext string, n int) string {
+			return fmt.Sprintf("%s.v%d%s", base, n, ext)
+		})
+		if freeErr != nil {
+			return conflictPlan{}, freeErr
+		}
+		current := path
+		if collision != "" {
+			current = filepath.Join(filepath.Dir(path), collision)
+		}
+		return conflictPlan{target: path, current: current, versioned: versioned}, nil
+	}
+}
+
+// conflictError описывает то, что уже лежит по path. не вышло сделать stat — хотя бы голый ErrAlreadyExists.
+func (uc *FileManagementUseCase) conflictError(path string) error {
+	info, err := uc.storage.Stat(path)
+	if err != nil {
+		return domain.ErrAlreadyExists
+	}
+	return &domain.ExistsError{Existing: domain.FileData{
+		Name:    info.Name(),
+		IsDir:   info.IsDir(),
+		Path:    filepath.ToSlash(path),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}}
+}
+
+// freeName подбирает первое свободное имя рядом с path по заданному шаблону.
+// кандидат прогоняется через sanitizePath, чтобы не создать файл, к которому потом не достучаться.
+func (uc *FileManagementUseCase) freeName(path string, format func(base, ext string, n int) string) (string, error) {
+	dir := filepath.Dir(path)
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(filepath.Base(path), ext)
+
+	for n := 1; n <= maxConflictSuffix; n++ {
+		candidate, err := uc.sanitizePath(filepath.Join(dir, format(base, ext, n)))
+		if err != nil {
+			return "", err
+		}
+
+		exists, err := uc.exists(candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check '%s': %w", candidate, err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("no free name for '%s': %w", path, domain.ErrAlreadyExists)
+}
+
+// exists с учётом file.case_insensitive: `File.txt` занимает и имя `file.txt`.
+func (uc *FileManagementUseCase) exists(relPath string) (bool, error) {
+	_, err := uc.storage.Stat(relPath)
+	if err == nil {
+		return true, nil
+	}
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+	collision, err := uc.caseCollision(relPath)
+	return collision != "", err
+}
@@ -0,0 +1,253 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_UploadFile_Conflict(t *testing.T) {
+	t.Run("overwrite by default", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("old"), 0o644))
+
+		storedPath, err := uc.UploadFile("file.txt", strings.NewReader("new"), domain.UploadOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "file.txt", storedPath)
+		data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	})
+
+	t.Run("error policy", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("old"), 0o644))
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("new"),
+			domain.UploadOptions{Conflict: domain.ConflictError})
+
+		assert.True(t, errors.Is(err, domain.ErrAlreadyExists))
+	})
+
+	t.Run("error policy without conflict", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		storedPath, err := uc.UploadFile("file.txt", strings.NewReader("new"),
+			domain.UploadOptions{Conflict: domain.ConflictError})
+
+		require.NoError(t, err)
+		assert.Equal(t, "file.txt", storedPath)
+	})
+
+	t.Run("rename policy", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("old"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file (1).txt"), []byte("old1"), 0o644))
+
+		storedPath, err := uc.UploadFile("file.txt", strings.NewReader("new"),
+			domain.UploadOptions{Conflict: domain.ConflictRename})
+
+		require.NoError(t, err)
+		assert.Equal(t, "file (2).txt", storedPath)
+		data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+	})
+
+	t.Run("version policy keeps history", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("first"), 0o644))
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("second"),
+			domain.UploadOptions{Conflict: domain.ConflictVersion})
+		require.NoError(t, err)
+		storedPath, err := uc.UploadFile("file.txt", strings.NewReader("third"),
+			domain.UploadOptions{Conflict: domain.ConflictVersion})
+		require.NoError(t, err)
+
+		assert.Equal(t, "file.txt", storedPath)
+		for name, want := range map[string]string{
+			"file.txt":    "third",
+			"file.v1.txt": "first",
+			"file.v2.txt": "second",
+		} {
+			data, readErr := os.ReadFile(filepath.Join(tmpDir, name))
+			require.NoError(t, readErr)
+			assert.Equal(t, want, string(data), name)
+		}
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("new"),
+			domain.UploadOptions{Conflict: "merge"})
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+	})
+
+	t.Run("failed versioned upload keeps the original", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("first"), 0o644))
+		badSum := sha256.Sum256([]byte("something else"))
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("second"),
+			domain.UploadOptions{Conflict: domain.ConflictVersion, ContentSHA256: badSum[:]})
+
+		assert.ErrorIs(t, err, domain.ErrChecksumMismatch)
+		assert.Equal(t, map[string]string{"file.txt": "first"}, readTree(t, tmpDir),
+			"no .v1 and no temp file left behind")
+	})
+
+	t.Run("version policy disabled", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		uc.cfg.File.DisableVersioning = true
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("first"), 0o644))
+
+		_, err := uc.UploadFile("file.txt", strings.NewReader("second"),
+			domain.UploadOptions{Conflict: domain.ConflictVersion})
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedOperation)
+		data, readErr := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+		require.NoError(t, readErr)
+		assert.Equal(t, "first", string(data))
+	})
+}
+
+func TestFileManagementUseCase_UploadFile_OverwritePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		conflict domain.ConflictPolicy
+		wantPath string
+		wantErr  error
+		wantOld  string
+	}{
+		{name: "overwrite", policy: domain.OverwritePolicyOverwrite, wantPath: "file.txt", wantOld: "new"},
+		{name: "reject", policy: domain.OverwritePolicyReject, wantErr: domain.ErrAlreadyExists, wantOld: "old"},
+		{name: "rename", policy: domain.OverwritePolicyRename, wantPath: "file (1).txt", wantOld: "old"},
+		{
+			name:     "request conflict wins",
+			policy:   domain.OverwritePolicyReject,
+			conflict: domain.ConflictOverwrite,
+			wantPath: "file.txt",
+			wantOld:  "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.File.OverwritePolicy = tt.policy
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte("old"), 0o644))
+
+			storedPath, err := uc.UploadFile("file.txt", strings.NewReader("new"),
+				domain.UploadOptions{Conflict: tt.conflict})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantPath, storedPath)
+			}
+			data, err := os.ReadFile(filepath.Join(tmpDir, "file.txt"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOld, string(data))
+		})
+	}
+}
+
+func TestFileManagementUseCase_Rename_Conflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    domain.ConflictPolicy
+		wantPath  string
+		wantErr   error
+		wantFiles map[string]string
+	}{
+		{
+			name:      "overwrite",
+			policy:    domain.ConflictOverwrite,
+			wantPath:  "b.txt",
+			wantFiles: map[string]string{"b.txt": "a"},
+		},
+		{
+			name:      "rename",
+			policy:    domain.ConflictRename,
+			wantPath:  "b (1).txt",
+			wantFiles: map[string]string{"b.txt": "b", "b (1).txt": "a"},
+		},
+		{
+			name:      "version",
+			policy:    domain.ConflictVersion,
+			wantPath:  "b.txt",
+			wantFiles: map[string]string{"b.txt": "a", "b.v1.txt": "b"},
+		},
+		{
+			name:      "error",
+			policy:    domain.ConflictError,
+			wantErr:   domain.ErrAlreadyExists,
+			wantFiles: map[string]string{"a.txt": "a", "b.txt": "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			writeTree(t, tmpDir, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+			finalPath, err := uc.Rename("a.txt", "b.txt", tt.policy)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantPath, finalPath)
+			}
+			for name, content := range tt.wantFiles {
+				data, readErr := os.ReadFile(filepath.Join(tmpDir, name))
+				require.NoError(t, readErr, name)
+				assert.Equal(t, content, string(data), name)
+			}
+		})
+	}
+
+	t.Run("rename to itself is not a conflict", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, map[string]string{"a.txt": "a"})
+
+		finalPath, err := uc.Rename("a.txt", "a.txt", domain.ConflictRename)
+
+		require.NoError(t, err)
+		assert.Equal(t, "a.txt", finalPath)
+		assert.FileExists(t, filepath.Join(tmpDir, "a.txt"))
+	})
+	t.Run("error describes the existing item", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, map[string]string{"a.txt": "a", "docs/b.txt": "existing"})
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "docs/a.txt"), []byte("a"), 0o644))
+
+		_, err := uc.Rename("docs/a.txt", "docs/b.txt", domain.ConflictError)
+
+		var conflict *domain.ExistsError
+		require.ErrorAs(t, err, &conflict)
+		assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+		assert.Equal(t, "docs/b.txt", conflict.Existing.Path)
+		assert.Equal(t, "b.txt", conflict.Existing.Name)
+		assert.Equal(t, int64(len("existing")), conflict.Existing.Size)
+		assert.False(t, conflict.Existing.IsDir)
+		assert.False(t, conflict.Existing.ModTime.IsZero())
+
+		_, err = uc.Rename("a.txt", "docs", domain.ConflictError)
+		require.ErrorAs(t, err, &conflict)
+		assert.True(t, conflict.Existing.IsDir)
+	})
+}
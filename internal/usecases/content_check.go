@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// sniffLen столько байт смотрит http.DetectContentType.
+const sniffLen = 512
+
+const categoryApplication = "application"
+
+// extensionCategories ожидаемая категория (верхний уровень MIME) по расширению.
+// сюда попадают только форматы, которые DetectContentType узнаёт надёжно: например, svg
+// он видит как text/xml, а ogg как application/ogg, поэтому их тут нет.
+// расширения не из списка не проверяются.
+var extensionCategories = map[string]string{
+	".jpg":  "image",
+	".jpeg": "image",
+	".png":  "image",
+	".gif":  "image",
+	".webp": "image",
+	".bmp":  "image",
+	".ico":  "image",
+	".wav":  "audio",
+	".mp3":  "audio",
+	".mp4":  "video",
+	".webm": "video",
+	".avi":  "video",
+	".txt":  "text",
+	".csv":  "text",
+	".md":   "text",
+	".log":  "text",
+	".html": "text",
+	".pdf":  categoryApplication,
+	".zip":  categoryApplication,
+}
+
+// checkContentType сверяет начало содержимого с расширением и возвращает reader,
+// который отдаёт файл целиком, включая уже прочитанные для сниффинга байты.
+func (uc *FileManagementUseCase) checkContentType(path string, file io.Reader) (io.Reader, error) {
+	mode := uc.cfg.File.ContentTypeCheck
+	if mode == "" || mode == domain.ContentCheckOff {
+		return file, nil
+	}
+
+	expected, known := extensionCategories[strings.ToLower(filepath.Ext(path))]
+	if !known {
+		return file, nil
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read upload header: %w", err)
+	}
+	head = head[:n]
+	restored := io.MultiReader(bytes.NewReader(head), file)
+
+	// пустой файл DetectContentType считает текстом, сверять нечего.
+	if n == 0 {
+		return restored, nil
+	}
+
+	detected := http.DetectContentType(head)
+	category, _, _ := strings.Cut(detected, "/")
+	if category == expected {
+		return restored, nil
+	}
+	// lenient ловит только классику: исполняемый/бинарный файл под видом картинки или текста.
+	if mode == domain.ContentCheckLenient && category != categoryApplication {
+		return restored, nil
+	}
+
+	return nil, fmt.Errorf("file '%s' looks like %s, expected %s/*: %w",
+		path, detected, expected, domain.ErrUnsupportedOperation)
+}
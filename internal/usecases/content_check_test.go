@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+var (
+	pngHeader = "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+	elfHeader = "\x7fELF\x02\x01\x01" + strings.Repeat("\x00", 16)
+)
+
+func TestFileManagementUseCase_UploadFile_ContentTypeCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		file    string
+		content string
+		wantErr bool
+	}{
+		{"off allows spoofed image", domain.ContentCheckOff, "a.jpg", elfHeader, false},
+		{"lenient rejects binary as image", domain.ContentCheckLenient, "a.jpg", elfHeader, true},
+		{"lenient rejects binary as text", domain.ContentCheckLenient, "notes.txt", elfHeader, true},
+		{"lenient allows text as image", domain.ContentCheckLenient, "a.png", "just text", false},
+		{"strict rejects text as image", domain.ContentCheckStrict, "a.png", "just text", true},
+		{"strict allows real png", domain.ContentCheckStrict, "a.png", pngHeader, false},
+		{"extension is case insensitive", domain.ContentCheckStrict, "A.PNG", elfHeader, true},
+		{"unknown extension not checked", domain.ContentCheckStrict, "tool.bin", elfHeader, false},
+		{"empty file not checked", domain.ContentCheckStrict, "empty.jpg", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.File.ContentTypeCheck = tt.mode
+
+			_, err := uc.UploadFile(tt.file, strings.NewReader(tt.content), domain.UploadOptions{})
+
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, domain.ErrUnsupportedOperation), "got %v", err)
+				assert.NoFileExists(t, filepath.Join(tmpDir, tt.file))
+				return
+			}
+			require.NoError(t, err)
+			// байты, прочитанные для сниффинга, должны попасть в файл.
+			data, readErr := os.ReadFile(filepath.Join(tmpDir, tt.file))
+			require.NoError(t, readErr)
+			assert.Equal(t, tt.content, string(data))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// CreateTree создаёт под parent набор подпапок (например src, docs, tests) одним вызовом.
+// сначала проверяются все пути, и только потом что-то создаётся; если создание упало посередине,
+// папки, которых до вызова не было, удаляются обратно.
+func (uc *FileManagementUseCase) CreateTree(parent string, subdirs []string) error {
+	if len(subdirs) == 0 {
+		return fmt.Errorf("no subdirectories given: %w", domain.ErrInvalidParameter)
+	}
+
+	sanitizedParent, err := uc.sanitizePath(parent)
+	if err != nil {
+		return err
+	}
+	if componentsErr := uc.validateComponents(sanitizedParent); componentsErr != nil {
+		return componentsErr
+	}
+
+	targets := make([]string, 0, len(subdirs))
+	for _, sub := range subdirs {
+		target, subErr := uc.treeTarget(sanitizedParent, sub)
+		if subErr != nil {
+			return subErr
+		}
+		targets = append(targets, target)
+	}
+
+	created := uc.missingDirs(targets)
+	for _, target := range targets {
+		if createErr := uc.storage.CreateDirectory(target); createErr != nil {
+			uc.rollbackDirs(created)
+			return fmt.Errorf("could not create folder '%s': %w", target, createErr)
+		}
+	}
+	return nil
+}
+
+// treeTarget путь подпапки относительно корня; подпапка может быть вложенной (src/main), но не выходить из parent.
+func (uc *FileManagementUseCase) treeTarget(parent, sub string) (string, error) {
+	clean := filepath.Clean(sub)
+	if sub == "" || clean == domain.PathCurrent || filepath.IsAbs(clean) ||
+		clean == domain.PathTraversalPrefix ||
+		strings.HasPrefix(clean, domain.PathTraversalPrefix+string(filepath.Separator)) {
+		return "", fmt.Errorf("subdirectory '%s' must stay inside '%s': %w", sub, parent, domain.ErrInvalidName)
+	}
+
+	target, err := uc.sanitizePath(filepath.Join(parent, clean))
+	if err != nil {
+		return "", err
+	}
+	if componentsErr := uc.validateComponents(target); componentsErr != nil {
+		return "", componentsErr
+	}
+	return target, nil
+}
+
+// missingDirs все ещё не существующие директории, которые появятся при создании targets (вместе с промежуточными).
+func (uc *FileManagementUseCase) missingDirs(targets []string) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, target := range targets {
+		for dir := target; dir != domain.PathCurrent && !seen[dir]; dir = filepath.Dir(dir) {
+			seen[dir] = true
+			if _, err := uc.storage.Stat(dir); os.IsNotExist(err) {
+				missing = append(missing, dir)
+			}
+		}
+	}
+	return missing
+}
+
+// rollbackDirs удаляет созданные папки, начиная с самых глубоких. папку, в которую кто-то уже успел
+// что-то положить, removeEmptyDir не тронет.
+func (uc *FileManagementUseCase) rollbackDirs(dirs []string) {
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	for _, dir := range dirs {
+		err := uc.removeEmptyDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to roll back folder %s: %v", dir, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_CreateTree(t *testing.T) {
+	t.Run("creates all subdirs", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+
+		require.NoError(t, uc.CreateTree("project", []string{"src", "docs", "tests/unit"}))
+
+		for _, dir := range []string{"project/src", "project/docs", "project/tests/unit"} {
+			info, err := os.Stat(filepath.Join(tmpDir, dir))
+			require.NoError(t, err, dir)
+			assert.True(t, info.IsDir())
+		}
+	})
+
+	tests := []struct {
+		name    string
+		parent  string
+		subdirs []string
+		wantErr error
+	}{
+		{"invalid component", "project", []string{"src", "bad*name"}, domain.ErrInvalidName},
+		{"escapes parent", "project", []string{"src", "../other"}, domain.ErrInvalidName},
+		{"absolute subdir", "project", []string{"/etc"}, domain.ErrInvalidName},
+		{"empty subdir", "project", []string{"src", ""}, domain.ErrInvalidName},
+		{"no subdirs", "project", nil, domain.ErrInvalidParameter},
+		{"parent traversal", "../x", []string{"src"}, domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+
+			err := uc.CreateTree(tt.parent, tt.subdirs)
+
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			entries, readErr := os.ReadDir(tmpDir)
+			require.NoError(t, readErr)
+			assert.Empty(t, entries, "nothing is created when validation fails")
+		})
+	}
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "project", "existing"), 0o755))
+		storage := hookStorage(uc)
+		storage.createDirectoryFunc = func(relPath string) error {
+			if relPath == filepath.Join("project", "docs") {
+				return errors.New("disk full")
+			}
+			return storage.FileStorage.CreateDirectory(relPath)
+		}
+
+		err := uc.CreateTree("project", []string{"src/main", "existing", "docs"})
+
+		require.Error(t, err)
+		_, statErr := os.Stat(filepath.Join(tmpDir, "project", "src"))
+		assert.True(t, os.IsNotExist(statErr), "created dirs are removed")
+		_, statErr = os.Stat(filepath.Join(tmpDir, "project", "existing"))
+		assert.NoError(t, statErr, "pre-existing dirs are kept")
+	})
+}
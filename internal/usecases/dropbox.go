@@ -0,0 +1,25 @@
+package usecases
+
+import (
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// isDropBox проверяет, лежит ли путь внутри директории-"почтового ящика" из file.dropbox_dirs.
+// в такие директории можно только загружать: листинг и скачивание запрещены.
+func (uc *FileManagementUseCase) isDropBox(relPath string) bool {
+	clean := filepath.Clean(relPath)
+	for _, dir := range uc.cfg.File.DropBoxDirs {
+		dropBox := filepath.Clean(dir)
+		// корень дроп-боксом быть не может, иначе пропадёт весь листинг.
+		if dropBox == domain.PathCurrent {
+			continue
+		}
+		if clean == dropBox || strings.HasPrefix(clean, dropBox+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
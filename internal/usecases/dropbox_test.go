@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_isDropBox(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	uc.cfg.File.DropBoxDirs = []string{"inbox", "team/submissions/", "."}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"inbox", true},
+		{"inbox/report.pdf", true},
+		{"team/submissions", true},
+		{"team/submissions/a/b.txt", true},
+		{"inbox-old", false},
+		{"team", false},
+		{"", false},
+		{"docs/inbox", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, uc.isDropBox(tt.path))
+		})
+	}
+}
+
+func TestFileManagementUseCase_DropBox(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.DropBoxDirs = []string{"inbox"}
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "inbox"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "public.txt"), []byte("public"), 0o644))
+
+	t.Run("upload allowed", func(t *testing.T) {
+		_, err := uc.UploadFile("inbox/secret.txt", strings.NewReader("secret"), domain.UploadOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("list denied", func(t *testing.T) {
+		_, err := uc.List("inbox", domain.ListOptions{})
+		assert.True(t, errors.Is(err, domain.ErrPermissionDenied))
+	})
+
+	t.Run("download denied", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := uc.ServeFile(w, httptest.NewRequest("GET", "/download", nil), "inbox/secret.txt")
+		assert.True(t, errors.Is(err, domain.ErrPermissionDenied))
+	})
+
+	t.Run("folder download denied", func(t *testing.T) {
+		err := uc.ServeFolderAsZip(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), "inbox")
+		assert.True(t, errors.Is(err, domain.ErrPermissionDenied))
+	})
+
+	t.Run("parent zip skips drop-box", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), ""))
+
+		body := w.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"public.txt"}, names)
+	})
+}
@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"file-manager/internal/domain"
+)
+
+// FindDuplicates ищет одинаковые файлы под path: sha256 -> пути (от корня хранилища) для групп из двух и больше.
+// сначала файлы группируются по размеру, хешируются только совпавшие по размеру. пустые файлы не считаются,
+// скрытые и drop-box пропускаются как в zip. обход тяжёлый, поэтому прерывается по ctx и лимитам обхода.
+func (uc *FileManagementUseCase) FindDuplicates(
+	ctx context.Context, path string,
+) (groups map[string][]string, truncated bool, err error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, false, fmt.Errorf("find duplicates in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return nil, false, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	bySize := make(map[int64][]string)
+	truncated, err = uc.guardedWalk(sanitizedPath, func(_, rel string, info os.FileInfo) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !info.IsDir() && info.Size() > 0 {
+			bySize[info.Size()] = append(bySize[info.Size()], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to walk '%s': %w", sanitizedPath, err)
+	}
+
+	result := make(map[string][]string)
+	for _, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		byHash := make(map[string][]string, len(candidates))
+		for _, rel := range candidates {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, false, fmt.Errorf("find duplicates in '%s': %w", sanitizedPath, ctxErr)
+			}
+			sum, hashErr := uc.hashStored(filepath.Join(sanitizedPath, rel))
+			if hashErr != nil {
+				return nil, false, fmt.Errorf("hash '%s': %w", rel, hashErr)
+			}
+			key := hex.EncodeToString(sum)
+			byHash[key] = append(byHash[key], filepath.ToSlash(filepath.Join(sanitizedPath, rel)))
+		}
+		for sum, paths := range byHash {
+			if len(paths) > 1 {
+				slices.Sort(paths)
+				result[sum] = paths
+			}
+		}
+	}
+	return result, truncated, nil
+}
@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_FindDuplicates(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"docs/a.txt":       "same content",
+		"docs/sub/b.txt":   "same content",
+		"c.txt":            "same content",
+		"docs/d.txt":       "diff content", // тот же размер, другой хеш
+		"docs/e.txt":       "unique and longer",
+		"docs/.hidden.txt": "same content",
+		"docs/empty1.txt":  "",
+		"docs/empty2.txt":  "",
+	})
+
+	t.Run("whole storage", func(t *testing.T) {
+		groups, truncated, err := uc.FindDuplicates(context.Background(), "")
+
+		require.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, map[string][]string{
+			sha256Hex("same content"): {"c.txt", "docs/a.txt", "docs/sub/b.txt"},
+		}, groups)
+	})
+
+	t.Run("subfolder paths stay rooted", func(t *testing.T) {
+		groups, _, err := uc.FindDuplicates(context.Background(), "docs")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			sha256Hex("same content"): {"docs/a.txt", "docs/sub/b.txt"},
+		}, groups)
+	})
+
+	t.Run("no duplicates", func(t *testing.T) {
+		groups, _, err := uc.FindDuplicates(context.Background(), "docs/sub")
+
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := uc.FindDuplicates(ctx, "")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "missing folder", path: "nope", wantErr: domain.ErrFileNotFound},
+		{name: "file instead of folder", path: "c.txt", wantErr: domain.ErrFileNotFound},
+		{name: "path traversal", path: "../etc", wantErr: domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := uc.FindDuplicates(context.Background(), tt.path)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// extractTmpPrefix архив распаковывается в скрытую папку и переименовывается в готовую одним Move,
+// так что недораспакованное содержимое не видно в листинге и не путается с уже распакованным.
+const extractTmpPrefix = ".extracting-"
+
+// ExtractArchive распаковывает zip из хранилища в папку destPath. записи проверяются как при автораспаковке:
+// выход за папку отклоняет архив целиком, запрещённые расширения пропускаются. destPath нет — создаётся,
+// есть — содержимое сливается в неё, совпавшие имена получают суффикс `(1)`: распаковка ничего не затирает.
+func (uc *FileManagementUseCase) ExtractArchive(zipPath, destPath string) error {
+	archive, err := uc.sanitizePath(zipPath)
+	if err != nil {
+		return err
+	}
+	dest, err := uc.sanitizePath(destPath)
+	if err != nil {
+		return err
+	}
+	if uc.isDropBox(archive) {
+		return fmt.Errorf("extract from drop-box '%s': %w", archive, domain.ErrPermissionDenied)
+	}
+
+	archiveInfo, err := uc.storage.Stat(archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("archive '%s' not found: %w", archive, domain.ErrFileNotFound)
+		}
+		return fmt.Errorf("failed to stat '%s': %w", archive, err)
+	}
+	if archiveInfo.IsDir() {
+		return fmt.Errorf("extract '%s': %w", archive, domain.ErrIsDirectory)
+	}
+
+	destInfo, err := uc.storage.Stat(dest)
+	switch {
+	case os.IsNotExist(err):
+		return uc.extractZip(archive, archiveInfo.Size(), dest)
+	case err != nil:
+		return fmt.Errorf("failed to stat '%s': %w", dest, err)
+	case !destInfo.IsDir():
+		return fmt.Errorf("extract into file '%s': %w", dest, domain.ErrAlreadyExists)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(archive), filepath.Ext(archive))
+	tmp := filepath.Join(dest, extractTmpPrefix+name)
+	if err = uc.extractInto(archive, archiveInfo.Size(), tmp); err != nil {
+		return err
+	}
+	var skipped []string
+	if err = uc.mergeDir(tmp, dest, domain.ConflictRename, &skipped); err != nil {
+		uc.removeExtractTmp(tmp)
+		return err
+	}
+	return nil
+}
+
+// extractZip распаковывает архив в ещё не существующую папку target.
+func (uc *FileManagementUseCase) extractZip(archive string, size int64, target string) error {
+	tmp := filepath.Join(filepath.Dir(target), extractTmpPrefix+filepath.Base(target))
+	if err := uc.extractInto(archive, size, tmp); err != nil {
+		return err
+	}
+	return uc.storage.Move(tmp, target)
+}
+
+// extractInto распаковывает архив в папку tmp, при ошибке tmp убирается. архив читается через хранилище,
+// так что распаковка работает и с зашифрованным, и с хранилищем в памяти; size — размер из storage.Stat.
+func (uc *FileManagementUseCase) extractInto(archive string, size int64, tmp string) error {
+	file, err := uc.storage.Open(archive)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer closeLogged(file, archive)
+
+	zr, err := zip.NewReader(readerAt(file), size)
+	if err != nil {
+		if errors.Is(err, zip.ErrFormat) {
+			return fmt.Errorf("'%s' is not a zip archive: %w", archive, domain.ErrInvalidParameter)
+		}
+		return fmt.Errorf("open zip: %w", err)
+	}
+
+	if err = uc.checkArchive(zr.File); err != nil {
+		return err
+	}
+
+	if err = uc.storage.CreateDirectory(tmp); err != nil {
+		return fmt.Errorf("create '%s': %w", tmp, err)
+	}
+	if err = uc.extractFiles(zr.File, tmp); err != nil {
+		uc.removeExtractTmp(tmp)
+		return err
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) removeExtractTmp(tmp string) {
+	if err := uc.storage.Remove(tmp); err != nil {
+		logrus.Errorf("Extract: failed to clean up '%s': %v", tmp, err)
+	}
+}
+
+// checkArchive до записи чего-либо: zip-slip и кривые имена отклоняют архив целиком,
+// заявленные размеры дают отказать бомбе сразу (фактические дополнительно режет limitedEntry).
+func (uc *FileManagementUseCase) checkArchive(files []*zip.File) error {
+	cfg := uc.cfg
+	if cfg.File.MaxZipEntries > 0 && len(files) > cfg.File.MaxZipEntries {
+		return fmt.Errorf("%d entries, limit %d: %w", len(files), cfg.File.MaxZipEntries, domain.ErrArchiveTooLarge)
+	}
+
+	var total uint64
+	for _, f := range files {
+		rel, err := uc.entryPath(f.Name)
+		if err != nil {
+			return err
+		}
+		if err = uc.validateComponents(rel); err != nil {
+			return fmt.Errorf("entry '%s': %w", f.Name, err)
+		}
+		total += f.UncompressedSize64
+	}
+	if maxBytes := cfg.File.AutoExtractMaxBytes; maxBytes > 0 && total > uint64(maxBytes) {
+		return fmt.Errorf("%d bytes unpacked, limit %d: %w", total, maxBytes, domain.ErrArchiveTooLarge)
+	}
+	return nil
+}
+
+// entryPath имя записи как относительный путь хранилища. абсолютные пути и `..` отклоняются тем же sanitizePath.
+func (uc *FileManagementUseCase) entryPath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, domain.PathRoot))
+	if path.IsAbs(clean) || clean == domain.PathTraversalPrefix ||
+		strings.HasPrefix(clean, domain.PathTraversalPrefix+domain.PathRoot) {
+		return "", fmt.Errorf("entry '%s' escapes the archive: %w", name, domain.ErrPathTraversal)
+	}
+	rel, err := uc.sanitizePath(clean)
+	if err != nil {
+		return "", fmt.Errorf("entry '%s': %w", name, err)
+	}
+	return rel, nil
+}
+
+func (uc *FileManagementUseCase) extractFiles(files []*zip.File, tmp string) error {
+	cfg := uc.cfg
+	budget := cfg.File.AutoExtractMaxBytes
+	capped := budget > 0
+	for _, f := range files {
+		rel, err := uc.entryPath(f.Name)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(tmp, rel)
+
+		switch {
+		case f.FileInfo().IsDir():
+			if err = uc.storage.CreateDirectory(dest); err != nil {
+				return fmt.Errorf("create '%s': %w", dest, err)
+			}
+			continue
+		case !f.Mode().IsRegular():
+			logrus.Warnf("Extract: special entry '%s' skipped", f.Name)
+			continue
+		case domain.IsForbiddenName(filepath.Base(rel), cfg.File.ForbiddenExtensions):
+			logrus.Warnf("Extract: forbidden entry '%s' skipped", f.Name)
+			continue
+		}
+
+		// бюджет выбран ровно: лимит 0 у limitedEntry значит «без ограничения», поэтому отказ здесь,
+		// иначе остальные записи бомбы прошли бы только под max_upload_size каждая.
+		if capped && budget <= 0 {
+			return fmt.Errorf("entry '%s' after %d bytes unpacked: %w",
+				f.Name, cfg.File.AutoExtractMaxBytes, domain.ErrArchiveTooLarge)
+		}
+		limit := cfg.Server.MaxUploadSize
+		if capped && (limit <= 0 || budget < limit) {
+			limit = budget
+		}
+		written, err := uc.extractFile(f, dest, limit)
+		if err != nil {
+			return fmt.Errorf("entry '%s': %w", f.Name, err)
+		}
+		budget -= written
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) extractFile(f *zip.File, dest string, limit int64) (int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer closeLogged(rc, f.Name)
+
+	entry := &limitedEntry{r: rc, remaining: limit}
+	content, err := uc.checkContentType(dest, entry)
+	if err != nil {
+		return 0, err
+	}
+	if err = uc.storage.WriteFile(dest, content); err != nil {
+		return 0, err
+	}
+	return entry.read, nil
+}
+
+// limitedEntry читает не больше remaining байт (0 — без ограничения), дальше ErrArchiveTooLarge,
+// а не тихая обрезка: заголовок zip мог соврать о размере.
+type limitedEntry struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+}
+
+func (l *limitedEntry) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.remaining > 0 && l.read > l.remaining {
+		return n, fmt.Errorf("entry larger than %d bytes: %w", l.remaining, domain.ErrArchiveTooLarge)
+	}
+	return n, err
+}
+
+// readerAt zip читает архив с произвольных смещений. файл с диска умеет ReadAt сам,
+// расшифровка и память дают только Seek, для них ReadAt собирается из Seek и чтения.
+func readerAt(rs io.ReadSeeker) io.ReaderAt {
+	if ra, ok := rs.(io.ReaderAt); ok {
+		return ra
+	}
+	return &seekReaderAt{rs: rs}
+}
+
+type seekReaderAt struct {
+	mu sync.Mutex
+	rs io.ReadSeeker
+}
+
+func (s *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(s.rs, p)
+	// контракт ReaderAt: недочитали до конца — io.EOF.
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
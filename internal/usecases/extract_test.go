@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func extractLimits(f *config.FileConfig) {
+	f.AutoExtractMaxBytes = 1 << 20
+	f.ForbiddenExtensions = []string{".env"}
+}
+
+func TestFileManagementUseCase_ExtractArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		files   map[string]string
+		dest    string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name:    "into new folder",
+			entries: map[string]string{"a.txt": "a", "sub/b.txt": "b", "empty/": ""},
+			dest:    "out",
+			want: map[string]string{
+				"out/a.txt": "a", "out/sub/b.txt": "b", "out/empty": "/",
+			},
+		},
+		{
+			name:    "in place keeps existing files",
+			entries: map[string]string{"a.txt": "new", "sub/b.txt": "b"},
+			files:   map[string]string{"a.txt": "old", "sub/c.txt": "c"},
+			dest:    "",
+			want: map[string]string{
+				"a.txt": "old", "a (1).txt": "new", "sub/b.txt": "b", "sub/c.txt": "c",
+			},
+		},
+		{
+			name:    "forbidden entries skipped",
+			entries: map[string]string{"ok.txt": "ok", "secrets.env": "s"},
+			dest:    "out",
+			want:    map[string]string{"out/ok.txt": "ok"},
+		},
+		{
+			name:    "zip slip rejects whole archive",
+			entries: map[string]string{"ok.txt": "ok", "../evil.txt": "x"},
+			dest:    "out",
+			want:    map[string]string{},
+			wantErr: domain.ErrPathTraversal,
+		},
+		{
+			name:    "destination is a file",
+			entries: map[string]string{"a.txt": "a"},
+			files:   map[string]string{"out": "file"},
+			dest:    "out",
+			want:    map[string]string{"out": "file"},
+			wantErr: domain.ErrAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t, extractLimits)
+			writeTree(t, tmpDir, tt.files)
+			writeZip(t, filepath.Join(tmpDir, "arc.zip"), tt.entries)
+
+			err := uc.ExtractArchive("arc.zip", tt.dest)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			tree := readTree(t, tmpDir)
+			delete(tree, "arc.zip")
+			assert.Equal(t, tt.want, tree)
+		})
+	}
+}
+
+func TestFileManagementUseCase_ExtractArchive_BadSource(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, extractLimits)
+	writeTree(t, tmpDir, map[string]string{"fake.zip": "not a zip", "dir/x.txt": "x"})
+
+	assert.ErrorIs(t, uc.ExtractArchive("missing.zip", "out"), domain.ErrFileNotFound)
+	assert.ErrorIs(t, uc.ExtractArchive("fake.zip", "out"), domain.ErrInvalidParameter)
+	assert.ErrorIs(t, uc.ExtractArchive("dir", "out"), domain.ErrIsDirectory)
+	assert.NoDirExists(t, filepath.Join(tmpDir, "out"))
+}
+
+func TestFileManagementUseCase_ExtractArchive_ThroughStorage(t *testing.T) {
+	// архива на диске нет, а открытый файл не умеет ReadAt — как у расшифровки и памяти.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("sub/a.txt")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("a"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	uc, _ := newLocalUseCase(t, extractLimits)
+	storage := newMapStorage(t, fstest.MapFS{"arc.zip": {Data: buf.Bytes()}})
+	open := storage.openFunc
+	storage.openFunc = func(relPath string) (io.ReadSeekCloser, error) {
+		f, openErr := open(relPath)
+		return struct{ io.ReadSeekCloser }{f}, openErr
+	}
+	written := map[string]string{}
+	storage.writeFileFunc = func(relPath string, file io.Reader) error {
+		data, readErr := io.ReadAll(file)
+		written[filepath.ToSlash(relPath)] = string(data)
+		return readErr
+	}
+	var moved [2]string
+	storage.moveFunc = func(oldRel, newRel string) error {
+		moved = [2]string{oldRel, newRel}
+		return nil
+	}
+	uc.storage = storage
+
+	require.NoError(t, uc.ExtractArchive("arc.zip", "out"))
+
+	assert.Equal(t, map[string]string{".extracting-out/sub/a.txt": "a"}, written)
+	assert.Equal(t, [2]string{".extracting-out", "out"}, moved)
+}
+
+func TestFileManagementUseCase_ExtractFiles_BudgetUsedUp(t *testing.T) {
+	// заголовки в сумме укладываются в бюджет, но после точного исчерпания следующая запись не должна
+	// откатиться к лимиту max_upload_size.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range [][2]string{{"a.txt", "1234"}, {"b.txt", "5"}} {
+		w, err := zw.Create(e[0])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	uc, tmpDir := newLocalUseCase(t, extractLimits)
+	uc.cfg.File.AutoExtractMaxBytes = 4
+	uc.cfg.Server.MaxUploadSize = 1 << 20
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "tmp"), 0o755))
+
+	err = uc.extractFiles(zr.File, "tmp")
+
+	assert.ErrorIs(t, err, domain.ErrArchiveTooLarge)
+	assert.NoFileExists(t, filepath.Join(tmpDir, "tmp/b.txt"))
+}
@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+const (
+	fetchMaxRedirects = 10
+	fetchDialTimeout  = 30 * time.Second
+)
+
+// FetchURL качает rawURL в destDir через обычный UploadFile: лимит размера, запрещённые имена, проверка
+// содержимого и атомарная запись те же, что у загрузки с клиента. занятое имя получает суффикс, как при rename.
+// имя берётся из Content-Disposition, иначе из последнего сегмента пути url.
+func (uc *FileManagementUseCase) FetchURL(destDir, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("url '%s': %w", rawURL, domain.ErrInvalidParameter)
+	}
+	if err = uc.checkFetchURL(u); err != nil {
+		return "", err
+	}
+
+	resp, err := uc.fetchClient.Get(u.String())
+	if err != nil {
+		// ошибки CheckRedirect и Control приходят обёрнутыми в *url.Error, %w их сохраняет.
+		return "", fmt.Errorf("fetch '%s': %w", u.Redacted(), err)
+	}
+	defer closeLogged(resp.Body, u.Redacted())
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch '%s': remote answered %s: %w",
+			u.Redacted(), resp.Status, domain.ErrInvalidParameter)
+	}
+	maxSize := uc.cfg.Server.MaxUploadSize
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return "", fmt.Errorf("remote file size %d exceeds maximum %d: %w",
+			resp.ContentLength, maxSize, domain.ErrUnsupportedOperation)
+	}
+
+	name := fetchFileName(resp)
+	if name == "" {
+		return "", fmt.Errorf("cannot pick a file name for '%s': %w", u.Redacted(), domain.ErrInvalidParameter)
+	}
+	if domain.IsForbiddenName(name, uc.cfg.File.ForbiddenExtensions) {
+		return "", fmt.Errorf("file '%s': %w", name, domain.ErrUnsupportedOperation)
+	}
+
+	var body io.Reader = resp.Body
+	if maxSize > 0 {
+		body = &sizeLimitReader{r: body, limit: maxSize}
+	}
+	return uc.UploadFile(filepath.Join(destDir, name), body, domain.UploadOptions{Conflict: domain.ConflictRename})
+}
+
+// checkFetchURL схема и хост; на редиректах проверяется заново, иначе allowlist обходится одним 302.
+func (uc *FileManagementUseCase) checkFetchURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("url '%s': only http and https are allowed: %w", u.Redacted(), domain.ErrInvalidParameter)
+	}
+	allowed := uc.cfg.Server.FetchAllowedHosts
+	sameHost := func(h string) bool { return strings.EqualFold(h, u.Hostname()) }
+	if len(allowed) > 0 && !slices.ContainsFunc(allowed, sameHost) {
+		return fmt.Errorf("host '%s' is not in server.fetch_allowed_hosts: %w",
+			u.Hostname(), domain.ErrPermissionDenied)
+	}
+	return nil
+}
+
+// newFetchClient без allowlist соединяться можно только с публичными адресами. проверка в Control,
+// то есть по адресу, к которому реально идёт соединение: подмена DNS между проверкой и запросом не поможет.
+// прокси из окружения не используется, иначе проверялся бы адрес прокси, а не цели.
+func newFetchClient(cfg *config.Config, checkRedirect func(u *url.URL) error) *http.Client {
+	dialer := &net.Dialer{Timeout: fetchDialTimeout}
+	if len(cfg.Server.FetchAllowedHosts) == 0 {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("address %s is not public: %w", host, domain.ErrPermissionDenied)
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: cfg.Server.FetchTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: fetchDialTimeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= fetchMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects: %w", fetchMaxRedirects, domain.ErrInvalidParameter)
+			}
+			return checkRedirect(req.URL)
+		},
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// fetchFileName имя из Content-Disposition или из пути итогового (после редиректов) url, "" — не нашлось.
+func fetchFileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := filepath.Base(filepath.FromSlash(params["filename"])); params["filename"] != "" && name != "." {
+			return name
+		}
+	}
+	name := path.Base(resp.Request.URL.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// sizeLimitReader обрывает чтение, когда тело переросло лимит: ContentLength сервер может не прислать или соврать,
+// а чанк загрузки приходит без размера вовсе.
+type sizeLimitReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, fmt.Errorf("data exceeds maximum %d bytes: %w", l.limit, domain.ErrUnsupportedOperation)
+	}
+	return n, err
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_FetchURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files/report.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("report body"))
+	})
+	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="../named.txt"`)
+		_, _ = w.Write([]byte("named body"))
+	})
+	mux.HandleFunc("/tool.exe", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("MZ"))
+	})
+	mux.HandleFunc("/big.txt", func(w http.ResponseWriter, r *http.Request) {
+		// без Content-Length: лимит должен сработать на чтении.
+		_, _ = w.Write([]byte("0123456789"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("0123456789"))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		target := strings.Replace("http://"+r.Host+"/files/report.txt", "127.0.0.1", "localhost", 1)
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name     string
+		url      string
+		allowed  []string
+		maxSize  int64
+		existing string
+		wantPath string
+		wantBody string
+		wantErr  error
+	}{
+		{name: "file name from url", url: srv.URL + "/files/report.txt", allowed: []string{"127.0.0.1"},
+			wantPath: "report.txt", wantBody: "report body"},
+		{name: "file name from content-disposition", url: srv.URL + "/download", allowed: []string{"127.0.0.1"},
+			wantPath: "named.txt", wantBody: "named body"},
+		{name: "taken name gets a suffix", url: srv.URL + "/files/report.txt", allowed: []string{"127.0.0.1"},
+			existing: "report.txt", wantPath: "report (1).txt", wantBody: "report body"},
+		{name: "only http and https", url: "ftp://127.0.0.1/a.txt", allowed: []string{"127.0.0.1"},
+			wantErr: domain.ErrInvalidParameter},
+		{name: "host outside allowlist", url: srv.URL + "/files/report.txt", allowed: []string{"example.com"},
+			wantErr: domain.ErrPermissionDenied},
+		{name: "loopback without allowlist", url: srv.URL + "/files/report.txt",
+			wantErr: domain.ErrPermissionDenied},
+		{name: "redirect outside allowlist", url: srv.URL + "/redirect", allowed: []string{"127.0.0.1"},
+			wantErr: domain.ErrPermissionDenied},
+		{name: "remote error", url: srv.URL + "/missing.txt", allowed: []string{"127.0.0.1"},
+			wantErr: domain.ErrInvalidParameter},
+		{name: "forbidden extension", url: srv.URL + "/tool.exe", allowed: []string{"127.0.0.1"},
+			wantErr: domain.ErrUnsupportedOperation},
+		{name: "body over the upload limit", url: srv.URL + "/big.txt", allowed: []string{"127.0.0.1"}, maxSize: 15,
+			wantErr: domain.ErrUnsupportedOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.Server.FetchAllowedHosts = tt.allowed
+			uc.cfg.Server.MaxUploadSize = tt.maxSize
+			uc.cfg.File.ForbiddenExtensions = []string{".exe"}
+			uc.fetchClient = newFetchClient(uc.cfg, uc.checkFetchURL)
+			if tt.existing != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(tmpDir, tt.existing), []byte("old"), 0o644))
+			}
+
+			stored, err := uc.FetchURL("", tt.url)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				entries, readErr := os.ReadDir(tmpDir)
+				require.NoError(t, readErr)
+				assert.Empty(t, entries, "nothing must be stored")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, stored)
+			data, err := os.ReadFile(filepath.Join(tmpDir, stored))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(data))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"archive/zip"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -10,8 +11,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/text/unicode/norm"
 
 	"file-manager/internal/config"
 	"file-manager/internal/domain"
@@ -21,19 +24,52 @@ type FileManagementUseCase struct {
 	storage   domain.FileStorage
 	cfg       *config.Config
 	validName *regexp.Regexp
+	zipCache  *zipCache
+	// statsCache последние результаты Stats, см. stats.go.
+	statsCache statsCache
+	// caseInsensitive имена в хранилище сравниваются без учёта регистра (file.case_insensitive).
+	caseInsensitive bool
+	// events получатель событий (прогресс загрузок), nil — события не создаются.
+	events domain.EventPublisher
+	// postUpload хуки после записи загрузки, см. WithPostUploadHook.
+	postUpload []domain.PostUploadHook
+	// fetchClient http-клиент FetchURL с защитой от SSRF, см. fetch_url.go.
+	fetchClient *http.Client
+	// trash согласует перенос в корзину, восстановление и фоновую очистку, см. trash_purge.go.
+	trash trashState
 }
 
-func NewFileManagementUseCase(storage domain.FileStorage, cfg *config.Config) *FileManagementUseCase {
+func NewFileManagementUseCase(storage domain.FileStorage, cfg *config.Config, opts ...Option) *FileManagementUseCase {
 	regex := regexp.MustCompile(cfg.File.ValidNameRegex)
-	return &FileManagementUseCase{
+	uc := &FileManagementUseCase{
 		storage:   storage,
 		cfg:       cfg,
 		validName: regex,
 	}
+	if cfg.File.ZipCache.Enabled {
+		uc.zipCache = newZipCache(cfg.File.ZipCache.Dir, cfg.File.ZipCache.MaxBytes)
+	}
+	uc.fetchClient = newFetchClient(cfg, uc.checkFetchURL)
+	uc.caseInsensitive = caseInsensitiveMode(cfg.File.CaseInsensitive, storage)
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 // sanitizePath нужен для нормализации путей, чтобы атаки через обход директорий.
 func (uc *FileManagementUseCase) sanitizePath(path string) (string, error) {
+	// macOS присылает имена в NFD, остальные клиенты в NFC, приводим к одной форме,
+	// иначе "café.txt" из разных систем ляжет двумя визуально одинаковыми файлами.
+	if uc.cfg.File.NormalizeUnicode {
+		path = norm.NFC.String(path)
+	}
+	// на linux filepath.Clean считает `\` обычным символом, поэтому замена до него:
+	// тогда `a\..\..\etc` после замены проходит через Clean и проверку обхода, как обычный путь.
+	if uc.cfg.File.NormalizeBackslashes {
+		path = strings.ReplaceAll(path, `\`, domain.PathRoot)
+	}
+
 	clean := filepath.Clean(path)
 
 	// отклоняю абсолютные пути, чтобы предотвратить доступ за пределы базовой директории хранилища.
@@ -69,11 +105,37 @@ func (uc *FileManagementUseCase) sanitizePath(path string) (string, error) {
 	return clean, nil
 }
 
-func (uc *FileManagementUseCase) List(path string) ([]domain.FileData, error) {
+// validateComponents проверяет регуляркой имён каждую компоненту пути, sanitizePath смотрит только на base.
+func (uc *FileManagementUseCase) validateComponents(sanitizedPath string) error {
+	if sanitizedPath == domain.PathCurrent {
+		return nil
+	}
+	for _, component := range strings.Split(filepath.ToSlash(sanitizedPath), domain.PathRoot) {
+		if !uc.validName.MatchString(component) {
+			return fmt.Errorf("path component '%s' is invalid: %w", component, domain.ErrInvalidName)
+		}
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) List(path string, opts domain.ListOptions) ([]domain.FileData, error) {
 	sanitizedPath, err := uc.sanitizePath(path)
 	if err != nil {
 		return nil, err
 	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, fmt.Errorf("listing drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	if !opts.Sort.Valid() {
+		return nil, fmt.Errorf("unknown sort field '%s': %w", opts.Sort, domain.ErrInvalidParameter)
+	}
+	// шаблон проверяю до чтения директории, filepath.Match ругается на кривой шаблон только при сравнении.
+	if opts.Filter != "" {
+		if _, matchErr := filepath.Match(opts.Filter, ""); matchErr != nil {
+			return nil, fmt.Errorf("bad filter pattern '%s': %w", opts.Filter, domain.ErrInvalidName)
+		}
+	}
 
 	entries, err := uc.storage.ReadDirectory(sanitizedPath)
 	if err != nil {
@@ -86,52 +148,239 @@ func (uc *FileManagementUseCase) List(path string) ([]domain.FileData, error) {
 		return nil, fmt.Errorf("failed to list path '%s': %w", sanitizedPath, err)
 	}
 
+	ignore, ignoredDir, err := uc.ignoreFor(sanitizedPath)
+	if err != nil {
+		return nil, err
+	}
+	if ignoredDir {
+		return nil, fmt.Errorf("directory '%s' is excluded by %s: %w",
+			sanitizedPath, domain.IgnoreFileName, domain.ErrFileNotFound)
+	}
+
+	var cutoff time.Time
+	if opts.MaxAge > 0 {
+		cutoff = time.Now().Add(-opts.MaxAge)
+	}
+
 	files := make([]domain.FileData, 0, len(entries))
 	for _, fi := range entries {
+		if !uc.matchesFilter(fi, opts) || !matchesAge(fi, opts, cutoff) {
+			continue
+		}
+		entryPath := filepath.Join(sanitizedPath, fi.Name())
+		if uc.isTrashDir(entryPath) || uc.isTrashIndex(entryPath) || uc.isUploadMeta(fi.Name()) {
+			continue
+		}
+		if ignore != nil && (fi.Name() == domain.IgnoreFileName ||
+			ignore.ignored(filepath.Join(sanitizedPath, fi.Name()), fi.IsDir())) {
+			continue
+		}
+		forbidden := uc.cfg.File.MarkForbidden && domain.IsForbiddenName(fi.Name(), uc.cfg.File.ForbiddenExtensions)
 		files = append(files, domain.FileData{
-			Name:  fi.Name(),
-			IsDir: fi.IsDir(),
+			Name:         fi.Name(),
+			IsDir:        fi.IsDir(),
+			Forbidden:    forbidden,
+			Size:         fi.Size(),
+			ModTime:      fi.ModTime(),
+			HasThumbnail: hasThumbnail(fi),
 		})
 	}
 
+	sortListing(files, opts)
 	return files, nil
 }
 
-func (uc *FileManagementUseCase) UploadFile(path string, file io.Reader) error {
+func (uc *FileManagementUseCase) matchesFilter(fi os.FileInfo, opts domain.ListOptions) bool {
+	if opts.Filter == "" || (fi.IsDir() && !opts.FilterDirs) {
+		return true
+	}
+	// шаблон уже проверен в List, ошибки тут быть не может.
+	matched, _ := filepath.Match(opts.Filter, fi.Name())
+	return matched
+}
+
+// matchesAge отсекает записи старше cutoff, нулевой cutoff — фильтра нет.
+func matchesAge(fi os.FileInfo, opts domain.ListOptions, cutoff time.Time) bool {
+	if cutoff.IsZero() || (fi.IsDir() && !opts.FilterDirs) {
+		return true
+	}
+	return !fi.ModTime().Before(cutoff)
+}
+
+// UploadFile возвращает путь, под которым файл реально сохранён,
+// он может отличаться от запрошенного при политике ConflictRename.
+func (uc *FileManagementUseCase) UploadFile(path string, file io.Reader, opts domain.UploadOptions) (string, error) {
 	sanitizedPath, err := uc.sanitizePath(path)
 	if err != nil {
+		return "", err
+	}
+	// `name.` расширением не считается: Ext вернёт одну точку.
+	if uc.cfg.File.RequireExtension && len(filepath.Ext(sanitizedPath)) < 2 {
+		return "", fmt.Errorf("file '%s' has no extension: %w", filepath.Base(sanitizedPath), domain.ErrInvalidName)
+	}
+	// подложить чужой сайдкар загрузкой нельзя, иначе метаданные ничего не доказывают.
+	if uc.isUploadMeta(sanitizedPath) {
+		return "", fmt.Errorf("file '%s' looks like upload metadata: %w",
+			filepath.Base(sanitizedPath), domain.ErrInvalidName)
+	}
+
+	// проверка до resolveConflict: при политике version старый файл уже был бы отодвинут.
+	file, err = uc.checkContentType(sanitizedPath, file)
+	if err != nil {
+		return "", err
+	}
+
+	plan, err := uc.planConflict(sanitizedPath, uc.uploadConflict(opts.Conflict))
+	if err != nil {
+		return "", err
+	}
+	targetPath := plan.target
+
+	if len(opts.ContentMD5) > 0 {
+		file = newChecksumReader(file, opts.ContentMD5)
+	}
+	if len(opts.ContentSHA256) > 0 {
+		file = newSHA256Reader(file, opts.ContentSHA256)
+	}
+	if uc.events != nil {
+		file = newProgressReader(file, targetPath, uc.events)
+	}
+
+	if writeErr := uc.writeUpload(plan, file); writeErr != nil {
+		return "", fmt.Errorf("failed to upload file to '%s': %w", targetPath, writeErr)
+	}
+	info := domain.UploadInfo{OriginalName: filepath.Base(sanitizedPath), Uploader: opts.Uploader}
+	for _, hook := range uc.postUpload {
+		hook.AfterUpload(targetPath, info)
+	}
+	return targetPath, nil
+}
+
+// uploadTempPrefix временный файл новой версии при conflict=version, скрытый, как и пробный файл хранилища.
+const uploadTempPrefix = ".upload-"
+
+// writeUpload пишет загрузку по плану. с политикой version новая версия сначала целиком ложится во временный
+// файл рядом и только потом меняется местами со старой: оборванная загрузка, неверный хеш или переполнение
+// не оставят на месте пустоту, а старый файл — под `.vN`.
+func (uc *FileManagementUseCase) writeUpload(plan conflictPlan, file io.Reader) error {
+	if plan.versioned == "" {
+		return uc.storage.WriteFile(plan.target, file)
+	}
+
+	tmp := filepath.Join(filepath.Dir(plan.target), probeName(uploadTempPrefix))
+	if err := uc.storage.WriteFile(tmp, file); err != nil {
+		uc.removeUploadTemp(tmp)
 		return err
 	}
-	if writeErr := uc.storage.WriteFile(sanitizedPath, file); writeErr != nil {
-		return fmt.Errorf("failed to upload file to '%s': %w", sanitizedPath, writeErr)
+	if err := uc.moveAside(plan); err != nil {
+		uc.removeUploadTemp(tmp)
+		return err
+	}
+	if err := uc.storage.Move(tmp, plan.target); err != nil {
+		if backErr := uc.storage.Move(plan.versioned, plan.current); backErr != nil {
+			logrus.Errorf("Failed to move '%s' back to '%s': %v", plan.versioned, plan.current, backErr)
+		}
+		uc.removeUploadTemp(tmp)
+		return err
 	}
 	return nil
 }
 
-func (uc *FileManagementUseCase) Delete(path string) error {
+func (uc *FileManagementUseCase) removeUploadTemp(tmp string) {
+	if err := uc.storage.Remove(tmp); err != nil {
+		logrus.Warnf("Failed to remove upload temp file '%s': %v", tmp, err)
+	}
+}
+
+// OpenWriter для серверной генерации контента (отчёты и т.п.) прямо в хранилище, без промежуточного буфера.
+// как и UploadFile, перезаписывает существующий файл; он заменяется только на Close.
+func (uc *FileManagementUseCase) OpenWriter(path string) (io.WriteCloser, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := uc.storage.OpenWriter(sanitizedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open writer for '%s': %w", sanitizedPath, err)
+	}
+	return writer, nil
+}
+
+func (uc *FileManagementUseCase) Delete(path string, opts domain.DeleteOptions) error {
 	sanitizedPath, err := uc.sanitizePath(path)
 	if err != nil {
 		return err
 	}
+	if err = uc.checkDeletePreconditions(sanitizedPath, opts); err != nil {
+		return err
+	}
+	// из самой корзины удаляется уже насовсем.
+	if uc.cfg.File.TrashEnabled && !uc.isTrash(sanitizedPath) {
+		return uc.moveToTrash(sanitizedPath)
+	}
 	if removeErr := uc.storage.Remove(sanitizedPath); removeErr != nil {
 		return fmt.Errorf("could not delete file/folder '%s': %w", sanitizedPath, removeErr)
 	}
 	return nil
 }
 
-func (uc *FileManagementUseCase) Rename(oldPath, newPath string) error {
+// checkDeletePreconditions оптимистичная блокировка для скриптовой чистки: между stat и удалением
+// есть маленькое окно, но перезаписанный "с тех пор" файл так уже не удалить.
+func (uc *FileManagementUseCase) checkDeletePreconditions(sanitizedPath string, opts domain.DeleteOptions) error {
+	if opts.IfSize == nil && opts.IfModTime == nil {
+		return nil
+	}
+
+	// if-size сравнивается с размером открытого текста, его же клиент видит в листинге.
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("could not stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return fmt.Errorf("failed to stat '%s': %w", sanitizedPath, err)
+	}
+
+	if opts.IfSize != nil && info.Size() != *opts.IfSize {
+		return fmt.Errorf("'%s' size is %d, expected %d: %w",
+			sanitizedPath, info.Size(), *opts.IfSize, domain.ErrPreconditionFailed)
+	}
+	if opts.IfModTime != nil && info.ModTime().Unix() != opts.IfModTime.Unix() {
+		return fmt.Errorf("'%s' modified at %s, expected %s: %w",
+			sanitizedPath, info.ModTime().UTC().Format(time.RFC3339), opts.IfModTime.UTC().Format(time.RFC3339),
+			domain.ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// Rename применяет ту же политику конфликтов, что и загрузка, и возвращает итоговый путь:
+// при ConflictRename он может получить суффикс `(1)`.
+func (uc *FileManagementUseCase) Rename(oldPath, newPath string, policy domain.ConflictPolicy) (string, error) {
 	sanitizedOldPath, err := uc.sanitizePath(oldPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	sanitizedNewPath, err := uc.sanitizePath(newPath)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if moveErr := uc.storage.Move(sanitizedOldPath, sanitizedNewPath); moveErr != nil {
-		return fmt.Errorf("could not rename '%s' to '%s': %w", sanitizedOldPath, sanitizedNewPath, moveErr)
+	// переименование в самого себя не конфликт, иначе rename выдал бы `file (1).txt`.
+	if sanitizedOldPath == sanitizedNewPath {
+		return sanitizedNewPath, nil
 	}
-	return nil
+
+	targetPath := sanitizedNewPath
+	// на регистронезависимой ФС `a.txt` -> `A.txt` меняет только регистр, конфликтовать тут не с кем.
+	if !uc.caseInsensitive || !strings.EqualFold(sanitizedOldPath, sanitizedNewPath) {
+		targetPath, err = uc.resolveConflict(sanitizedNewPath, policy)
+		if err != nil {
+			return "", err
+		}
+	}
+	if moveErr := uc.storage.Move(sanitizedOldPath, targetPath); moveErr != nil {
+		return "", fmt.Errorf("could not rename '%s' to '%s': %w", sanitizedOldPath, targetPath, moveErr)
+	}
+	return targetPath, nil
 }
 
 func (uc *FileManagementUseCase) CreateFolder(path string) error {
@@ -139,6 +388,11 @@ func (uc *FileManagementUseCase) CreateFolder(path string) error {
 	if err != nil {
 		return err
 	}
+	// MkdirAll создаёт все промежуточные директории, поэтому проверяю каждую компоненту
+	// заранее, а не только последнюю, иначе валидный префикс успеет создаться.
+	if componentsErr := uc.validateComponents(sanitizedPath); componentsErr != nil {
+		return componentsErr
+	}
 	if createErr := uc.storage.CreateDirectory(sanitizedPath); createErr != nil {
 		return fmt.Errorf("could not create folder '%s': %w", sanitizedPath, createErr)
 	}
@@ -150,100 +404,260 @@ func (uc *FileManagementUseCase) ServeFile(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		return err
 	}
+	if uc.isDropBox(sanitizedPath) {
+		return fmt.Errorf("download from drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return fmt.Errorf("download of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
 
-	fullPath := uc.storage.GetAbsolutePath(sanitizedPath)
-	if _, statErr := os.Stat(fullPath); statErr != nil {
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil {
 		if os.IsNotExist(statErr) {
 			return fmt.Errorf("file not found at '%s': %w", sanitizedPath, domain.ErrFileNotFound)
 		}
 		return fmt.Errorf("failed to stat file at '%s': %w", sanitizedPath, statErr)
 	}
+	if info.IsDir() {
+		return fmt.Errorf("'%s' is a folder, download it as zip: %w", sanitizedPath, domain.ErrIsDirectory)
+	}
+	servedPath := sanitizedPath
+	name := filepath.Base(sanitizedPath)
 
 	// MIME.
 	// для корреткного скачивания файлов.
-	mimeType := mime.TypeByExtension(filepath.Ext(fullPath))
+	mimeType := mime.TypeByExtension(filepath.Ext(name))
 	if mimeType == domain.PathEmpty {
 		mimeType = domain.MIMEOctetStream
 	}
 	w.Header().Set("Content-Type", mimeType)
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filepath.Base(fullPath)))
-	http.ServeFile(w, r, fullPath)
+	disposition := "attachment"
+	if r.URL.Query().Get(domain.QueryParamInline) == "true" {
+		// загруженный html или svg, открытый на нашем origin, мог бы выполнить скрипт от имени пользователя:
+		// sandbox запрещает скрипты и отделяет origin, nosniff не даёт браузеру угадать html по содержимому.
+		disposition = "inline"
+		w.Header().Set("Content-Security-Policy", "sandbox")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=\"%s\"", disposition, name))
+	if uc.cfg.File.GzipStatic {
+		// ответ зависит от Accept-Encoding, кеши между клиентом и нами должны это знать.
+		w.Header().Add("Vary", "Accept-Encoding")
+		if variantInfo, ok := uc.gzipVariant(r, sanitizedPath); ok {
+			w.Header().Set("Content-Encoding", encodingGzip)
+			servedPath, info = sanitizedPath+gzipSuffix, variantInfo
+		}
+	}
+
+	// читаем через хранилище, а не с диска: зашифрованное хранилище отдаёт тут уже расшифрованный поток.
+	file, err := uc.storage.Open(servedPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file at '%s': %w", servedPath, err)
+	}
+	defer closeLogged(file, servedPath)
+
+	// ServeContent сам отвечает 304 на совпавший If-None-Match, не пишет тело на HEAD и разбирает Range
+	// (416 с `Content-Range: bytes */size` на недостижимый диапазон, multipart/byteranges на несколько
+	// диапазонов сразу). свой путь отдачи должен это сохранить или отдавать такие запросы сюда же.
+	w.Header().Set("ETag", fileETag(info))
+	http.ServeContent(w, r, name, info.ModTime(), file)
 	return nil
 }
 
+// fileETag по размеру и времени изменения, как у nginx: без чтения файла.
+func fileETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
 // shouldSkipFile исключить чувствительные файлы из zip архива.
 // чтобы не включить скрытые или системные файлы.
 func (uc *FileManagementUseCase) shouldSkipFile(info os.FileInfo) bool {
 	return strings.HasPrefix(info.Name(), domain.HiddenFilePrefix)
 }
 
-// добавление файлов в zip архив
-func (uc *FileManagementUseCase) addFileToZip(zipWriter *zip.Writer, fullPath, filePath string) error {
-	rel, err := filepath.Rel(fullPath, filePath)
-	if err != nil {
-		return fmt.Errorf("failed to get relative path: %w", err)
+// createZipArchive рекурсивно обхожу дерево директорий и добавляю все не скрытые файлы
+func (uc *FileManagementUseCase) createZipArchive(zipWriter *zip.Writer, relRoot string) error {
+	return uc.walkZipEntries(relRoot,
+		func(file, rel string, _ os.FileInfo) error {
+			return uc.copyToZip(zipWriter, rel, file)
+		},
+		func(dirEntry string) error {
+			if _, createErr := zipWriter.Create(dirEntry); createErr != nil {
+				return fmt.Errorf("failed to create zip directory entry: %w", createErr)
+			}
+			return nil
+		},
+	)
+}
+
+// walkZipEntries перечисляет ровно то, что попадёт в архив: файлы через onFile и,
+// при file.zip_include_empty_dirs, пустые директории через onEmptyDir (имя уже с `/` на конце,
+// такую запись zip считает директорией). общий код для архива и ZipManifest.
+func (uc *FileManagementUseCase) walkZipEntries(
+	relRoot string,
+	onFile func(file, rel string, info os.FileInfo) error,
+	onEmptyDir func(dirEntry string) error,
+) error {
+	if !uc.cfg.File.ZipIncludeEmptyDirs {
+		return uc.walkArchive(relRoot, onFile)
 	}
 
-	dstFile, err := zipWriter.Create(rel)
+	// пустая = в архив из неё ничего не попало, поэтому папка только со скрытыми файлами тоже пустая.
+	var dirs []string
+	nonEmpty := make(map[string]bool)
+	err := uc.walkArchiveEntries(relRoot, func(file, rel string, info os.FileInfo) error {
+		nonEmpty[filepath.Dir(rel)] = true
+		if info.IsDir() {
+			dirs = append(dirs, rel)
+			return nil
+		}
+		return onFile(file, rel, info)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create zip entry: %w", err)
+		return err
 	}
 
-	srcFile, openErr := os.Open(filePath)
-	if openErr != nil {
-		return fmt.Errorf("failed to open file: %w", openErr)
+	for _, dir := range dirs {
+		if nonEmpty[dir] {
+			continue
+		}
+		if dirErr := onEmptyDir(filepath.ToSlash(dir) + "/"); dirErr != nil {
+			return dirErr
+		}
 	}
-	defer func() {
-		if closeErr := srcFile.Close(); closeErr != nil {
-			logrus.Warnf("Failed to close file %s: %v", filePath, closeErr)
+	return nil
+}
+
+// walkArchive обходит папку по тем же правилам, что и архив: скрытые файлы пропускаются,
+// вложенные drop-box директории тоже, иначе их содержимое утекло бы через архив родителя.
+// fn вызывается только для файлов: file — путь в хранилище, rel — относительно relRoot.
+func (uc *FileManagementUseCase) walkArchive(
+	relRoot string,
+	fn func(file, rel string, info os.FileInfo) error,
+) error {
+	return uc.walkArchiveEntries(relRoot, func(file, rel string, info os.FileInfo) error {
+		if info.IsDir() {
+			return nil
 		}
-	}()
+		return fn(file, rel, info)
+	})
+}
 
-	if _, copyErr := io.Copy(dstFile, srcFile); copyErr != nil {
-		return fmt.Errorf("failed to copy file to zip: %w", copyErr)
+// walkArchiveEntries то же, что walkArchive, но fn зовётся и для вложенных директорий (кроме самого корня).
+// обход идёт через хранилище, а не по диску, поэтому видит то же, что листинг: монтирования,
+// размеры открытого текста у зашифрованного хранилища, хранилище в памяти.
+// как у filepath.Walk: записи по имени, симлинки не раскрываются, filepath.SkipDir на папке её пропускает.
+func (uc *FileManagementUseCase) walkArchiveEntries(
+	relRoot string,
+	fn func(file, rel string, info os.FileInfo) error,
+) error {
+	// правила .fmignore по папкам: у каждой свои плюс родительские, заводятся при входе в папку.
+	rootIgnore, ignoredRoot, err := uc.ignoreFor(relRoot)
+	if err != nil {
+		return err
+	}
+	if ignoredRoot {
+		return nil
 	}
 
-	return nil
+	rootInfo, err := uc.storage.Stat(relRoot)
+	if err != nil {
+		return err
+	}
+	// имя корня хранилища (base_path) к скрытым не относится, проверяется только выбранная папка.
+	if filepath.Clean(relRoot) != domain.PathCurrent && uc.shouldSkipFile(rootInfo) {
+		return nil
+	}
+	if !rootInfo.IsDir() {
+		return fn(relRoot, domain.PathCurrent, rootInfo)
+	}
+	if uc.isDropBox(relRoot) {
+		return nil
+	}
+	return uc.walkArchiveDir(relRoot, "", rootIgnore, fn)
 }
 
-// createZipArchive рекурсивно обхожу дерево директорий и добавляю все не скрытые файлы
-func (uc *FileManagementUseCase) createZipArchive(zipWriter *zip.Writer, fullPath string) error {
-	return filepath.Walk(fullPath, func(file string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
+func (uc *FileManagementUseCase) walkArchiveDir(
+	dir, relDir string,
+	ignore *ignoreMatcher,
+	fn func(file, rel string, info os.FileInfo) error,
+) error {
+	entries, err := uc.storage.ReadDirectory(dir)
+	if err != nil {
+		return err
+	}
 
+	for _, info := range entries {
 		if uc.shouldSkipFile(info) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+			continue
+		}
+		storageRel := filepath.Join(dir, info.Name())
+		rel := filepath.Join(relDir, info.Name())
+		if ignore.ignored(storageRel, info.IsDir()) || uc.isTrashIndex(storageRel) {
+			continue
 		}
 
-		if info.IsDir() {
-			return nil
+		if !info.IsDir() {
+			if fnErr := fn(storageRel, rel, info); fnErr != nil {
+				return fnErr
+			}
+			continue
 		}
 
-		return uc.addFileToZip(zipWriter, fullPath, file)
-	})
+		// корзина не попадает в архив родителя, даже если её имя не скрытое.
+		if uc.isDropBox(storageRel) || uc.isTrashDir(storageRel) {
+			continue
+		}
+		childIgnore, childErr := ignore.child(uc, storageRel)
+		if childErr != nil {
+			return childErr
+		}
+		if fnErr := fn(storageRel, rel, info); fnErr != nil {
+			if errors.Is(fnErr, filepath.SkipDir) {
+				continue
+			}
+			return fnErr
+		}
+		if walkErr := uc.walkArchiveDir(storageRel, rel, childIgnore, fn); walkErr != nil {
+			return walkErr
+		}
+	}
+	return nil
 }
 
-func (uc *FileManagementUseCase) ServeFolderAsZip(w http.ResponseWriter, path string) error {
+func (uc *FileManagementUseCase) ServeFolderAsZip(w http.ResponseWriter, r *http.Request, path string) error {
 	sanitizedPath, err := uc.sanitizePath(path)
 	if err != nil {
 		return err
 	}
+	if uc.isDropBox(sanitizedPath) {
+		return fmt.Errorf("download from drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return fmt.Errorf("download of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
 
-	fullPath := uc.storage.GetAbsolutePath(sanitizedPath)
-	info, statErr := os.Stat(fullPath)
+	info, statErr := uc.storage.Stat(sanitizedPath)
 	if statErr != nil || !info.IsDir() {
 		return fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
 	}
 
-	zipName := filepath.Base(sanitizedPath) + domain.ExtensionZip
+	if limitErr := uc.checkZipLimits(sanitizedPath); limitErr != nil {
+		return limitErr
+	}
+
+	zipName := uc.zipFileName(r, sanitizedPath)
 	w.Header().Set("Content-Type", domain.MIMEZip)
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", zipName))
 
+	if uc.zipCache != nil {
+		return uc.serveCachedZip(w, r, sanitizedPath, zipName)
+	}
+	// без кеша размер архива неизвестен до сборки, а собирать его ради HEAD незачем.
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
 	zipWriter := zip.NewWriter(w)
 	defer func() {
 		if closeErr := zipWriter.Close(); closeErr != nil {
@@ -251,9 +665,25 @@ func (uc *FileManagementUseCase) ServeFolderAsZip(w http.ResponseWriter, path st
 		}
 	}()
 
-	if archiveErr := uc.createZipArchive(zipWriter, fullPath); archiveErr != nil {
+	if archiveErr := uc.createZipArchive(zipWriter, sanitizedPath); archiveErr != nil {
 		return fmt.Errorf("failed to create zip for folder '%s': %w", sanitizedPath, archiveErr)
 	}
 
 	return nil
 }
+
+// Capabilities собирает только несекретные настройки, пути и внутренние параметры сюда не попадают.
+func (uc *FileManagementUseCase) Capabilities() domain.Capabilities {
+	return domain.Capabilities{
+		StorageType:         uc.cfg.Storage.Type,
+		MaxUploadSize:       uc.cfg.Server.MaxUploadSize,
+		MaxNameLength:       uc.cfg.File.MaxNameLength,
+		ForbiddenExtensions: uc.cfg.File.ForbiddenExtensions,
+		ConflictPolicies:    uc.conflictPolicies(),
+		Versioning:          !uc.cfg.File.DisableVersioning,
+		Trash:               uc.cfg.File.TrashEnabled,
+		// zip папки отдаёт только маршрут download_folder, без него скачать папку целиком нечем.
+		ZipDownload:       uc.cfg.Routes.DownloadFolder != "",
+		MaxZipSourceBytes: uc.cfg.File.MaxZipSourceBytes,
+	}
+}
@@ -1,27 +1,112 @@
 package usecases
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"file-manager/internal/adapters/localstorage"
 	"file-manager/internal/config"
 	"file-manager/internal/domain"
 )
 
+// newLocalUseCase собирает usecase на настоящем localstorage во временной папке.
+// configure правит конфиг до создания, как если бы он пришёл из config.yaml.
+func newLocalUseCase(t *testing.T, configure ...func(*config.FileConfig)) (*FileManagementUseCase, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		File: config.FileConfig{
+			MaxNameLength:  255,
+			ValidNameRegex: `^[\w\-. ()]+$`,
+		},
+	}
+	for _, fn := range configure {
+		fn(&cfg.File)
+	}
+	return NewFileManagementUseCase(localstorage.NewLocalStorageService(tmpDir, 0o755), cfg), tmpDir
+}
+
+// hookedStorage пропускает вызовы в хранилище под ним, а заданные функции их подменяют:
+// так тест считает обращения или роняет отдельную операцию.
+type hookedStorage struct {
+	domain.FileStorage
+
+	openFunc            func(relPath string) (io.ReadSeekCloser, error)
+	writeFileFunc       func(relPath string, file io.Reader) error
+	removeFunc          func(relPath string) error
+	moveFunc            func(oldRel, newRel string) error
+	createDirectoryFunc func(relPath string) error
+}
+
+// hookStorage ставит hookedStorage поверх хранилища usecase.
+func hookStorage(uc *FileManagementUseCase) *hookedStorage {
+	s := &hookedStorage{FileStorage: uc.storage}
+	uc.storage = s
+	return s
+}
+
+func (s *hookedStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	if s.openFunc != nil {
+		return s.openFunc(relPath)
+	}
+	return s.FileStorage.Open(relPath)
+}
+
+func (s *hookedStorage) WriteFile(relPath string, file io.Reader) error {
+	if s.writeFileFunc != nil {
+		return s.writeFileFunc(relPath, file)
+	}
+	return s.FileStorage.WriteFile(relPath, file)
+}
+
+func (s *hookedStorage) Remove(relPath string) error {
+	if s.removeFunc != nil {
+		return s.removeFunc(relPath)
+	}
+	return s.FileStorage.Remove(relPath)
+}
+
+func (s *hookedStorage) Move(oldRel, newRel string) error {
+	if s.moveFunc != nil {
+		return s.moveFunc(oldRel, newRel)
+	}
+	return s.FileStorage.Move(oldRel, newRel)
+}
+
+func (s *hookedStorage) CreateDirectory(relPath string) error {
+	if s.createDirectoryFunc != nil {
+		return s.createDirectoryFunc(relPath)
+	}
+	return s.FileStorage.CreateDirectory(relPath)
+}
+
 // mockFileStorage is a mock implementation of FileStorage for testing.
 type mockFileStorage struct {
 	basePath string
 
 	readDirectoryFunc   func(relPath string) ([]os.FileInfo, error)
+	openFunc            func(relPath string) (io.ReadSeekCloser, error)
+	statFunc            func(relPath string) (os.FileInfo, error)
 	writeFileFunc       func(relPath string, file io.Reader) error
+	openWriterFunc      func(relPath string) (io.WriteCloser, error)
 	removeFunc          func(relPath string) error
 	moveFunc            func(oldRel, newRel string) error
 	createDirectoryFunc func(relPath string) error
@@ -32,7 +117,32 @@ func (m *mockFileStorage) ReadDirectory(relPath string) ([]os.FileInfo, error) {
 	if m.readDirectoryFunc != nil {
 		return m.readDirectoryFunc(relPath)
 	}
-	return nil, nil
+	// как Open и Stat: по умолчанию с диска, обход архивов идёт через хранилище.
+	entries, err := os.ReadDir(m.GetAbsolutePath(relPath))
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if info, infoErr := e.Info(); infoErr == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func (m *mockFileStorage) Open(relPath string) (io.ReadSeekCloser, error) {
+	if m.openFunc != nil {
+		return m.openFunc(relPath)
+	}
+	return os.Open(m.GetAbsolutePath(relPath))
+}
+
+func (m *mockFileStorage) Stat(relPath string) (os.FileInfo, error) {
+	if m.statFunc != nil {
+		return m.statFunc(relPath)
+	}
+	return os.Stat(m.GetAbsolutePath(relPath))
 }
 
 func (m *mockFileStorage) WriteFile(relPath string, file io.Reader) error {
@@ -42,6 +152,13 @@ func (m *mockFileStorage) WriteFile(relPath string, file io.Reader) error {
 	return nil
 }
 
+func (m *mockFileStorage) OpenWriter(relPath string) (io.WriteCloser, error) {
+	if m.openWriterFunc != nil {
+		return m.openWriterFunc(relPath)
+	}
+	return nil, nil
+}
+
 func (m *mockFileStorage) Remove(relPath string) error {
 	if m.removeFunc != nil {
 		return m.removeFunc(relPath)
@@ -203,7 +320,7 @@ func TestFileManagementUseCase_List(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		files, err := uc.List("")
+		files, err := uc.List("", domain.ListOptions{})
 
 		require.NoError(t, err)
 		require.Len(t, files, 1)
@@ -230,7 +347,7 @@ func TestFileManagementUseCase_List(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		files, err := uc.List("nonexistent")
+		files, err := uc.List("nonexistent", domain.ListOptions{})
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrFileNotFound))
@@ -256,12 +373,170 @@ func TestFileManagementUseCase_List(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		files, err := uc.List("restricted")
+		files, err := uc.List("restricted", domain.ListOptions{})
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrPermissionDenied))
 		assert.Nil(t, files)
 	})
+
+	t.Run("sorted", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, map[string]string{"b.txt": "bb", "a.txt": "aaa", "c.txt": "c"})
+		require.NoError(t, os.Mkdir(filepath.Join(tmpDir, "zdir"), 0o755))
+
+		files, err := uc.List("", domain.ListOptions{Sort: domain.SortBySize, Desc: true, DirsFirst: true})
+
+		require.NoError(t, err)
+		names := make([]string, len(files))
+		for i, f := range files {
+			names[i] = f.Name
+		}
+		assert.Equal(t, []string{"zdir", "a.txt", "b.txt", "c.txt"}, names)
+	})
+
+	t.Run("unknown sort field", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		_, err := uc.List("", domain.ListOptions{Sort: "owner"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+	})
+}
+
+func TestFileManagementUseCase_List_Filter(t *testing.T) {
+	cfg := &config.Config{
+		File: config.FileConfig{
+			MaxNameLength:  255,
+			ValidNameRegex: `^[\w\-. ]+$`,
+		},
+	}
+	mockStorage := &mockFileStorage{
+		basePath: "/storage",
+		readDirectoryFunc: func(relPath string) ([]os.FileInfo, error) {
+			return []os.FileInfo{
+				&mockFileInfo{name: "report.pdf"},
+				&mockFileInfo{name: "notes.txt"},
+				&mockFileInfo{name: "archive", isDir: true},
+			}, nil
+		},
+	}
+	uc := NewFileManagementUseCase(mockStorage, cfg)
+
+	names := func(files []domain.FileData) []string {
+		result := make([]string, 0, len(files))
+		for _, f := range files {
+			result = append(result, f.Name)
+		}
+		return result
+	}
+
+	t.Run("directories always shown", func(t *testing.T) {
+		files, err := uc.List("", domain.ListOptions{Filter: "*.pdf"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"report.pdf", "archive"}, names(files))
+	})
+
+	t.Run("filter directories too", func(t *testing.T) {
+		files, err := uc.List("", domain.ListOptions{Filter: "*.pdf", FilterDirs: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"report.pdf"}, names(files))
+	})
+
+	t.Run("bad pattern", func(t *testing.T) {
+		files, err := uc.List("", domain.ListOptions{Filter: "[a-"})
+
+		assert.True(t, errors.Is(err, domain.ErrInvalidName))
+		assert.Nil(t, files)
+	})
+}
+
+func TestFileManagementUseCase_List_MaxAge(t *testing.T) {
+	cfg := &config.Config{
+		File: config.FileConfig{
+			MaxNameLength:  255,
+			ValidNameRegex: `^[\w\-. ]+$`,
+		},
+	}
+	now := time.Now()
+	mockStorage := &mockFileStorage{
+		basePath: "/storage",
+		readDirectoryFunc: func(relPath string) ([]os.FileInfo, error) {
+			return []os.FileInfo{
+				&mockFileInfo{name: "fresh.csv", modTime: now.Add(-time.Hour)},
+				&mockFileInfo{name: "processed.csv", modTime: now.Add(-10 * 24 * time.Hour)},
+				&mockFileInfo{name: "old-dir", isDir: true, modTime: now.Add(-30 * 24 * time.Hour)},
+			}, nil
+		},
+	}
+	uc := NewFileManagementUseCase(mockStorage, cfg)
+
+	tests := []struct {
+		name string
+		opts domain.ListOptions
+		want []string
+	}{
+		{"no limit", domain.ListOptions{}, []string{"fresh.csv", "processed.csv", "old-dir"}},
+		{
+			"old files hidden, dirs kept", domain.ListOptions{MaxAge: 7 * 24 * time.Hour},
+			[]string{"fresh.csv", "old-dir"},
+		},
+		{"dirs too", domain.ListOptions{MaxAge: 7 * 24 * time.Hour, FilterDirs: true}, []string{"fresh.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := uc.List("", tt.opts)
+
+			require.NoError(t, err)
+			names := make([]string, 0, len(files))
+			for _, f := range files {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestFileManagementUseCase_List_MarkForbidden(t *testing.T) {
+	newUseCase := func(mark bool) *FileManagementUseCase {
+		cfg := &config.Config{
+			File: config.FileConfig{
+				MaxNameLength:       255,
+				ValidNameRegex:      `^[\w\-. ]+$`,
+				ForbiddenExtensions: []string{".env"},
+				MarkForbidden:       mark,
+			},
+		}
+		mockStorage := &mockFileStorage{
+			basePath: "/storage",
+			readDirectoryFunc: func(relPath string) ([]os.FileInfo, error) {
+				return []os.FileInfo{
+					&mockFileInfo{name: "prod.env"},
+					&mockFileInfo{name: "notes.txt"},
+				}, nil
+			},
+		}
+		return NewFileManagementUseCase(mockStorage, cfg)
+	}
+
+	t.Run("marked when enabled", func(t *testing.T) {
+		files, err := newUseCase(true).List("", domain.ListOptions{})
+
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.True(t, files[0].Forbidden)
+		assert.False(t, files[1].Forbidden)
+	})
+
+	t.Run("not marked when disabled", func(t *testing.T) {
+		files, err := newUseCase(false).List("", domain.ListOptions{})
+
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+		assert.False(t, files[0].Forbidden)
+	})
 }
 
 func TestFileManagementUseCase_UploadFile(t *testing.T) {
@@ -289,9 +564,10 @@ func TestFileManagementUseCase_UploadFile(t *testing.T) {
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
 		testData := strings.NewReader("test content")
-		err := uc.UploadFile("test.txt", testData)
+		storedPath, err := uc.UploadFile("test.txt", testData, domain.UploadOptions{})
 
 		assert.NoError(t, err)
+		assert.Equal(t, "test.txt", storedPath)
 		assert.Equal(t, "test.txt", writtenPath)
 		assert.Equal(t, "test content", string(writtenData))
 	})
@@ -312,13 +588,44 @@ func TestFileManagementUseCase_UploadFile(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		err := uc.UploadFile("../../etc/passwd", strings.NewReader("evil"))
+		_, err := uc.UploadFile("../../etc/passwd", strings.NewReader("evil"), domain.UploadOptions{})
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrPathTraversal))
 	})
 }
 
+func TestFileManagementUseCase_UploadFile_RequireExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		required bool
+		wantErr  error
+	}{
+		{name: "extensionless rejected", file: "README", required: true, wantErr: domain.ErrInvalidName},
+		{name: "with extension allowed", file: "README.md", required: true},
+		{name: "trailing dot is not an extension", file: "notes.", required: true, wantErr: domain.ErrInvalidName},
+		{name: "nested extensionless rejected", file: "docs/Makefile", required: true, wantErr: domain.ErrInvalidName},
+		{name: "off by default", file: "README"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.File.RequireExtension = tt.required
+
+			_, err := uc.UploadFile(tt.file, strings.NewReader("content"), domain.UploadOptions{})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.NoFileExists(t, filepath.Join(tmpDir, tt.file))
+				return
+			}
+			require.NoError(t, err)
+			assert.FileExists(t, filepath.Join(tmpDir, tt.file))
+		})
+	}
+}
+
 func TestFileManagementUseCase_Delete(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		cfg := &config.Config{
@@ -341,13 +648,79 @@ func TestFileManagementUseCase_Delete(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		err := uc.Delete("test.txt")
+		err := uc.Delete("test.txt", domain.DeleteOptions{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, "test.txt", deletedPath)
 	})
 }
 
+func TestFileManagementUseCase_Delete_Preconditions(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	removed := false
+	hookStorage(uc).removeFunc = func(relPath string) error {
+		removed = true
+		return nil
+	}
+	writeTree(t, tmpDir, map[string]string{"report.csv": "12345"})
+	mtime := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "report.csv"), mtime, mtime))
+
+	size := func(n int64) *int64 { return &n }
+	at := func(tm time.Time) *time.Time { return &tm }
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    domain.DeleteOptions
+		wantErr error
+	}{
+		{"size matches", "report.csv", domain.DeleteOptions{IfSize: size(5)}, nil},
+		{"size differs", "report.csv", domain.DeleteOptions{IfSize: size(4)}, domain.ErrPreconditionFailed},
+		{"mtime matches", "report.csv", domain.DeleteOptions{IfModTime: at(mtime)}, nil},
+		{
+			"mtime matches to the second", "report.csv",
+			domain.DeleteOptions{IfModTime: at(mtime.Add(300 * time.Millisecond))}, nil,
+		},
+		{
+			"mtime differs", "report.csv",
+			domain.DeleteOptions{IfModTime: at(mtime.Add(time.Minute))}, domain.ErrPreconditionFailed,
+		},
+		{"both match", "report.csv", domain.DeleteOptions{IfSize: size(5), IfModTime: at(mtime)}, nil},
+		{"missing file", "nope.csv", domain.DeleteOptions{IfSize: size(5)}, domain.ErrFileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed = false
+			err := uc.Delete(tt.path, tt.opts)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				assert.False(t, removed)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, removed)
+		})
+	}
+}
+
+func TestFileManagementUseCase_Delete_PreconditionsThroughStorage(t *testing.T) {
+	// if-size сверяется с размером из хранилища (у зашифрованного — открытого текста), а не с диска.
+	uc, _ := newLocalUseCase(t)
+	storage := newMapStorage(t, fstest.MapFS{"report.csv": {Data: []byte("12345")}})
+	removed := false
+	storage.removeFunc = func(relPath string) error {
+		removed = true
+		return nil
+	}
+	uc.storage = storage
+	size := int64(5)
+
+	require.NoError(t, uc.Delete("report.csv", domain.DeleteOptions{IfSize: &size}))
+	assert.True(t, removed)
+}
+
 func TestFileManagementUseCase_Rename(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		cfg := &config.Config{
@@ -371,9 +744,10 @@ func TestFileManagementUseCase_Rename(t *testing.T) {
 		}
 		uc := NewFileManagementUseCase(mockStorage, cfg)
 
-		err := uc.Rename("old.txt", "new.txt")
+		finalPath, err := uc.Rename("old.txt", "new.txt", "")
 
 		assert.NoError(t, err)
+		assert.Equal(t, "new.txt", finalPath)
 		assert.Equal(t, "old.txt", oldPath)
 		assert.Equal(t, "new.txt", newPath)
 	})
@@ -408,6 +782,37 @@ func TestFileManagementUseCase_CreateFolder(t *testing.T) {
 	})
 }
 
+func TestFileManagementUseCase_CreateFolder_Nested(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"all components valid", "valid/also-valid/leaf", nil},
+		{"invalid leaf", "valid/also-valid/bad<name>", domain.ErrInvalidName},
+		{"invalid middle component", "valid/bad<name>/leaf", domain.ErrInvalidName},
+		{"invalid first component", "bad|name/leaf", domain.ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+
+			err := uc.CreateFolder(tt.path)
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				assert.DirExists(t, filepath.Join(tmpDir, tt.path))
+				return
+			}
+			assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+			entries, readErr := os.ReadDir(tmpDir)
+			require.NoError(t, readErr)
+			assert.Empty(t, entries, "valid prefix must not be created")
+		})
+	}
+}
+
 func TestFileManagementUseCase_shouldSkipFile(t *testing.T) {
 	cfg := &config.Config{
 		File: config.FileConfig{
@@ -437,15 +842,613 @@ func TestFileManagementUseCase_shouldSkipFile(t *testing.T) {
 }
 
 type mockFileInfo struct {
-	name  string
-	size  int64
-	mode  os.FileMode
-	isDir bool
+	name    string
+	size    int64
+	mode    os.FileMode
+	isDir   bool
+	modTime time.Time
 }
 
 func (m *mockFileInfo) Name() string       { return m.name }
 func (m *mockFileInfo) Size() int64        { return m.size }
 func (m *mockFileInfo) Mode() os.FileMode  { return m.mode }
 func (m *mockFileInfo) IsDir() bool        { return m.isDir }
-func (m *mockFileInfo) ModTime() time.Time { return time.Time{} }
+func (m *mockFileInfo) ModTime() time.Time { return m.modTime }
 func (m *mockFileInfo) Sys() interface{}   { return nil }
+
+func TestFileManagementUseCase_ServeFolderAsZip_EmptyDirs(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"backup/a.txt":               "a",
+		"backup/full/b.txt":          "b",
+		"backup/only-hidden/.secret": "s",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "backup", "empty"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "backup", "parent", "nested-empty"), 0o755))
+
+	zipNames := func() []string {
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), "backup"))
+		body := w.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		return names
+	}
+
+	t.Run("disabled drops empty dirs", func(t *testing.T) {
+		uc.cfg.File.ZipIncludeEmptyDirs = false
+		assert.ElementsMatch(t, []string{"a.txt", "full/b.txt"}, zipNames())
+	})
+
+	t.Run("enabled keeps empty dirs", func(t *testing.T) {
+		uc.cfg.File.ZipIncludeEmptyDirs = true
+		assert.ElementsMatch(t, []string{
+			"a.txt",
+			"full/b.txt",
+			"empty/",
+			"only-hidden/",
+			"parent/nested-empty/",
+		}, zipNames())
+	})
+}
+
+func TestFileManagementUseCase_ServeFile_Head(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"docs/report.txt": "hello world"})
+
+	head := httptest.NewRecorder()
+	require.NoError(t, uc.ServeFile(head, httptest.NewRequest("HEAD", "/download", nil), "docs/report.txt"))
+
+	assert.Equal(t, http.StatusOK, head.Code)
+	assert.Empty(t, head.Body.String())
+	assert.Equal(t, "11", head.Header().Get("Content-Length"))
+	assert.Contains(t, head.Header().Get("Content-Type"), "text/plain")
+	etag := head.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	t.Run("GET sends the same headers with the body", func(t *testing.T) {
+		get := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFile(get, httptest.NewRequest("GET", "/download", nil), "docs/report.txt"))
+
+		assert.Equal(t, "hello world", get.Body.String())
+		assert.Equal(t, etag, get.Header().Get("ETag"))
+	})
+
+	t.Run("matching If-None-Match", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/download", nil)
+		r.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFile(w, r, "docs/report.txt"))
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("ETag changes with content", func(t *testing.T) {
+		writeTree(t, tmpDir, map[string]string{"docs/report.txt": "hello world, again"})
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFile(w, httptest.NewRequest("HEAD", "/download", nil), "docs/report.txt"))
+
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("folder zip HEAD is not built", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("HEAD", "/download-folder", nil), "docs"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, domain.MIMEZip, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "docs.zip")
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestFileManagementUseCase_ServeFile_Inline(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"media/clip.mp4": "0123456789", "page.html": "<script></script>"})
+
+	tests := []struct {
+		name            string
+		url             string
+		path            string
+		wantDisposition string
+		wantCSP         string
+	}{
+		{
+			name: "attachment by default", url: "/download", path: "media/clip.mp4",
+			wantDisposition: `attachment; filename="clip.mp4"`,
+		},
+		{
+			name: "inline", url: "/download?inline=true", path: "media/clip.mp4",
+			wantDisposition: `inline; filename="clip.mp4"`, wantCSP: "sandbox",
+		},
+		{
+			name: "inline html is sandboxed", url: "/download?inline=true", path: "page.html",
+			wantDisposition: `inline; filename="page.html"`, wantCSP: "sandbox",
+		},
+		{
+			name: "other values keep attachment", url: "/download?inline=1", path: "media/clip.mp4",
+			wantDisposition: `attachment; filename="clip.mp4"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			require.NoError(t, uc.ServeFile(w, httptest.NewRequest("GET", tt.url, nil), tt.path))
+
+			assert.Equal(t, tt.wantDisposition, w.Header().Get("Content-Disposition"))
+			assert.Equal(t, tt.wantCSP, w.Header().Get("Content-Security-Policy"))
+		})
+	}
+
+	t.Run("inline range for seeking", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/download?inline=true", nil)
+		r.Header.Set("Range", "bytes=2-5")
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFile(w, r, "media/clip.mp4"))
+
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "2345", w.Body.String())
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	})
+}
+
+func TestFileManagementUseCase_Capabilities(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Server:  config.ServerConfig{MaxUploadSize: 1024},
+			Storage: config.StorageConfig{Type: "local", BasePath: "/secret/storage"},
+			File: config.FileConfig{
+				MaxNameLength:       255,
+				ForbiddenExtensions: []string{".env"},
+				ValidNameRegex:      `^[\w\-. ]+$`,
+				MaxZipSourceBytes:   1 << 20,
+			},
+			Routes: config.RoutesConfig{DownloadFolder: "/download-folder"},
+		}
+	}
+
+	t.Run("configured features", func(t *testing.T) {
+		caps := NewFileManagementUseCase(&mockFileStorage{}, newConfig()).Capabilities()
+
+		assert.Equal(t, "local", caps.StorageType)
+		assert.Equal(t, int64(1024), caps.MaxUploadSize)
+		assert.Equal(t, 255, caps.MaxNameLength)
+		assert.Equal(t, []string{".env"}, caps.ForbiddenExtensions)
+		assert.Contains(t, caps.ConflictPolicies, domain.ConflictVersion)
+		assert.True(t, caps.Versioning)
+		assert.True(t, caps.ZipDownload)
+		assert.Equal(t, int64(1<<20), caps.MaxZipSourceBytes)
+	})
+
+	t.Run("disabled features are reported off", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.File.DisableVersioning = true
+		cfg.Routes.DownloadFolder = ""
+
+		caps := NewFileManagementUseCase(&mockFileStorage{}, cfg).Capabilities()
+
+		assert.NotContains(t, caps.ConflictPolicies, domain.ConflictVersion)
+		assert.False(t, caps.Versioning)
+		assert.False(t, caps.ZipDownload)
+	})
+}
+
+func TestFileManagementUseCase_sanitizePath_UnicodeNormalization(t *testing.T) {
+	const (
+		nfcName = "caf\u00e9.txt"  // é одним символом
+		nfdName = "cafe\u0301.txt" // e + combining acute
+	)
+
+	newUseCase := func(normalize bool) *FileManagementUseCase {
+		cfg := &config.Config{
+			File: config.FileConfig{
+				MaxNameLength:    255,
+				ValidNameRegex:   `^[\p{L}\p{M}\p{N}_\-. ]+$`,
+				NormalizeUnicode: normalize,
+			},
+		}
+		return NewFileManagementUseCase(&mockFileStorage{basePath: "/storage"}, cfg)
+	}
+
+	t.Run("normalization on", func(t *testing.T) {
+		uc := newUseCase(true)
+
+		fromNFC, err := uc.sanitizePath(nfcName)
+		require.NoError(t, err)
+		fromNFD, err := uc.sanitizePath("docs/" + nfdName)
+		require.NoError(t, err)
+
+		assert.Equal(t, nfcName, fromNFC)
+		assert.Equal(t, "docs/"+nfcName, fromNFD)
+	})
+
+	t.Run("normalization off", func(t *testing.T) {
+		uc := newUseCase(false)
+
+		fromNFD, err := uc.sanitizePath(nfdName)
+		require.NoError(t, err)
+
+		assert.Equal(t, nfdName, fromNFD)
+	})
+
+	t.Run("upload stores one name", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		uc.cfg.File.NormalizeUnicode = true
+		uc.validName = regexp.MustCompile(`^[\p{L}\p{M}\p{N}_\-. ]+$`)
+
+		_, err := uc.UploadFile(nfcName, strings.NewReader("nfc"), domain.UploadOptions{})
+		require.NoError(t, err)
+		_, err = uc.UploadFile(nfdName, strings.NewReader("nfd"), domain.UploadOptions{})
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, nfcName, entries[0].Name())
+	})
+}
+
+func TestFileManagementUseCase_sanitizePath_Backslashes(t *testing.T) {
+	newUseCase := func(normalize bool) *FileManagementUseCase {
+		cfg := &config.Config{
+			File: config.FileConfig{
+				MaxNameLength:        255,
+				ValidNameRegex:       `^[\w\-. \\]+$`,
+				NormalizeBackslashes: normalize,
+			},
+		}
+		return NewFileManagementUseCase(&mockFileStorage{basePath: "/storage"}, cfg)
+	}
+
+	tests := []struct {
+		name      string
+		normalize bool
+		path      string
+		want      string
+		wantErr   error
+	}{
+		{name: "nested windows path", normalize: true, path: `a\b\c.txt`, want: "a/b/c.txt"},
+		{name: "mixed separators", normalize: true, path: `docs/a\c.txt`, want: "docs/a/c.txt"},
+		{name: "traversal caught", normalize: true, path: `a\..\..\etc\passwd`, wantErr: domain.ErrPathTraversal},
+		{name: "kept literally when off", normalize: false, path: `a\b\c.txt`, want: `a\b\c.txt`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newUseCase(tt.normalize).sanitizePath(tt.path)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("upload lands in nested folders", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		uc.cfg.File.NormalizeBackslashes = true
+
+		storedPath, err := uc.UploadFile(`reports\2024\q1.csv`, strings.NewReader("x"), domain.UploadOptions{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "reports/2024/q1.csv", storedPath)
+		assert.FileExists(t, filepath.Join(tmpDir, "reports", "2024", "q1.csv"))
+	})
+}
+
+func TestFileManagementUseCase_OpenWriter(t *testing.T) {
+	var gotPath string
+	storage := &mockFileStorage{
+		openWriterFunc: func(relPath string) (io.WriteCloser, error) {
+			gotPath = relPath
+			return nopWriteCloser{io.Discard}, nil
+		},
+	}
+	uc := NewFileManagementUseCase(storage, &config.Config{
+		File: config.FileConfig{MaxNameLength: 255, ValidNameRegex: `^[\w\-. ()]+$`},
+	})
+
+	t.Run("sanitized path", func(t *testing.T) {
+		w, err := uc.OpenWriter("reports/./daily.txt")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, "reports/daily.txt", gotPath)
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		_, err := uc.OpenWriter("../escape.txt")
+		assert.True(t, errors.Is(err, domain.ErrPathTraversal))
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		storage.openWriterFunc = func(string) (io.WriteCloser, error) { return nil, os.ErrPermission }
+		_, err := uc.OpenWriter("report.txt")
+		assert.True(t, errors.Is(err, os.ErrPermission))
+	})
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// Range отдаёт http.ServeFile/ServeContent; тест держит 416 на всех путях отдачи, чтобы их переделки его не сломали.
+func TestFileManagementUseCase_Serve_UnsatisfiableRange(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"docs/report.txt":    "hello world",
+		"docs/report.txt.gz": "gzipped",
+	})
+	rangeRequest := func(target, acceptEncoding string) *http.Request {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Set("Range", "bytes=99999999-")
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		gzip      bool
+		zipCache  bool
+		serve     func(w http.ResponseWriter) error
+		wantRange string
+	}{
+		{
+			name: "plain file",
+			serve: func(w http.ResponseWriter) error {
+				return uc.ServeFile(w, rangeRequest("/download", ""), "docs/report.txt")
+			},
+			wantRange: "bytes */11",
+		},
+		{
+			name: "gzip variant",
+			gzip: true,
+			serve: func(w http.ResponseWriter) error {
+				return uc.ServeFile(w, rangeRequest("/download", "gzip"), "docs/report.txt")
+			},
+			wantRange: "bytes */7",
+		},
+		{
+			name:     "cached folder zip",
+			zipCache: true,
+			serve: func(w http.ResponseWriter) error {
+				return uc.ServeFolderAsZip(w, rangeRequest("/download-folder", ""), "docs")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.GzipStatic = tt.gzip
+			uc.zipCache = nil
+			if tt.zipCache {
+				uc.zipCache = newZipCache(t.TempDir(), 1<<20)
+				// первый запрос кладёт архив в кеш, Range работает уже по нему.
+				require.NoError(t, uc.ServeFolderAsZip(httptest.NewRecorder(),
+					httptest.NewRequest("GET", "/download-folder", nil), "docs"))
+			}
+
+			w := httptest.NewRecorder()
+			require.NoError(t, tt.serve(w))
+
+			assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+			if tt.wantRange != "" {
+				assert.Equal(t, tt.wantRange, w.Header().Get("Content-Range"))
+			} else {
+				assert.Regexp(t, `^bytes \*/\d+$`, w.Header().Get("Content-Range"))
+			}
+		})
+	}
+}
+
+// ServeFile читает через storage.Open: так зашифрованное хранилище отдаёт расшифрованный поток, а не байты с диска.
+func TestFileManagementUseCase_ServeFile_ReadsThroughStorage(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"docs/report.txt": "ciphertext on disk"})
+	hookStorage(uc).openFunc = func(relPath string) (io.ReadSeekCloser, error) {
+		assert.Equal(t, filepath.Join("docs", "report.txt"), relPath)
+		return readSeekNopCloser{strings.NewReader("plain text")}, nil
+	}
+
+	tests := []struct {
+		name       string
+		rangeValue string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "whole file", wantStatus: http.StatusOK, wantBody: "plain text"},
+		{name: "range", rangeValue: "bytes=6-", wantStatus: http.StatusPartialContent, wantBody: "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/download", nil)
+			if tt.rangeValue != "" {
+				r.Header.Set("Range", tt.rangeValue)
+			}
+			w := httptest.NewRecorder()
+
+			require.NoError(t, uc.ServeFile(w, r, "docs/report.txt"))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+
+	t.Run("folder", func(t *testing.T) {
+		err := uc.ServeFile(httptest.NewRecorder(), httptest.NewRequest("GET", "/download", nil), "docs")
+		assert.ErrorIs(t, err, domain.ErrIsDirectory)
+		assert.ErrorIs(t, err, domain.ErrInvalidParameter)
+	})
+}
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error { return nil }
+
+// newMapStorage хранилище без диска под собой: Stat, ReadDirectory и Open отвечают из fsys,
+// а basePath пустой папкой, так что любое чтение мимо хранилища ничего не найдёт.
+func newMapStorage(t *testing.T, fsys fstest.MapFS) *mockFileStorage {
+	t.Helper()
+	key := func(relPath string) string { return path.Clean(filepath.ToSlash("./" + relPath)) }
+	return &mockFileStorage{
+		basePath: t.TempDir(),
+		statFunc: func(relPath string) (os.FileInfo, error) {
+			return fs.Stat(fsys, key(relPath))
+		},
+		readDirectoryFunc: func(relPath string) ([]os.FileInfo, error) {
+			entries, err := fs.ReadDir(fsys, key(relPath))
+			if err != nil {
+				return nil, err
+			}
+			infos := make([]os.FileInfo, 0, len(entries))
+			for _, e := range entries {
+				info, infoErr := e.Info()
+				require.NoError(t, infoErr)
+				infos = append(infos, info)
+			}
+			return infos, nil
+		},
+		openFunc: func(relPath string) (io.ReadSeekCloser, error) {
+			f, err := fsys.Open(key(relPath))
+			if err != nil {
+				return nil, err
+			}
+			return f.(io.ReadSeekCloser), nil
+		},
+	}
+}
+
+// newMutableMapStorage как newMapStorage, но Move и Remove меняют саму карту, как на настоящем хранилище.
+func newMutableMapStorage(t *testing.T, fsys fstest.MapFS) *mockFileStorage {
+	t.Helper()
+	key := func(relPath string) string { return path.Clean(filepath.ToSlash("./" + relPath)) }
+	storage := newMapStorage(t, fsys)
+	storage.moveFunc = func(oldRel, newRel string) error {
+		from, to := key(oldRel), key(newRel)
+		for name, file := range fsys {
+			if name == from || strings.HasPrefix(name, from+"/") {
+				fsys[to+strings.TrimPrefix(name, from)] = file
+				delete(fsys, name)
+			}
+		}
+		return nil
+	}
+	storage.removeFunc = func(relPath string) error {
+		k := key(relPath)
+		for name := range fsys {
+			if name == k || strings.HasPrefix(name, k+"/") {
+				delete(fsys, name)
+			}
+		}
+		return nil
+	}
+	return storage
+}
+
+func TestFileManagementUseCase_Serve_WithoutDisk(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	uc.storage = newMapStorage(t, fstest.MapFS{
+		"docs/report.txt":   {Data: []byte("report"), ModTime: time.Unix(1700000000, 0)},
+		"docs/sub/note.txt": {Data: []byte("note")},
+		"docs/.secret":      {Data: []byte("hidden")},
+	})
+
+	t.Run("file", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFile(w, httptest.NewRequest("GET", "/download", nil), "docs/report.txt"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "report", w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="report.txt"`)
+		assert.Equal(t, time.Unix(1700000000, 0).UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := uc.ServeFile(httptest.NewRecorder(), httptest.NewRequest("GET", "/download", nil), "docs/nope.txt")
+		assert.ErrorIs(t, err, domain.ErrFileNotFound)
+	})
+
+	t.Run("folder as zip", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/download", nil), "docs"))
+
+		assert.Equal(t, map[string]string{
+			"report.txt":   "report",
+			"sub/note.txt": "note",
+		}, zipContents(t, w.Body.Bytes()))
+	})
+
+	t.Run("missing folder", func(t *testing.T) {
+		err := uc.ServeFolderAsZip(httptest.NewRecorder(), httptest.NewRequest("GET", "/download", nil), "nope")
+		assert.ErrorIs(t, err, domain.ErrFileNotFound)
+	})
+}
+
+// несколько диапазонов в одном Range качалки шлют ради докачки кусками; ответ должен остаться multipart/byteranges.
+func TestFileManagementUseCase_Serve_MultiRange(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"docs/report.txt":    "hello world",
+		"docs/report.txt.gz": "gzipped body",
+	})
+
+	tests := []struct {
+		name           string
+		gzip           bool
+		acceptEncoding string
+		wantParts      []string
+	}{
+		{name: "plain file", wantParts: []string{"hel", "world"}},
+		{name: "gzip variant", gzip: true, acceptEncoding: "gzip", wantParts: []string{"gzi", "d bod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.GzipStatic = tt.gzip
+			r := httptest.NewRequest("GET", "/download", nil)
+			r.Header.Set("Range", "bytes=0-2,6-10")
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			require.NoError(t, uc.ServeFile(w, r, "docs/report.txt"))
+
+			assert.Equal(t, http.StatusPartialContent, w.Code)
+			assert.Equal(t, tt.wantParts, readByteRanges(t, w))
+		})
+	}
+}
+
+// readByteRanges тела частей multipart/byteranges по порядку.
+func readByteRanges(t *testing.T, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/byteranges", mediaType)
+
+	var parts []string
+	reader := multipart.NewReader(w.Body, params["boundary"])
+	for {
+		part, partErr := reader.NextPart()
+		if errors.Is(partErr, io.EOF) {
+			return parts
+		}
+		require.NoError(t, partErr)
+		assert.NotEmpty(t, part.Header.Get("Content-Range"))
+		body, readErr := io.ReadAll(part)
+		require.NoError(t, readErr)
+		parts = append(parts, string(body))
+	}
+}
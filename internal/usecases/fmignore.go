@@ -0,0 +1,195 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// ignoreRule одна строка `.fmignore`. base — папка (от корня хранилища, через `/`), где лежит файл:
+// шаблон со слешем привязан к ней, без слеша совпадает с именем на любой глубине под ней.
+type ignoreRule struct {
+	base     string
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher правила от корня до текущей папки, как у gitignore: ниже по дереву добавляются новые,
+// побеждает последнее совпавшее. nil — исключений нет.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// ignored relPath от корня хранилища. исключённая папка не обходится вовсе,
+// поэтому `!` не вернёт файл из неё — так же ведёт себя git.
+func (m *ignoreMatcher) ignored(relPath string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		target, ok := ruleTarget(rule, relPath)
+		if ok && rule.re.MatchString(target) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// ruleTarget с чем сравнивать шаблон: путь относительно base или только имя. ok=false — путь не под base.
+func ruleTarget(rule ignoreRule, relPath string) (string, bool) {
+	inner := relPath
+	if rule.base != domain.PathCurrent {
+		var found bool
+		inner, found = strings.CutPrefix(relPath, rule.base+domain.PathRoot)
+		if !found {
+			return "", false
+		}
+	}
+	if rule.anchored {
+		return inner, true
+	}
+	return path.Base(inner), true
+}
+
+// ignoreFor правила всех `.fmignore` от корня до dirRel включительно. hidden — сама dirRel или одна из папок
+// на пути к ней исключена правилами выше неё. nil без ошибки, если file.fmignore выключен.
+func (uc *FileManagementUseCase) ignoreFor(dirRel string) (m *ignoreMatcher, hidden bool, err error) {
+	if !uc.cfg.File.FMIgnore {
+		return nil, false, nil
+	}
+	m = &ignoreMatcher{}
+	if err = m.add(uc, domain.PathCurrent); err != nil {
+		return nil, false, err
+	}
+	clean := filepath.ToSlash(filepath.Clean(dirRel))
+	if clean == domain.PathCurrent {
+		return m, false, nil
+	}
+	dir := domain.PathCurrent
+	for _, component := range strings.Split(clean, domain.PathRoot) {
+		dir = path.Join(dir, component)
+		if m.ignored(dir, true) {
+			return m, true, nil
+		}
+		if m, err = m.child(uc, dir); err != nil {
+			return nil, false, err
+		}
+	}
+	return m, false, nil
+}
+
+// child правила для вложенной папки: родительские плюс её `.fmignore`. родитель не меняется.
+func (m *ignoreMatcher) child(uc *FileManagementUseCase, dirRel string) (*ignoreMatcher, error) {
+	if m == nil {
+		return nil, nil
+	}
+	c := &ignoreMatcher{rules: m.rules[:len(m.rules):len(m.rules)]}
+	if err := c.add(uc, filepath.ToSlash(dirRel)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (m *ignoreMatcher) add(uc *FileManagementUseCase, dirRel string) error {
+	data, err := uc.readStored(filepath.Join(filepath.FromSlash(dirRel), domain.IgnoreFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s in '%s': %w", domain.IgnoreFileName, dirRel, err)
+	}
+	rules, err := parseIgnore(dirRel, data)
+	if err != nil {
+		return err
+	}
+	m.rules = append(m.rules, rules...)
+	return nil
+}
+
+// parseIgnore подмножество gitignore: `#` комментарии, `!` отрицание, `/` в конце — только папки,
+// `/` в начале или середине привязывает к папке файла, `*`, `?`, `[...]` и `**`.
+func parseIgnore(base string, data []byte) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// `\#` и `\!` — имя, начинающееся с этих символов.
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, domain.PathRoot) {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, domain.PathRoot)
+		}
+		if line == "" {
+			continue
+		}
+		rule.anchored = strings.Contains(line, domain.PathRoot)
+		line = strings.TrimPrefix(line, domain.PathRoot)
+
+		re, err := regexp.Compile("^" + ignoreGlobToRegexp(line) + "$")
+		if err != nil {
+			return nil, fmt.Errorf("bad %s pattern '%s' in '%s': %w",
+				domain.IgnoreFileName, line, base, domain.ErrInvalidParameter)
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+func ignoreGlobToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
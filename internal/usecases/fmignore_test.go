@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+var ignoreTree = map[string]string{
+	".fmignore":            "# logs\n*.log\n!keep.log\nbuild/\n/secret.txt\ndocs/**/draft.md\n",
+	"a.log":                "x",
+	"keep.log":             "x",
+	"secret.txt":           "x",
+	"t.tmp":                "x",
+	"build/x.bin":          "x",
+	"docs/draft.md":        "x",
+	"docs/a/b/draft.md":    "x",
+	"docs/guide.md":        "x",
+	"sub/.fmignore":        "*.tmp\n",
+	"sub/secret.txt":       "x",
+	"sub/t.tmp":            "x",
+	"sub/ok.txt":           "x",
+	"sub/build/y.bin":      "x",
+	"sub/deep/nested.tmp":  "x",
+	"sub/deep/nested.txt":  "x",
+	"sub/deep/debug.log":   "x",
+	"sub/deep/keep.log":    "x",
+	"sub/deep/build.txt":   "x",
+	"sub/deep/.fmignore":   "!*.tmp\n",
+	"sub/deep/other.tmp":   "x",
+	"sub/deep/readme.md":   "x",
+	"sub/deep/build/z.bin": "x",
+}
+
+func fmIgnoreOn(f *config.FileConfig) {
+	f.FMIgnore = true
+}
+
+func listNames(t *testing.T, uc *FileManagementUseCase, path string) []string {
+	t.Helper()
+	files, err := uc.List(path, domain.ListOptions{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileManagementUseCase_List_FMIgnore(t *testing.T) {
+	t.Run("patterns accumulate down the tree", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, fmIgnoreOn)
+		writeTree(t, tmpDir, ignoreTree)
+
+		assert.Equal(t, []string{"docs", "keep.log", "sub", "t.tmp"}, listNames(t, uc, ""))
+		assert.Equal(t, []string{"deep", "ok.txt", "secret.txt"}, listNames(t, uc, "sub"))
+		assert.Equal(t, []string{"build.txt", "keep.log", "nested.tmp", "nested.txt", "other.tmp", "readme.md"},
+			listNames(t, uc, "sub/deep"))
+		assert.Equal(t, []string{"a", "guide.md"}, listNames(t, uc, "docs"))
+	})
+
+	t.Run("excluded directory is not listed directly", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, fmIgnoreOn)
+		writeTree(t, tmpDir, ignoreTree)
+
+		for _, path := range []string{"build", "sub/build", "sub/deep/build"} {
+			_, err := uc.List(path, domain.ListOptions{})
+			assert.ErrorIs(t, err, domain.ErrFileNotFound, path)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t)
+		writeTree(t, tmpDir, ignoreTree)
+
+		assert.Contains(t, listNames(t, uc, ""), domain.IgnoreFileName)
+		assert.Contains(t, listNames(t, uc, ""), "a.log")
+		assert.Contains(t, listNames(t, uc, "sub"), "t.tmp")
+	})
+}
+
+func TestFileManagementUseCase_WalkArchive_FMIgnore(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, fmIgnoreOn)
+	writeTree(t, tmpDir, ignoreTree)
+
+	collect := func(rel string) []string {
+		var files []string
+		err := uc.walkArchive(rel, func(_, r string, _ os.FileInfo) error {
+			files = append(files, filepath.ToSlash(r))
+			return nil
+		})
+		require.NoError(t, err)
+		sort.Strings(files)
+		return files
+	}
+
+	assert.Equal(t, []string{
+		"docs/guide.md", "keep.log",
+		"sub/deep/build.txt", "sub/deep/keep.log", "sub/deep/nested.tmp", "sub/deep/nested.txt",
+		"sub/deep/other.tmp", "sub/deep/readme.md",
+		"sub/ok.txt", "sub/secret.txt", "t.tmp",
+	}, collect(""))
+	// zip подпапки учитывает правила родителей.
+	assert.Equal(t, []string{
+		"deep/build.txt", "deep/keep.log", "deep/nested.tmp", "deep/nested.txt", "deep/other.tmp", "deep/readme.md",
+		"ok.txt", "secret.txt",
+	}, collect("sub"))
+	assert.Empty(t, collect("build"))
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	rules, err := parseIgnore("src", []byte("*.o\n/gen\nlib/**\n**/cache/\nfile?.txt\n[ab].md\n\\#notes\n"))
+	require.NoError(t, err)
+	m := &ignoreMatcher{rules: rules}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"src/main.o", false, true},
+		{"src/a/b/main.o", false, true},
+		{"main.o", false, false},
+		{"src/gen", true, true},
+		{"src/a/gen", true, false},
+		{"src/lib/x/y.go", false, true},
+		{"src/lib", true, false},
+		{"src/a/cache", true, true},
+		{"src/a/cache", false, false},
+		{"src/file1.txt", false, true},
+		{"src/file10.txt", false, false},
+		{"src/a.md", false, true},
+		{"src/c.md", false, false},
+		{"src/#notes", false, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.ignored(tt.path, tt.isDir), tt.path)
+	}
+}
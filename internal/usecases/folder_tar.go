@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// ServeFolderAsTarGz отдаёт папку как tar.gz: в отличие от zip, tar хранит права (и с
+// file.tar_preserve_ownership владельцев). в архив попадает то же, что в zip (скрытые, drop-box, корзина
+// и .fmignore исключаются), плюс записи всех папок, чтобы сохранились их права.
+// симлинк на файл кладётся обычным файлом, как в zip.
+func (uc *FileManagementUseCase) ServeFolderAsTarGz(w http.ResponseWriter, path string) error {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return fmt.Errorf("download from drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return fmt.Errorf("download of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+	if limitErr := uc.checkZipLimits(sanitizedPath); limitErr != nil {
+		return limitErr
+	}
+
+	name := uc.archiveName(sanitizedPath, uc.cfg.File.ZipName.FullPath) + domain.ExtensionTarGz
+	w.Header().Set("Content-Type", domain.MIMEGzip)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer func() {
+		if closeErr := tarWriter.Close(); closeErr != nil {
+			logrus.Errorf("Failed to close tar writer: %v", closeErr)
+		}
+		if closeErr := gzipWriter.Close(); closeErr != nil {
+			logrus.Errorf("Failed to close gzip writer: %v", closeErr)
+		}
+	}()
+
+	err = uc.walkArchiveEntries(sanitizedPath, func(file, rel string, info os.FileInfo) error {
+		return uc.addToTar(tarWriter, file, rel, info)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create tar.gz for folder '%s': %w", sanitizedPath, err)
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) addToTar(tw *tar.Writer, file, rel string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := uc.storage.Stat(file)
+		if err != nil || !target.Mode().IsRegular() {
+			logrus.Warnf("Tar: symlink '%s' skipped", rel)
+			return nil
+		}
+		info = target
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return nil
+	}
+
+	hdr, err := tarHeader(info, filepath.ToSlash(rel), uc.cfg.File.TarPreserveOwnership)
+	if err != nil {
+		return fmt.Errorf("tar header for '%s': %w", rel, err)
+	}
+	if err = tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write tar header: %w", err)
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	src, err := uc.storage.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer closeLogged(src, file)
+	// ровно hdr.Size байт: файл, дописанный во время выгрузки, не должен сломать архив.
+	if _, err = io.CopyN(tw, src, hdr.Size); err != nil {
+		return fmt.Errorf("failed to copy file to tar: %w", err)
+	}
+	return nil
+}
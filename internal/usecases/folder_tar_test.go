@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+type tarEntry struct {
+	content string
+	mode    int64
+	isDir   bool
+}
+
+// tarGzContents записи tar.gz по имени.
+func tarGzContents(t *testing.T, data []byte) map[string]tarEntry {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	entries := map[string]tarEntry{}
+	for {
+		hdr, nextErr := tr.Next()
+		if errors.Is(nextErr, io.EOF) {
+			return entries
+		}
+		require.NoError(t, nextErr)
+		content, readErr := io.ReadAll(tr)
+		require.NoError(t, readErr)
+		entries[hdr.Name] = tarEntry{content: string(content), mode: hdr.Mode, isDir: hdr.Typeflag == tar.TypeDir}
+	}
+}
+
+func TestFileManagementUseCase_ServeFolderAsTarGz(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"proj/run.sh":         "#!/bin/sh",
+		"proj/src/main.go":    "package main",
+		"proj/.env":           "secret",
+		"proj/.git/config":    "x",
+		"proj/empty/.keep":    "",
+		"other/untouched.txt": "o",
+	})
+	require.NoError(t, os.Chmod(filepath.Join(tmpDir, "proj/run.sh"), 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "other/untouched.txt"),
+		filepath.Join(tmpDir, "proj/link.txt")))
+
+	w := httptest.NewRecorder()
+	require.NoError(t, uc.ServeFolderAsTarGz(w, "proj"))
+
+	assert.Equal(t, domain.MIMEGzip, w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="proj.tar.gz"`, w.Header().Get("Content-Disposition"))
+	entries := tarGzContents(t, w.Body.Bytes())
+	assert.Equal(t, map[string]tarEntry{
+		"run.sh":      {content: "#!/bin/sh", mode: 0o755},
+		"src/":        {mode: 0o755, isDir: true},
+		"src/main.go": {content: "package main", mode: 0o644},
+		"empty/":      {mode: 0o755, isDir: true},
+		"link.txt":    {content: "o", mode: 0o644},
+	}, entries)
+}
+
+func TestFileManagementUseCase_ServeFolderAsTarGz_Errors(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"a.txt": "a"})
+
+	assert.ErrorIs(t, uc.ServeFolderAsTarGz(httptest.NewRecorder(), "missing"), domain.ErrFileNotFound)
+	assert.ErrorIs(t, uc.ServeFolderAsTarGz(httptest.NewRecorder(), "a.txt"), domain.ErrFileNotFound)
+	assert.ErrorIs(t, uc.ServeFolderAsTarGz(httptest.NewRecorder(), "../etc"), domain.ErrPathTraversal)
+}
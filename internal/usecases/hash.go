@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"file-manager/internal/domain"
+)
+
+// HashFile sha256 содержимого файла в hex. файл читается потоком через storage,
+// так что у зашифрованных считается хэш открытого текста. папки не хэшируются.
+func (uc *FileManagementUseCase) HashFile(path string) (string, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return "", err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return "", fmt.Errorf("hash in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return "", fmt.Errorf("hash of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("file not found at '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return "", fmt.Errorf("failed to stat file at '%s': %w", sanitizedPath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("hash of folder '%s': %w", sanitizedPath, domain.ErrUnsupportedOperation)
+	}
+
+	sum, err := uc.hashStored(sanitizedPath)
+	if err != nil {
+		return "", fmt.Errorf("could not hash '%s': %w", sanitizedPath, err)
+	}
+	return hex.EncodeToString(sum), nil
+}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_HashFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "file", path: "a.txt", want: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{
+			name: "empty file", path: "empty.txt",
+			want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{name: "missing", path: "nope.txt", wantErr: domain.ErrFileNotFound},
+		{name: "folder", path: "dir", wantErr: domain.ErrUnsupportedOperation},
+		{name: "traversal", path: "../etc/passwd", wantErr: domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			writeTree(t, tmpDir, map[string]string{"a.txt": "hello", "empty.txt": "", "dir/x.txt": "x"})
+
+			got, err := uc.HashFile(tt.path)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
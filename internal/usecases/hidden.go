@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// isHiddenPath путь ведёт в скрытый файл или лежит внутри скрытой папки.
+// zip пропускает оба случая, так что и прямое скачивание (file.block_hidden_download) проверяет все компоненты.
+func isHiddenPath(relPath string) bool {
+	clean := filepath.ToSlash(filepath.Clean(relPath))
+	if clean == domain.PathCurrent {
+		return false
+	}
+	for _, component := range strings.Split(clean, domain.PathRoot) {
+		if strings.HasPrefix(component, domain.HiddenFilePrefix) {
+			return true
+		}
+	}
+	return false
+}
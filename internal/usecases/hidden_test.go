@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"file-manager/internal/domain"
+)
+
+func TestIsHiddenPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".secret", true},
+		{"docs/.secret", true},
+		{".git/config", true},
+		{"docs/a.txt", false},
+		{"docs/a.tar.gz", false},
+		{".", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, isHiddenPath(tt.path))
+		})
+	}
+}
+
+func TestFileManagementUseCase_BlockHiddenDownload(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		".secret":            "token",
+		"docs/.hidden/a.txt": "a",
+		"docs/visible.txt":   "v",
+	})
+
+	serve := func(path string) (*httptest.ResponseRecorder, error) {
+		w := httptest.NewRecorder()
+		return w, uc.ServeFile(w, httptest.NewRequest("GET", "/download", nil), path)
+	}
+
+	t.Run("disabled serves hidden files", func(t *testing.T) {
+		uc.cfg.File.BlockHiddenDownload = false
+		w, err := serve(".secret")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"hidden file", ".secret", domain.ErrPermissionDenied},
+		{"inside hidden folder", "docs/.hidden/a.txt", domain.ErrPermissionDenied},
+		{"regular file", "docs/visible.txt", nil},
+	}
+	for _, tt := range tests {
+		t.Run("enabled "+tt.name, func(t *testing.T) {
+			uc.cfg.File.BlockHiddenDownload = true
+			w, err := serve(tt.path)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			assert.Empty(t, w.Body.String())
+		})
+	}
+
+	t.Run("enabled blocks hidden folder zip", func(t *testing.T) {
+		uc.cfg.File.BlockHiddenDownload = true
+		w := httptest.NewRecorder()
+		err := uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), "docs/.hidden")
+		assert.True(t, errors.Is(err, domain.ErrPermissionDenied), "got %v", err)
+	})
+}
@@ -0,0 +1,266 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// stripJPEGQuality jpeg перекодируется с потерями, 92 на глаз не отличить от оригинала с телефона.
+	stripJPEGQuality = 92
+	// stripMaxPixels больше не декодируем: картинка целиком ложится в память, 64Мп это ~256МБ RGBA.
+	stripMaxPixels = 64 << 20
+
+	exifOrientationTag = 0x0112
+)
+
+var (
+	jpegExifHeader = []byte("Exif\x00\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+	// pngMetadataChunks текстовые чанки, EXIF и время, остальное нужно для отрисовки.
+	pngMetadataChunks = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+)
+
+// ImageMetadataStripper PostUploadHook: перекодирует загруженные jpeg/png без EXIF (в т.ч. GPS),
+// XMP и текстовых чанков.
+// файл без метаданных не трогается, чтобы лишний раз не пережимать jpeg.
+// если картинка не декодируется, она остаётся как есть, в лог пишется предупреждение.
+type ImageMetadataStripper struct {
+	storage domain.FileStorage
+}
+
+func NewImageMetadataStripper(storage domain.FileStorage) *ImageMetadataStripper {
+	return &ImageMetadataStripper{storage: storage}
+}
+
+func (s *ImageMetadataStripper) AfterUpload(relPath string, _ domain.UploadInfo) {
+	if err := s.strip(relPath); err != nil {
+		logrus.Warnf("Failed to strip image metadata from %s, file left as is: %v", relPath, err)
+	}
+}
+
+func (s *ImageMetadataStripper) strip(relPath string) error {
+	ext := strings.ToLower(filepath.Ext(relPath))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return nil
+	}
+	// через хранилище: с диска у зашифрованного пришёл бы шифротекст, и EXIF молча остался бы в файле.
+	f, err := s.storage.Open(relPath)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	closeLogged(f, relPath)
+	if err != nil {
+		return err
+	}
+
+	var encoded []byte
+	if ext == ".png" {
+		encoded, err = stripPNG(data)
+	} else {
+		encoded, err = stripJPEG(data)
+	}
+	if err != nil || encoded == nil {
+		return err
+	}
+	return s.storage.WriteFile(relPath, bytes.NewReader(encoded))
+}
+
+// stripJPEG nil без ошибки — метаданных нет.
+func stripJPEG(data []byte) ([]byte, error) {
+	hasMetadata, orientation := jpegMetadata(data)
+	if !hasMetadata {
+		return nil, nil
+	}
+	if err := checkPixels(jpeg.DecodeConfig(bytes.NewReader(data))); err != nil {
+		return nil, err
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode jpeg: %w", err)
+	}
+	// ориентация живёт в EXIF: без неё снимок с телефона лёг бы на бок, поэтому поворачиваем пиксели.
+	img = applyOrientation(img, orientation)
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: stripJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// stripPNG png.Encode пишет только чанки изображения, так что перекодирование без потерь.
+func stripPNG(data []byte) ([]byte, error) {
+	if !pngHasMetadata(data) {
+		return nil, nil
+	}
+	if err := checkPixels(png.DecodeConfig(bytes.NewReader(data))); err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode png: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func checkPixels(cfg image.Config, err error) error {
+	if err != nil {
+		return fmt.Errorf("decode config: %w", err)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > stripMaxPixels {
+		return fmt.Errorf("image %dx%d is too large to re-encode", cfg.Width, cfg.Height)
+	}
+	return nil
+}
+
+// jpegMetadata смотрит сегменты до начала скана: APP1..APP15 (EXIF, XMP, IPTC) и комментарии.
+// APP0 (JFIF) ничего о снимке не говорит. заодно достаёт ориентацию из EXIF, 1 — без поворота.
+// битый заголовок считается метаданными: пусть декодер решит, картинка это или нет.
+func jpegMetadata(data []byte) (hasMetadata bool, orientation int) {
+	orientation = 1
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return false, orientation
+	}
+	for i := 2; i+1 < len(data); {
+		if data[i] != 0xFF {
+			return true, orientation
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			i += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			return hasMetadata, orientation
+		}
+		if i+4 > len(data) {
+			return true, orientation
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return true, orientation
+		}
+		segment := data[i+4 : i+2+length]
+		if (marker >= 0xE1 && marker <= 0xEF) || marker == 0xFE {
+			hasMetadata = true
+		}
+		if marker == 0xE1 && bytes.HasPrefix(segment, jpegExifHeader) {
+			orientation = exifOrientation(segment[len(jpegExifHeader):])
+		}
+		i += 2 + length
+	}
+	return true, orientation
+}
+
+// exifOrientation тег 0x0112 из первого IFD, при любой неразберихе 1.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for n := range count {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag {
+			continue
+		}
+		value := int(order.Uint16(tiff[entry+8 : entry+10]))
+		if value < 1 || value > 8 {
+			return 1
+		}
+		return value
+	}
+	return 1
+}
+
+// applyOrientation поворачивает/отражает картинку так, как её показал бы просмотрщик по EXIF.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// pngHasMetadata ищет текстовые/EXIF чанки, битая структура — тоже повод отдать файл декодеру.
+func pngHasMetadata(data []byte) bool {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return false
+	}
+	for i := len(pngSignature); i+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunk := string(data[i+4 : i+8])
+		if pngMetadataChunks[chunk] {
+			return true
+		}
+		if chunk == "IEND" {
+			return false
+		}
+		i += 12 + length
+	}
+	return true
+}
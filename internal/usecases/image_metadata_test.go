@@ -0,0 +1,225 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+// testImage 4x2: левая половина красная, правая синяя — после поворота видно, куда ушли пиксели.
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := range 2 {
+		for x := range 4 {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// exifSegment APP1 с little-endian TIFF, в первом IFD одна ориентация.
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	ifd := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(ifd[0:], 1)
+	binary.LittleEndian.PutUint16(ifd[2:], exifOrientationTag)
+	binary.LittleEndian.PutUint16(ifd[4:], 3)
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], orientation)
+	payload := append(append([]byte("Exif\x00\x00"), tiff...), ifd...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func jpegWithExif(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(), &jpeg.Options{Quality: 100}))
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), exifSegment(orientation)...), data[2:]...)
+}
+
+func pngWithText(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage()))
+	data := buf.Bytes()
+
+	body := []byte("tEXtComment\x00gps here")
+	chunk := make([]byte, 4, 4+len(body)+4)
+	binary.BigEndian.PutUint32(chunk, uint32(len(body)-4))
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+
+	iend := len(data) - 12
+	return append(append(append([]byte{}, data[:iend]...), chunk...), data[iend:]...)
+}
+
+func TestImageMetadataStripper(t *testing.T) {
+	plainJPEG := func(t *testing.T) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, testImage(), nil))
+		return buf.Bytes()
+	}
+	brokenJPEG := append([]byte{0xFF, 0xD8}, append(exifSegment(1), []byte("not really a jpeg")...)...)
+
+	tests := []struct {
+		name      string
+		file      string
+		content   func(t *testing.T) []byte
+		unchanged bool
+		check     func(t *testing.T, data []byte)
+	}{
+		{
+			name:    "jpeg loses exif and is rotated",
+			file:    "photo.JPG",
+			content: func(t *testing.T) []byte { return jpegWithExif(t, 6) },
+			check: func(t *testing.T, data []byte) {
+				hasMetadata, _ := jpegMetadata(data)
+				assert.False(t, hasMetadata)
+				img, err := jpeg.Decode(bytes.NewReader(data))
+				require.NoError(t, err)
+				assert.Equal(t, image.Rect(0, 0, 2, 4), img.Bounds())
+				// поворот по часовой: левая (красная) половина ушла наверх.
+				r, _, b, _ := img.At(0, 0).RGBA()
+				assert.Greater(t, r, b)
+			},
+		},
+		{
+			name:    "png loses text chunks",
+			file:    "shot.png",
+			content: pngWithText,
+			check: func(t *testing.T, data []byte) {
+				assert.False(t, pngHasMetadata(data))
+				img, err := png.Decode(bytes.NewReader(data))
+				require.NoError(t, err)
+				assert.Equal(t, testImage().Bounds(), img.Bounds())
+			},
+		},
+		{
+			name:      "jpeg without metadata is not re-encoded",
+			file:      "plain.jpg",
+			content:   plainJPEG,
+			unchanged: true,
+		},
+		{
+			name:      "undecodable image is left as is",
+			file:      "broken.jpg",
+			content:   func(t *testing.T) []byte { return brokenJPEG },
+			unchanged: true,
+		},
+		{
+			name:      "other files are ignored",
+			file:      "notes.txt",
+			content:   func(t *testing.T) []byte { return []byte("Exif\x00\x00") },
+			unchanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.postUpload = []domain.PostUploadHook{NewImageMetadataStripper(uc.storage)}
+			original := tt.content(t)
+
+			stored, err := uc.UploadFile(tt.file, bytes.NewReader(original), domain.UploadOptions{})
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(filepath.Join(tmpDir, stored))
+			require.NoError(t, err)
+			if tt.unchanged {
+				assert.Equal(t, original, data)
+				return
+			}
+			tt.check(t, data)
+		})
+	}
+}
+
+func TestImageMetadataStripper_ReadsThroughStorage(t *testing.T) {
+	// на диске файла нет (у зашифрованного там шифротекст): картинка должна прочитаться через хранилище.
+	storage := newMapStorage(t, fstest.MapFS{"shot.png": {Data: pngWithText(t)}})
+	var written []byte
+	storage.writeFileFunc = func(relPath string, file io.Reader) error {
+		var err error
+		written, err = io.ReadAll(file)
+		return err
+	}
+
+	NewImageMetadataStripper(storage).AfterUpload("shot.png", domain.UploadInfo{})
+
+	require.NotNil(t, written, "stripped image written back")
+	assert.False(t, pngHasMetadata(written))
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// у каждой ориентации своё место для красного угла (0,0) исходника 4x2.
+	tests := []struct {
+		orientation int
+		bounds      image.Rectangle
+		red         image.Point
+	}{
+		{1, image.Rect(0, 0, 4, 2), image.Pt(0, 0)},
+		{2, image.Rect(0, 0, 4, 2), image.Pt(3, 0)},
+		{3, image.Rect(0, 0, 4, 2), image.Pt(3, 1)},
+		{4, image.Rect(0, 0, 4, 2), image.Pt(0, 1)},
+		{5, image.Rect(0, 0, 2, 4), image.Pt(0, 0)},
+		{6, image.Rect(0, 0, 2, 4), image.Pt(1, 0)},
+		{7, image.Rect(0, 0, 2, 4), image.Pt(1, 3)},
+		{8, image.Rect(0, 0, 2, 4), image.Pt(0, 3)},
+	}
+
+	for _, tt := range tests {
+		src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+		src.Set(0, 0, color.RGBA{R: 255, A: 255})
+
+		got := applyOrientation(src, tt.orientation)
+
+		assert.Equal(t, tt.bounds, got.Bounds(), "orientation %d", tt.orientation)
+		r, _, _, _ := got.At(tt.red.X, tt.red.Y).RGBA()
+		assert.NotZero(t, r, "orientation %d", tt.orientation)
+	}
+}
+
+func TestFileManagementUseCase_UploadFile_PostUploadHook(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	hook := &recordingHook{}
+	WithPostUploadHook(hook)(uc)
+
+	stored, err := uc.UploadFile("a.txt", bytes.NewReader([]byte("x")), domain.UploadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{stored}, hook.paths)
+
+	hookStorage(uc).writeFileFunc = func(string, io.Reader) error { return assert.AnError }
+	_, err = uc.UploadFile("b.txt", bytes.NewReader([]byte("x")), domain.UploadOptions{})
+	require.Error(t, err)
+	assert.Equal(t, []string{stored}, hook.paths, "failed upload must not reach hooks")
+}
+
+type recordingHook struct {
+	paths []string
+}
+
+func (h *recordingHook) AfterUpload(relPath string, _ domain.UploadInfo) {
+	h.paths = append(h.paths, relPath)
+}
@@ -0,0 +1,30 @@
+package usecases
+
+import (
+	"fmt"
+
+	"file-manager/internal/domain"
+)
+
+// ListPage режет готовый листинг: фильтры и сортировка применяются ко всей папке, иначе страницы
+// при сортировке по размеру показывали бы не то. память на папку всё равно нужна, экономится шаблон и ответ.
+func (uc *FileManagementUseCase) ListPage(
+	path string, opts domain.ListOptions, offset, limit int,
+) ([]domain.FileData, int, error) {
+	if offset < 0 || limit < 0 {
+		return nil, 0, fmt.Errorf("bad page offset %d, limit %d: %w", offset, limit, domain.ErrInvalidParameter)
+	}
+	files, err := uc.List(path, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(files)
+	if offset >= total {
+		return []domain.FileData{}, total, nil
+	}
+	end := total
+	if limit > 0 && limit < total-offset {
+		end = offset + limit
+	}
+	return files[offset:end], total, nil
+}
@@ -0,0 +1,70 @@
+package usecases
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_ListPage(t *testing.T) {
+	cfg := &config.Config{File: config.FileConfig{MaxNameLength: 255, ValidNameRegex: `^[\w\-. ]+$`}}
+	storage := &mockFileStorage{
+		basePath: "/storage",
+		readDirectoryFunc: func(string) ([]os.FileInfo, error) {
+			// хранилище отдаёт вразнобой, страницы режутся после сортировки.
+			return []os.FileInfo{
+				&mockFileInfo{name: "d.txt"},
+				&mockFileInfo{name: "a.txt"},
+				&mockFileInfo{name: "e.txt"},
+				&mockFileInfo{name: "b.txt"},
+				&mockFileInfo{name: "c.txt"},
+			}, nil
+		},
+	}
+	uc := NewFileManagementUseCase(storage, cfg)
+	sorted := domain.ListOptions{Sort: domain.SortByName}
+
+	tests := []struct {
+		name      string
+		offset    int
+		limit     int
+		wantNames []string
+		wantErr   error
+	}{
+		{name: "first page", offset: 0, limit: 2, wantNames: []string{"a.txt", "b.txt"}},
+		{name: "middle page", offset: 2, limit: 2, wantNames: []string{"c.txt", "d.txt"}},
+		{name: "last partial page", offset: 4, limit: 2, wantNames: []string{"e.txt"}},
+		{name: "no limit", offset: 3, limit: 0, wantNames: []string{"d.txt", "e.txt"}},
+		{name: "offset past the end", offset: 10, limit: 2, wantNames: []string{}},
+		{name: "negative offset", offset: -1, limit: 2, wantErr: domain.ErrInvalidParameter},
+		{name: "negative limit", offset: 0, limit: -2, wantErr: domain.ErrInvalidParameter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, total, err := uc.ListPage("", sorted, tt.offset, tt.limit)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 5, total)
+			names := make([]string, 0, len(page))
+			for _, f := range page {
+				names = append(names, f.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	t.Run("list error", func(t *testing.T) {
+		_, _, err := uc.ListPage("../outside", sorted, 0, 2)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// sortListing сортирует записи по opts.Sort. при равенстве поля порядок по имени (всегда по возрастанию),
+// чтобы одинаковые размеры не прыгали между запросами. имена без учёта регистра, как в файловых менеджерах.
+func sortListing(files []domain.FileData, opts domain.ListOptions) {
+	if opts.Sort == "" && !opts.DirsFirst {
+		return
+	}
+	byName := func(a, b domain.FileData) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.Name, b.Name))
+	}
+	slices.SortStableFunc(files, func(a, b domain.FileData) int {
+		if opts.DirsFirst && a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		var c int
+		switch opts.Sort {
+		case domain.SortByName:
+			c = byName(a, b)
+		case domain.SortBySize:
+			c = cmp.Compare(a.Size, b.Size)
+		case domain.SortByModTime:
+			c = a.ModTime.Compare(b.ModTime)
+		}
+		if opts.Desc {
+			c = -c
+		}
+		if c == 0 && opts.Sort != "" {
+			return byName(a, b)
+		}
+		return c
+	})
+}
@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"file-manager/internal/domain"
+)
+
+func TestSortListing(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	listing := func() []domain.FileData {
+		return []domain.FileData{
+			{Name: "b.txt", Size: 20, ModTime: base.Add(time.Hour)},
+			{Name: "photos", IsDir: true, ModTime: base.Add(3 * time.Hour)},
+			{Name: "A.txt", Size: 10, ModTime: base.Add(2 * time.Hour)},
+			{Name: "c.txt", Size: 10, ModTime: base},
+			{Name: "archive", IsDir: true, ModTime: base.Add(4 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts domain.ListOptions
+		want []string
+	}{
+		{name: "unsorted keeps storage order", want: []string{"b.txt", "photos", "A.txt", "c.txt", "archive"}},
+		{
+			name: "name ignores case",
+			opts: domain.ListOptions{Sort: domain.SortByName},
+			want: []string{"A.txt", "archive", "b.txt", "c.txt", "photos"},
+		},
+		{
+			name: "name desc",
+			opts: domain.ListOptions{Sort: domain.SortByName, Desc: true},
+			want: []string{"photos", "c.txt", "b.txt", "archive", "A.txt"},
+		},
+		{
+			name: "size ties broken by name",
+			opts: domain.ListOptions{Sort: domain.SortBySize},
+			want: []string{"archive", "photos", "A.txt", "c.txt", "b.txt"},
+		},
+		{
+			name: "size desc, ties still by name ascending",
+			opts: domain.ListOptions{Sort: domain.SortBySize, Desc: true},
+			want: []string{"b.txt", "A.txt", "c.txt", "archive", "photos"},
+		},
+		{
+			name: "modtime desc with dirs first",
+			opts: domain.ListOptions{Sort: domain.SortByModTime, Desc: true, DirsFirst: true},
+			want: []string{"archive", "photos", "A.txt", "b.txt", "c.txt"},
+		},
+		{
+			name: "dirs first only",
+			opts: domain.ListOptions{DirsFirst: true},
+			want: []string{"photos", "archive", "b.txt", "A.txt", "c.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := listing()
+
+			sortListing(files, tt.opts)
+
+			names := make([]string, len(files))
+			for i, f := range files {
+				names[i] = f.Name
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
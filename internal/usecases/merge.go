@@ -0,0 +1,120 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// Merge сливает папку src в существующую папку dst: совпавшие подпапки сливаются рекурсивно, файлы переносятся
+// с политикой policy для каждого совпавшего имени, опустевшие папки src удаляются. dst нет — обычный перенос.
+// файл против папки с тем же именем не перезаписывается ни при какой политике: с rename запись уезжает
+// под свободное имя (`photos (1)`), иначе остаётся в src. оставшиеся записи перечисляются в ErrAlreadyExists,
+// всё остальное к этому моменту уже перенесено.
+func (uc *FileManagementUseCase) Merge(srcPath, dstPath string, policy domain.ConflictPolicy) error {
+	src, err := uc.sanitizePath(srcPath)
+	if err != nil {
+		return err
+	}
+	dst, err := uc.sanitizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if src == domain.PathCurrent || src == dst || strings.HasPrefix(dst, src+string(filepath.Separator)) {
+		return fmt.Errorf("merge '%s' into '%s': %w", src, dst, domain.ErrInvalidParameter)
+	}
+	if err = uc.checkConflictPolicy(policy); err != nil {
+		return err
+	}
+
+	srcInfo, err := uc.storage.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("merge: '%s' not found: %w", src, domain.ErrFileNotFound)
+		}
+		return fmt.Errorf("merge: stat '%s': %w", src, err)
+	}
+	if !srcInfo.IsDir() {
+		return fmt.Errorf("merge: '%s' is not a folder: %w", src, domain.ErrInvalidParameter)
+	}
+	dstInfo, err := uc.storage.Stat(dst)
+	switch {
+	case os.IsNotExist(err):
+		if moveErr := uc.storage.Move(src, dst); moveErr != nil {
+			return fmt.Errorf("could not move '%s' to '%s': %w", src, dst, moveErr)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("merge: stat '%s': %w", dst, err)
+	case !dstInfo.IsDir():
+		return fmt.Errorf("merge: '%s' is a file, not a folder: %w", dst, domain.ErrAlreadyExists)
+	}
+
+	var skipped []string
+	if err = uc.mergeDir(src, dst, policy, &skipped); err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("merge '%s' into '%s': left in place %s: %w",
+			src, dst, strings.Join(skipped, ", "), domain.ErrAlreadyExists)
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) mergeDir(src, dst string, policy domain.ConflictPolicy, skipped *[]string) error {
+	entries, err := uc.storage.ReadDirectory(src)
+	if err != nil {
+		return fmt.Errorf("merge: read '%s': %w", src, err)
+	}
+	for _, entry := range entries {
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+		target, statErr := uc.storage.Stat(to)
+		switch {
+		case os.IsNotExist(statErr):
+		case statErr != nil:
+			return fmt.Errorf("merge: stat '%s': %w", to, statErr)
+		case entry.IsDir() && target.IsDir():
+			if err = uc.mergeDir(from, to, policy, skipped); err != nil {
+				return err
+			}
+			continue
+		case entry.IsDir() != target.IsDir():
+			if policy != domain.ConflictRename {
+				*skipped = append(*skipped, from)
+				continue
+			}
+			if to, err = uc.resolveConflict(to, domain.ConflictRename); err != nil {
+				return err
+			}
+		default:
+			resolved, resolveErr := uc.resolveConflict(to, policy)
+			if errors.Is(resolveErr, domain.ErrAlreadyExists) {
+				*skipped = append(*skipped, from)
+				continue
+			}
+			if resolveErr != nil {
+				return resolveErr
+			}
+			to = resolved
+		}
+		if err = uc.storage.Move(from, to); err != nil {
+			return fmt.Errorf("could not move '%s' to '%s': %w", from, to, err)
+		}
+	}
+
+	// папка удаляется, только если из неё уехало всё: Remove рекурсивный, оставленное пропало бы.
+	left, err := uc.storage.ReadDirectory(src)
+	if err != nil {
+		return fmt.Errorf("merge: read '%s': %w", src, err)
+	}
+	if len(left) == 0 {
+		if err = uc.storage.Remove(src); err != nil {
+			return fmt.Errorf("merge: remove emptied '%s': %w", src, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+// readTree файлы под root с содержимым, пустые папки со значением "/".
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		if d.IsDir() {
+			if entries, _ := os.ReadDir(path); len(entries) == 0 {
+				tree[filepath.ToSlash(rel)] = "/"
+			}
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		tree[filepath.ToSlash(rel)] = string(data)
+		return err
+	})
+	require.NoError(t, err)
+	return tree
+}
+
+func TestFileManagementUseCase_Merge(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst string
+		policy   domain.ConflictPolicy
+		files    map[string]string
+		want     map[string]string
+		wantErr  error
+	}{
+		{
+			name: "disjoint contents",
+			src:  "a", dst: "b",
+			files: map[string]string{"a/x.txt": "x", "a/sub/y.txt": "y", "b/z.txt": "z"},
+			want:  map[string]string{"b/x.txt": "x", "b/sub/y.txt": "y", "b/z.txt": "z"},
+		},
+		{
+			name: "nested folders merge and overwrite by default",
+			src:  "a", dst: "b",
+			files: map[string]string{
+				"a/sub/y.txt": "new", "a/sub/n.txt": "n", "b/sub/y.txt": "old", "b/sub/k.txt": "k",
+			},
+			want: map[string]string{"b/sub/y.txt": "new", "b/sub/n.txt": "n", "b/sub/k.txt": "k"},
+		},
+		{
+			name: "rename policy keeps both files",
+			src:  "a", dst: "b", policy: domain.ConflictRename,
+			files: map[string]string{"a/r.txt": "new", "b/r.txt": "old"},
+			want:  map[string]string{"b/r.txt": "old", "b/r (1).txt": "new"},
+		},
+		{
+			name: "version policy keeps old as version",
+			src:  "a", dst: "b", policy: domain.ConflictVersion,
+			files: map[string]string{"a/r.txt": "new", "b/r.txt": "old"},
+			want:  map[string]string{"b/r.txt": "new", "b/r.v1.txt": "old"},
+		},
+		{
+			name: "error policy leaves conflicting file in source",
+			src:  "a", dst: "b", policy: domain.ConflictError,
+			files:   map[string]string{"a/r.txt": "new", "a/free.txt": "f", "b/r.txt": "old"},
+			want:    map[string]string{"a/r.txt": "new", "b/free.txt": "f", "b/r.txt": "old"},
+			wantErr: domain.ErrAlreadyExists,
+		},
+		{
+			name: "folder against file is never overwritten",
+			src:  "a", dst: "b",
+			files: map[string]string{
+				"a/photos/p.jpg": "p", "a/notes": "n", "b/photos": "file", "b/notes/keep.txt": "k",
+			},
+			want: map[string]string{
+				"a/photos/p.jpg": "p", "a/notes": "n", "b/photos": "file", "b/notes/keep.txt": "k",
+			},
+			wantErr: domain.ErrAlreadyExists,
+		},
+		{
+			name: "folder against file with rename policy",
+			src:  "a", dst: "b", policy: domain.ConflictRename,
+			files: map[string]string{"a/photos/p.jpg": "p", "b/photos": "file"},
+			want:  map[string]string{"b/photos (1)/p.jpg": "p", "b/photos": "file"},
+		},
+		{
+			name: "missing destination is a plain move",
+			src:  "a", dst: "c",
+			files: map[string]string{"a/x.txt": "x"},
+			want:  map[string]string{"c/x.txt": "x"},
+		},
+		{
+			name: "destination is a file",
+			src:  "a", dst: "b.txt",
+			files:   map[string]string{"a/x.txt": "x", "b.txt": "b"},
+			want:    map[string]string{"a/x.txt": "x", "b.txt": "b"},
+			wantErr: domain.ErrAlreadyExists,
+		},
+		{
+			name: "source is a file",
+			src:  "a.txt", dst: "b",
+			files:   map[string]string{"a.txt": "a", "b/x.txt": "x"},
+			want:    map[string]string{"a.txt": "a", "b/x.txt": "x"},
+			wantErr: domain.ErrInvalidParameter,
+		},
+		{
+			name: "into own subfolder",
+			src:  "a", dst: "a/sub",
+			files:   map[string]string{"a/sub/x.txt": "x"},
+			want:    map[string]string{"a/sub/x.txt": "x"},
+			wantErr: domain.ErrInvalidParameter,
+		},
+		{
+			name: "missing source",
+			src:  "nope", dst: "b",
+			files:   map[string]string{"b/x.txt": "x"},
+			want:    map[string]string{"b/x.txt": "x"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name: "unknown policy",
+			src:  "a", dst: "b", policy: "merge-harder",
+			files:   map[string]string{"a/x.txt": "x", "b/y.txt": "y"},
+			want:    map[string]string{"a/x.txt": "x", "b/y.txt": "y"},
+			wantErr: domain.ErrInvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			writeTree(t, tmpDir, tt.files)
+
+			err := uc.Merge(tt.src, tt.dst, tt.policy)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, readTree(t, tmpDir))
+		})
+	}
+}
+
+func TestFileManagementUseCase_Merge_ThroughStorage(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	fsys := fstest.MapFS{
+		"a":       {Mode: fs.ModeDir},
+		"a/x.txt": {Data: []byte("x")},
+		"b/y.txt": {Data: []byte("y")},
+	}
+	uc.storage = newMutableMapStorage(t, fsys)
+
+	require.NoError(t, uc.Merge("a", "b", domain.ConflictError))
+
+	assert.ElementsMatch(t, []string{"b/x.txt", "b/y.txt"}, slices.Collect(maps.Keys(fsys)))
+}
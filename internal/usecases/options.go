@@ -0,0 +1,20 @@
+package usecases
+
+import "file-manager/internal/domain"
+
+// Option необязательная настройка FileManagementUseCase.
+type Option func(*FileManagementUseCase)
+
+// WithEventPublisher куда отправлять события (прогресс загрузок), без него события не создаются.
+func WithEventPublisher(publisher domain.EventPublisher) Option {
+	return func(uc *FileManagementUseCase) {
+		uc.events = publisher
+	}
+}
+
+// WithPostUploadHook хуки вызываются по порядку после каждой удачной загрузки.
+func WithPostUploadHook(hooks ...domain.PostUploadHook) Option {
+	return func(uc *FileManagementUseCase) {
+		uc.postUpload = append(uc.postUpload, hooks...)
+	}
+}
@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// pathLocks занятые пути хранилища. путь занят, если занят он сам, папка над ним или что-то внутри него:
+// восстановление папки `docs` и очистка `docs/a.txt` в корзине трогают одни и те же файлы.
+type pathLocks struct {
+	mu   sync.Mutex
+	held map[string]struct{}
+}
+
+// tryLock занимает path, false — он или пересекающийся путь уже заняты. ждать не умеет: вызывающий
+// либо пропускает путь, либо отвечает ошибкой.
+func (l *pathLocks) tryLock(path string) bool {
+	path = filepath.Clean(path)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for held := range l.held {
+		if pathsOverlap(held, path) {
+			return false
+		}
+	}
+	if l.held == nil {
+		l.held = make(map[string]struct{})
+	}
+	l.held[path] = struct{}{}
+	return true
+}
+
+func (l *pathLocks) unlock(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, filepath.Clean(path))
+}
+
+// pathsOverlap a и b совпадают или один лежит внутри другого.
+func pathsOverlap(a, b string) bool {
+	sep := string(filepath.Separator)
+	return a == b || strings.HasPrefix(a, b+sep) || strings.HasPrefix(b, a+sep)
+}
@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathLocks_TryLock(t *testing.T) {
+	tests := []struct {
+		name   string
+		held   string
+		path   string
+		wantOK bool
+	}{
+		{name: "same path", held: "docs/a.txt", path: "docs/a.txt", wantOK: false},
+		{name: "inside held dir", held: "docs", path: "docs/a.txt", wantOK: false},
+		{name: "held inside dir", held: "docs/a.txt", path: "docs", wantOK: false},
+		{name: "sibling", held: "docs/a.txt", path: "docs/b.txt", wantOK: true},
+		{name: "shared prefix is not a parent", held: "docs", path: "docs2/a.txt", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var locks pathLocks
+			assert.True(t, locks.tryLock(filepath.FromSlash(tt.held)))
+
+			assert.Equal(t, tt.wantOK, locks.tryLock(filepath.FromSlash(tt.path)))
+		})
+	}
+}
+
+func TestPathLocks_Unlock(t *testing.T) {
+	var locks pathLocks
+	assert.True(t, locks.tryLock("docs"))
+	assert.False(t, locks.tryLock("docs/a.txt"))
+
+	locks.unlock("docs")
+
+	assert.True(t, locks.tryLock("docs/a.txt"))
+}
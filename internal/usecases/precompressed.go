@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	gzipSuffix   = ".gz"
+	encodingGzip = "gzip"
+)
+
+// gzipVariant информация о `file.txt.gz` рядом с файлом, если его можно отдать вместо file.txt (file.gzip_static,
+// как gzip_static в nginx). вариант должен быть обычным файлом и после раскрытия симлинков оставаться внутри basePath.
+// симлинки есть только на диске, так что хранилище без диска под собой (memory) вариантов не отдаёт.
+func (uc *FileManagementUseCase) gzipVariant(r *http.Request, relPath string) (os.FileInfo, bool) {
+	if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		return nil, false
+	}
+
+	info, err := uc.storage.Stat(relPath + gzipSuffix)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, false
+	}
+
+	variant := uc.storage.GetAbsolutePath(relPath + gzipSuffix)
+	resolved, err := filepath.EvalSymlinks(variant)
+	if err != nil {
+		return nil, false
+	}
+	basePath, err := filepath.EvalSymlinks(uc.storage.GetAbsolutePath(""))
+	if err != nil {
+		return nil, false
+	}
+	rel, err := filepath.Rel(basePath, resolved)
+	if err != nil || strings.HasPrefix(rel, domain.PathTraversalPrefix) {
+		return nil, false
+	}
+	return info, true
+}
+
+// acceptsGzip разбирает Accept-Encoding: gzip (или `*`) без q=0.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encodingGzip && coding != "*" {
+			continue
+		}
+		q, hasQ := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !hasQ {
+			return true
+		}
+		if weight, err := strconv.ParseFloat(q, 64); err == nil && weight > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManagementUseCase_ServeFile_GzipStatic(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.GzipStatic = true
+	writeTree(t, tmpDir, map[string]string{
+		"app.js":    "plain",
+		"app.js.gz": "gzipped",
+		"solo.txt":  "only plain",
+		"link.txt":  "plain link",
+	})
+	outside := filepath.Join(t.TempDir(), "secret.gz")
+	require.NoError(t, os.WriteFile(outside, []byte("outside"), 0o644))
+	if err := os.Symlink(outside, filepath.Join(tmpDir, "link.txt.gz")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		accept       string
+		disabled     bool
+		wantBody     string
+		wantEncoding string
+	}{
+		{name: "variant served", path: "app.js", accept: "gzip, br", wantBody: "gzipped", wantEncoding: "gzip"},
+		{name: "wildcard", path: "app.js", accept: "*", wantBody: "gzipped", wantEncoding: "gzip"},
+		{name: "client refuses gzip", path: "app.js", accept: "gzip;q=0, br", wantBody: "plain"},
+		{name: "no accept-encoding", path: "app.js", wantBody: "plain"},
+		{name: "no variant", path: "solo.txt", accept: "gzip", wantBody: "only plain"},
+		{name: "variant outside base", path: "link.txt", accept: "gzip", wantBody: "plain link"},
+		{name: "disabled", path: "app.js", accept: "gzip", disabled: true, wantBody: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.GzipStatic = !tt.disabled
+			r := httptest.NewRequest("GET", "/download?path="+tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			require.NoError(t, uc.ServeFile(w, r, tt.path))
+
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			assert.Equal(t, tt.wantEncoding, w.Header().Get("Content-Encoding"))
+			assert.Contains(t, w.Header().Get("Content-Disposition"), `filename="`+tt.path+`"`)
+			if !tt.disabled {
+				assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: "gzip", want: true},
+		{header: "GZIP", want: true},
+		{header: "br, gzip;q=0.5", want: true},
+		{header: "gzip;q=0", want: false},
+		{header: "gzip;q=0.0, *;q=0", want: false},
+		{header: "identity", want: false},
+		{header: "*;q=1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, acceptsGzip(tt.header))
+		})
+	}
+}
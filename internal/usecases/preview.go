@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// previewSniffBytes сколько байт с начала файла смотреть на нулевой байт, как делает git.
+	previewSniffBytes = 8000
+	// previewMaxBytes потолок прочитанного на превью: файл в одну гигантскую строку не читается целиком.
+	previewMaxBytes = 256 * 1024
+)
+
+// PreviewText первые maxLines строк текстового файла. maxLines 0 или больше file.preview_max_lines
+// урезается до потолка. truncated — в файле есть ещё строки или упёрлись в previewMaxBytes.
+// файл с нулевым байтом в начале считается бинарным: ErrUnsupportedOperation.
+func (uc *FileManagementUseCase) PreviewText(path string, maxLines int) ([]string, bool, error) {
+	if maxLines < 0 {
+		return nil, false, fmt.Errorf("preview lines %d: %w", maxLines, domain.ErrInvalidParameter)
+	}
+	if maxLines == 0 || maxLines > uc.cfg.File.PreviewMaxLines {
+		maxLines = uc.cfg.File.PreviewMaxLines
+	}
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, false, fmt.Errorf("preview in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.cfg.File.BlockHiddenDownload && isHiddenPath(sanitizedPath) {
+		return nil, false, fmt.Errorf("preview of hidden '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, fmt.Errorf("file not found at '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return nil, false, fmt.Errorf("failed to stat file at '%s': %w", sanitizedPath, err)
+	}
+	if info.IsDir() {
+		return nil, false, fmt.Errorf("preview '%s': %w", sanitizedPath, domain.ErrIsDirectory)
+	}
+
+	// через storage, чтобы зашифрованные файлы превьюшились расшифрованными.
+	file, err := uc.storage.Open(sanitizedPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not open '%s': %w", sanitizedPath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(io.LimitReader(file, previewMaxBytes+1), previewSniffBytes)
+	head, err := reader.Peek(previewSniffBytes)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, false, fmt.Errorf("could not read '%s': %w", sanitizedPath, err)
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, false, fmt.Errorf("preview of binary '%s': %w", sanitizedPath, domain.ErrUnsupportedOperation)
+	}
+
+	lines := make([]string, 0, maxLines)
+	read := 0
+	for len(lines) < maxLines {
+		line, readErr := reader.ReadString('\n')
+		read += len(line)
+		if read > previewMaxBytes {
+			// последняя строка обрезана посередине, отдаём что влезло.
+			lines = append(lines, trimLineEnd(line[:len(line)-(read-previewMaxBytes)]))
+			return lines, true, nil
+		}
+		if line != "" && (readErr == nil || errors.Is(readErr, io.EOF)) {
+			lines = append(lines, trimLineEnd(line))
+		}
+		if errors.Is(readErr, io.EOF) {
+			return lines, false, nil
+		}
+		if readErr != nil {
+			return nil, false, fmt.Errorf("could not read '%s': %w", sanitizedPath, readErr)
+		}
+	}
+	// строк ровно maxLines — дальше только конец файла.
+	if _, err = reader.Peek(1); errors.Is(err, io.EOF) {
+		return lines, false, nil
+	}
+	return lines, true, nil
+}
+
+// trimLineEnd срезает `\n` и `\r\n` в конце строки.
+func trimLineEnd(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_PreviewText(t *testing.T) {
+	long := strings.Repeat("x", previewMaxBytes+10)
+
+	tests := []struct {
+		name          string
+		content       string
+		path          string
+		maxLines      int
+		wantLines     []string
+		wantTruncated bool
+		wantErr       error
+	}{
+		{
+			name: "short file", content: "a\nb\n", maxLines: 10,
+			wantLines: []string{"a", "b"},
+		},
+		{
+			name: "no trailing newline and crlf", content: "a\r\nb", maxLines: 10,
+			wantLines: []string{"a", "b"},
+		},
+		{
+			name: "cut by lines", content: "1\n2\n3\n4\n", maxLines: 2,
+			wantLines: []string{"1", "2"}, wantTruncated: true,
+		},
+		{
+			name: "exactly max lines", content: "1\n2\n", maxLines: 2,
+			wantLines: []string{"1", "2"},
+		},
+		{
+			name: "zero uses config cap", content: "1\n2\n3\n4\n", maxLines: 0,
+			wantLines: []string{"1", "2", "3"}, wantTruncated: true,
+		},
+		{
+			name: "above config cap", content: "1\n2\n3\n4\n", maxLines: 100,
+			wantLines: []string{"1", "2", "3"}, wantTruncated: true,
+		},
+		{
+			name: "empty file", content: "", maxLines: 10,
+			wantLines: []string{},
+		},
+		{
+			name: "huge single line", content: long, maxLines: 10,
+			wantLines: []string{long[:previewMaxBytes]}, wantTruncated: true,
+		},
+		{name: "binary", content: "PK\x03\x04\x00\x00", maxLines: 10, wantErr: domain.ErrUnsupportedOperation},
+		{name: "negative lines", content: "a", maxLines: -1, wantErr: domain.ErrInvalidParameter},
+		{name: "missing", path: "nope.txt", maxLines: 10, wantErr: domain.ErrFileNotFound},
+		{name: "folder", path: "dir", maxLines: 10, wantErr: domain.ErrIsDirectory},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			uc.cfg.File.PreviewMaxLines = 3
+			writeTree(t, tmpDir, map[string]string{"file.txt": tt.content, "dir/x.txt": "x"})
+			path := tt.path
+			if path == "" {
+				path = "file.txt"
+			}
+
+			lines, truncated, err := uc.PreviewText(path, tt.maxLines)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLines, lines)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
+
+func TestFileManagementUseCase_PreviewText_ThroughStorage(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	uc.cfg.File.PreviewMaxLines = 10
+	uc.storage = newMapStorage(t, fstest.MapFS{"notes.txt": {Data: []byte("a\nb\n")}})
+
+	lines, truncated, err := uc.PreviewText("notes.txt", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.False(t, truncated)
+}
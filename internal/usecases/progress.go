@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"file-manager/internal/domain"
+)
+
+// прогресс шлём не на каждый Read, а когда набралось progressEveryBytes или прошло progressEvery,
+// иначе мелкие чтения io.Copy завалят подписчиков событиями.
+const (
+	progressEveryBytes = 1 << 20
+	progressEvery      = 500 * time.Millisecond
+)
+
+// progressReader публикует UploadProgress по мере чтения загрузки, на EOF — финальное с Done.
+type progressReader struct {
+	r         io.Reader
+	path      string
+	publisher domain.EventPublisher
+	read      int64
+	lastBytes int64
+	lastTime  time.Time
+	now       func() time.Time
+}
+
+func newProgressReader(r io.Reader, path string, publisher domain.EventPublisher) *progressReader {
+	return &progressReader{r: r, path: path, publisher: publisher, lastTime: time.Now(), now: time.Now}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	if errors.Is(err, io.EOF) {
+		p.publisher.Publish(domain.UploadProgress{Path: p.path, Bytes: p.read, Done: true})
+		return n, err
+	}
+	if n > 0 {
+		now := p.now()
+		if p.read-p.lastBytes >= progressEveryBytes || now.Sub(p.lastTime) >= progressEvery {
+			p.lastBytes, p.lastTime = p.read, now
+			p.publisher.Publish(domain.UploadProgress{Path: p.path, Bytes: p.read})
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(event domain.Event) {
+	p.events = append(p.events, event)
+}
+
+// chunkedReader отдаёт данные кусками по size байт, как сеть.
+type chunkedReader struct {
+	r    io.Reader
+	size int
+}
+
+func (c *chunkedReader) Read(b []byte) (int, error) {
+	return c.r.Read(b[:min(len(b), c.size)])
+}
+
+func TestProgressReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		chunk     int
+		tick      time.Duration
+		wantBytes []int64
+	}{
+		{
+			name: "every megabyte", size: 3 * progressEveryBytes, chunk: 64 << 10,
+			wantBytes: []int64{
+				progressEveryBytes, 2 * progressEveryBytes, 3 * progressEveryBytes,
+				3 * progressEveryBytes, // Done
+			},
+		},
+		{name: "every interval", size: 300, chunk: 100, tick: progressEvery, wantBytes: []int64{100, 200, 300, 300}},
+		{name: "small upload only done", size: 10, chunk: 10, wantBytes: []int64{10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			src := &chunkedReader{r: bytes.NewReader(make([]byte, tt.size)), size: tt.chunk}
+			p := newProgressReader(src, "inbox/a.bin", publisher)
+			now := time.Unix(0, 0)
+			p.lastTime = now
+			p.now = func() time.Time {
+				now = now.Add(tt.tick)
+				return now
+			}
+
+			n, err := io.Copy(io.Discard, p)
+			require.NoError(t, err)
+			assert.Equal(t, int64(tt.size), n)
+
+			var got []int64
+			for _, e := range publisher.events {
+				progress := e.(domain.UploadProgress)
+				assert.Equal(t, "inbox/a.bin", progress.Path)
+				got = append(got, progress.Bytes)
+			}
+			assert.Equal(t, tt.wantBytes, got)
+			last := publisher.events[len(publisher.events)-1].(domain.UploadProgress)
+			assert.True(t, last.Done)
+		})
+	}
+}
+
+func TestFileManagementUseCase_UploadFile_Progress(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	publisher := &recordingPublisher{}
+	WithEventPublisher(publisher)(uc)
+
+	storedPath, err := uc.UploadFile("report.txt", strings.NewReader("content"), domain.UploadOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, domain.UploadProgress{Path: storedPath, Bytes: 7, Done: true}, publisher.events[0])
+	assert.Equal(t, domain.EventUploadProgress, publisher.events[0].EventType())
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// PruneEmptyDirs удаляет пустые директории под path снизу вверх: папка, в которой остались
+// только пустые подпапки, тоже удаляется. сам path не удаляется никогда.
+// скрытые директории (корзина, временные) и drop-box не трогаем, даже если они пустые.
+func (uc *FileManagementUseCase) PruneEmptyDirs(path string) (int, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, fmt.Errorf("could not stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return 0, fmt.Errorf("failed to stat '%s': %w", sanitizedPath, err)
+	}
+	if !info.IsDir() {
+		return 0, fmt.Errorf("prune of a file '%s': %w", sanitizedPath, domain.ErrInvalidParameter)
+	}
+
+	removed := 0
+	if _, err = uc.pruneDir(sanitizedPath, &removed); err != nil {
+		return removed, fmt.Errorf("failed to prune '%s': %w", sanitizedPath, err)
+	}
+	return removed, nil
+}
+
+// pruneDir чистит детей dir и сообщает, осталась ли dir пустой. саму dir удаляет вызывающий.
+func (uc *FileManagementUseCase) pruneDir(relDir string, removed *int) (bool, error) {
+	entries, err := uc.storage.ReadDirectory(relDir)
+	if err != nil {
+		return false, err
+	}
+
+	remaining := len(entries)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), domain.HiddenFilePrefix) {
+			continue
+		}
+		childRel := filepath.Join(relDir, entry.Name())
+		if uc.isDropBox(childRel) {
+			continue
+		}
+
+		empty, pruneErr := uc.pruneDir(childRel, removed)
+		if pruneErr != nil {
+			return false, pruneErr
+		}
+		if !empty {
+			continue
+		}
+		if removeErr := uc.removeEmptyDir(childRel); removeErr != nil {
+			logrus.Warnf("Failed to remove empty directory %s: %v", childRel, removeErr)
+			continue
+		}
+		*removed++
+		remaining--
+	}
+	return remaining == 0, nil
+}
+
+// removeEmptyDir удаляет папку, только если она пуста. storage.Remove рекурсивный, как os.RemoveAll,
+// поэтому пустоту проверяем прямо перед удалением: что туда успели положить раньше, не пропадёт.
+func (uc *FileManagementUseCase) removeEmptyDir(relDir string) error {
+	entries, err := uc.storage.ReadDirectory(relDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("folder '%s' is not empty: %w", relDir, domain.ErrAlreadyExists)
+	}
+	return uc.storage.Remove(relDir)
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_PruneEmptyDirs(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.DropBoxDirs = []string{"inbox"}
+	writeTree(t, tmpDir, map[string]string{
+		"keep/file.txt":          "x",
+		"keep/empty-sibling/.gk": "hidden file keeps dir",
+		"mixed/deep/file.txt":    "x",
+	})
+	for _, dir := range []string{
+		"empty",
+		"nested/a/b/c",
+		"mixed/empty",
+		"mixed/deep/empty",
+		".trash/old",
+		"inbox",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0o755))
+	}
+
+	removed, err := uc.PruneEmptyDirs("")
+
+	require.NoError(t, err)
+	// empty, nested, nested/a, nested/a/b, nested/a/b/c, mixed/empty, mixed/deep/empty.
+	assert.Equal(t, 7, removed)
+	for _, gone := range []string{"empty", "nested", "mixed/empty", "mixed/deep/empty"} {
+		assert.NoDirExists(t, filepath.Join(tmpDir, gone))
+	}
+	for _, kept := range []string{"keep", "keep/empty-sibling", "mixed/deep", ".trash/old", "inbox"} {
+		assert.DirExists(t, filepath.Join(tmpDir, kept))
+	}
+	assert.DirExists(t, tmpDir)
+}
+
+func TestFileManagementUseCase_PruneEmptyDirs_KeepsRoot(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "only", "empty"), 0o755))
+
+	removed, err := uc.PruneEmptyDirs("only")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.DirExists(t, filepath.Join(tmpDir, "only"), "pruned path itself must survive")
+	assert.NoDirExists(t, filepath.Join(tmpDir, "only", "empty"))
+}
+
+func TestFileManagementUseCase_PruneEmptyDirs_Errors(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"file.txt": "x"})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"missing", "nope", domain.ErrFileNotFound},
+		{"file", "file.txt", domain.ErrInvalidParameter},
+		{"traversal", "../x", domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.PruneEmptyDirs(tt.path)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
+
+func TestFileManagementUseCase_PruneEmptyDirs_ThroughStorage(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	fsys := fstest.MapFS{
+		"keep/file.txt": {Data: []byte("x")},
+		"empty":         {Mode: fs.ModeDir},
+		"nested":        {Mode: fs.ModeDir},
+		"nested/a":      {Mode: fs.ModeDir},
+		".trash":        {Mode: fs.ModeDir},
+		".trash/old":    {Mode: fs.ModeDir},
+	}
+	uc.storage = newMutableMapStorage(t, fsys)
+
+	removed, err := uc.PruneEmptyDirs("")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.ElementsMatch(t, []string{"keep/file.txt", ".trash", ".trash/old"}, slices.Collect(maps.Keys(fsys)))
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// Rebuild сбрасывает кеши без перезапуска сервера. кеши заполняются лениво,
+// так что после сброса они пересобираются первыми же запросами.
+// выключенная подсистема не ошибка: сбрасывать нечего, в ответ она просто не попадает.
+func (uc *FileManagementUseCase) Rebuild(target string) ([]string, error) {
+	switch target {
+	case domain.RebuildZip, domain.RebuildAll:
+	default:
+		return nil, fmt.Errorf("unknown rebuild target '%s': %w", target, domain.ErrInvalidParameter)
+	}
+
+	rebuilt := make([]string, 0, 1)
+	if uc.zipCache != nil {
+		removed, err := uc.zipCache.clear()
+		if err != nil {
+			return rebuilt, fmt.Errorf("failed to clear zip cache: %w", err)
+		}
+		logrus.Infof("Zip cache cleared, %d archives removed", removed)
+		rebuilt = append(rebuilt, domain.RebuildZip)
+	}
+	return rebuilt, nil
+}
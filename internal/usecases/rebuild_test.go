@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Rebuild(t *testing.T) {
+	t.Run("zip cache cleared", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+		cacheDir := t.TempDir()
+		uc.zipCache = newZipCache(cacheDir, 1<<20)
+		_, cached, err := uc.zipCache.build("one", fillZip("a"))
+		require.NoError(t, err)
+		require.True(t, cached)
+
+		for _, target := range []string{domain.RebuildZip, domain.RebuildAll} {
+			rebuilt, err := uc.Rebuild(target)
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{domain.RebuildZip}, rebuilt)
+		}
+		assert.NoFileExists(t, filepath.Join(cacheDir, "one.zip"))
+		_, ok := uc.zipCache.get("one")
+		assert.False(t, ok)
+		assert.Zero(t, uc.zipCache.total)
+	})
+
+	t.Run("cache disabled", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		rebuilt, err := uc.Rebuild(domain.RebuildAll)
+
+		require.NoError(t, err)
+		assert.Empty(t, rebuilt)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+
+		for _, target := range []string{"", "thumbs", domain.RebuildTemplate} {
+			_, err := uc.Rebuild(target)
+			assert.ErrorIs(t, err, domain.ErrInvalidParameter, target)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"container/heap"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"file-manager/internal/domain"
+)
+
+// RecentFiles возвращает limit последних изменённых файлов под path, самые свежие первыми.
+// дерево обходится целиком, но в памяти держим только top-N в min-куче.
+func (uc *FileManagementUseCase) RecentFiles(path string, limit int) ([]domain.FileData, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("recent files limit %d: %w", limit, domain.ErrInvalidParameter)
+	}
+
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, fmt.Errorf("recent files in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		return nil, fmt.Errorf("failed to stat '%s': %w", sanitizedPath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("recent files of a file '%s': %w", sanitizedPath, domain.ErrInvalidParameter)
+	}
+
+	top := make(recentHeap, 0, limit)
+	err = uc.walkArchive(sanitizedPath, func(_, rel string, info os.FileInfo) error {
+		if len(top) < limit {
+			heap.Push(&top, recentEntry{rel: rel, info: info})
+			return nil
+		}
+		// куча полна: новый файл вытесняет самый старый из top-N только если он свежее.
+		if info.ModTime().After(top[0].info.ModTime()) {
+			top[0] = recentEntry{rel: rel, info: info}
+			heap.Fix(&top, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk '%s': %w", sanitizedPath, err)
+	}
+
+	sort.Sort(sort.Reverse(top))
+	result := make([]domain.FileData, 0, len(top))
+	for _, entry := range top {
+		result = append(result, domain.FileData{
+			Name:    entry.info.Name(),
+			Path:    filepath.ToSlash(filepath.Join(sanitizedPath, entry.rel)),
+			Size:    entry.info.Size(),
+			ModTime: entry.info.ModTime(),
+		})
+	}
+	return result, nil
+}
+
+type recentEntry struct {
+	rel  string
+	info os.FileInfo
+}
+
+// recentHeap min-куча по времени изменения, в вершине самый старый из отобранных.
+type recentHeap []recentEntry
+
+func (h recentHeap) Len() int { return len(h) }
+
+func (h recentHeap) Less(i, j int) bool {
+	ti, tj := h[i].info.ModTime(), h[j].info.ModTime()
+	if ti.Equal(tj) {
+		// при равном времени порядок стабильный, чтобы ответ не прыгал между запросами.
+		return h[i].rel > h[j].rel
+	}
+	return ti.Before(tj)
+}
+
+func (h recentHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *recentHeap) Push(x any) { *h = append(*h, x.(recentEntry)) }
+
+func (h *recentHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
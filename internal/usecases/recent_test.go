@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_RecentFiles(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"old.txt":          "1",
+		"docs/mid.txt":     "22",
+		"docs/sub/new.txt": "333",
+		"docs/newest.txt":  "4444",
+		".hidden/fresh":    "skip",
+		"docs/.secret":     "skip",
+	})
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mtimes := map[string]time.Duration{
+		"old.txt":          0,
+		"docs/mid.txt":     time.Hour,
+		"docs/sub/new.txt": 2 * time.Hour,
+		"docs/newest.txt":  3 * time.Hour,
+		".hidden/fresh":    10 * time.Hour,
+		"docs/.secret":     10 * time.Hour,
+	}
+	for name, offset := range mtimes {
+		ts := base.Add(offset)
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, name), ts, ts))
+	}
+
+	paths := func(files []domain.FileData) []string {
+		result := make([]string, 0, len(files))
+		for _, f := range files {
+			result = append(result, f.Path)
+		}
+		return result
+	}
+
+	t.Run("top N newest first", func(t *testing.T) {
+		files, err := uc.RecentFiles("", 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/newest.txt", "docs/sub/new.txt"}, paths(files))
+		assert.Equal(t, "newest.txt", files[0].Name)
+		assert.Equal(t, int64(4), files[0].Size)
+		assert.True(t, files[0].ModTime.Equal(base.Add(3*time.Hour)))
+	})
+
+	t.Run("limit larger than tree", func(t *testing.T) {
+		files, err := uc.RecentFiles("", 100)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/newest.txt", "docs/sub/new.txt", "docs/mid.txt", "old.txt"}, paths(files))
+	})
+
+	t.Run("subtree keeps paths relative to root", func(t *testing.T) {
+		files, err := uc.RecentFiles("docs/sub", 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docs/sub/new.txt"}, paths(files))
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		limit   int
+		wantErr error
+	}{
+		{"zero limit", "", 0, domain.ErrInvalidParameter},
+		{"path is a file", "old.txt", 5, domain.ErrInvalidParameter},
+		{"missing path", "nope", 5, domain.ErrFileNotFound},
+		{"path traversal", "../etc", 5, domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.RecentFiles(tt.path, tt.limit)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"archive/zip"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// ArchiveManifestName первая запись архива выборки: sha256, размер и имя каждого файла.
+	ArchiveManifestName = "MANIFEST.txt"
+	archiveZipName      = "archive" + domain.ExtensionZip
+)
+
+// ServeSelectionArchive как ServeSelectionAsZip, но первой записью кладёт MANIFEST.txt, чтобы скачавший
+// мог проверить архив без сервера. sha256 нужен до начала отдачи, поэтому файлы читаются дважды:
+// сначала хеш, потом копия в архив. файл, поменявшийся между проходами, в манифесте будет со старой суммой.
+func (uc *FileManagementUseCase) ServeSelectionArchive(w http.ResponseWriter, paths []string, flatten bool) error {
+	files, err := uc.selectFiles(paths, flatten)
+	if err != nil {
+		return err
+	}
+	manifest, err := uc.archiveManifest(files)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", domain.MIMEZip)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archiveZipName))
+
+	zipWriter := zip.NewWriter(w)
+	defer func() {
+		if closeErr := zipWriter.Close(); closeErr != nil {
+			logrus.Errorf("Failed to close zip writer: %v", closeErr)
+		}
+	}()
+
+	manifestWriter, err := zipWriter.Create(ArchiveManifestName)
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+	if _, err = manifestWriter.Write([]byte(manifest)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ArchiveManifestName, err)
+	}
+	for _, f := range files {
+		if copyErr := uc.copyToZip(zipWriter, f.name, f.relPath); copyErr != nil {
+			return fmt.Errorf("failed to add '%s' to archive: %w", f.name, copyErr)
+		}
+	}
+	return nil
+}
+
+// archiveManifest строки `sha256  размер  имя` в порядке архива. файл выборки с именем манифеста
+// переименовывается (` (1)`), иначе в архиве было бы две записи MANIFEST.txt.
+func (uc *FileManagementUseCase) archiveManifest(files []selectedFile) (string, error) {
+	taken := make(map[string]bool, len(files)+1)
+	for _, f := range files {
+		taken[f.name] = true
+	}
+	taken[ArchiveManifestName] = true
+
+	var b strings.Builder
+	for i := range files {
+		if files[i].name == ArchiveManifestName {
+			files[i].name = uniqueZipName(ArchiveManifestName, taken)
+			taken[files[i].name] = true
+		}
+		sum, err := uc.hashStored(files[i].relPath)
+		if err != nil {
+			return "", fmt.Errorf("hash '%s': %w", files[i].name, err)
+		}
+		fmt.Fprintf(&b, "%s  %d  %s\n", hex.EncodeToString(sum), files[i].size, files[i].name)
+	}
+	return b.String(), nil
+}
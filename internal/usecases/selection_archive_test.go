@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_ServeSelectionArchive(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.ForbiddenExtensions = []string{".env"}
+	writeTree(t, tmpDir, map[string]string{
+		"a/report.txt":   "a",
+		"b/report.txt":   "bb",
+		"b/MANIFEST.txt": "user manifest",
+		"b/.secret":      "hidden",
+		"b/prod.env":     "forbidden",
+	})
+	manifestLine := func(content, name string) string {
+		return fmt.Sprintf("%s  %d  %s\n", sha256Hex(content), len(content), name)
+	}
+
+	tests := []struct {
+		name         string
+		paths        []string
+		flatten      bool
+		wantManifest string
+		wantFiles    map[string]string
+	}{
+		{
+			name:  "relative structure",
+			paths: []string{"a/report.txt", "b/report.txt", "b/.secret", "b/prod.env"},
+			wantManifest: manifestLine("a", "a/report.txt") +
+				manifestLine("bb", "b/report.txt"),
+			wantFiles: map[string]string{"a/report.txt": "a", "b/report.txt": "bb"},
+		},
+		{
+			name:    "flatten keeps manifest name for the generated one",
+			paths:   []string{"b/MANIFEST.txt", "a/report.txt", "b/report.txt"},
+			flatten: true,
+			wantManifest: manifestLine("user manifest", "MANIFEST (1).txt") +
+				manifestLine("a", "report.txt") +
+				manifestLine("bb", "report (1).txt"),
+			wantFiles: map[string]string{
+				"MANIFEST (1).txt": "user manifest",
+				"report.txt":       "a",
+				"report (1).txt":   "bb",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			require.NoError(t, uc.ServeSelectionArchive(w, tt.paths, tt.flatten))
+
+			assert.Equal(t, domain.MIMEZip, w.Header().Get("Content-Type"))
+			assert.Contains(t, w.Header().Get("Content-Disposition"), "archive.zip")
+			zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+			require.NoError(t, err)
+			require.NotEmpty(t, zr.File)
+			assert.Equal(t, ArchiveManifestName, zr.File[0].Name, "manifest goes first")
+
+			contents := zipContents(t, w.Body.Bytes())
+			assert.Equal(t, tt.wantManifest, contents[ArchiveManifestName])
+			delete(contents, ArchiveManifestName)
+			assert.Equal(t, tt.wantFiles, contents)
+		})
+	}
+
+	t.Run("invalid selection writes nothing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := uc.ServeSelectionArchive(w, []string{"a/nope.txt"}, false)
+
+		assert.ErrorIs(t, err, domain.ErrFileNotFound)
+		assert.Empty(t, w.Header().Get("Content-Type"))
+		assert.Zero(t, w.Body.Len())
+	})
+}
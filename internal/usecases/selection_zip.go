@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+const (
+	// MaxSelection сколько путей можно выбрать в один архив.
+	MaxSelection     = 5000
+	selectionZipName = "selection" + domain.ExtensionZip
+)
+
+// selectedFile файл выборки: где лежит в хранилище и под каким именем пойдёт в архив.
+type selectedFile struct {
+	relPath string
+	name    string
+	size    int64
+}
+
+// ServeSelectionAsZip стримит zip ровно из перечисленных файлов. пути проверяются все до отправки заголовков,
+// так что кривой или отсутствующий путь даёт обычную ошибку, а не оборванный архив.
+// скрытые и запрещённые файлы молча пропускаются, как при zip папки. flatten кладёт всё в корень архива,
+// одинаковые имена тогда получают суффикс ` (1)`.
+func (uc *FileManagementUseCase) ServeSelectionAsZip(w http.ResponseWriter, paths []string, flatten bool) error {
+	files, err := uc.selectFiles(paths, flatten)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", domain.MIMEZip)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", selectionZipName))
+
+	zipWriter := zip.NewWriter(w)
+	defer func() {
+		if closeErr := zipWriter.Close(); closeErr != nil {
+			logrus.Errorf("Failed to close zip writer: %v", closeErr)
+		}
+	}()
+
+	for _, f := range files {
+		if copyErr := uc.copyToZip(zipWriter, f.name, f.relPath); copyErr != nil {
+			return fmt.Errorf("failed to add '%s' to selection zip: %w", f.name, copyErr)
+		}
+	}
+	return nil
+}
+
+func (uc *FileManagementUseCase) selectFiles(paths []string, flatten bool) ([]selectedFile, error) {
+	if len(paths) == 0 || len(paths) > MaxSelection {
+		return nil, fmt.Errorf("%d paths selected, expected 1..%d: %w",
+			len(paths), MaxSelection, domain.ErrInvalidParameter)
+	}
+
+	files := make([]selectedFile, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	names := make(map[string]bool, len(paths))
+	var total int64
+	for _, path := range paths {
+		sanitizedPath, err := uc.sanitizePath(path)
+		if err != nil {
+			return nil, err
+		}
+		if seen[sanitizedPath] {
+			continue
+		}
+		seen[sanitizedPath] = true
+
+		if uc.isDropBox(sanitizedPath) {
+			return nil, fmt.Errorf("download from drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+		}
+		if isHiddenPath(sanitizedPath) ||
+			domain.IsForbiddenName(filepath.Base(sanitizedPath), uc.cfg.File.ForbiddenExtensions) {
+			logrus.Debugf("Skipping '%s' in selection zip", sanitizedPath)
+			continue
+		}
+
+		info, statErr := uc.storage.Stat(sanitizedPath)
+		if statErr != nil {
+			return nil, fmt.Errorf("selected file '%s' not found: %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("selected '%s' is a folder: %w", sanitizedPath, domain.ErrInvalidParameter)
+		}
+		total += info.Size()
+
+		name := filepath.ToSlash(sanitizedPath)
+		if flatten {
+			name = uniqueZipName(filepath.Base(sanitizedPath), names)
+		}
+		names[name] = true
+		files = append(files, selectedFile{relPath: sanitizedPath, name: name, size: info.Size()})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nothing to download in selection: %w", domain.ErrInvalidParameter)
+	}
+	if maxEntries := uc.cfg.File.MaxZipEntries; maxEntries > 0 && len(files) > maxEntries {
+		return nil, fmt.Errorf("selection has more than %d zip entries: %w", maxEntries, domain.ErrArchiveTooLarge)
+	}
+	if maxBytes := uc.cfg.File.MaxZipSourceBytes; maxBytes > 0 && total > maxBytes {
+		return nil, fmt.Errorf("selection has more than %d bytes: %w", maxBytes, domain.ErrArchiveTooLarge)
+	}
+	return files, nil
+}
+
+// uniqueZipName `name`, а если занято — `name (1).ext`, `name (2).ext` и так далее.
+func uniqueZipName(name string, taken map[string]bool) string {
+	if !taken[name] {
+		return name
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// copyToZip добавляет файл хранилища в архив под именем name.
+func (uc *FileManagementUseCase) copyToZip(zipWriter *zip.Writer, name, relPath string) error {
+	dstFile, err := zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+
+	srcFile, err := uc.storage.Open(relPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer closeLogged(srcFile, relPath)
+
+	if _, err = io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to copy file to zip: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+// zipContents имя записи -> содержимое.
+func zipContents(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	result := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, openErr := f.Open()
+		require.NoError(t, openErr)
+		content, readErr := io.ReadAll(rc)
+		require.NoError(t, readErr)
+		require.NoError(t, rc.Close())
+		result[f.Name] = string(content)
+	}
+	return result
+}
+
+func TestFileManagementUseCase_ServeSelectionAsZip(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.ForbiddenExtensions = []string{".env"}
+	writeTree(t, tmpDir, map[string]string{
+		"a/report.txt":     "a",
+		"b/report.txt":     "b",
+		"b/notes.txt":      "notes",
+		"b/.secret":        "hidden",
+		"b/prod.env":       "forbidden",
+		"b/.cache/one.txt": "hidden dir",
+	})
+	selection := []string{"a/report.txt", "b/report.txt", "b/notes.txt", "b/.secret", "b/prod.env", "b/.cache/one.txt"}
+
+	t.Run("relative structure kept", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		require.NoError(t, uc.ServeSelectionAsZip(w, append(selection, "a/report.txt"), false))
+
+		assert.Equal(t, domain.MIMEZip, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "selection.zip")
+		assert.Equal(t, map[string]string{
+			"a/report.txt": "a",
+			"b/report.txt": "b",
+			"b/notes.txt":  "notes",
+		}, zipContents(t, w.Body.Bytes()))
+	})
+
+	t.Run("flatten renames collisions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		require.NoError(t, uc.ServeSelectionAsZip(w, selection, true))
+
+		contents := zipContents(t, w.Body.Bytes())
+		names := make([]string, 0, len(contents))
+		for name := range contents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		assert.Equal(t, []string{"notes.txt", "report (1).txt", "report.txt"}, names)
+		assert.Equal(t, "b", contents["report (1).txt"])
+	})
+
+	tests := []struct {
+		name    string
+		paths   []string
+		setup   func()
+		wantErr error
+	}{
+		{name: "empty selection", paths: nil, wantErr: domain.ErrInvalidParameter},
+		{name: "only hidden", paths: []string{"b/.secret"}, wantErr: domain.ErrInvalidParameter},
+		{name: "missing file", paths: []string{"a/report.txt", "a/nope.txt"}, wantErr: domain.ErrFileNotFound},
+		{name: "folder", paths: []string{"a"}, wantErr: domain.ErrInvalidParameter},
+		{name: "path traversal", paths: []string{"../etc/passwd"}, wantErr: domain.ErrPathTraversal},
+		{
+			name: "over size limit", paths: []string{"b/notes.txt"},
+			setup: func() { uc.cfg.File.MaxZipSourceBytes = 2 }, wantErr: domain.ErrArchiveTooLarge,
+		},
+		{
+			name: "over entry limit", paths: []string{"a/report.txt", "b/report.txt"},
+			setup: func() { uc.cfg.File.MaxZipEntries = 1 }, wantErr: domain.ErrArchiveTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.MaxZipSourceBytes, uc.cfg.File.MaxZipEntries = 0, 0
+			if tt.setup != nil {
+				tt.setup()
+			}
+			w := httptest.NewRecorder()
+
+			err := uc.ServeSelectionAsZip(w, tt.paths, false)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, w.Header().Get("Content-Type"), "no headers before validation passes")
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// MaxStatBatch сколько путей можно спросить за один вызов StatMany, каждый файл ещё и хешируется.
+const MaxStatBatch = 500
+
+// StatMany отдаёт метаданные сразу для набора путей в том же порядке.
+// ошибка по отдельному пути не валит весь запрос, а попадает в FileData.Error.
+func (uc *FileManagementUseCase) StatMany(paths []string) ([]domain.FileData, error) {
+	if len(paths) > MaxStatBatch {
+		return nil, fmt.Errorf("%d paths requested, at most %d allowed: %w",
+			len(paths), MaxStatBatch, domain.ErrInvalidParameter)
+	}
+
+	result := make([]domain.FileData, 0, len(paths))
+	for _, path := range paths {
+		data, err := uc.statOne(path)
+		if err != nil {
+			logrus.Debugf("Stat of '%s' failed: %v", path, err)
+			data = domain.FileData{Path: path, Error: statErrorMarker(err)}
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+func (uc *FileManagementUseCase) statOne(path string) (domain.FileData, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return domain.FileData{}, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return domain.FileData{}, fmt.Errorf("stat in drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+	if uc.isUploadMeta(sanitizedPath) {
+		return domain.FileData{}, fmt.Errorf("stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	// через хранилище: у зашифрованного размер и хеш открытого текста, как в листинге и /hash.
+	info, err := uc.storage.Stat(sanitizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.FileData{}, fmt.Errorf("stat '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+		}
+		if os.IsPermission(err) {
+			return domain.FileData{}, fmt.Errorf("stat '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+		}
+		return domain.FileData{}, fmt.Errorf("stat '%s': %w", sanitizedPath, err)
+	}
+
+	data := domain.FileData{
+		Name:      info.Name(),
+		IsDir:     info.IsDir(),
+		Forbidden: uc.cfg.File.MarkForbidden && domain.IsForbiddenName(info.Name(), uc.cfg.File.ForbiddenExtensions),
+		Path:      sanitizedPath,
+		Size:      info.Size(),
+		ModTime:   info.ModTime(),
+	}
+	if !info.IsDir() {
+		sum, hashErr := uc.hashStored(sanitizedPath)
+		if hashErr != nil {
+			return domain.FileData{}, fmt.Errorf("hash '%s': %w", sanitizedPath, hashErr)
+		}
+		data.Checksum = hex.EncodeToString(sum)
+	}
+	return data, nil
+}
+
+func statErrorMarker(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrFileNotFound):
+		return domain.StatErrorNotFound
+	case errors.Is(err, domain.ErrPathTraversal) || errors.Is(err, domain.ErrPathTooLong) ||
+		errors.Is(err, domain.ErrInvalidName):
+		return domain.StatErrorInvalidPath
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return domain.StatErrorForbidden
+	default:
+		return domain.StatErrorInternal
+	}
+}
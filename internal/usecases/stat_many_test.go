@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_StatMany(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.DropBoxDirs = []string{"inbox"}
+	writeTree(t, tmpDir, map[string]string{
+		"docs/a.txt":   "hello",
+		"inbox/secret": "x",
+	})
+	sum := sha256.Sum256([]byte("hello"))
+
+	files, err := uc.StatMany([]string{"docs/a.txt", "docs", "missing.txt", "../etc/passwd", "inbox/secret"})
+	require.NoError(t, err)
+	require.Len(t, files, 5)
+
+	assert.Equal(t, "a.txt", files[0].Name)
+	assert.Equal(t, "docs/a.txt", files[0].Path)
+	assert.Equal(t, int64(5), files[0].Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), files[0].Checksum)
+	assert.Empty(t, files[0].Error)
+
+	assert.True(t, files[1].IsDir)
+	assert.Empty(t, files[1].Checksum, "directories are not hashed")
+
+	tests := []struct {
+		idx  int
+		path string
+		want string
+	}{
+		{2, "missing.txt", domain.StatErrorNotFound},
+		{3, "../etc/passwd", domain.StatErrorInvalidPath},
+		{4, "inbox/secret", domain.StatErrorForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.path, files[tt.idx].Path, "original path echoed back")
+			assert.Equal(t, tt.want, files[tt.idx].Error)
+			assert.Empty(t, files[tt.idx].Name)
+		})
+	}
+
+	t.Run("batch too large", func(t *testing.T) {
+		_, err := uc.StatMany(make([]string, MaxStatBatch+1))
+		assert.True(t, errors.Is(err, domain.ErrInvalidParameter))
+	})
+}
+
+func TestFileManagementUseCase_StatMany_ThroughStorage(t *testing.T) {
+	// размер и хеш из хранилища, а не с диска: у зашифрованного они совпадут с листингом и /hash.
+	uc, _ := newLocalUseCase(t)
+	uc.storage = newMapStorage(t, fstest.MapFS{"a.txt": {Data: []byte("hello")}})
+	sum := sha256.Sum256([]byte("hello"))
+
+	files, err := uc.StatMany([]string{"a.txt"})
+
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Empty(t, files[0].Error)
+	assert.Equal(t, int64(5), files[0].Size)
+	assert.Equal(t, hex.EncodeToString(sum[:]), files[0].Checksum)
+}
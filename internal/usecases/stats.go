@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"file-manager/internal/domain"
+)
+
+// StatsTopExtensions сколько расширений попадает в разбивку StorageStats.
+const StatsTopExtensions = 10
+
+// statsCache результаты Stats по папкам. сигнатура — modtime самой папки: дешёвая, но видит
+// только изменения прямых детей, правки глубже подхватываются через refresh.
+type statsCache struct {
+	mu      sync.Mutex
+	entries map[string]statsCacheEntry
+}
+
+type statsCacheEntry struct {
+	modTime time.Time
+	stats   domain.StorageStats
+}
+
+func (c *statsCache) get(path string, modTime time.Time) (domain.StorageStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || !entry.modTime.Equal(modTime) {
+		return domain.StorageStats{}, false
+	}
+	return entry.stats, true
+}
+
+func (c *statsCache) put(path string, modTime time.Time, stats domain.StorageStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]statsCacheEntry)
+	}
+	c.entries[path] = statsCacheEntry{modTime: modTime, stats: stats}
+}
+
+// Stats считает файлы, папки, байты и топ расширений под path за один обход (скрытое и drop-box
+// пропускаются как в zip). обход дорогой, поэтому результат кешируется, refresh обходит заново.
+func (uc *FileManagementUseCase) Stats(path string, refresh bool) (domain.StorageStats, error) {
+	var stats domain.StorageStats
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return stats, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return stats, fmt.Errorf("stats of drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return stats, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	if !refresh {
+		if cached, ok := uc.statsCache.get(sanitizedPath, info.ModTime()); ok {
+			return cached, nil
+		}
+	}
+
+	stats.ComputedAt = time.Now()
+	byExt := make(map[string]*domain.ExtensionStats)
+	stats.Truncated, err = uc.guardedWalk(sanitizedPath, func(_, _ string, fi os.FileInfo) error {
+		if fi.IsDir() {
+			stats.Dirs++
+			return nil
+		}
+		stats.Files++
+		stats.Bytes += fi.Size()
+
+		ext := strings.ToLower(filepath.Ext(fi.Name()))
+		entry, ok := byExt[ext]
+		if !ok {
+			entry = &domain.ExtensionStats{Ext: ext}
+			byExt[ext] = entry
+		}
+		entry.Files++
+		entry.Bytes += fi.Size()
+		return nil
+	})
+	if err != nil {
+		return domain.StorageStats{}, fmt.Errorf("failed to walk '%s': %w", sanitizedPath, err)
+	}
+
+	stats.Extensions = topExtensions(byExt, StatsTopExtensions)
+	uc.statsCache.put(sanitizedPath, info.ModTime(), stats)
+	return stats, nil
+}
+
+// topExtensions n самых частых расширений, при равенстве — тяжёлые, потом по имени, чтобы порядок был стабильным.
+func topExtensions(byExt map[string]*domain.ExtensionStats, n int) []domain.ExtensionStats {
+	result := make([]domain.ExtensionStats, 0, len(byExt))
+	for _, entry := range byExt {
+		result = append(result, *entry)
+	}
+	slices.SortFunc(result, func(a, b domain.ExtensionStats) int {
+		return cmp.Or(cmp.Compare(b.Files, a.Files), cmp.Compare(b.Bytes, a.Bytes), strings.Compare(a.Ext, b.Ext))
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
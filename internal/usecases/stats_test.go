@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Stats(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"a.txt":          "12345",
+		"docs/b.TXT":     "123",
+		"docs/c.pdf":     "1234567890",
+		"docs/sub/d.txt": "1",
+		"Makefile":       "12",
+		".git/config":    "hidden",
+		"docs/.env":      "hidden",
+	})
+
+	t.Run("totals and breakdown", func(t *testing.T) {
+		stats, err := uc.Stats("", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), stats.Files)
+		assert.Equal(t, int64(2), stats.Dirs)
+		assert.Equal(t, int64(21), stats.Bytes)
+		assert.Equal(t, []domain.ExtensionStats{
+			{Ext: ".txt", Files: 3, Bytes: 9},
+			{Ext: ".pdf", Files: 1, Bytes: 10},
+			{Ext: "", Files: 1, Bytes: 2},
+		}, stats.Extensions)
+		assert.False(t, stats.ComputedAt.IsZero())
+	})
+
+	t.Run("cached until root changes or refresh", func(t *testing.T) {
+		first, err := uc.Stats("docs", false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), first.Files)
+
+		// правка глубже корня не меняет его modtime, кеш отдаёт старое.
+		writeTree(t, tmpDir, map[string]string{"docs/sub/e.txt": "1"})
+		cached, err := uc.Stats("docs", false)
+		require.NoError(t, err)
+		assert.Equal(t, first, cached)
+
+		refreshed, err := uc.Stats("docs", true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), refreshed.Files)
+
+		writeTree(t, tmpDir, map[string]string{"docs/f.txt": "1"})
+		later := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(tmpDir, "docs"), later, later))
+		changed, err := uc.Stats("docs", false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), changed.Files)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "missing folder", path: "nope", wantErr: domain.ErrFileNotFound},
+		{name: "file instead of folder", path: "a.txt", wantErr: domain.ErrFileNotFound},
+		{name: "path traversal", path: "../etc", wantErr: domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Stats(tt.path, false)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTopExtensions(t *testing.T) {
+	byExt := make(map[string]*domain.ExtensionStats)
+	for i := range StatsTopExtensions + 3 {
+		ext := fmt.Sprintf(".e%02d", i)
+		byExt[ext] = &domain.ExtensionStats{Ext: ext, Files: int64(i), Bytes: 1}
+	}
+
+	top := topExtensions(byExt, StatsTopExtensions)
+
+	require.Len(t, top, StatsTopExtensions)
+	assert.Equal(t, fmt.Sprintf(".e%02d", StatsTopExtensions+2), top[0].Ext)
+	assert.Equal(t, ".e03", top[len(top)-1].Ext)
+}
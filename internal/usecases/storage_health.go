@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// storageProbePrefix имя пробного файла для проверки записи, точка в начале — чтобы он не светился в листинге.
+const storageProbePrefix = ".health-"
+
+// StorageHealth следит, что базовый путь хранилища существует и доступен на запись.
+// без этого отмонтированный том выглядит как ErrFileNotFound на каждый запрос.
+type StorageHealth struct {
+	storage domain.FileStorage
+	healthy atomic.Bool
+}
+
+// NewStorageHealth сразу делает одну проверку, чтобы флаг был честным до первого тика.
+func NewStorageHealth(storage domain.FileStorage) *StorageHealth {
+	s := &StorageHealth{storage: storage}
+	_ = s.Check()
+	return s
+}
+
+// Healthy последний результат проверки, дёшево: можно звать на каждый запрос.
+func (s *StorageHealth) Healthy() bool {
+	return s.healthy.Load()
+}
+
+// Check проверяет base_path сейчас и обновляет флаг.
+func (s *StorageHealth) Check() error {
+	err := s.probe()
+	healthy := err == nil
+	if s.healthy.Swap(healthy) != healthy {
+		if healthy {
+			logrus.Info("Storage is available again")
+		} else {
+			logrus.Errorf("Storage became unavailable: %v", err)
+		}
+	}
+	return err
+}
+
+// Run периодически вызывает Check, пока не отменят ctx.
+func (s *StorageHealth) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.Check()
+		}
+	}
+}
+
+// probe идёт через хранилище, а не по GetAbsolutePath: у памяти и облачных бэкендов пути на диске нет.
+func (s *StorageHealth) probe() error {
+	basePath := s.storage.GetAbsolutePath("")
+
+	info, err := s.storage.Stat(domain.PathCurrent)
+	if err != nil {
+		return fmt.Errorf("stat base path '%s': %v: %w", basePath, err, domain.ErrStorageUnavailable)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("base path '%s' is not a directory: %w", basePath, domain.ErrStorageUnavailable)
+	}
+
+	// stat не ловит read-only перемонтирование, поэтому пишем и сразу удаляем пробный файл.
+	name := probeName(storageProbePrefix)
+	if err = s.storage.WriteFile(name, strings.NewReader("")); err != nil {
+		return fmt.Errorf("base path '%s' is not writable: %v: %w", basePath, err, domain.ErrStorageUnavailable)
+	}
+	if removeErr := s.storage.Remove(name); removeErr != nil {
+		logrus.Warnf("Failed to remove storage probe %s: %v", name, removeErr)
+	}
+	return nil
+}
+
+// probeName уникальное имя пробного файла: пробы из разных процессов на одном хранилище не сталкиваются.
+func probeName(prefix string) string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return prefix + hex.EncodeToString(buf)
+}
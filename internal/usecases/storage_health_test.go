@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestStorageHealth_Check(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "storage")
+	require.NoError(t, os.Mkdir(base, 0o755))
+	storage := &mockFileStorage{
+		getAbsolutePathFunc: func(relPath string) string { return filepath.Join(base, relPath) },
+	}
+
+	health := NewStorageHealth(storage)
+	assert.True(t, health.Healthy())
+
+	entries, err := os.ReadDir(base)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "probe file must be cleaned up")
+
+	t.Run("base path removed", func(t *testing.T) {
+		require.NoError(t, os.Remove(base))
+
+		err := health.Check()
+		assert.True(t, errors.Is(err, domain.ErrStorageUnavailable))
+		assert.True(t, errors.Is(err, domain.ErrUnsupportedOperation))
+		assert.False(t, health.Healthy())
+	})
+
+	t.Run("base path is a file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(base, []byte("x"), 0o644))
+		t.Cleanup(func() { _ = os.Remove(base) })
+
+		assert.True(t, errors.Is(health.Check(), domain.ErrStorageUnavailable))
+		assert.False(t, health.Healthy())
+	})
+
+	t.Run("base path restored", func(t *testing.T) {
+		require.NoError(t, os.Mkdir(base, 0o755))
+
+		assert.NoError(t, health.Check())
+		assert.True(t, health.Healthy())
+	})
+}
+
+func TestStorageHealth_Run(t *testing.T) {
+	base := t.TempDir()
+	storage := &mockFileStorage{
+		getAbsolutePathFunc: func(relPath string) string { return filepath.Join(base, relPath) },
+	}
+	health := NewStorageHealth(storage)
+	require.True(t, health.Healthy())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		health.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	require.NoError(t, os.RemoveAll(base))
+	assert.Eventually(t, func() bool { return !health.Healthy() }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestStorageHealth_ThroughStorage(t *testing.T) {
+	// у memory GetAbsolutePath виртуальный (/mem), на диске его нет: проба обязана идти через хранилище.
+	var written, removed string
+	storage := &mockFileStorage{
+		getAbsolutePathFunc: func(relPath string) string { return filepath.Join("/mem", relPath) },
+		statFunc: func(relPath string) (os.FileInfo, error) {
+			return fstest.MapFS{}.Stat(".")
+		},
+		writeFileFunc: func(relPath string, file io.Reader) error {
+			written = relPath
+			return nil
+		},
+		removeFunc: func(relPath string) error {
+			removed = relPath
+			return nil
+		},
+	}
+
+	health := NewStorageHealth(storage)
+
+	assert.True(t, health.Healthy())
+	assert.True(t, strings.HasPrefix(written, storageProbePrefix), written)
+	assert.Equal(t, written, removed, "probe file must be cleaned up")
+
+	t.Run("write refused", func(t *testing.T) {
+		storage.writeFileFunc = func(relPath string, file io.Reader) error { return os.ErrPermission }
+
+		assert.True(t, errors.Is(health.Check(), domain.ErrStorageUnavailable))
+		assert.False(t, health.Healthy())
+	})
+}
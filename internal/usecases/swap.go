@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// swapTempPrefix временное имя A на время обмена, скрытое, чтобы не мелькало в листинге.
+const swapTempPrefix = ".swap-"
+
+// Swap меняет местами содержимое двух путей одной папки тремя переименованиями: A -> tmp, B -> A, tmp -> B.
+// каждый шаг — rename в пределах папки, так что читатель видит либо старую, либо новую версию файла целиком,
+// но между первым и вторым шагом A на мгновение отсутствует. при сбое шаги откатываются, данные не теряются.
+func (uc *FileManagementUseCase) Swap(pathA, pathB string) error {
+	a, err := uc.sanitizePath(pathA)
+	if err != nil {
+		return err
+	}
+	b, err := uc.sanitizePath(pathB)
+	if err != nil {
+		return err
+	}
+	if a == b {
+		return fmt.Errorf("swap '%s' with itself: %w", a, domain.ErrInvalidParameter)
+	}
+	// между папками rename может оказаться копированием (другой том, точка монтирования), атомарности нет.
+	if filepath.Dir(a) != filepath.Dir(b) {
+		return fmt.Errorf("swap '%s' and '%s': paths must share a directory: %w", a, b, domain.ErrInvalidParameter)
+	}
+	for _, p := range []string{a, b} {
+		if _, statErr := uc.storage.Stat(p); statErr != nil {
+			return fmt.Errorf("swap: '%s' not found: %w", p, domain.ErrFileNotFound)
+		}
+	}
+
+	tmp := filepath.Join(filepath.Dir(a), swapTempPrefix+filepath.Base(a))
+	// хвост прерванного обмена: в нём лежит чьё-то содержимое, затирать его нельзя.
+	if _, statErr := uc.storage.Stat(tmp); statErr == nil {
+		return fmt.Errorf("swap: leftover '%s' from an interrupted swap: %w", tmp, domain.ErrAlreadyExists)
+	}
+
+	if err = uc.storage.Move(a, tmp); err != nil {
+		return fmt.Errorf("swap: could not move '%s' aside: %w", a, err)
+	}
+	if err = uc.storage.Move(b, a); err != nil {
+		uc.undoSwapStep(tmp, a)
+		return fmt.Errorf("swap: could not move '%s' to '%s': %w", b, a, err)
+	}
+	if err = uc.storage.Move(tmp, b); err != nil {
+		uc.undoSwapStep(a, b)
+		uc.undoSwapStep(tmp, a)
+		return fmt.Errorf("swap: could not move '%s' to '%s': %w", a, b, err)
+	}
+	return nil
+}
+
+// undoSwapStep откат одного шага. если и он не удался, файл остаётся под временным именем, и это в логе.
+func (uc *FileManagementUseCase) undoSwapStep(from, to string) {
+	if err := uc.storage.Move(from, to); err != nil {
+		logrus.Errorf("Swap rollback failed, '%s' was left in place of '%s': %v", from, to, err)
+	}
+}
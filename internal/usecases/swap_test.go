@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Swap(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		failOn  int
+		setup   map[string]string
+		wantErr error
+		swapped bool
+	}{
+		{name: "contents exchanged", a: "app/current.bin", b: "app/staged.bin", swapped: true},
+		{name: "same path", a: "app/current.bin", b: "app/current.bin", wantErr: domain.ErrInvalidParameter},
+		{name: "different directories", a: "app/current.bin", b: "other.bin",
+			setup: map[string]string{"other.bin": "other"}, wantErr: domain.ErrInvalidParameter},
+		{name: "missing file", a: "app/current.bin", b: "app/nope.bin", wantErr: domain.ErrFileNotFound},
+		{name: "leftover from interrupted swap", a: "app/current.bin", b: "app/staged.bin",
+			setup: map[string]string{"app/.swap-current.bin": "orphan"}, wantErr: domain.ErrAlreadyExists},
+		{name: "first move fails", a: "app/current.bin", b: "app/staged.bin", failOn: 1},
+		{name: "second move fails, rolled back", a: "app/current.bin", b: "app/staged.bin", failOn: 2},
+		{name: "third move fails, rolled back", a: "app/current.bin", b: "app/staged.bin", failOn: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t)
+			writeTree(t, tmpDir, map[string]string{"app/current.bin": "v1", "app/staged.bin": "v2"})
+			writeTree(t, tmpDir, tt.setup)
+			storage := hookStorage(uc)
+			moves := 0
+			storage.moveFunc = func(oldRel, newRel string) error {
+				moves++
+				if moves == tt.failOn {
+					return errors.New("disk hiccup")
+				}
+				return storage.FileStorage.Move(oldRel, newRel)
+			}
+
+			err := uc.Swap(tt.a, tt.b)
+
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.failOn > 0:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+			read := func(rel string) string {
+				data, readErr := os.ReadFile(filepath.Join(tmpDir, rel))
+				require.NoError(t, readErr)
+				return string(data)
+			}
+			if tt.swapped {
+				assert.Equal(t, "v2", read("app/current.bin"))
+				assert.Equal(t, "v1", read("app/staged.bin"))
+			} else {
+				assert.Equal(t, "v1", read("app/current.bin"))
+				assert.Equal(t, "v2", read("app/staged.bin"))
+			}
+			if tt.setup == nil {
+				assert.NoFileExists(t, filepath.Join(tmpDir, "app", swapTempPrefix+"current.bin"))
+			}
+		})
+	}
+}
+
+func TestFileManagementUseCase_Swap_ThroughStorage(t *testing.T) {
+	uc, _ := newLocalUseCase(t)
+	fsys := fstest.MapFS{
+		"app/current.bin": {Data: []byte("current")},
+		"app/staged.bin":  {Data: []byte("staged")},
+	}
+	uc.storage = newMutableMapStorage(t, fsys)
+
+	require.NoError(t, uc.Swap("app/current.bin", "app/staged.bin"))
+
+	assert.Equal(t, "staged", string(fsys["app/current.bin"].Data))
+	assert.Equal(t, "current", string(fsys["app/staged.bin"].Data))
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"archive/tar"
+	"os"
+)
+
+// tarHeader заголовок записи tar для файла или папки, name — путь внутри архива.
+// по умолчанию владелец не пишется (чужие uid/gid при распаковке только мешают), режим — только права.
+// с file.tar_preserve_ownership uid/gid и полный режим (setuid, sticky) берутся из stat,
+// чтобы бэкап можно было распаковать с исходными владельцами; вне unix это no-op.
+func tarHeader(fi os.FileInfo, name string, preserveOwnership bool) (*tar.Header, error) {
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	if fi.IsDir() {
+		hdr.Name += "/"
+	}
+	hdr.Format = tar.FormatPAX
+
+	if !preserveOwnership {
+		hdr.Uid, hdr.Gid = 0, 0
+		hdr.Uname, hdr.Gname = "", ""
+		hdr.Mode = int64(fi.Mode().Perm())
+		return hdr, nil
+	}
+	applyOwnership(hdr, fi)
+	return hdr, nil
+}
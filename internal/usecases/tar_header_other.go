@@ -0,0 +1,11 @@
+//go:build !unix
+
+package usecases
+
+import (
+	"archive/tar"
+	"os"
+)
+
+// applyOwnership вне unix владельцев в FileInfo нет, оставляем что дал tar.FileInfoHeader.
+func applyOwnership(*tar.Header, os.FileInfo) {}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarHeader(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{"docs/a.txt": "hello"})
+	require.NoError(t, os.Chmod(filepath.Join(tmpDir, "docs/a.txt"), 0o640))
+
+	tests := []struct {
+		name     string
+		path     string
+		wantName string
+		wantSize int64
+	}{
+		{name: "file", path: "docs/a.txt", wantName: "docs/a.txt", wantSize: 5},
+		{name: "directory", path: "docs", wantName: "docs/", wantSize: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi, err := os.Stat(filepath.Join(tmpDir, tt.path))
+			require.NoError(t, err)
+
+			hdr, err := tarHeader(fi, tt.path, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, hdr.Name)
+			assert.Equal(t, tt.wantSize, hdr.Size)
+			assert.Equal(t, int64(fi.Mode().Perm()), hdr.Mode)
+			assert.Zero(t, hdr.Uid)
+			assert.Zero(t, hdr.Gid)
+			assert.Empty(t, hdr.Uname)
+			assert.Empty(t, hdr.Gname)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package usecases
+
+import (
+	"archive/tar"
+	"os"
+	"syscall"
+)
+
+// modeBits права вместе с setuid, setgid и sticky, как их хранит st_mode.
+const modeBits = 0o7777
+
+func applyOwnership(hdr *tar.Header, fi os.FileInfo) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	hdr.Uid = int(st.Uid)
+	hdr.Gid = int(st.Gid)
+	hdr.Mode = int64(st.Mode & modeBits)
+}
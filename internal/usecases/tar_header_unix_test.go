@@ -0,0 +1,34 @@
+//go:build unix
+
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarHeader_PreserveOwnership(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTree(t, tmpDir, map[string]string{"bin/tool": "#!/bin/sh"})
+	path := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.Chmod(path, 0o755|os.ModeSticky))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	st := fi.Sys().(*syscall.Stat_t)
+
+	hdr, err := tarHeader(fi, "bin", true)
+	require.NoError(t, err)
+	assert.Equal(t, int(st.Uid), hdr.Uid)
+	assert.Equal(t, int(st.Gid), hdr.Gid)
+	assert.Equal(t, int64(0o1755), hdr.Mode, "sticky bit kept")
+
+	hdr, err = tarHeader(fi, "bin", false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0o755), hdr.Mode, "only permissions without the option")
+}
@@ -0,0 +1,20 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// thumbnailExtensions картинки, из которых можно сделать превью стандартной библиотекой (image/jpeg, png, gif).
+var thumbnailExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+}
+
+// hasThumbnail по расширению, без чтения файла: листинг не должен открывать каждую картинку.
+func hasThumbnail(fi os.FileInfo) bool {
+	return !fi.IsDir() && thumbnailExtensions[strings.ToLower(filepath.Ext(fi.Name()))]
+}
@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestHasThumbnail(t *testing.T) {
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"photo.jpg", false, true},
+		{"PHOTO.JPEG", false, true},
+		{"icon.png", false, true},
+		{"anim.gif", false, true},
+		{"doc.pdf", false, false},
+		{"noext", false, false},
+		{"album.jpg", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hasThumbnail(&mockFileInfo{name: tt.name, isDir: tt.isDir}))
+		})
+	}
+}
+
+func TestFileManagementUseCase_List_HasThumbnail(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"gallery/a.png":        "png",
+		"gallery/notes.txt":    "txt",
+		"gallery/sub.jpg/x.md": "dir named like an image",
+	})
+
+	files, err := uc.List("gallery", domain.ListOptions{})
+
+	require.NoError(t, err)
+	got := make(map[string]bool)
+	for _, f := range files {
+		got[f.Name] = f.HasThumbnail
+	}
+	assert.Equal(t, map[string]bool{"a.png": true, "notes.txt": false, "sub.jpg": false}, got)
+}
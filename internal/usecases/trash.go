@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"file-manager/internal/domain"
+)
+
+// корзина повторяет дерево хранилища: `docs/report.txt` уезжает в `.trash/docs/report.txt`, так Restore
+// знает, куда вернуть. при совпадении имени в корзине запись получает суффикс `(1)` и восстанавливается под ним.
+// Move между монтированиями не работает, поэтому у каждого монтирования своя корзина:
+// `photos/a.jpg` уезжает в `photos/.trash/a.jpg`.
+
+// trashDirs корзина корня и корзины монтирований.
+func (uc *FileManagementUseCase) trashDirs() []string {
+	dirs := []string{filepath.Clean(uc.cfg.File.TrashDir)}
+	for _, mount := range slices.Sorted(maps.Keys(uc.cfg.Storage.Mounts)) {
+		dirs = append(dirs, filepath.Join(mount, uc.cfg.File.TrashDir))
+	}
+	return dirs
+}
+
+// trashFor корзина, в которую уезжает relPath, и путь записи внутри неё.
+func (uc *FileManagementUseCase) trashFor(relPath string) (string, string) {
+	clean := filepath.Clean(relPath)
+	if mount, rest, ok := strings.Cut(clean, string(filepath.Separator)); ok {
+		if _, isMount := uc.cfg.Storage.Mounts[mount]; isMount {
+			return filepath.Join(mount, uc.cfg.File.TrashDir), rest
+		}
+	}
+	return filepath.Clean(uc.cfg.File.TrashDir), clean
+}
+
+// trashRoot корзина, в которой лежит relPath (или которой он является), "" — не в корзине.
+func (uc *FileManagementUseCase) trashRoot(relPath string) string {
+	if !uc.cfg.File.TrashEnabled {
+		return ""
+	}
+	clean := filepath.Clean(relPath)
+	for _, trash := range uc.trashDirs() {
+		if clean == trash || strings.HasPrefix(clean, trash+string(filepath.Separator)) {
+			return trash
+		}
+	}
+	return ""
+}
+
+// isTrash путь ведёт в корзину или внутрь неё. с выключенной корзиной такой папки для юзкейса нет.
+func (uc *FileManagementUseCase) isTrash(relPath string) bool {
+	return uc.trashRoot(relPath) != ""
+}
+
+// isTrashDir relPath — сама папка корзины. её прячут листинг и архивы родителя, а содержимое внутри видно.
+func (uc *FileManagementUseCase) isTrashDir(relPath string) bool {
+	trash := uc.trashRoot(relPath)
+	return trash != "" && trash == filepath.Clean(relPath)
+}
+
+// trashOrigin откуда удалена запись корзины: `.trash/docs/a.txt` — это `docs/a.txt`, `photos/.trash/a.jpg` —
+// `photos/a.jpg`, сама корзина — корень или монтирование. false — relPath не в корзине.
+func (uc *FileManagementUseCase) trashOrigin(relPath string) (string, bool) {
+	trash := uc.trashRoot(relPath)
+	if trash == "" {
+		return "", false
+	}
+	inner, err := filepath.Rel(trash, filepath.Clean(relPath))
+	if err != nil {
+		return "", false
+	}
+	mount := strings.TrimSuffix(trash, filepath.Clean(uc.cfg.File.TrashDir))
+	return filepath.Join(mount, inner), true
+}
+
+// moveToTrash переносит запись в корзину, недостающие папки по пути создаются.
+func (uc *FileManagementUseCase) moveToTrash(sanitizedPath string) error {
+	if sanitizedPath == domain.PathCurrent {
+		return fmt.Errorf("delete storage root: %w", domain.ErrInvalidParameter)
+	}
+	exists, err := uc.exists(sanitizedPath)
+	if err != nil {
+		return fmt.Errorf("failed to check '%s': %w", sanitizedPath, err)
+	}
+	if !exists {
+		return fmt.Errorf("could not delete '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	uc.trash.mu.Lock()
+	defer uc.trash.mu.Unlock()
+
+	trash, inner := uc.trashFor(sanitizedPath)
+	target := filepath.Join(trash, inner)
+	if err = uc.storage.CreateDirectory(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("could not prepare trash for '%s': %w", sanitizedPath, err)
+	}
+	if target, err = uc.resolveConflict(target, domain.ConflictRename); err != nil {
+		return err
+	}
+	if err = uc.storage.Move(sanitizedPath, target); err != nil {
+		return fmt.Errorf("could not move '%s' to trash: %w", sanitizedPath, err)
+	}
+	uc.recordTrashed(trash, target, time.Now())
+	return nil
+}
+
+// Restore возвращает запись из корзины на прежнее место. path — путь внутри корзины, как в её листинге;
+// для корзины монтирования — с именем монтирования впереди (`photos/a.jpg` из `photos/.trash/a.jpg`).
+// на прежнем месте уже что-то лежит — ErrAlreadyExists, ничего не затирается.
+func (uc *FileManagementUseCase) Restore(path string) error {
+	if !uc.cfg.File.TrashEnabled {
+		return fmt.Errorf("restore: trash is disabled: %w", domain.ErrUnsupportedOperation)
+	}
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return err
+	}
+	if sanitizedPath == domain.PathCurrent || uc.isTrash(sanitizedPath) {
+		return fmt.Errorf("restore '%s': %w", sanitizedPath, domain.ErrInvalidParameter)
+	}
+
+	trash, inner := uc.trashFor(sanitizedPath)
+	if filepath.ToSlash(inner) == trashIndexName {
+		return fmt.Errorf("restore '%s': %w", sanitizedPath, domain.ErrInvalidParameter)
+	}
+	source := filepath.Join(trash, inner)
+	// очистка, которая как раз стирает эту запись, её пропустит, а занятую очисткой — не отдаём.
+	if !uc.trash.restoring.tryLock(source) {
+		return fmt.Errorf("'%s' is being restored or purged: %w", sanitizedPath, domain.ErrPathBusy)
+	}
+	defer uc.trash.restoring.unlock(source)
+	// проверка, перенос и индекс под trash.mu: иначе EmptyTrash мог бы стереть запись посреди восстановления
+	// или оставить в индексе то, чего уже нет.
+	uc.trash.mu.Lock()
+	defer uc.trash.mu.Unlock()
+
+	exists, err := uc.exists(source)
+	if err != nil {
+		return fmt.Errorf("failed to check '%s': %w", source, err)
+	}
+	if !exists {
+		return fmt.Errorf("'%s' is not in trash: %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+	if _, err = uc.resolveConflict(sanitizedPath, domain.ConflictError); err != nil {
+		return err
+	}
+	if parent := filepath.Dir(sanitizedPath); parent != domain.PathCurrent {
+		if err = uc.storage.CreateDirectory(parent); err != nil {
+			return fmt.Errorf("could not recreate '%s': %w", parent, err)
+		}
+	}
+	if err = uc.storage.Move(source, sanitizedPath); err != nil {
+		return fmt.Errorf("could not restore '%s': %w", sanitizedPath, err)
+	}
+	uc.forgetTrashed(trash, source)
+	uc.pruneTrashParents(filepath.Dir(source))
+	return nil
+}
+
+// pruneTrashParents убирает опустевшие после Restore папки корзины, сама корзина остаётся.
+// ошибка тут не мешает восстановлению, пустая папка в корзине никому не вредит.
+func (uc *FileManagementUseCase) pruneTrashParents(dir string) {
+	for trash := uc.trashRoot(dir); trash != "" && dir != trash; dir = filepath.Dir(dir) {
+		entries, err := uc.storage.ReadDirectory(dir)
+		if err != nil || len(entries) > 0 {
+			return
+		}
+		if err = uc.storage.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+// EmptyTrash стирает содержимое корзины без возврата, вместе с корзинами монтирований.
+func (uc *FileManagementUseCase) EmptyTrash() error {
+	if !uc.cfg.File.TrashEnabled {
+		return fmt.Errorf("empty trash: trash is disabled: %w", domain.ErrUnsupportedOperation)
+	}
+	uc.trash.mu.Lock()
+	defer uc.trash.mu.Unlock()
+
+	for _, trash := range uc.trashDirs() {
+		if err := uc.storage.Remove(trash); err != nil {
+			return fmt.Errorf("could not empty trash '%s': %w", trash, err)
+		}
+	}
+	return nil
+}
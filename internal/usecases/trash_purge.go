@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// trashIndexName индекс удалений в корне каждой корзины: путь записи внутри корзины -> когда её удалили.
+// время изменения для срока хранения не годится: Move его сохраняет, и давно не тронутый файл
+// стёрся бы в первый же проход после удаления.
+const trashIndexName = ".deleted.json"
+
+type trashIndex map[string]time.Time
+
+// isTrashIndex relPath — индекс удалений в корне корзины, в листинге и архивах его не видно.
+func (uc *FileManagementUseCase) isTrashIndex(relPath string) bool {
+	return filepath.Base(relPath) == trashIndexName && uc.isTrashDir(filepath.Dir(relPath))
+}
+
+// trashState согласует изменения корзины. mu держат перенос в корзину, правка индекса и очистка,
+// чтобы очистка не стёрла папку, в которую как раз переносят свежеудалённое.
+// restoring — записи, которые сейчас восстанавливаются: Restore их занимает, очистка пропускает.
+type trashState struct {
+	mu        sync.Mutex
+	restoring pathLocks
+}
+
+// TrashPurger фоном стирает из корзины записи, удалённые раньше file.trash_retention, и помнит итог
+// последнего прохода для /trash-purge.
+type TrashPurger struct {
+	uc        *FileManagementUseCase
+	retention time.Duration
+
+	mu   sync.Mutex
+	last *domain.TrashPurgeReport
+}
+
+func NewTrashPurger(uc *FileManagementUseCase, retention time.Duration) *TrashPurger {
+	return &TrashPurger{uc: uc, retention: retention}
+}
+
+// Run чистит сразу и дальше каждые interval, пока не отменят ctx.
+func (p *TrashPurger) Run(ctx context.Context, interval time.Duration) {
+	p.Purge(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.Purge(now)
+		}
+	}
+}
+
+// Purge один проход: стирает всё, что удалено раньше now минус срок хранения. ошибка не прерывает Run,
+// она попадает в лог и в итог прохода.
+func (p *TrashPurger) Purge(now time.Time) domain.TrashPurgeReport {
+	report := domain.TrashPurgeReport{StartedAt: now}
+	if err := p.uc.purgeTrash(now.Add(-p.retention), &report); err != nil {
+		report.Error = err.Error()
+		logrus.Errorf("Trash purge failed: %v", err)
+	}
+	logrus.WithFields(logrus.Fields{
+		"purged":      report.Purged,
+		"bytes_freed": report.BytesFreed,
+		"skipped":     report.Skipped,
+	}).Info("Trash purge finished")
+
+	p.mu.Lock()
+	p.last = &report
+	p.mu.Unlock()
+	return report
+}
+
+// LastRun итог последнего прохода, false — проходов ещё не было.
+func (p *TrashPurger) LastRun() (domain.TrashPurgeReport, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		return domain.TrashPurgeReport{}, false
+	}
+	return *p.last, true
+}
+
+// purgeTrash стирает записи всех корзин, удалённые не позже cutoff.
+func (uc *FileManagementUseCase) purgeTrash(cutoff time.Time, report *domain.TrashPurgeReport) error {
+	for _, trash := range uc.trashDirs() {
+		if err := uc.purgeTrashDir(trash, cutoff, report); err != nil {
+			return fmt.Errorf("purge trash '%s': %w", trash, err)
+		}
+	}
+	return nil
+}
+
+// purgeTrashDir записи без отметки в индексе (положены в обход Delete или до появления индекса)
+// отмечаются временем прохода и уйдут через полный срок хранения, а не сразу.
+func (uc *FileManagementUseCase) purgeTrashDir(trash string, cutoff time.Time, report *domain.TrashPurgeReport) error {
+	uc.trash.mu.Lock()
+	defer uc.trash.mu.Unlock()
+
+	if _, err := uc.storage.Stat(trash); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	index, err := uc.readTrashIndex(trash)
+	if err != nil {
+		return err
+	}
+	if err = uc.indexUntracked(trash, "", index, report.StartedAt); err != nil {
+		return err
+	}
+
+	for _, inner := range slices.Sorted(maps.Keys(index)) {
+		if index[inner].After(cutoff) {
+			continue
+		}
+		entry := filepath.Join(trash, filepath.FromSlash(inner))
+		if !uc.trash.restoring.tryLock(entry) {
+			report.Skipped++
+			continue
+		}
+		size, removed, purgeErr := uc.purgeTrashEntry(entry)
+		uc.trash.restoring.unlock(entry)
+		if purgeErr != nil {
+			err = purgeErr
+			break
+		}
+		delete(index, inner)
+		if removed {
+			report.Purged++
+			report.BytesFreed += size
+		}
+	}
+	// индекс сохраняем и после ошибки: уже стёртые записи из него надо убрать.
+	if writeErr := uc.writeTrashIndex(trash, index); err == nil {
+		err = writeErr
+	}
+	return err
+}
+
+// purgeTrashEntry стирает запись корзины и опустевшие над ней папки. false без ошибки — записи уже нет.
+func (uc *FileManagementUseCase) purgeTrashEntry(entry string) (int64, bool, error) {
+	size, err := uc.storedSize(entry)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if err = uc.storage.Remove(entry); err != nil {
+		return 0, false, fmt.Errorf("could not remove '%s': %w", entry, err)
+	}
+	uc.pruneTrashParents(filepath.Dir(entry))
+	return size, true, nil
+}
+
+// indexUntracked отмечает временем now записи корзины, которых нет в индексе. папка, внутри которой
+// есть отмеченные записи, сама записью не считается: её содержимое разбирается глубже.
+func (uc *FileManagementUseCase) indexUntracked(trash, innerDir string, index trashIndex, now time.Time) error {
+	entries, err := uc.storage.ReadDirectory(filepath.Join(trash, innerDir))
+	if err != nil {
+		return err
+	}
+	for _, info := range entries {
+		inner := filepath.ToSlash(filepath.Join(innerDir, info.Name()))
+		if _, tracked := index[inner]; tracked || inner == trashIndexName {
+			continue
+		}
+		if info.IsDir() && indexedUnder(index, inner) {
+			if err = uc.indexUntracked(trash, inner, index, now); err != nil {
+				return err
+			}
+			continue
+		}
+		index[inner] = now
+	}
+	return nil
+}
+
+func indexedUnder(index trashIndex, dir string) bool {
+	for inner := range index {
+		if strings.HasPrefix(inner, dir+domain.PathRoot) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTrashed отмечает в индексе, что target только что попал в корзину. звать под uc.trash.mu.
+// индекс не записался — не беда: очистка отметит запись сама, просто позже.
+func (uc *FileManagementUseCase) recordTrashed(trash, target string, at time.Time) {
+	if err := uc.updateTrashIndex(trash, func(index trashIndex) {
+		index[trashKey(trash, target)] = at
+	}); err != nil {
+		logrus.Warnf("Failed to record '%s' in trash index: %v", target, err)
+	}
+}
+
+// forgetTrashed убирает из индекса восстановленную запись и всё, что было отмечено внутри неё. звать под uc.trash.mu.
+func (uc *FileManagementUseCase) forgetTrashed(trash, source string) {
+	key := trashKey(trash, source)
+	if err := uc.updateTrashIndex(trash, func(index trashIndex) {
+		maps.DeleteFunc(index, func(inner string, _ time.Time) bool {
+			return inner == key || strings.HasPrefix(inner, key+domain.PathRoot)
+		})
+	}); err != nil {
+		logrus.Warnf("Failed to drop '%s' from trash index: %v", source, err)
+	}
+}
+
+// trashKey путь внутри корзины в виде ключа индекса.
+func trashKey(trash, entry string) string {
+	inner, err := filepath.Rel(trash, entry)
+	if err != nil {
+		return filepath.ToSlash(entry)
+	}
+	return filepath.ToSlash(inner)
+}
+
+func (uc *FileManagementUseCase) updateTrashIndex(trash string, update func(trashIndex)) error {
+	index, err := uc.readTrashIndex(trash)
+	if err != nil {
+		return err
+	}
+	update(index)
+	return uc.writeTrashIndex(trash, index)
+}
+
+func (uc *FileManagementUseCase) readTrashIndex(trash string) (trashIndex, error) {
+	index := trashIndex{}
+	data, err := uc.readStored(filepath.Join(trash, trashIndexName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("bad trash index in '%s': %w", trash, err)
+	}
+	return index, nil
+}
+
+// writeTrashIndex пустой индекс удаляется, чтобы опустевшая корзина не держала файл.
+func (uc *FileManagementUseCase) writeTrashIndex(trash string, index trashIndex) error {
+	name := filepath.Join(trash, trashIndexName)
+	if len(index) == 0 {
+		return uc.storage.Remove(name)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	return uc.storage.WriteFile(name, bytes.NewReader(data))
+}
+
+// storedSize размер файла или всего содержимого папки, через хранилище.
+func (uc *FileManagementUseCase) storedSize(relPath string) (int64, error) {
+	info, err := uc.storage.Stat(relPath)
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return info.Size(), nil
+	}
+	entries, err := uc.storage.ReadDirectory(relPath)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			total += entry.Size()
+			continue
+		}
+		size, sizeErr := uc.storedSize(filepath.Join(relPath, entry.Name()))
+		if sizeErr != nil {
+			return 0, sizeErr
+		}
+		total += size
+	}
+	return total, nil
+}
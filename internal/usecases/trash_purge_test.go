@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+const testRetention = 24 * time.Hour
+
+func TestTrashPurger_Purge(t *testing.T) {
+	t.Run("expired entries removed, fresh kept", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/old.txt": "old data", "docs/new.txt": "new"})
+		require.NoError(t, uc.Delete("docs/old.txt", domain.DeleteOptions{}))
+		require.NoError(t, uc.Delete("docs/new.txt", domain.DeleteOptions{}))
+		now := time.Now()
+		uc.recordTrashed(".bin", ".bin/docs/old.txt", now.Add(-2*testRetention))
+
+		report := NewTrashPurger(uc, testRetention).Purge(now)
+
+		assert.Equal(t, 1, report.Purged)
+		assert.Equal(t, int64(len("old data")), report.BytesFreed)
+		assert.Empty(t, report.Error)
+		assert.Equal(t, map[string]string{"docs": "/", ".bin/docs/new.txt": "new"}, readTrashTree(t, tmpDir))
+		index, err := uc.readTrashIndex(".bin")
+		require.NoError(t, err)
+		assert.Len(t, index, 1)
+		assert.Contains(t, index, "docs/new.txt")
+	})
+
+	t.Run("deleted folder counted once with its size", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/a.txt": "aa", "docs/sub/b.txt": "bbb"})
+		require.NoError(t, uc.Delete("docs", domain.DeleteOptions{}))
+
+		report := NewTrashPurger(uc, testRetention).Purge(time.Now().Add(2 * testRetention))
+
+		assert.Equal(t, 1, report.Purged)
+		assert.Equal(t, int64(5), report.BytesFreed)
+		assert.Equal(t, map[string]string{".bin": "/"}, readTree(t, tmpDir), "empty index is removed")
+	})
+
+	t.Run("untracked entries wait a full retention", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{".bin/legacy.txt": "x"})
+		purger := NewTrashPurger(uc, testRetention)
+		now := time.Now()
+
+		report := purger.Purge(now)
+		assert.Zero(t, report.Purged)
+		assert.Equal(t, map[string]string{".bin/legacy.txt": "x"}, readTrashTree(t, tmpDir))
+
+		report = purger.Purge(now.Add(2 * testRetention))
+		assert.Equal(t, 1, report.Purged)
+		assert.Equal(t, map[string]string{".bin": "/"}, readTree(t, tmpDir))
+	})
+
+	t.Run("entry being restored is skipped", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/a.txt": "a"})
+		require.NoError(t, uc.Delete("docs/a.txt", domain.DeleteOptions{}))
+		require.True(t, uc.trash.restoring.tryLock(".bin/docs"))
+
+		report := NewTrashPurger(uc, testRetention).Purge(time.Now().Add(2 * testRetention))
+
+		assert.Zero(t, report.Purged)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, "a", readTrashTree(t, tmpDir)[".bin/docs/a.txt"])
+	})
+
+	t.Run("mount trash", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		uc.cfg.Storage.Mounts = map[string]string{"photos": "/mnt/photos"}
+		writeTree(t, tmpDir, map[string]string{"photos/a.jpg": "jpeg"})
+		require.NoError(t, uc.Delete("photos/a.jpg", domain.DeleteOptions{}))
+
+		report := NewTrashPurger(uc, testRetention).Purge(time.Now().Add(2 * testRetention))
+
+		assert.Equal(t, 1, report.Purged)
+		assert.Equal(t, int64(4), report.BytesFreed)
+		assert.Equal(t, map[string]string{"photos/.bin": "/"}, readTree(t, tmpDir))
+	})
+
+	t.Run("restored entry leaves the index", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/a.txt": "a"})
+		require.NoError(t, uc.Delete("docs/a.txt", domain.DeleteOptions{}))
+
+		require.NoError(t, uc.Restore("docs/a.txt"))
+
+		index, err := uc.readTrashIndex(".bin")
+		require.NoError(t, err)
+		assert.Empty(t, index)
+		assert.Equal(t, map[string]string{"docs/a.txt": "a", ".bin": "/"}, readTree(t, tmpDir))
+	})
+}
+
+func TestTrashPurger_LastRun(t *testing.T) {
+	uc, _ := newLocalUseCase(t, trashConfig(true))
+	purger := NewTrashPurger(uc, testRetention)
+
+	_, ok := purger.LastRun()
+	assert.False(t, ok)
+
+	report := purger.Purge(time.Now())
+	last, ok := purger.LastRun()
+	assert.True(t, ok)
+	assert.Equal(t, report, last)
+}
+
+func TestFileManagementUseCase_List_HidesTrashIndex(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+	writeTree(t, tmpDir, map[string]string{"docs/a.txt": "a"})
+	require.NoError(t, uc.Delete("docs/a.txt", domain.DeleteOptions{}))
+
+	files, err := uc.List(".bin", domain.ListOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "docs", files[0].Name)
+}
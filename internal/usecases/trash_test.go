@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/adapters/localstorage"
+	"file-manager/internal/adapters/mountstorage"
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func trashConfig(enabled bool) func(*config.FileConfig) {
+	return func(f *config.FileConfig) {
+		f.TrashEnabled = enabled
+		f.TrashDir = ".bin"
+	}
+}
+
+// readTrashTree readTree без индексов удалений, их проверяют отдельно в trash_purge_test.go.
+func readTrashTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	tree := readTree(t, root)
+	for rel := range tree {
+		if path.Base(rel) == trashIndexName {
+			delete(tree, rel)
+		}
+	}
+	return tree
+}
+
+func TestFileManagementUseCase_Delete_Trash(t *testing.T) {
+	t.Run("moves into trash keeping the path", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/report.txt": "v1", "docs/sub/a.txt": "a"})
+
+		require.NoError(t, uc.Delete("docs/report.txt", domain.DeleteOptions{}))
+		require.NoError(t, uc.Delete("docs/sub", domain.DeleteOptions{}))
+
+		assert.Equal(t, map[string]string{
+			"docs":                 "/",
+			".bin/docs/report.txt": "v1",
+			".bin/docs/sub/a.txt":  "a",
+		}, readTrashTree(t, tmpDir))
+	})
+
+	t.Run("name collision in trash", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{"docs/report.txt": "v2", ".bin/docs/report.txt": "v1"})
+
+		require.NoError(t, uc.Delete("docs/report.txt", domain.DeleteOptions{}))
+
+		tree := readTrashTree(t, tmpDir)
+		assert.Equal(t, "v1", tree[".bin/docs/report.txt"])
+		assert.Equal(t, "v2", tree[".bin/docs/report (1).txt"])
+	})
+
+	t.Run("deleting inside trash is permanent", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{".bin/docs/report.txt": "v1"})
+
+		require.NoError(t, uc.Delete(".bin/docs/report.txt", domain.DeleteOptions{}))
+
+		assert.Equal(t, map[string]string{".bin/docs": "/"}, readTrashTree(t, tmpDir))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t, trashConfig(true))
+
+		err := uc.Delete("ghost.txt", domain.DeleteOptions{})
+
+		assert.ErrorIs(t, err, domain.ErrFileNotFound)
+	})
+
+	t.Run("disabled removes for good", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(false))
+		writeTree(t, tmpDir, map[string]string{"docs/report.txt": "v1"})
+
+		require.NoError(t, uc.Delete("docs/report.txt", domain.DeleteOptions{}))
+
+		assert.Equal(t, map[string]string{"docs": "/"}, readTrashTree(t, tmpDir))
+	})
+}
+
+func TestFileManagementUseCase_Restore(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		path    string
+		files   map[string]string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name: "restores and recreates parents", enabled: true, path: "docs/sub/a.txt",
+			files: map[string]string{".bin/docs/sub/a.txt": "a", ".bin/other.txt": "o"},
+			want:  map[string]string{"docs/sub/a.txt": "a", ".bin/other.txt": "o"},
+		},
+		{
+			name: "restores folder", enabled: true, path: "docs",
+			files: map[string]string{".bin/docs/a.txt": "a", ".bin/docs/b.txt": "b"},
+			want:  map[string]string{"docs/a.txt": "a", "docs/b.txt": "b", ".bin": "/"},
+		},
+		{
+			name: "occupied original place", enabled: true, path: "docs/a.txt",
+			files:   map[string]string{".bin/docs/a.txt": "old", "docs/a.txt": "new"},
+			want:    map[string]string{".bin/docs/a.txt": "old", "docs/a.txt": "new"},
+			wantErr: domain.ErrAlreadyExists,
+		},
+		{
+			name: "not in trash", enabled: true, path: "docs/a.txt",
+			files:   map[string]string{"docs/b.txt": "b"},
+			want:    map[string]string{"docs/b.txt": "b"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name: "trash itself", enabled: true, path: ".bin",
+			files:   map[string]string{".bin/a.txt": "a"},
+			want:    map[string]string{".bin/a.txt": "a"},
+			wantErr: domain.ErrInvalidParameter,
+		},
+		{
+			name: "trash index", enabled: true, path: trashIndexName,
+			files:   map[string]string{".bin/a.txt": "a"},
+			want:    map[string]string{".bin/a.txt": "a"},
+			wantErr: domain.ErrInvalidParameter,
+		},
+		{
+			name: "disabled", enabled: false, path: "a.txt",
+			files:   map[string]string{".bin/a.txt": "a"},
+			want:    map[string]string{".bin/a.txt": "a"},
+			wantErr: domain.ErrUnsupportedOperation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, tmpDir := newLocalUseCase(t, trashConfig(tt.enabled))
+			writeTree(t, tmpDir, tt.files)
+
+			err := uc.Restore(tt.path)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, readTrashTree(t, tmpDir))
+		})
+	}
+}
+
+func TestFileManagementUseCase_Restore_Busy(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+	writeTree(t, tmpDir, map[string]string{".bin/docs/a.txt": "a"})
+	// так запись держит очистка корзины.
+	require.True(t, uc.trash.restoring.tryLock(filepath.Join(".bin", "docs")))
+
+	err := uc.Restore("docs/a.txt")
+
+	assert.ErrorIs(t, err, domain.ErrPathBusy)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+	assert.Equal(t, map[string]string{".bin/docs/a.txt": "a"}, readTrashTree(t, tmpDir))
+}
+
+func TestFileManagementUseCase_Restore_ConcurrentEmpty(t *testing.T) {
+	const files = 200
+	for round := 0; round < 20; round++ {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		tree := make(map[string]string, files)
+		for i := 0; i < files; i++ {
+			tree[fmt.Sprintf("docs/%03d.txt", i)] = "x"
+		}
+		writeTree(t, tmpDir, tree)
+		require.NoError(t, uc.Delete("docs", domain.DeleteOptions{}))
+
+		var wg sync.WaitGroup
+		var restoreErr, emptyErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			restoreErr = uc.Restore("docs")
+		}()
+		go func() {
+			defer wg.Done()
+			emptyErr = uc.EmptyTrash()
+		}()
+		wg.Wait()
+
+		require.NoError(t, emptyErr)
+		got := readTrashTree(t, tmpDir)
+		if restoreErr == nil {
+			// восстановили — значит целиком: очистка не успела выгрызть часть папки.
+			assert.Equal(t, tree, got, "round %d", round)
+		} else {
+			assert.ErrorIs(t, restoreErr, domain.ErrFileNotFound, "round %d", round)
+			assert.Empty(t, got, "round %d", round)
+		}
+		index, err := uc.readTrashIndex(".bin")
+		require.NoError(t, err)
+		assert.Empty(t, index, "round %d", round)
+	}
+}
+
+func TestFileManagementUseCase_EmptyTrash(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+		writeTree(t, tmpDir, map[string]string{".bin/docs/a.txt": "a", "keep.txt": "k"})
+
+		require.NoError(t, uc.EmptyTrash())
+
+		assert.Equal(t, map[string]string{"keep.txt": "k"}, readTrashTree(t, tmpDir))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t, trashConfig(false))
+
+		assert.ErrorIs(t, uc.EmptyTrash(), domain.ErrUnsupportedOperation)
+	})
+}
+
+func TestFileManagementUseCase_TrashHidden(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+	writeTree(t, tmpDir, map[string]string{"docs/a.txt": "a", "docs/b.txt": "b"})
+	require.NoError(t, uc.Delete("docs/b.txt", domain.DeleteOptions{}))
+	// имя корзины без точки, чтобы её не прятало правило скрытых файлов.
+	require.NoError(t, os.Rename(filepath.Join(tmpDir, ".bin"), filepath.Join(tmpDir, "bin")))
+	uc.cfg.File.TrashDir = "bin"
+
+	files, err := uc.List("", domain.ListOptions{})
+	require.NoError(t, err)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"docs"}, names)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/download-folder", nil), ""))
+	entries := zipContents(t, w.Body.Bytes())
+	assert.Contains(t, entries, "docs/a.txt")
+	for name := range entries {
+		assert.NotContains(t, name, "bin/", "trash must not be zipped")
+	}
+	assert.True(t, uc.Capabilities().Trash)
+
+	t.Run("contents listed and zipped", func(t *testing.T) {
+		files, err := uc.List("bin", domain.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "docs", files[0].Name)
+
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/download-folder", nil), "bin"))
+		assert.Contains(t, zipContents(t, w.Body.Bytes()), "docs/b.txt")
+	})
+}
+
+func TestFileManagementUseCase_Trash_Mounts(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, trashConfig(true))
+	uc.cfg.Storage.Mounts = map[string]string{"photos": "/mnt/photos"}
+	// монтирование лежит в той же папке, чтобы readTrashTree видел всё дерево сразу;
+	// Move между ним и корнем MountStorage всё равно не пропускает.
+	photos := localstorage.NewLocalStorageService(filepath.Join(tmpDir, "photos"), 0o755)
+	uc.storage = mountstorage.NewMountStorage(uc.storage, map[string]domain.FileStorage{"photos": photos})
+	writeTree(t, tmpDir, map[string]string{"photos/2024/a.jpg": "a", "docs/b.txt": "b"})
+
+	require.NoError(t, uc.Delete("photos/2024/a.jpg", domain.DeleteOptions{}))
+	require.NoError(t, uc.Delete("docs/b.txt", domain.DeleteOptions{}))
+	assert.Equal(t, map[string]string{
+		"photos/2024":            "/",
+		"photos/.bin/2024/a.jpg": "a",
+		"docs":                   "/",
+		".bin/docs/b.txt":        "b",
+	}, readTrashTree(t, tmpDir))
+
+	files, err := uc.List("photos", domain.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 1, "mount trash is hidden from listing")
+	assert.Equal(t, "2024", files[0].Name)
+
+	origin, inTrash := uc.trashOrigin("photos/.bin/2024/a.jpg")
+	assert.True(t, inTrash)
+	assert.Equal(t, filepath.FromSlash("photos/2024/a.jpg"), origin)
+
+	require.NoError(t, uc.Restore("photos/2024/a.jpg"))
+	assert.Equal(t, map[string]string{
+		"photos/2024/a.jpg": "a",
+		"photos/.bin":       "/",
+		"docs":              "/",
+		".bin/docs/b.txt":   "b",
+	}, readTrashTree(t, tmpDir))
+
+	require.NoError(t, uc.Delete("photos/2024/a.jpg", domain.DeleteOptions{}))
+	require.NoError(t, uc.EmptyTrash())
+	assert.Equal(t, map[string]string{"photos/2024": "/", "docs": "/"}, readTrashTree(t, tmpDir))
+}
@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"file-manager/internal/domain"
+)
+
+// Tree дерево папки path за один обход, скрытое и drop-box пропускаются как в zip.
+// обход ограничен file.max_walk_depth/max_walk_entries, оборванное дерево помечено Truncated у корня.
+// collapse склеивает цепочки папок, где внутри ровно одна подпапка и нет файлов, в один узел.
+func (uc *FileManagementUseCase) Tree(path string, collapse bool) (*domain.TreeNode, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, fmt.Errorf("tree of drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return nil, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	root := &domain.TreeNode{Name: info.Name(), Path: filepath.ToSlash(sanitizedPath), IsDir: true}
+	// Walk идёт в лексическом порядке, так что родитель всегда уже в dirs, а дети отсортированы.
+	dirs := map[string]*domain.TreeNode{".": root}
+	root.Truncated, err = uc.guardedWalk(sanitizedPath, func(_, rel string, fi os.FileInfo) error {
+		node := &domain.TreeNode{
+			Name:  fi.Name(),
+			Path:  filepath.ToSlash(filepath.Join(sanitizedPath, rel)),
+			IsDir: fi.IsDir(),
+		}
+		if fi.IsDir() {
+			dirs[rel] = node
+		} else {
+			node.Size = fi.Size()
+		}
+		parent := dirs[filepath.Dir(rel)]
+		parent.Children = append(parent.Children, node)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk '%s': %w", sanitizedPath, err)
+	}
+
+	if collapse {
+		for _, child := range root.Children {
+			collapseChain(child)
+		}
+	}
+	return root, nil
+}
+
+// collapseChain сам корень запроса не склеивается: клиент должен получить ту папку, которую просил.
+func collapseChain(node *domain.TreeNode) {
+	if !node.IsDir {
+		return
+	}
+	for len(node.Children) == 1 && node.Children[0].IsDir {
+		only := node.Children[0]
+		node.Name += domain.PathRoot + only.Name
+		node.Path = only.Path
+		node.Children = only.Children
+	}
+	for _, child := range node.Children {
+		collapseChain(child)
+	}
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_Tree(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"src/com/example/project/Main.java":   "class",
+		"src/com/example/project/util/X.java": "x",
+		"src/readme.md":                       "doc",
+		"docs/a/b.txt":                        "b",
+		"docs/.cache/c.txt":                   "hidden",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "empty/chain"), 0o755))
+
+	t.Run("full tree", func(t *testing.T) {
+		tree, err := uc.Tree("src", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "src", tree.Path)
+		require.Len(t, tree.Children, 2)
+		com := tree.Children[0]
+		assert.Equal(t, "com", com.Name)
+		require.Len(t, com.Children, 1)
+		assert.Equal(t, "example", com.Children[0].Name)
+		assert.Equal(t, &domain.TreeNode{Name: "readme.md", Path: "src/readme.md", Size: 3}, tree.Children[1])
+	})
+
+	t.Run("collapsed chains", func(t *testing.T) {
+		tree, err := uc.Tree("", true)
+
+		require.NoError(t, err)
+		got, err := json.Marshal(tree.Children)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[
+			{"name": "docs/a", "path": "docs/a", "is_dir": true, "children": [
+				{"name": "b.txt", "path": "docs/a/b.txt", "is_dir": false, "size": 1}
+			]},
+			{"name": "empty/chain", "path": "empty/chain", "is_dir": true},
+			{"name": "src", "path": "src", "is_dir": true, "children": [
+				{"name": "com/example/project", "path": "src/com/example/project", "is_dir": true, "children": [
+					{"name": "Main.java", "path": "src/com/example/project/Main.java", "is_dir": false, "size": 5},
+					{"name": "util", "path": "src/com/example/project/util", "is_dir": true, "children": [
+						{"name": "X.java", "path": "src/com/example/project/util/X.java", "is_dir": false, "size": 1}
+					]}
+				]},
+				{"name": "readme.md", "path": "src/readme.md", "is_dir": false, "size": 3}
+			]}
+		]`, string(got))
+	})
+
+	t.Run("requested folder is not merged", func(t *testing.T) {
+		tree, err := uc.Tree("src/com", true)
+
+		require.NoError(t, err)
+		assert.Equal(t, "com", tree.Name)
+		require.Len(t, tree.Children, 1)
+		assert.Equal(t, "example/project", tree.Children[0].Name)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "missing folder", path: "nope", wantErr: domain.ErrFileNotFound},
+		{name: "file instead of folder", path: "src/readme.md", wantErr: domain.ErrFileNotFound},
+		{name: "path traversal", path: "../etc", wantErr: domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.Tree(tt.path, false)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"file-manager/internal/domain"
+)
+
+// UploadMetadataWriter PostUploadHook: пишет рядом с загруженным файлом сайдкар `<file>.meta.json`
+// (исходное имя, время, кто загрузил, размер и sha256), так происхождение файла видно без базы.
+// сайдкар живёт отдельно от файла: переименование и удаление файла его не трогают.
+type UploadMetadataWriter struct {
+	storage domain.FileStorage
+	now     func() time.Time
+}
+
+func NewUploadMetadataWriter(storage domain.FileStorage) *UploadMetadataWriter {
+	return &UploadMetadataWriter{storage: storage, now: time.Now}
+}
+
+func (w *UploadMetadataWriter) AfterUpload(relPath string, info domain.UploadInfo) {
+	if err := w.write(relPath, info); err != nil {
+		logrus.Warnf("Failed to write upload metadata for %s: %v", relPath, err)
+	}
+}
+
+func (w *UploadMetadataWriter) write(relPath string, info domain.UploadInfo) error {
+	// размер и хеш через хранилище: у зашифрованного это открытый текст, как в листинге и /hash.
+	stat, err := w.storage.Stat(relPath)
+	if err != nil {
+		return err
+	}
+	f, err := w.storage.Open(relPath)
+	if err != nil {
+		return err
+	}
+	sum, err := hashReader(f)
+	closeLogged(f, relPath)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(domain.UploadMetadata{
+		OriginalName: info.OriginalName,
+		UploadedAt:   w.now().UTC(),
+		Uploader:     info.Uploader,
+		Size:         stat.Size(),
+		Checksum:     hex.EncodeToString(sum),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.storage.WriteFile(relPath+domain.UploadMetaSuffix, bytes.NewReader(data))
+}
+
+// isUploadMeta имя — сайдкар метаданных загрузки. с выключенной file.write_upload_metadata
+// `.meta.json` обычные файлы.
+func (uc *FileManagementUseCase) isUploadMeta(name string) bool {
+	return uc.cfg.File.WriteUploadMetadata && strings.HasSuffix(name, domain.UploadMetaSuffix)
+}
+
+// Info сведения о файле или папке, как в StatMany. withMeta добавляет сайдкар загрузки, если он есть.
+func (uc *FileManagementUseCase) Info(path string, withMeta bool) (domain.FileData, error) {
+	data, err := uc.statOne(path)
+	if err != nil || !withMeta || data.IsDir {
+		return data, err
+	}
+
+	meta, err := uc.readUploadMeta(data.Path + domain.UploadMetaSuffix)
+	if err != nil {
+		return domain.FileData{}, err
+	}
+	data.UploadMetadata = meta
+	return data, nil
+}
+
+// readUploadMeta nil без ошибки — сайдкара нет (файл загружен до включения флага или положен не загрузкой).
+func (uc *FileManagementUseCase) readUploadMeta(relPath string) (*domain.UploadMetadata, error) {
+	raw, err := uc.readStored(relPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read upload metadata '%s': %w", relPath, err)
+	}
+	var meta domain.UploadMetadata
+	if err = json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("parse upload metadata '%s': %w", relPath, err)
+	}
+	return &meta, nil
+}
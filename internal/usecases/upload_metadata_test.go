@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/config"
+	"file-manager/internal/domain"
+)
+
+func TestUploadMetadataWriter(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t, func(f *config.FileConfig) { f.WriteUploadMetadata = true })
+	writer := NewUploadMetadataWriter(uc.storage)
+	writer.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	WithPostUploadHook(writer)(uc)
+	writeTree(t, tmpDir, map[string]string{"docs/report.txt": "old"})
+
+	stored, err := uc.UploadFile("docs/report.txt", strings.NewReader("hello"),
+		domain.UploadOptions{Conflict: domain.ConflictRename, Uploader: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "docs/report (1).txt", stored)
+
+	data, err := uc.Info(stored, true)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.UploadMetadata{
+		OriginalName: "report.txt",
+		UploadedAt:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Uploader:     "alice",
+		Size:         5,
+		Checksum:     "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}, data.UploadMetadata)
+
+	t.Run("hidden from listing", func(t *testing.T) {
+		files, listErr := uc.List("docs", domain.ListOptions{})
+		require.NoError(t, listErr)
+		names := make([]string, 0, len(files))
+		for _, f := range files {
+			names = append(names, f.Name)
+		}
+		assert.ElementsMatch(t, []string{"report.txt", "report (1).txt"}, names)
+	})
+
+	t.Run("sidecar not reachable by stat", func(t *testing.T) {
+		_, statErr := uc.Info("docs/report (1).txt.meta.json", false)
+		assert.ErrorIs(t, statErr, domain.ErrFileNotFound)
+	})
+
+	t.Run("sidecar name cannot be uploaded", func(t *testing.T) {
+		_, uploadErr := uc.UploadFile("docs/report.txt.meta.json", strings.NewReader("{}"), domain.UploadOptions{})
+		assert.ErrorIs(t, uploadErr, domain.ErrInvalidName)
+	})
+
+	t.Run("without meta flag", func(t *testing.T) {
+		plain, infoErr := uc.Info(stored, false)
+		require.NoError(t, infoErr)
+		assert.Nil(t, plain.UploadMetadata)
+		assert.Equal(t, int64(5), plain.Size)
+	})
+
+	t.Run("file without sidecar", func(t *testing.T) {
+		old, infoErr := uc.Info("docs/report.txt", true)
+		require.NoError(t, infoErr)
+		assert.Nil(t, old.UploadMetadata)
+	})
+}
+
+func TestUploadMetadata_Disabled(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{"a.txt.meta.json": "{}"})
+
+	_, err := uc.UploadFile("b.txt.meta.json", bytes.NewReader([]byte("{}")), domain.UploadOptions{})
+	require.NoError(t, err)
+	files, err := uc.List("", domain.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "without the flag .meta.json are ordinary files")
+}
+
+func TestUploadMetadataWriter_ReadsThroughStorage(t *testing.T) {
+	// на диске ничего нет: размер и хеш должны прийти из хранилища (как у зашифрованного — открытый текст).
+	storage := newMapStorage(t, fstest.MapFS{"a.txt": {Data: []byte("hello")}})
+	var sidecar []byte
+	storage.writeFileFunc = func(relPath string, file io.Reader) error {
+		assert.Equal(t, "a.txt"+domain.UploadMetaSuffix, relPath)
+		var err error
+		sidecar, err = io.ReadAll(file)
+		return err
+	}
+	writer := NewUploadMetadataWriter(storage)
+
+	require.NoError(t, writer.write("a.txt", domain.UploadInfo{OriginalName: "a.txt"}))
+
+	var meta domain.UploadMetadata
+	require.NoError(t, json.Unmarshal(sidecar, &meta))
+	assert.Equal(t, int64(5), meta.Size)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", meta.Checksum)
+}
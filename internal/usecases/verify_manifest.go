@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"file-manager/internal/domain"
+)
+
+// MaxManifestEntries сколько файлов можно сверить за один вызов VerifyManifest.
+const MaxManifestEntries = 100000
+
+// VerifyManifest сверяет папку root с ранее снятым манифестом (пути и sha256) для проверки бэкапа.
+// расхождения отдаются в onDiff по мере обхода, итог — в отчёте. файлы, которые не попадают в zip
+// (скрытые, drop-box), не считаются лишними. проверка тяжёлая, поэтому прерывается по ctx.
+func (uc *FileManagementUseCase) VerifyManifest(
+	ctx context.Context,
+	root string,
+	manifest []domain.ManifestEntry,
+	onDiff func(domain.ManifestDiff) error,
+) (domain.ManifestReport, error) {
+	var report domain.ManifestReport
+
+	expected, err := manifestChecksums(manifest)
+	if err != nil {
+		return report, err
+	}
+
+	sanitizedPath, err := uc.sanitizePath(root)
+	if err != nil {
+		return report, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return report, fmt.Errorf("verify drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return report, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	emit := func(p, status string) error {
+		switch status {
+		case domain.ManifestMissing:
+			report.Missing++
+		case domain.ManifestChanged:
+			report.Changed++
+		case domain.ManifestExtra:
+			report.Extra++
+		}
+		return onDiff(domain.ManifestDiff{Path: p, Status: status})
+	}
+
+	err = uc.walkArchive(sanitizedPath, func(file, rel string, _ os.FileInfo) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		rel = filepath.ToSlash(rel)
+		want, listed := expected[rel]
+		if !listed {
+			return emit(rel, domain.ManifestExtra)
+		}
+		delete(expected, rel)
+		report.Checked++
+
+		sum, hashErr := uc.hashStored(file)
+		if hashErr != nil {
+			return fmt.Errorf("hash '%s': %w", rel, hashErr)
+		}
+		if hex.EncodeToString(sum) != want {
+			return emit(rel, domain.ManifestChanged)
+		}
+		report.Matched++
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to verify '%s': %w", sanitizedPath, err)
+	}
+
+	// отсутствующие в порядке путей, чтобы отчёт был одинаковым от запуска к запуску.
+	for _, p := range slices.Sorted(maps.Keys(expected)) {
+		report.Checked++
+		if err = emit(p, domain.ManifestMissing); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// manifestChecksums путь -> hex sha256 в нижнем регистре. путь выше корня или дубль — ошибка манифеста.
+func manifestChecksums(manifest []domain.ManifestEntry) (map[string]string, error) {
+	if len(manifest) > MaxManifestEntries {
+		return nil, fmt.Errorf("%d manifest entries, at most %d allowed: %w",
+			len(manifest), MaxManifestEntries, domain.ErrInvalidParameter)
+	}
+
+	expected := make(map[string]string, len(manifest))
+	for _, e := range manifest {
+		p := path.Clean(strings.TrimPrefix(filepath.ToSlash(e.Path), "/"))
+		if p == domain.PathCurrent || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("manifest path '%s' is outside the root: %w", e.Path, domain.ErrInvalidParameter)
+		}
+		sum, err := hex.DecodeString(e.Checksum)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("bad checksum for '%s': %w", e.Path, domain.ErrInvalidParameter)
+		}
+		if _, dup := expected[p]; dup {
+			return nil, fmt.Errorf("duplicate manifest path '%s': %w", e.Path, domain.ErrInvalidParameter)
+		}
+		expected[p] = hex.EncodeToString(sum)
+	}
+	return expected, nil
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFileManagementUseCase_VerifyManifest(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"backup/same.txt":     "same",
+		"backup/sub/edit.txt": "edited",
+		"backup/new.txt":      "new",
+		"backup/.hidden":      "ignored",
+	})
+
+	manifest := []domain.ManifestEntry{
+		{Path: "same.txt", Checksum: sha256Hex("same")},
+		{Path: "sub/edit.txt", Checksum: sha256Hex("original")},
+		{Path: "gone/b.txt", Checksum: sha256Hex("b")},
+		{Path: "/gone/a.txt", Checksum: strings.ToUpper(sha256Hex("a"))},
+	}
+
+	var diffs []domain.ManifestDiff
+	report, err := uc.VerifyManifest(context.Background(), "backup", manifest, func(d domain.ManifestDiff) error {
+		diffs = append(diffs, d)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ManifestReport{Checked: 4, Matched: 1, Missing: 2, Changed: 1, Extra: 1}, report)
+	assert.ElementsMatch(t, []domain.ManifestDiff{
+		{Path: "sub/edit.txt", Status: domain.ManifestChanged},
+		{Path: "new.txt", Status: domain.ManifestExtra},
+	}, diffs[:2])
+	assert.Equal(t, []domain.ManifestDiff{
+		{Path: "gone/a.txt", Status: domain.ManifestMissing},
+		{Path: "gone/b.txt", Status: domain.ManifestMissing},
+	}, diffs[2:], "missing files reported in path order")
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := uc.VerifyManifest(ctx, "backup", manifest, func(domain.ManifestDiff) error { return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("callback error stops the walk", func(t *testing.T) {
+		stop := errors.New("client gone")
+		_, err := uc.VerifyManifest(context.Background(), "backup", nil,
+			func(domain.ManifestDiff) error { return stop })
+		assert.ErrorIs(t, err, stop)
+	})
+
+	tests := []struct {
+		name     string
+		root     string
+		manifest []domain.ManifestEntry
+		wantErr  error
+	}{
+		{"missing root", "nope", nil, domain.ErrFileNotFound},
+		{"file as root", "backup/same.txt", nil, domain.ErrFileNotFound},
+		{"path traversal", "../etc", nil, domain.ErrPathTraversal},
+		{"bad checksum", "backup", []domain.ManifestEntry{{Path: "a", Checksum: "xyz"}}, domain.ErrInvalidParameter},
+		{
+			"entry outside root", "backup",
+			[]domain.ManifestEntry{{Path: "../a", Checksum: sha256Hex("a")}}, domain.ErrInvalidParameter,
+		},
+		{
+			"duplicate entry", "backup",
+			[]domain.ManifestEntry{{Path: "a", Checksum: sha256Hex("a")}, {Path: "./a", Checksum: sha256Hex("a")}},
+			domain.ErrInvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.VerifyManifest(context.Background(), tt.root, tt.manifest,
+				func(domain.ManifestDiff) error { return nil })
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errWalkLimit останавливает обход по file.max_walk_entries. наружу не уходит: guardedWalk
+// превращает его в truncated, ответ остаётся успешным, просто неполным.
+var errWalkLimit = errors.New("walk limit reached")
+
+// guardedWalk walkArchiveEntries с лимитами file.max_walk_depth и file.max_walk_entries (0 — без лимита).
+// записи глубже лимита пропускаются (в папки ниже обход не спускается), после лимита записей обход обрывается.
+func (uc *FileManagementUseCase) guardedWalk(
+	relRoot string,
+	fn func(file, rel string, info os.FileInfo) error,
+) (truncated bool, err error) {
+	maxDepth, maxEntries := uc.cfg.File.MaxWalkDepth, uc.cfg.File.MaxWalkEntries
+	entries := 0
+	err = uc.walkArchiveEntries(relRoot, func(file, rel string, info os.FileInfo) error {
+		if maxDepth > 0 && strings.Count(filepath.ToSlash(rel), "/")+1 > maxDepth {
+			truncated = true
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if maxEntries > 0 && entries > maxEntries {
+			truncated = true
+			return errWalkLimit
+		}
+		return fn(file, rel, info)
+	})
+	if errors.Is(err, errWalkLimit) {
+		return true, nil
+	}
+	return truncated, err
+}
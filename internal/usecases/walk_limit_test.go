@@ -0,0 +1,107 @@
+package usecases
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManagementUseCase_GuardedWalk(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"a.txt":          "a",
+		"b.txt":          "b",
+		"d1/c.txt":       "c",
+		"d1/d2/d.txt":    "d",
+		"d1/d2/d3/e.txt": "e",
+	})
+
+	all := []string{"a.txt", "b.txt", "d1", "d1/c.txt", "d1/d2", "d1/d2/d.txt", "d1/d2/d3", "d1/d2/d3/e.txt"}
+
+	tests := []struct {
+		name          string
+		maxDepth      int
+		maxEntries    int
+		want          []string
+		wantTruncated bool
+	}{
+		{
+			name: "no limits",
+			want: all,
+		},
+		{
+			name:          "depth",
+			maxDepth:      2,
+			want:          []string{"a.txt", "b.txt", "d1", "d1/c.txt", "d1/d2"},
+			wantTruncated: true,
+		},
+		{
+			name:     "depth exactly fits",
+			maxDepth: 4,
+			want:     all,
+		},
+		{
+			name:          "entries",
+			maxEntries:    3,
+			want:          []string{"a.txt", "b.txt", "d1"},
+			wantTruncated: true,
+		},
+		{
+			name:       "entries exactly fit",
+			maxEntries: 8,
+			want:       all,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.MaxWalkDepth = tt.maxDepth
+			uc.cfg.File.MaxWalkEntries = tt.maxEntries
+
+			var got []string
+			truncated, err := uc.guardedWalk("", func(_, rel string, _ os.FileInfo) error {
+				got = append(got, filepath.ToSlash(rel))
+				return nil
+			})
+
+			require.NoError(t, err)
+			sort.Strings(got)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantTruncated, truncated)
+		})
+	}
+}
+
+func TestFileManagementUseCase_WalkLimits_Truncated(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"a.txt":        "same",
+		"deep/b.txt":   "same",
+		"deep/x/c.txt": "same",
+	})
+	uc.cfg.File.MaxWalkDepth = 2
+
+	tree, err := uc.Tree("", false)
+	require.NoError(t, err)
+	assert.True(t, tree.Truncated)
+
+	stats, err := uc.Stats("", true)
+	require.NoError(t, err)
+	assert.True(t, stats.Truncated)
+	assert.Equal(t, int64(2), stats.Files)
+
+	groups, truncated, err := uc.FindDuplicates(context.Background(), "")
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, map[string][]string{sha256Hex("same"): {"a.txt", "deep/b.txt"}}, groups)
+
+	uc.cfg.File.MaxWalkDepth = 0
+	tree, err = uc.Tree("", false)
+	require.NoError(t, err)
+	assert.False(t, tree.Truncated)
+}
@@ -0,0 +1,303 @@
+package usecases
+
+import (
+	"archive/zip"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	zipCacheDirName   = "file-manager-zip-cache"
+	zipCacheTmpPrefix = "building-"
+)
+
+// zipCache кеш готовых zip-архивов папок на диске с вытеснением по LRU.
+// ключ — сигнатура содержимого папки, так что изменённая папка просто получает новый ключ,
+// а старый архив со временем вытесняется.
+type zipCache struct {
+	dir      string
+	maxBytes int64
+
+	once    sync.Once
+	initErr error
+
+	mu      sync.Mutex
+	total   int64
+	lru     *list.List // front — самый свежий
+	entries map[string]*list.Element
+}
+
+type zipCacheEntry struct {
+	key  string
+	size int64
+}
+
+func newZipCache(dir string, maxBytes int64) *zipCache {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), zipCacheDirName)
+	}
+	return &zipCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// init лениво создаёт директорию и подхватывает архивы, оставшиеся с прошлого запуска.
+func (c *zipCache) init() error {
+	c.once.Do(func() {
+		if err := os.MkdirAll(c.dir, 0o700); err != nil {
+			c.initErr = fmt.Errorf("failed to create zip cache dir: %w", err)
+			return
+		}
+
+		dirEntries, err := os.ReadDir(c.dir)
+		if err != nil {
+			c.initErr = fmt.Errorf("failed to read zip cache dir: %w", err)
+			return
+		}
+
+		type found struct {
+			key  string
+			info os.FileInfo
+		}
+		var existing []found
+		for _, e := range dirEntries {
+			name := e.Name()
+			if strings.HasPrefix(name, zipCacheTmpPrefix) {
+				// недописанный архив от упавшего процесса.
+				_ = os.Remove(filepath.Join(c.dir, name))
+				continue
+			}
+			info, infoErr := e.Info()
+			if infoErr != nil || !strings.HasSuffix(name, ".zip") {
+				continue
+			}
+			existing = append(existing, found{key: strings.TrimSuffix(name, ".zip"), info: info})
+		}
+
+		// старые в хвост LRU, свежие в голову.
+		sort.Slice(existing, func(i, j int) bool {
+			return existing[i].info.ModTime().Before(existing[j].info.ModTime())
+		})
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, f := range existing {
+			c.entries[f.key] = c.lru.PushFront(&zipCacheEntry{key: f.key, size: f.info.Size()})
+			c.total += f.info.Size()
+		}
+		c.evictLocked()
+	})
+	return c.initErr
+}
+
+func (c *zipCache) path(key string) string {
+	return filepath.Join(c.dir, key+".zip")
+}
+
+// get возвращает путь к архиву и помечает его как недавно использованный.
+func (c *zipCache) get(key string) (string, bool) {
+	if c.init() != nil {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if _, err := os.Stat(c.path(key)); err != nil {
+		// файл удалили снаружи, забываем запись.
+		c.removeLocked(elem)
+		return "", false
+	}
+	c.lru.MoveToFront(elem)
+	return c.path(key), true
+}
+
+// build собирает архив во временный файл и атомарно публикует его под ключом.
+// архив больше лимита кеша не сохраняется, вызывающий получает временный путь и должен удалить его сам (cached=false).
+func (c *zipCache) build(key string, fill func(*zip.Writer) error) (string, bool, error) {
+	if err := c.init(); err != nil {
+		return "", false, err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, zipCacheTmpPrefix+"*.zip")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp zip: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	zipWriter := zip.NewWriter(tmp)
+	fillErr := fill(zipWriter)
+	closeErr := zipWriter.Close()
+	fileCloseErr := tmp.Close()
+	if fillErr != nil || closeErr != nil || fileCloseErr != nil {
+		_ = os.Remove(tmpPath)
+		if fillErr != nil {
+			return "", false, fillErr
+		}
+		if closeErr != nil {
+			return "", false, fmt.Errorf("failed to close zip writer: %w", closeErr)
+		}
+		return "", false, fmt.Errorf("failed to close temp zip: %w", fileCloseErr)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to stat temp zip: %w", err)
+	}
+	if info.Size() > c.maxBytes {
+		return tmpPath, false, nil
+	}
+
+	if err := os.Rename(tmpPath, c.path(key)); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to publish cached zip: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		// параллельный запрос успел собрать тот же архив, rename просто перезаписал файл.
+		c.total -= elem.Value.(*zipCacheEntry).size
+		elem.Value.(*zipCacheEntry).size = info.Size()
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.lru.PushFront(&zipCacheEntry{key: key, size: info.Size()})
+	}
+	c.total += info.Size()
+	c.evictLocked()
+
+	return c.path(key), true, nil
+}
+
+// evictLocked удаляет самые давние архивы, пока кеш не влезет в лимит.
+// уже открытые на отдачу файлы на unix дочитываются и после удаления.
+func (c *zipCache) evictLocked() {
+	for c.total > c.maxBytes {
+		oldest := c.lru.Back()
+		if oldest == nil {
+			return
+		}
+		entry := oldest.Value.(*zipCacheEntry)
+		if err := os.Remove(c.path(entry.key)); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Failed to evict cached zip %s: %v", entry.key, err)
+		}
+		c.removeLocked(oldest)
+	}
+}
+
+// clear выбрасывает все архивы, следующие скачивания соберут их заново. возвращает, сколько удалено.
+func (c *zipCache) clear() (int, error) {
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for c.lru.Len() > 0 {
+		oldest := c.lru.Back()
+		entry := oldest.Value.(*zipCacheEntry)
+		if err := os.Remove(c.path(entry.key)); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove cached zip %s: %w", entry.key, err)
+		}
+		c.removeLocked(oldest)
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *zipCache) removeLocked(elem *list.Element) {
+	entry := elem.Value.(*zipCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	c.total -= entry.size
+}
+
+// folderSignature дешёвая сигнатура содержимого папки: пути, размеры и время изменения
+// всех файлов, которые попали бы в архив. содержимое не читается, так что сигнатура
+// считается быстро даже для больших папок. директории тоже учитываются — пустая папка
+// может оказаться в архиве (file.zip_include_empty_dirs).
+func (uc *FileManagementUseCase) folderSignature(relRoot string) (string, error) {
+	sig := sha256.New()
+	fmt.Fprintf(sig, "%s\x00%t\x00", filepath.ToSlash(relRoot), uc.cfg.File.ZipIncludeEmptyDirs)
+	err := uc.walkArchiveEntries(relRoot, func(file, rel string, info os.FileInfo) error {
+		if info.IsDir() {
+			fmt.Fprintf(sig, "d %s\x00", filepath.ToSlash(rel))
+			return nil
+		}
+		fmt.Fprintf(sig, "%s\x00%d\x00%d\x00", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sig.Sum(nil)), nil
+}
+
+// serveCachedZip отдаёт архив из кеша через http.ServeContent, поэтому работают Range и If-Range:
+// оборвавшуюся загрузку неизменённой папки можно докачать.
+func (uc *FileManagementUseCase) serveCachedZip(
+	w http.ResponseWriter,
+	r *http.Request,
+	relRoot, zipName string,
+) error {
+	key, err := uc.folderSignature(relRoot)
+	if err != nil {
+		return fmt.Errorf("failed to compute signature for folder '%s': %w", relRoot, err)
+	}
+
+	zipFile, err := uc.openCachedZip(key, relRoot)
+	if err != nil {
+		return fmt.Errorf("failed to create zip for folder '%s': %w", relRoot, err)
+	}
+	defer closeLogged(zipFile, zipFile.Name())
+
+	info, err := zipFile.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat cached zip: %w", err)
+	}
+
+	w.Header().Set("ETag", `"`+key+`"`)
+	http.ServeContent(w, r, zipName, info.ModTime(), zipFile)
+	return nil
+}
+
+func (uc *FileManagementUseCase) openCachedZip(key, relRoot string) (*os.File, error) {
+	if cached, ok := uc.zipCache.get(key); ok {
+		// между get и Open архив могли вытеснить, тогда просто собираем заново.
+		if f, err := os.Open(cached); err == nil {
+			return f, nil
+		}
+	}
+
+	built, cached, err := uc.zipCache.build(key, func(zipWriter *zip.Writer) error {
+		return uc.createZipArchive(zipWriter, relRoot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(built)
+	if !cached {
+		// архив больше лимита кеша: отдаём один раз, на unix открытый файл читается и после удаления.
+		_ = os.Remove(built)
+	}
+	return f, err
+}
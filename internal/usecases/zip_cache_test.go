@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fillZip(content string) func(*zip.Writer) error {
+	return func(zw *zip.Writer) error {
+		w, err := zw.Create("file.txt")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(content))
+		return err
+	}
+}
+
+func TestZipCache_LRU(t *testing.T) {
+	dir := t.TempDir()
+	cache := newZipCache(dir, 1)
+
+	// лимит подбираю по размеру одного архива, чтобы влезало ровно два.
+	probe, cached, err := newZipCache(t.TempDir(), 1<<20).build("probe", fillZip("aaaa"))
+	require.NoError(t, err)
+	require.True(t, cached)
+	info, err := os.Stat(probe)
+	require.NoError(t, err)
+	cache.maxBytes = info.Size() * 2
+
+	_, cached, err = cache.build("one", fillZip("aaaa"))
+	require.NoError(t, err)
+	require.True(t, cached)
+	_, cached, err = cache.build("two", fillZip("bbbb"))
+	require.NoError(t, err)
+	require.True(t, cached)
+
+	// "one" становится свежим, вытеснен будет "two".
+	_, ok := cache.get("one")
+	require.True(t, ok)
+	_, _, err = cache.build("three", fillZip("cccc"))
+	require.NoError(t, err)
+
+	_, ok = cache.get("two")
+	assert.False(t, ok, "least recently used entry must be evicted")
+	assert.NoFileExists(t, filepath.Join(dir, "two.zip"))
+	_, ok = cache.get("one")
+	assert.True(t, ok)
+	_, ok = cache.get("three")
+	assert.True(t, ok)
+	assert.LessOrEqual(t, cache.total, cache.maxBytes)
+}
+
+func TestZipCache_OversizedNotCached(t *testing.T) {
+	dir := t.TempDir()
+	cache := newZipCache(dir, 10)
+
+	built, cached, err := cache.build("big", fillZip(strings.Repeat("x", 1000)))
+
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.FileExists(t, built)
+	_, ok := cache.get("big")
+	assert.False(t, ok)
+}
+
+func TestZipCache_PicksUpExistingArchives(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.zip"), []byte("zip"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, zipCacheTmpPrefix+"123.zip"), []byte("partial"), 0o600))
+	cache := newZipCache(dir, 1<<20)
+
+	path, ok := cache.get("old")
+
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "old.zip"), path)
+	assert.NoFileExists(t, filepath.Join(dir, zipCacheTmpPrefix+"123.zip"))
+}
+
+func TestFileManagementUseCase_ServeFolderAsZip_Cached(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.zipCache = newZipCache(t.TempDir(), 1<<20)
+	writeTree(t, tmpDir, map[string]string{
+		"photos/a.txt":     strings.Repeat("a", 500),
+		"photos/sub/b.txt": "b",
+	})
+
+	serve := func(rangeHeader, ifRange string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/download-folder?path=photos", nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		if ifRange != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+		w := httptest.NewRecorder()
+		require.NoError(t, uc.ServeFolderAsZip(w, req, "photos"))
+		return w
+	}
+
+	full := serve("", "")
+	require.Equal(t, http.StatusOK, full.Code)
+	etag := full.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "bytes", full.Header().Get("Accept-Ranges"))
+
+	t.Run("resume unchanged folder", func(t *testing.T) {
+		partial := serve("bytes=10-", etag)
+
+		assert.Equal(t, http.StatusPartialContent, partial.Code)
+		assert.Equal(t, etag, partial.Header().Get("ETag"))
+		assert.Equal(t, full.Body.Bytes()[10:], partial.Body.Bytes())
+	})
+
+	t.Run("changed folder regenerates", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		changed := filepath.Join(tmpDir, "photos/sub/b.txt")
+		require.NoError(t, os.WriteFile(changed, []byte("bb"), 0o644))
+		require.NoError(t, os.Chtimes(changed, later, later))
+
+		partial := serve("bytes=10-", etag)
+
+		assert.Equal(t, http.StatusOK, partial.Code, "stale If-Range must get the full new archive")
+		assert.NotEqual(t, etag, partial.Header().Get("ETag"))
+	})
+}
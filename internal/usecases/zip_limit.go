@@ -0,0 +1,66 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"file-manager/internal/domain"
+)
+
+// errZipScanStop прерывает предварительный обход, как только ответ уже известен.
+var errZipScanStop = errors.New("zip scan stopped")
+
+// checkZipLimits быстро проходит папку перед сборкой zip и отказывает, если архив выйдет больше
+// file.max_zip_source_bytes или в нём будет больше file.max_zip_entries записей (огромный central directory).
+// оценка размера ограничена max_zip_scan_files: папку, где файлов больше, считаем слишком большой,
+// чтобы сама проверка не стала дорогой.
+func (uc *FileManagementUseCase) checkZipLimits(relRoot string) error {
+	maxBytes, maxEntries := uc.cfg.File.MaxZipSourceBytes, uc.cfg.File.MaxZipEntries
+	if maxBytes <= 0 && maxEntries <= 0 {
+		return nil
+	}
+
+	var total int64
+	var files, entries int
+	var reason string
+	checkEntries := func() error {
+		entries++
+		if maxEntries > 0 && entries > maxEntries {
+			reason = fmt.Sprintf("more than %d zip entries", maxEntries)
+			return errZipScanStop
+		}
+		return nil
+	}
+
+	err := uc.walkZipEntries(relRoot,
+		func(_, _ string, info os.FileInfo) error {
+			if entriesErr := checkEntries(); entriesErr != nil {
+				return entriesErr
+			}
+			if maxBytes <= 0 {
+				return nil
+			}
+			files++
+			total += info.Size()
+			switch {
+			case total > maxBytes:
+				reason = fmt.Sprintf("more than %d bytes", maxBytes)
+			case uc.cfg.File.MaxZipScanFiles > 0 && files > uc.cfg.File.MaxZipScanFiles:
+				reason = fmt.Sprintf("more than %d files", uc.cfg.File.MaxZipScanFiles)
+			default:
+				return nil
+			}
+			return errZipScanStop
+		},
+		func(string) error { return checkEntries() },
+	)
+
+	if errors.Is(err, errZipScanStop) {
+		return fmt.Errorf("folder '%s' has %s: %w", relRoot, reason, domain.ErrArchiveTooLarge)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to measure folder '%s': %w", relRoot, err)
+	}
+	return nil
+}
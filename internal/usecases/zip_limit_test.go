@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_ServeFolderAsZip_SizeLimit(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	writeTree(t, tmpDir, map[string]string{
+		"big/a.bin":       "0123456789",
+		"big/sub/b.bin":   "0123456789",
+		"big/.hidden.bin": "not counted, not zipped",
+		"small/a.txt":     "abc",
+		"many/1.txt":      "1",
+		"many/2.txt":      "2",
+		"many/3.txt":      "3",
+	})
+	uc.cfg.File.MaxZipSourceBytes = 15
+	uc.cfg.File.MaxZipScanFiles = 2
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"over threshold", "big", domain.ErrArchiveTooLarge},
+		{"under threshold", "small", nil},
+		{"too many files to scan", "many", domain.ErrArchiveTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			err := uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), tt.path)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				assert.NotZero(t, w.Body.Len())
+				return
+			}
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			assert.Empty(t, w.Header().Get("Content-Disposition"), "refused before headers are sent")
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		uc.cfg.File.MaxZipSourceBytes = 0
+		w := httptest.NewRecorder()
+		assert.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), "big"))
+	})
+}
+
+func TestFileManagementUseCase_ServeFolderAsZip_EntryLimit(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	tree := make(map[string]string)
+	for i := range 50 {
+		tree[fmt.Sprintf("tiny/f%02d.txt", i)] = "x"
+	}
+	writeTree(t, tmpDir, tree)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "tiny", "empty"), 0o755))
+
+	serve := func() error {
+		w := httptest.NewRecorder()
+		return uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), "tiny")
+	}
+
+	tests := []struct {
+		name         string
+		maxEntries   int
+		includeEmpty bool
+		wantErr      bool
+	}{
+		{"disabled", 0, false, false},
+		{"exactly at limit", 50, false, false},
+		{"one over limit", 49, false, true},
+		{"empty dir entries count", 50, true, true},
+		{"empty dir entries at limit", 51, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc.cfg.File.MaxZipEntries = tt.maxEntries
+			uc.cfg.File.ZipIncludeEmptyDirs = tt.includeEmpty
+
+			err := serve()
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, domain.ErrArchiveTooLarge), "got %v", err)
+			assert.Contains(t, err.Error(), "zip entries")
+		})
+	}
+}
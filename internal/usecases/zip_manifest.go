@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"file-manager/internal/domain"
+)
+
+// ZipManifest список записей, которые были бы в zip папки, без сжатия и чтения содержимого.
+// размеры несжатые, так что их сумма — верхняя оценка размера архива.
+func (uc *FileManagementUseCase) ZipManifest(path string) ([]domain.ZipEntry, error) {
+	sanitizedPath, err := uc.sanitizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if uc.isDropBox(sanitizedPath) {
+		return nil, fmt.Errorf("manifest of drop-box '%s': %w", sanitizedPath, domain.ErrPermissionDenied)
+	}
+
+	info, statErr := uc.storage.Stat(sanitizedPath)
+	if statErr != nil || !info.IsDir() {
+		return nil, fmt.Errorf("could not stat folder '%s': %w", sanitizedPath, domain.ErrFileNotFound)
+	}
+
+	entries := make([]domain.ZipEntry, 0)
+	err = uc.walkZipEntries(sanitizedPath,
+		func(_, rel string, info os.FileInfo) error {
+			entries = append(entries, domain.ZipEntry{Name: filepath.ToSlash(rel), Size: info.Size()})
+			return nil
+		},
+		func(dirEntry string) error {
+			entries = append(entries, domain.ZipEntry{Name: dirEntry})
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build manifest for '%s': %w", sanitizedPath, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,78 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"file-manager/internal/domain"
+)
+
+func TestFileManagementUseCase_ZipManifest(t *testing.T) {
+	uc, tmpDir := newLocalUseCase(t)
+	uc.cfg.File.DropBoxDirs = []string{"photos/inbox"}
+	writeTree(t, tmpDir, map[string]string{
+		"photos/a.jpg":         "aaaa",
+		"photos/sub/b.jpg":     "bb",
+		"photos/.thumbs/a.jpg": "hidden",
+		"photos/inbox/x.jpg":   "dropbox",
+		"file.txt":             "x",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "photos", "empty"), 0o755))
+
+	t.Run("matches archive contents", func(t *testing.T) {
+		for _, includeEmpty := range []bool{false, true} {
+			uc.cfg.File.ZipIncludeEmptyDirs = includeEmpty
+
+			manifest, err := uc.ZipManifest("photos")
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			require.NoError(t, uc.ServeFolderAsZip(w, httptest.NewRequest("GET", "/", nil), "photos"))
+			body := w.Body.Bytes()
+			zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+			require.NoError(t, err)
+
+			var fromZip []domain.ZipEntry
+			for _, f := range zr.File {
+				fromZip = append(fromZip, domain.ZipEntry{Name: f.Name, Size: int64(f.UncompressedSize64)})
+			}
+			assert.ElementsMatch(t, fromZip, manifest, "include empty dirs: %v", includeEmpty)
+		}
+	})
+
+	t.Run("sizes", func(t *testing.T) {
+		uc.cfg.File.ZipIncludeEmptyDirs = true
+		manifest, err := uc.ZipManifest("photos")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []domain.ZipEntry{
+			{Name: "a.jpg", Size: 4},
+			{Name: "sub/b.jpg", Size: 2},
+			{Name: "empty/"},
+		}, manifest)
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{"file instead of folder", "file.txt", domain.ErrFileNotFound},
+		{"missing folder", "nope", domain.ErrFileNotFound},
+		{"drop-box", "photos/inbox", domain.ErrPermissionDenied},
+		{"path traversal", "../x", domain.ErrPathTraversal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uc.ZipManifest(tt.path)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package usecases
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"file-manager/internal/domain"
+)
+
+// zipFileName имя zip папки relPath по file.zip_name, `?zip_name=full|base` перекрывает full_path.
+// для папок с одинаковыми именами из разных мест, которые иначе сталкиваются в Загрузках.
+func (uc *FileManagementUseCase) zipFileName(r *http.Request, relPath string) string {
+	full := uc.cfg.File.ZipName.FullPath
+	switch r.URL.Query().Get(domain.QueryParamZipName) {
+	case domain.ZipNameFull:
+		full = true
+	case domain.ZipNameBase:
+		full = false
+	}
+	return uc.archiveName(relPath, full) + domain.ExtensionZip
+}
+
+// archiveName имя архива папки без расширения, общее для zip и tar.gz.
+func (uc *FileManagementUseCase) archiveName(relPath string, full bool) string {
+	cfg := uc.cfg.File.ZipName
+	name := filepath.Base(relPath)
+	if full && relPath != domain.PathCurrent {
+		name = strings.ReplaceAll(filepath.ToSlash(relPath), domain.PathRoot, "_")
+	}
+	name = cfg.Prefix + name + cfg.Suffix
+	if cfg.Timestamp != "" {
+		name += "-" + time.Now().Format(cfg.Timestamp)
+	}
+	return headerFilename(name)
+}
+
+// headerFilename убирает из имени то, что ломает кавычки Content-Disposition или путь на стороне клиента:
+// кавычки, слеши, управляющие символы (префикс и layout приходят из конфига, их никто не проверял).
+func headerFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '"', r == '\\', r == '/', r == ':', unicode.IsControl(r):
+			return '_'
+		default:
+			return r
+		}
+	}, name)
+}
@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"file-manager/internal/config"
+)
+
+func TestFileManagementUseCase_zipFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ZipNameConfig
+		url  string
+		path string
+		want string
+	}{
+		{name: "default base name", path: "projects/alpha", want: "alpha.zip"},
+		{
+			name: "full path", cfg: config.ZipNameConfig{FullPath: true},
+			path: "projects/alpha", want: "projects_alpha.zip",
+		},
+		{name: "query asks full", url: "?zip_name=full", path: "projects/alpha", want: "projects_alpha.zip"},
+		{
+			name: "query overrides config", cfg: config.ZipNameConfig{FullPath: true}, url: "?zip_name=base",
+			path: "projects/alpha", want: "alpha.zip",
+		},
+		{
+			name: "prefix and suffix", cfg: config.ZipNameConfig{Prefix: "backup-", Suffix: "-v2"},
+			path: "projects/alpha", want: "backup-alpha-v2.zip",
+		},
+		{
+			name: "unsafe config sanitized", cfg: config.ZipNameConfig{Prefix: "a\"b/c:\n"},
+			path: "alpha", want: "a_b_c__alpha.zip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _ := newLocalUseCase(t)
+			uc.cfg.File.ZipName = tt.cfg
+
+			got := uc.zipFileName(httptest.NewRequest("GET", "/download-folder"+tt.url, nil), tt.path)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("timestamp", func(t *testing.T) {
+		uc, _ := newLocalUseCase(t)
+		uc.cfg.File.ZipName = config.ZipNameConfig{Timestamp: "20060102"}
+
+		got := uc.zipFileName(httptest.NewRequest("GET", "/download-folder", nil), "projects/alpha")
+
+		assert.Regexp(t, regexp.MustCompile(`^alpha-\d{8}\.zip$`), got)
+	})
+}